@@ -2,16 +2,26 @@ package main
 
 import (
 	"context"
+	"fmt"
 	"log/slog"
 	"os"
 	"os/signal"
 	"path/filepath"
 	"syscall"
 	"time"
+	// Встроенная база часовых поясов: в минимальных образах ее нет
+	_ "time/tzdata"
 
+	"github.com/reelser-bot/internal/buildinfo"
 	"github.com/reelser-bot/internal/config"
 	"github.com/reelser-bot/internal/services/auth"
+	"github.com/reelser-bot/internal/services/delivery"
 	"github.com/reelser-bot/internal/services/downloader"
+	"github.com/reelser-bot/internal/services/janitor"
+	"github.com/reelser-bot/internal/services/pressure"
+	"github.com/reelser-bot/internal/services/probe"
+	"github.com/reelser-bot/internal/services/ytupdate"
+	"github.com/reelser-bot/internal/storage/redis"
 	"github.com/reelser-bot/internal/transport/telegram"
 )
 
@@ -19,7 +29,13 @@ func main() {
 	// Инициализация логгера
 	logger := initLogger()
 
-	logger.Info("Starting application...")
+	build := buildinfo.Get()
+	logger.Info("Starting application...",
+		slog.String("version", build.Version),
+		slog.String("commit", build.Commit),
+		slog.String("build_date", build.Date),
+		slog.String("go_version", build.GoVersion),
+	)
 
 	// Загрузка конфигурации
 	cfg, err := config.Load()
@@ -30,48 +46,57 @@ func main() {
 
 	logger.Info("Configuration loaded successfully")
 
-	// Создание временной директории
-	if err := os.MkdirAll(cfg.Download.TempDir, 0755); err != nil {
-		logger.Error("Failed to create temp directory",
-			slog.String("dir", cfg.Download.TempDir),
-			slog.Any("error", err),
-		)
-		os.Exit(1)
-	}
+	ytdlpUpdater := setupYtDlp(logger, cfg)
 
-	// Получаем абсолютный путь к временной директории
-	absTempDir, err := filepath.Abs(cfg.Download.TempDir)
-	if err != nil {
-		logger.Error("Failed to get absolute temp dir path", slog.Any("error", err))
+	// Внешние утилиты проверяются до запуска, а не при первой загрузке
+	if err := checkBinaries(logger, cfg); err != nil {
+		logger.Error("Required binary is missing", slog.Any("error", err))
 		os.Exit(1)
 	}
-	cfg.Download.TempDir = absTempDir
 
-	logger.Info("Temp directory created", slog.String("dir", cfg.Download.TempDir))
+	prepareTempDirs(logger, cfg)
 
 	// Создание сервиса авторизации
 	authService := auth.NewService(logger, cfg.Auth)
+	sharedCache := shareState(logger, cfg, authService)
+
+	// Связка ключей для зашифрованных файлов с секретами (проверена при загрузке конфигурации)
+	keyring, err := cfg.Storage.Keyring()
+	if err != nil {
+		logger.Error("Invalid storage encryption key", slog.Any("error", err))
+		os.Exit(1)
+	}
 
 	// Создание сервиса загрузки
-	downloadService := downloader.NewService(
-		logger,
-		cfg.Download.TempDir,
-		cfg.Download.VideoQuality,
-	)
+	downloadService := downloader.NewService(logger, cfg.Download, downloader.WithKeyring(keyring))
+
+	// Хранилище незавершенных отправок
+	deliveryStore := delivery.NewStore(logger, filepath.Join(cfg.Storage.DataDir, "deliveries.json"))
+
+	tempJanitor := startJanitor(logger, cfg, deliveryStore)
+
+	// Фоновые задачи: очистка временных каталогов, проверка маршрутов исходящего трафика
+	// и обновление yt-dlp
+	backgroundCtx, stopBackground := context.WithCancel(context.Background())
+	go tempJanitor.Run(backgroundCtx)
+	go downloadService.RunEgressChecks(backgroundCtx)
+	go ytdlpUpdater.Run(backgroundCtx)
 
 	// Создание бота
 	bot, err := telegram.NewBot(
-		cfg.Telegram.BotToken,
+		cfg,
 		logger,
 		downloadService,
 		authService,
-		cfg.Download.MaxVideoSizeMB,
-		cfg.Download.WorkerPoolSize,
+		deliveryStore,
 	)
 	if err != nil {
 		logger.Error("Failed to create bot", slog.Any("error", err))
 		os.Exit(1)
 	}
+	shareSettings(backgroundCtx, logger, bot, sharedCache)
+
+	watchPressure(backgroundCtx, logger, cfg, bot)
 
 	// Обработка сигналов для graceful shutdown
 	sigChan := make(chan os.Signal, 1)
@@ -84,6 +109,9 @@ func main() {
 		}
 	}()
 
+	// Стартовые проверки окружения; бот начнет принимать апдейты после их прохождения
+	go runStartupProbes(logger, cfg, bot)
+
 	logger.Info("Bot is running. Press Ctrl+C to stop.")
 
 	// Ожидание сигнала завершения
@@ -91,10 +119,214 @@ func main() {
 	logger.Info("Received shutdown signal, stopping bot...")
 
 	bot.Stop()
+	deliveryStore.Close()
+	stopBackground()
+	downloadService.Close()
+	tempJanitor.OnShutdown()
 
 	logger.Info("Application stopped")
 }
 
+// setupYtDlp настраивает автоматическое обновление yt-dlp; в режиме release
+// недостающий файл скачивается до проверки утилит
+func setupYtDlp(logger *slog.Logger, cfg *config.Config) *ytupdate.Updater {
+	updater, err := ytupdate.New(logger, ytupdate.Config{
+		Mode:       cfg.Download.YtDlpUpdate.Mode,
+		Binary:     ytdlpBinary(cfg),
+		ReleaseURL: cfg.Download.YtDlpUpdate.ReleaseURL,
+		Proxy:      cfg.Download.Proxy,
+		Interval:   cfg.Download.YtDlpUpdate.Interval,
+		Timeout:    cfg.Download.YtDlpUpdate.Timeout,
+	})
+	if err != nil {
+		logger.Error("Invalid yt-dlp update settings", slog.Any("error", err))
+		os.Exit(1)
+	}
+	if err := updater.OnStartup(context.Background()); err != nil {
+		logger.Error("Failed to install yt-dlp", slog.Any("error", err))
+		os.Exit(1)
+	}
+	return updater
+}
+
+// prepareTempDirs создает временные каталоги и переводит их пути в абсолютные
+func prepareTempDirs(logger *slog.Logger, cfg *config.Config) {
+	// Создание временной директории
+	if err := os.MkdirAll(cfg.Download.TempDir, 0755); err != nil {
+		logger.Error("Failed to create temp directory",
+			slog.String("dir", cfg.Download.TempDir),
+			slog.Any("error", err),
+		)
+		os.Exit(1)
+	}
+
+	// Получаем абсолютный путь к временной директории
+	absTempDir, err := filepath.Abs(cfg.Download.TempDir)
+	if err != nil {
+		logger.Error("Failed to get absolute temp dir path", slog.Any("error", err))
+		os.Exit(1)
+	}
+	cfg.Download.TempDir = absTempDir
+
+	logger.Info("Temp directory created", slog.String("dir", cfg.Download.TempDir))
+
+	// Быстрый временный каталог (tmpfs) для небольших файлов
+	if cfg.Download.FastTempDir != "" {
+		if err := os.MkdirAll(cfg.Download.FastTempDir, 0755); err != nil {
+			logger.Error("Failed to create fast temp directory",
+				slog.String("dir", cfg.Download.FastTempDir),
+				slog.Any("error", err),
+			)
+			os.Exit(1)
+		}
+		absFastDir, err := filepath.Abs(cfg.Download.FastTempDir)
+		if err != nil {
+			logger.Error("Failed to get absolute fast temp dir path", slog.Any("error", err))
+			os.Exit(1)
+		}
+		cfg.Download.FastTempDir = absFastDir
+
+		logger.Info("Fast temp directory enabled",
+			slog.String("dir", cfg.Download.FastTempDir),
+			slog.Int("max_file_mb", cfg.Download.FastTempMaxFileMB),
+			slog.Int("max_total_mb", cfg.Download.FastTempMaxTotalMB),
+		)
+	}
+}
+
+// shareState включает общее состояние экземпляров в Redis: авторизацию и настройки
+// чатов. Возвращает nil, если REDIS_URL не задан
+func shareState(logger *slog.Logger, cfg *config.Config, authService *auth.Service) *redis.Cache {
+	if cfg.Storage.RedisURL == "" {
+		return nil
+	}
+
+	client, err := redis.New(cfg.Storage.RedisURL)
+	if err != nil {
+		logger.Error("Invalid REDIS_URL", slog.Any("error", err))
+		os.Exit(1)
+	}
+
+	sharedCache := redis.NewCache(logger, client, cfg.Storage.RedisKeyPrefix)
+	if err := authService.Share(context.Background(), sharedCache); err != nil {
+		logger.Error("Failed to share auth state via Redis", slog.Any("error", err))
+		os.Exit(1)
+	}
+	logger.Info("Shared state enabled", slog.String("prefix", cfg.Storage.RedisKeyPrefix))
+	return sharedCache
+}
+
+// startJanitor очищает временные каталоги при старте; файлы незавершенных отправок
+// сохраняются для восстановления
+func startJanitor(logger *slog.Logger, cfg *config.Config, deliveryStore *delivery.Store) *janitor.Janitor {
+	j, err := janitor.New(
+		logger,
+		cfg.Download.TempWipePolicy,
+		cfg.Download.TempMaxAge,
+		cfg.Download.TempJanitorInterval,
+		deliveryStore.Files,
+		[]string{cfg.Download.TempDir, cfg.Download.FastTempDir},
+	)
+	if err != nil {
+		logger.Error("Invalid temp directory policy", slog.Any("error", err))
+		os.Exit(1)
+	}
+	j.OnStartup()
+	return j
+}
+
+// shareSettings переносит настройки чатов в общее состояние и слушает его изменения
+func shareSettings(ctx context.Context, logger *slog.Logger, bot *telegram.Bot, sharedCache *redis.Cache) {
+	if sharedCache == nil {
+		return
+	}
+	if err := bot.ShareSettings(context.Background(), sharedCache); err != nil {
+		logger.Error("Failed to share chat settings via Redis", slog.Any("error", err))
+		os.Exit(1)
+	}
+	go sharedCache.Run(ctx)
+}
+
+// watchPressure включает сброс нагрузки, когда на сервере мало места, памяти
+// или процессор перегружен
+func watchPressure(ctx context.Context, logger *slog.Logger, cfg *config.Config, bot *telegram.Bot) {
+	if cfg.Pressure.CheckInterval <= 0 {
+		return
+	}
+	monitor := pressure.New(logger, pressure.Config{
+		Interval:        cfg.Pressure.CheckInterval,
+		MinFreeDiskMB:   cfg.Pressure.MinFreeDiskMB,
+		MaxLoadPerCPU:   cfg.Pressure.MaxLoadPerCPU,
+		MinFreeMemoryMB: cfg.Pressure.MinFreeMemoryMB,
+	}, []string{cfg.Download.TempDir, cfg.Download.FastTempDir})
+	bot.WatchPressure(monitor, cfg.Pressure.Workers)
+	go monitor.Run(ctx)
+}
+
+// runStartupProbes проверяет Telegram, DNS платформ и yt-dlp, после чего помечает бота готовым.
+// Если проверки не проходят дольше STARTUP_PROBE_TIMEOUT, бот запускается в деградированном режиме
+func runStartupProbes(logger *slog.Logger, cfg *config.Config, bot *telegram.Bot) {
+	ctx, cancel := context.WithTimeout(context.Background(), cfg.Probe.Timeout)
+	defer cancel()
+
+	checks := []probe.Check{
+		{Name: "telegram", Run: bot.Ping},
+		probe.CommandCheck("yt_dlp", ytdlpBinary(cfg), "--version"),
+	}
+	for _, host := range cfg.Probe.Hosts {
+		checks = append(checks, probe.DNSCheck(host))
+	}
+
+	prober := probe.NewProber(logger, cfg.Probe.Interval, checks...)
+	report := prober.WaitReady(ctx)
+	prober.Log(report)
+
+	if !report.OK() {
+		logger.Warn("Startup probes did not pass in time, starting in degraded mode",
+			slog.Duration("timeout", cfg.Probe.Timeout),
+		)
+	}
+
+	bot.MarkReady()
+}
+
+// binaryCheckTimeout ограничивает запуск утилиты для получения версии
+const binaryCheckTimeout = 30 * time.Second
+
+// ytdlpBinary возвращает путь к yt-dlp из YTDLP_PATH или имя для поиска в PATH
+func ytdlpBinary(cfg *config.Config) string {
+	if cfg.Download.YtDlpPath != "" {
+		return cfg.Download.YtDlpPath
+	}
+	return "yt-dlp"
+}
+
+// checkBinaries проверяет yt-dlp и ffmpeg и пишет их версии в лог. Без yt-dlp
+// бот не работает; без ffmpeg работает, но не склеивает дорожки, не обрезает
+// и не сжимает видео, поэтому его отсутствие только логируется как ошибка
+func checkBinaries(logger *slog.Logger, cfg *config.Config) error {
+	ctx, cancel := context.WithTimeout(context.Background(), binaryCheckTimeout)
+	defer cancel()
+
+	path, version, err := probe.BinaryVersion(ctx, ytdlpBinary(cfg), "--version")
+	if err != nil {
+		return fmt.Errorf("%w; install yt-dlp (https://github.com/yt-dlp/yt-dlp) or set YTDLP_PATH", err)
+	}
+	logger.Info("yt-dlp found", slog.String("path", path), slog.String("version", version))
+	buildinfo.SetTool("yt-dlp", version)
+
+	path, version, err = probe.BinaryVersion(ctx, "ffmpeg", "-version")
+	if err != nil {
+		logger.Error("ffmpeg not found, merging formats, trimming, GIFs, video notes and compression will fail",
+			slog.Any("error", err),
+		)
+		return nil
+	}
+	logger.Info("ffmpeg found", slog.String("path", path), slog.String("version", version))
+	buildinfo.SetTool("ffmpeg", version)
+	return nil
+}
+
 // initLogger инициализирует логгер slog и на stdout, и в файл
 func initLogger() *slog.Logger {
 	opts := &slog.HandlerOptions{
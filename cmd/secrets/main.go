@@ -0,0 +1,103 @@
+// Команда secrets шифрует файлы с секретами бота ключом STORAGE_ENCRYPTION_KEY:
+//
+//	secrets keygen           — сгенерировать новый ключ
+//	secrets seal FILE...     — зашифровать файлы на месте
+//	secrets open FILE        — вывести расшифрованный файл в stdout
+//	secrets rotate FILE...   — перешифровать файлы текущим ключом после ротации
+//
+// Предыдущие ключи для расшифровки задаются в STORAGE_ENCRYPTION_PREVIOUS_KEYS
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/reelser-bot/internal/secrets"
+	"github.com/reelser-bot/internal/storage"
+)
+
+const usage = "usage: secrets keygen | seal FILE... | open FILE | rotate FILE..."
+
+func main() {
+	if err := run(os.Args[1:]); err != nil {
+		fmt.Fprintln(os.Stderr, "secrets:", err)
+		os.Exit(1)
+	}
+}
+
+func run(args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf(usage)
+	}
+
+	if args[0] == "keygen" {
+		key, err := secrets.GenerateKey()
+		if err != nil {
+			return err
+		}
+		fmt.Println(key)
+		return nil
+	}
+
+	keys, err := loadKeyring()
+	if err != nil {
+		return err
+	}
+	if keys == nil {
+		return fmt.Errorf("STORAGE_ENCRYPTION_KEY is not set")
+	}
+
+	files := args[1:]
+	switch {
+	case args[0] == "open" && len(files) == 1:
+		data, err := storage.LoadSecret(files[0], keys)
+		if err != nil {
+			return err
+		}
+		_, err = os.Stdout.Write(data)
+		return err
+
+	case (args[0] == "seal" || args[0] == "rotate") && len(files) > 0:
+		for _, file := range files {
+			if err := reseal(file, keys); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	return fmt.Errorf(usage)
+}
+
+// reseal шифрует файл текущим ключом; уже зашифрованный текущим ключом файл не меняется
+func reseal(file string, keys *secrets.Keyring) error {
+	raw, err := os.ReadFile(file)
+	if err != nil {
+		return err
+	}
+	if secrets.IsSealed(raw) && !keys.NeedsRotation(raw) {
+		fmt.Printf("%s: already sealed with the current key\n", file)
+		return nil
+	}
+
+	data, err := storage.LoadSecret(file, keys)
+	if err != nil {
+		return err
+	}
+	if err := storage.SaveSecret(file, data, keys); err != nil {
+		return err
+	}
+	fmt.Printf("%s: sealed\n", file)
+	return nil
+}
+
+// loadKeyring читает ключи из тех же переменных окружения, что и бот
+func loadKeyring() (*secrets.Keyring, error) {
+	var previous []string
+	for _, key := range strings.Split(os.Getenv("STORAGE_ENCRYPTION_PREVIOUS_KEYS"), ",") {
+		if key = strings.TrimSpace(key); key != "" {
+			previous = append(previous, key)
+		}
+	}
+	return secrets.NewKeyring(os.Getenv("STORAGE_ENCRYPTION_KEY"), previous...)
+}
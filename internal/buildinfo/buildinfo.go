@@ -0,0 +1,79 @@
+package buildinfo
+
+import (
+	"runtime"
+	"runtime/debug"
+	"sync"
+)
+
+// Сведения о сборке задаются при компиляции:
+//
+//	go build -ldflags "-X github.com/reelser-bot/internal/buildinfo.Version=v1.2.3 \
+//	  -X github.com/reelser-bot/internal/buildinfo.Commit=abc1234 \
+//	  -X github.com/reelser-bot/internal/buildinfo.Date=2024-05-01T10:00:00Z"
+//
+// Без ldflags коммит и дата берутся из VCS-сведений Go, если сборка шла из git
+var (
+	Version = "dev"
+	Commit  = ""
+	Date    = ""
+)
+
+// Info сведения о сборке и версиях внешних утилит
+type Info struct {
+	Version   string            `json:"version"`
+	Commit    string            `json:"commit,omitempty"`
+	Date      string            `json:"date,omitempty"`
+	GoVersion string            `json:"go_version"`
+	Tools     map[string]string `json:"tools,omitempty"`
+}
+
+var (
+	mu    sync.Mutex
+	tools = make(map[string]string)
+)
+
+// SetTool запоминает версию внешней утилиты (yt-dlp, ffmpeg); пустая версия удаляет запись
+func SetTool(name, version string) {
+	mu.Lock()
+	defer mu.Unlock()
+
+	if version == "" {
+		delete(tools, name)
+		return
+	}
+	tools[name] = version
+}
+
+// Get возвращает сведения о сборке и последние известные версии утилит
+func Get() Info {
+	info := Info{
+		Version:   Version,
+		Commit:    Commit,
+		Date:      Date,
+		GoVersion: runtime.Version(),
+	}
+
+	if info.Commit == "" || info.Date == "" {
+		if bi, ok := debug.ReadBuildInfo(); ok {
+			for _, s := range bi.Settings {
+				switch {
+				case s.Key == "vcs.revision" && info.Commit == "":
+					info.Commit = s.Value
+				case s.Key == "vcs.time" && info.Date == "":
+					info.Date = s.Value
+				}
+			}
+		}
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(tools) > 0 {
+		info.Tools = make(map[string]string, len(tools))
+		for name, version := range tools {
+			info.Tools[name] = version
+		}
+	}
+	return info
+}
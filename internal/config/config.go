@@ -2,12 +2,17 @@ package config
 
 import (
 	"fmt"
+	"net/url"
 	"os"
+	"regexp"
 	"runtime"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/joho/godotenv"
+
+	"github.com/reelser-bot/internal/secrets"
 )
 
 // Config содержит всю конфигурацию приложения
@@ -16,11 +21,107 @@ type Config struct {
 	Download DownloadConfig
 	Log      LogConfig
 	Auth     AuthConfig
+	Probe    ProbeConfig
+	Storage  StorageConfig
+	Hooks    HooksConfig
+	Texts    TextsConfig
+	Pressure PressureConfig
 }
 
 // TelegramConfig содержит настройки Telegram-бота
 type TelegramConfig struct {
 	BotToken string
+	// APIEndpoint адрес локального Bot API сервера (формат tgbotapi, например http://localhost:8081/bot%s/%s)
+	APIEndpoint string
+	// BusinessEnabled включает обработку сообщений Telegram Business (business_message)
+	BusinessEnabled bool
+	// AllowedChatTypes типы чатов, в которых работает бот: private, group (вместе с
+	// супергруппами), channel
+	AllowedChatTypes []string
+	// PaidMediaPrices цены платного медиа (в Telegram Stars) по ID каналов
+	PaidMediaPrices map[int64]int
+
+	// Минимальный интервал между загрузками в одном чате
+	CooldownPrivate time.Duration
+	CooldownGroup   time.Duration
+
+	// ShareButton добавляет под видео кнопку пересылки через inline-режим (нужен включенный inline mode)
+	ShareButton bool
+
+	// InlineCacheTime время кэширования inline-ответов (в Telegram и локально)
+	InlineCacheTime time.Duration
+
+	// DefaultTimezone часовой пояс чатов, не задавших свой через /timezone (пусто — время сервера)
+	DefaultTimezone string
+	// DefaultLanguage язык (en, ru), по которому форматируются размеры и время для
+	// пользователей, чей язык в Telegram неизвестен или не поддерживается
+	DefaultLanguage string
+
+	// GroupStatusInterval как часто обновляется закрепленный статус бота в группах (/status);
+	// 0 — команда выключена
+	GroupStatusInterval time.Duration
+
+	// UpdateQueueSize емкость очереди апдейтов перед воркерами (0 — вдвое больше воркеров)
+	UpdateQueueSize int
+
+	// Webhook настройки приема апдейтов через вебхук; без WebhookURL используется long polling
+	Webhook WebhookConfig
+
+	// HealthListen адрес HTTP-сервера проверки состояния (GET /healthz) в любом режиме
+	// приема апдейтов; пустой — проверка выключена
+	HealthListen string
+
+	// Политики повторов и таймаутов вызовов Bot API: обычные запросы
+	// (сообщения, правки, ответы) и загрузка файлов
+	Messages RetryPolicy
+	Uploads  RetryPolicy
+}
+
+// WebhookConfig описывает прием апдейтов через вебхук с переключением на long polling
+type WebhookConfig struct {
+	// URL публичный адрес вебхука (https); пустой — только long polling
+	URL string
+	// Listen адрес, на котором бот принимает запросы вебхука
+	Listen string
+	// Secret проверяется в заголовке X-Telegram-Bot-Api-Secret-Token; обязателен с URL
+	Secret string
+	// StallTimeout время без апдейтов при непустой очереди Telegram, после которого
+	// бот переключается на long polling
+	StallTimeout time.Duration
+	// CheckInterval интервал проверки getWebhookInfo и восстановления вебхука
+	CheckInterval time.Duration
+
+	// Sharding распределение чатов между несколькими экземплярами за одним вебхуком
+	Sharding ShardingConfig
+}
+
+// ShardingConfig описывает шардирование чатов по экземплярам консистентным хешированием.
+// Экземпляры регистрируются в общем каталоге и пересылают друг другу апдейты чужих чатов
+type ShardingConfig struct {
+	Enabled bool
+	// InstanceID уникальное имя экземпляра (по умолчанию имя хоста)
+	InstanceID string
+	// PeerURL внутренний адрес вебхука этого экземпляра для пересылки апдейтов
+	PeerURL string
+	// Secret общий секрет экземпляров: им подписываются пересылаемые апдейты
+	Secret string
+	// Dir каталог, общий для всех экземпляров (например, общий том)
+	Dir string
+	// Heartbeat интервал обновления регистрации; экземпляр без обновления дольше
+	// MemberTTL исключается из кольца
+	Heartbeat time.Duration
+	MemberTTL time.Duration
+}
+
+// RetryPolicy описывает повторы и таймауты одного класса вызовов
+type RetryPolicy struct {
+	// Attempts общее количество попыток (1 — без повторов)
+	Attempts int
+	// Timeout таймаут одной попытки (0 — без ограничения)
+	Timeout time.Duration
+	// Backoff задержка перед первым повтором, далее удваивается (но не больше MaxBackoff)
+	Backoff    time.Duration
+	MaxBackoff time.Duration
 }
 
 // DownloadConfig содержит настройки загрузки видео
@@ -28,12 +129,227 @@ type DownloadConfig struct {
 	TempDir        string
 	MaxVideoSizeMB int
 	VideoQuality   string
+
+	// Быстрый временный каталог (например, tmpfs) для небольших файлов.
+	// Файлы крупнее FastTempMaxFileMB или сверх общего бюджета уходят в TempDir
+	FastTempDir        string
+	FastTempMaxFileMB  int
+	FastTempMaxTotalMB int
+
+	// Очистка временных каталогов: политика (never, startup, shutdown, both),
+	// максимальный возраст файла и период фоновой очистки
+	TempWipePolicy      string
+	TempMaxAge          time.Duration
+	TempJanitorInterval time.Duration
+
+	// Лимиты размера видео по типу чата (private, group, supergroup, channel) и по ID чата, в MB
+	MaxVideoSizeByChatType map[string]int
+	MaxVideoSizeByChat     map[int64]int
+
+	// WorkerPoolSize наибольший размер пула воркеров загрузки (WORKER_POOL_MAX)
 	WorkerPoolSize int
+	// WorkerPoolMin наименьший размер пула; пул растет до WorkerPoolSize, пока в очереди
+	// ждут задачи, и сжимается при простое. По умолчанию равен WorkerPoolSize —
+	// фиксированный пул, как до появления автомасштабирования
+	WorkerPoolMin int
+	// AutoscaleInterval период пересчета размера пула
+	AutoscaleInterval time.Duration
+	// AutoscaleIdle сколько часть пула должна простаивать, чтобы пул уменьшился на воркер
+	AutoscaleIdle time.Duration
+	// QueueSize емкость очереди загрузок (0 — десять задач на воркер, но не меньше 100)
+	QueueSize int
+	// QueueChatWeights веса чатов при справедливом распределении воркеров (по умолчанию 1):
+	// чат с весом 2 получает вдвое больше времени воркеров, чем чат с весом 1
+	QueueChatWeights map[int64]int
+	// MaxConcurrentPerUser сколько загрузок одного пользователя выполняется одновременно
+	// (0 — без ограничения); остальные его ссылки ждут в очереди
+	MaxConcurrentPerUser int
+
+	// Таймаут загрузки: глобальный и границы адаптивного таймаута по платформам
+	Timeout    time.Duration
+	MinTimeout time.Duration
+	MaxTimeout time.Duration
+	// Бюджеты остальных этапов запроса: получение сведений (плейлисты), обработка
+	// (хуки, GIF, видеосообщения, сжатие) и отправка в Telegram с ожиданием и повторами
+	MetadataTimeout  time.Duration
+	TranscodeTimeout time.Duration
+	DeliveryTimeout  time.Duration
+
+	// Повторные попытки при временном требовании авторизации (Instagram)
+	LoginRetryDelay    time.Duration
+	LoginRetryAttempts int
+
+	// YtDlpPath путь к исполняемому файлу yt-dlp (пусто — yt-dlp из PATH)
+	YtDlpPath string
+	// YtDlpUpdate автоматическое обновление yt-dlp
+	YtDlpUpdate YtDlpUpdateConfig
+
+	// extractor-args yt-dlp для YouTube (обход "Sign in to confirm")
+	YouTubePlayerClient string
+	YouTubePOToken      string
+
+	// EnabledPlatforms список включенных платформ (пусто — все поддерживаемые)
+	EnabledPlatforms []string
+
+	// TikTokProviders адреса API, совместимых с TikWM, в порядке приоритета;
+	// при ошибке провайдера запрос повторяется у следующего
+	TikTokProviders []TikTokProvider
+
+	// TwitchMaxDuration максимальная длительность видео Twitch (защита от полных VOD)
+	TwitchMaxDuration time.Duration
+	// KickMaxDuration максимальная длительность видео Kick (защита от полных VOD)
+	KickMaxDuration time.Duration
+	// MaxVideoDuration максимальная длительность видео любой платформы, проверяется
+	// по метаданным до загрузки
+	MaxVideoDuration time.Duration
+
+	// GenericFallback скачивать через yt-dlp ссылки сайтов без отдельной поддержки.
+	// Результат ограничен расширениями GenericExtensions и длительностью GenericMaxDuration
+	GenericFallback    bool
+	GenericExtensions  []string
+	GenericMaxDuration time.Duration
+
+	// CookiesFile файл cookies yt-dlp (формат Netscape) для всех платформ;
+	// PlatformCookies файлы отдельных платформ (YTDLP_COOKIES_FILE_<ПЛАТФОРМА>).
+	// Файлы могут быть зашифрованы ключом STORAGE_ENCRYPTION_KEY
+	CookiesFile     string
+	PlatformCookies map[string]string
+
+	// Proxy прокси для всех загрузок (HTTP-клиенты загрузчиков и yt-dlp), но не для
+	// Bot API; становится маршрутом "*", если он не задан в PLATFORM_PROXIES
+	Proxy string
+
+	// EgressRoutes маршруты исходящего трафика по платформам в порядке приоритета:
+	// URL прокси или "direct"; ключ "*" задает маршруты для остальных платформ
+	EgressRoutes map[string][]string
+	// Проверка доступности маршрутов
+	EgressCheckInterval time.Duration
+	EgressCheckTimeout  time.Duration
+
+	// Subtitles встраивать субтитры на языке пользователя; SubtitleLanguage
+	// используется, если язык пользователя неизвестен
+	Subtitles        bool
+	SubtitleLanguage string
+
+	// MaxPlaylistItems сколько первых видео плейлиста скачивать (0 — плейлисты отключены)
+	MaxPlaylistItems int
+
+	// Кэш метаданных yt-dlp -J: сколько URL хранить и как долго (0 — кэш отключен)
+	MetadataCacheSize int
+	MetadataCacheTTL  time.Duration
+
+	// DailyBandwidthGB суточный бюджет трафика (загрузка + отправка), 0 — без ограничения.
+	// После исчерпания отправляются только видео, уже загруженные в Telegram
+	DailyBandwidthGB int
+	// BandwidthWarnPercent доля израсходованного бюджета в процентах, после которой
+	// к отправленным видео добавляется предупреждение (0 — без предупреждений)
+	BandwidthWarnPercent int
+
+	// Compression сжатие видео, превышающих лимит размера
+	Compression CompressionConfig
+}
+
+// TikTokProvider API для загрузки TikTok (формат ответа TikWM)
+type TikTokProvider struct {
+	// Endpoint адрес API, ссылка на пост передается параметром url
+	Endpoint string
+	// APIKey ключ провайдера (заголовок X-API-Key); пусто — без ключа
+	APIKey string
+}
+
+// YtDlpUpdateConfig настройки автоматического обновления yt-dlp
+type YtDlpUpdateConfig struct {
+	// Mode off, self (yt-dlp -U) или release (скачивание релиза GitHub в YTDLP_PATH)
+	Mode string
+	// ReleaseURL адрес файла релиза (пусто — сборка для текущей ОС и архитектуры)
+	ReleaseURL string
+	Interval   time.Duration
+	Timeout    time.Duration
+}
+
+// CompressionConfig настройки сжатия видео через ffmpeg
+type CompressionConfig struct {
+	// Enabled сжимать видео больше лимита вместо отказа
+	Enabled bool
+	// VideoBitrateKbps фиксированный битрейт видео; 0 — рассчитать по лимиту и длительности
+	VideoBitrateKbps int
+	// CRF качество x264, если длительность видео неизвестна
+	CRF              int
+	AudioBitrateKbps int
+	// Preset пресет скорости x264 (ultrafast ... veryslow)
+	Preset  string
+	Timeout time.Duration
 }
 
 // LogConfig содержит настройки логирования
 type LogConfig struct {
 	Level string
+	// JobFile файл сводных записей о задачах (JSON-строки); пусто — сводки пишутся в основной лог
+	JobFile string
+}
+
+// StorageConfig содержит настройки локального хранилища состояния
+type StorageConfig struct {
+	DataDir string
+	// TraceDir директория для вывода yt-dlp по запросам (пусто — трассировка отключена)
+	TraceDir      string
+	TraceMaxFiles int
+	// FailureHistorySize сколько последних неудавшихся загрузок хранить для /replay
+	FailureHistorySize int
+	// ReceiptHistorySize сколько последних публикаций в каналах хранить для /post
+	ReceiptHistorySize int
+	// FileIDCacheSize сколько file_id загруженных видео помнить для мгновенной повторной
+	// отправки (0 — не помнить); FileIDCachePersist сохраняет их на диск
+	FileIDCacheSize    int
+	FileIDCachePersist bool
+
+	// EncryptionKey ключ AES-256 (base64) для файлов с секретами: cookies, сессий, токенов.
+	// EncryptionPreviousKeys прежние ключи, которыми файлы еще можно расшифровать после ротации
+	EncryptionKey          string
+	EncryptionPreviousKeys []string
+
+	// RedisURL общее состояние нескольких экземпляров (авторизация и настройки чатов)
+	// в Redis: redis://[user:password@]host:port[/db]; пусто — только локальные файлы
+	RedisURL string
+	// RedisKeyPrefix префикс ключей и канала уведомлений в Redis
+	RedisKeyPrefix string
+}
+
+// Keyring возвращает связку ключей шифрования секретов; nil — шифрование выключено
+func (c StorageConfig) Keyring() (*secrets.Keyring, error) {
+	return secrets.NewKeyring(c.EncryptionKey, c.EncryptionPreviousKeys...)
+}
+
+// HooksConfig содержит настройки пользовательских хуков
+type HooksConfig struct {
+	PostProcessCommand       string
+	PostProcessURL           string
+	PostProcessTimeout       time.Duration
+	PostProcessFailurePolicy string
+
+	PolicyURL      string
+	PolicyTimeout  time.Duration
+	PolicyFailOpen bool
+}
+
+// PressureConfig содержит пороги сброса нагрузки: при нехватке места, памяти или
+// высокой загрузке бот уменьшает число воркеров и отклоняет запросы с низким приоритетом
+type PressureConfig struct {
+	// CheckInterval период проверки (0 — сброс нагрузки выключен)
+	CheckInterval time.Duration
+	// Пороги; 0 — метрика не проверяется
+	MinFreeDiskMB   int64
+	MaxLoadPerCPU   float64
+	MinFreeMemoryMB int64
+	// Workers сколько воркеров загрузки работает под давлением (0 — половина WORKER_POOL_MAX)
+	Workers int
+}
+
+// ProbeConfig содержит настройки стартовых проверок окружения
+type ProbeConfig struct {
+	Timeout  time.Duration
+	Interval time.Duration
+	Hosts    []string
 }
 
 // AuthConfig содержит настройки авторизации пользователей
@@ -41,6 +357,20 @@ type AuthConfig struct {
 	Enabled          bool
 	Tokens           []string
 	AllowedUsersFile string
+	AdminIDs         []int64
+	// ImportFile CSV пользователей (user_id, role, daily_quota), импортируемый при запуске
+	ImportFile string
+}
+
+// TextsConfig содержит настройки текстов /start и /help для конкретной инсталляции
+type TextsConfig struct {
+	// Файлы шаблонов text/template (пусто — встроенные тексты)
+	StartTemplateFile string
+	HelpTemplateFile  string
+
+	// Rules правила использования и Contact контакт для связи (выводятся, если заданы)
+	Rules   string
+	Contact string
 }
 
 // Load загружает конфигурацию из переменных окружения
@@ -49,33 +379,406 @@ func Load() (*Config, error) {
 	_ = godotenv.Load()
 
 	cfg := &Config{
-		Telegram: TelegramConfig{
-			BotToken: getEnv("TELEGRAM_BOT_TOKEN", ""),
-		},
-		Download: DownloadConfig{
-			TempDir:        getEnv("TEMP_DIR", "./tmp"),
-			MaxVideoSizeMB: getEnvAsInt("MAX_VIDEO_SIZE_MB", 50),
-			VideoQuality:   getEnv("VIDEO_QUALITY", "best"),
-			WorkerPoolSize: getEnvAsInt("WORKER_POOL_SIZE", runtime.NumCPU()),
-		},
+		Telegram: loadTelegramConfig(),
+		Download: loadDownloadConfig(),
 		Log: LogConfig{
-			Level: getEnv("LOG_LEVEL", "info"),
+			Level:   getEnv("LOG_LEVEL", "info"),
+			JobFile: getEnv("JOB_LOG_FILE", ""),
 		},
 		Auth: AuthConfig{
 			Enabled:          getEnvAsBool("AUTH_ENABLED", false),
 			Tokens:           splitAndTrim(getEnv("AUTH_TOKENS", "")),
 			AllowedUsersFile: getEnv("AUTH_ALLOWED_USERS_FILE", "./allowed_users.txt"),
+			AdminIDs:         getEnvAsInt64List("ADMIN_IDS"),
+			ImportFile:       getEnv("USERS_IMPORT_FILE", ""),
 		},
-	}
+		Storage: StorageConfig{
+			DataDir:       getEnv("DATA_DIR", "./data"),
+			TraceDir:      getEnv("TRACE_DIR", "./data/traces"),
+			TraceMaxFiles: getEnvAsInt("TRACE_MAX_FILES", 200),
+
+			FailureHistorySize: getEnvAsInt("FAILURE_HISTORY_SIZE", 500),
+			ReceiptHistorySize: getEnvAsInt("RECEIPT_HISTORY_SIZE", 1000),
+			FileIDCacheSize:    getEnvAsInt("FILE_ID_CACHE_SIZE", 5000),
+			FileIDCachePersist: getEnvAsBool("FILE_ID_CACHE_PERSIST", true),
 
-	// Валидация обязательных полей
-	if cfg.Telegram.BotToken == "" {
-		return nil, fmt.Errorf("TELEGRAM_BOT_TOKEN is required")
+			EncryptionKey:          getEnv("STORAGE_ENCRYPTION_KEY", ""),
+			EncryptionPreviousKeys: splitAndTrim(getEnv("STORAGE_ENCRYPTION_PREVIOUS_KEYS", "")),
+
+			RedisURL:       getEnv("REDIS_URL", ""),
+			RedisKeyPrefix: getEnv("REDIS_KEY_PREFIX", "reelser:"),
+		},
+		Hooks: HooksConfig{
+			PostProcessCommand:       getEnv("POSTPROCESS_HOOK_CMD", ""),
+			PostProcessURL:           getEnv("POSTPROCESS_HOOK_URL", ""),
+			PostProcessTimeout:       getEnvAsDuration("POSTPROCESS_HOOK_TIMEOUT", time.Minute),
+			PostProcessFailurePolicy: getEnv("POSTPROCESS_HOOK_FAILURE_POLICY", "ignore"),
+
+			PolicyURL:      getEnv("POLICY_HOOK_URL", ""),
+			PolicyTimeout:  getEnvAsDuration("POLICY_HOOK_TIMEOUT", 5*time.Second),
+			PolicyFailOpen: getEnvAsBool("POLICY_HOOK_FAIL_OPEN", true),
+		},
+		Probe: ProbeConfig{
+			Timeout:  getEnvAsDuration("STARTUP_PROBE_TIMEOUT", 2*time.Minute),
+			Interval: getEnvAsDuration("STARTUP_PROBE_INTERVAL", 10*time.Second),
+			Hosts:    splitAndTrim(getEnv("STARTUP_PROBE_HOSTS", "www.youtube.com,www.tiktok.com,tikwm.com,www.instagram.com")),
+		},
+		Pressure: PressureConfig{
+			CheckInterval:   getEnvAsDuration("PRESSURE_CHECK_INTERVAL", 30*time.Second),
+			MinFreeDiskMB:   int64(getEnvAsInt("PRESSURE_MIN_FREE_DISK_MB", 1024)),
+			MaxLoadPerCPU:   getEnvAsFloat("PRESSURE_MAX_LOAD_PER_CPU", 2),
+			MinFreeMemoryMB: int64(getEnvAsInt("PRESSURE_MIN_FREE_MEMORY_MB", 256)),
+			Workers:         getEnvAsInt("PRESSURE_WORKERS", 0),
+		},
+		Texts: TextsConfig{
+			StartTemplateFile: getEnv("START_TEMPLATE_FILE", ""),
+			HelpTemplateFile:  getEnv("HELP_TEMPLATE_FILE", ""),
+			Rules:             getEnv("BOT_RULES", ""),
+			Contact:           getEnv("BOT_CONTACT", ""),
+		},
+	}
+	if err := cfg.validate(); err != nil {
+		return nil, err
 	}
 
+	// Общий прокси используется для платформ без своего маршрута
+	if cfg.Download.Proxy != "" {
+		if _, ok := cfg.Download.EgressRoutes["*"]; !ok {
+			cfg.Download.EgressRoutes["*"] = []string{cfg.Download.Proxy}
+		}
+	}
 	return cfg, nil
 }
 
+// loadTelegramConfig читает настройки Telegram, вебхука и шардирования
+func loadTelegramConfig() TelegramConfig {
+	return TelegramConfig{
+		BotToken:    getEnv("TELEGRAM_BOT_TOKEN", ""),
+		APIEndpoint: getEnv("TELEGRAM_API_ENDPOINT", ""),
+
+		BusinessEnabled:  getEnvAsBool("TELEGRAM_BUSINESS_ENABLED", false),
+		AllowedChatTypes: splitAndTrim(strings.ToLower(getEnv("ALLOWED_CHAT_TYPES", "private,group,channel"))),
+		PaidMediaPrices:  getEnvAsInt64Map("PAID_MEDIA_CHANNELS"),
+
+		CooldownPrivate: getEnvAsDuration("CHAT_COOLDOWN_PRIVATE", 0),
+		CooldownGroup:   getEnvAsDuration("CHAT_COOLDOWN_GROUP", 0),
+
+		ShareButton:     getEnvAsBool("TELEGRAM_SHARE_BUTTON", true),
+		InlineCacheTime: getEnvAsDuration("TELEGRAM_INLINE_CACHE_TIME", time.Minute),
+
+		DefaultTimezone: getEnv("DEFAULT_TIMEZONE", ""),
+		DefaultLanguage: strings.ToLower(getEnv("DEFAULT_LANGUAGE", "en")),
+
+		GroupStatusInterval: getEnvAsDuration("GROUP_STATUS_INTERVAL", 5*time.Minute),
+
+		UpdateQueueSize: getEnvAsInt("UPDATE_QUEUE_SIZE", 0),
+
+		Webhook: WebhookConfig{
+			URL:           getEnv("WEBHOOK_URL", ""),
+			Listen:        getEnv("WEBHOOK_LISTEN", ":8443"),
+			Secret:        getEnv("WEBHOOK_SECRET", ""),
+			StallTimeout:  getEnvAsDuration("WEBHOOK_STALL_TIMEOUT", 5*time.Minute),
+			CheckInterval: getEnvAsDuration("WEBHOOK_CHECK_INTERVAL", time.Minute),
+
+			Sharding: ShardingConfig{
+				Enabled:    getEnvAsBool("SHARDING_ENABLED", false),
+				InstanceID: getEnv("SHARD_INSTANCE_ID", hostname()),
+				PeerURL:    getEnv("SHARD_PEER_URL", ""),
+				Secret:     getEnv("SHARD_SECRET", ""),
+				Dir:        getEnv("SHARD_DIR", ""),
+				Heartbeat:  getEnvAsDuration("SHARD_HEARTBEAT_INTERVAL", 10*time.Second),
+				MemberTTL:  getEnvAsDuration("SHARD_MEMBER_TTL", 30*time.Second),
+			},
+		},
+
+		HealthListen: getEnv("HEALTH_LISTEN", ""),
+
+		Messages: getEnvAsRetryPolicy("TELEGRAM_MESSAGE", RetryPolicy{
+			Attempts:   3,
+			Timeout:    30 * time.Second,
+			Backoff:    time.Second,
+			MaxBackoff: 30 * time.Second,
+		}),
+		Uploads: getEnvAsRetryPolicy("TELEGRAM_UPLOAD", RetryPolicy{
+			Attempts:   2,
+			Timeout:    10 * time.Minute,
+			Backoff:    5 * time.Second,
+			MaxBackoff: time.Minute,
+		}),
+	}
+}
+
+// loadDownloadConfig читает настройки загрузки: временные каталоги, пул воркеров,
+// очередь и таймауты; настройки платформ добавляет loadSourceConfig
+func loadDownloadConfig() DownloadConfig {
+	cfg := DownloadConfig{
+		TempDir:        getEnv("TEMP_DIR", "./tmp"),
+		MaxVideoSizeMB: getEnvAsInt("MAX_VIDEO_SIZE_MB", 50),
+		VideoQuality:   getEnv("VIDEO_QUALITY", "best"),
+
+		FastTempDir:        getEnv("TEMP_FAST_DIR", ""),
+		FastTempMaxFileMB:  getEnvAsInt("TEMP_FAST_MAX_FILE_MB", 64),
+		FastTempMaxTotalMB: getEnvAsInt("TEMP_FAST_MAX_TOTAL_MB", 512),
+
+		TempWipePolicy:      strings.ToLower(getEnv("TEMP_WIPE_POLICY", "startup")),
+		TempMaxAge:          getEnvAsDuration("TEMP_MAX_AGE", 2*time.Hour),
+		TempJanitorInterval: getEnvAsDuration("TEMP_JANITOR_INTERVAL", 10*time.Minute),
+
+		MaxVideoSizeByChatType: map[string]int{
+			"private":    getEnvAsInt("MAX_VIDEO_SIZE_MB_PRIVATE", 0),
+			"group":      getEnvAsInt("MAX_VIDEO_SIZE_MB_GROUP", 0),
+			"supergroup": getEnvAsInt("MAX_VIDEO_SIZE_MB_GROUP", 0),
+			"channel":    getEnvAsInt("MAX_VIDEO_SIZE_MB_CHANNEL", 0),
+		},
+		MaxVideoSizeByChat:   getEnvAsInt64Map("MAX_VIDEO_SIZE_MB_CHATS"),
+		WorkerPoolSize:       getEnvAsInt("WORKER_POOL_MAX", getEnvAsInt("WORKER_POOL_SIZE", runtime.NumCPU())),
+		AutoscaleInterval:    getEnvAsDuration("WORKER_AUTOSCALE_INTERVAL", 5*time.Second),
+		AutoscaleIdle:        getEnvAsDuration("WORKER_AUTOSCALE_IDLE", 2*time.Minute),
+		QueueSize:            getEnvAsInt("DOWNLOAD_QUEUE_SIZE", 0),
+		QueueChatWeights:     getEnvAsInt64Map("QUEUE_CHAT_WEIGHTS"),
+		MaxConcurrentPerUser: getEnvAsInt("MAX_CONCURRENT_PER_USER", 0),
+
+		Timeout:    getEnvAsDuration("DOWNLOAD_TIMEOUT", 5*time.Minute),
+		MinTimeout: getEnvAsDuration("DOWNLOAD_TIMEOUT_MIN", time.Minute),
+		MaxTimeout: getEnvAsDuration("DOWNLOAD_TIMEOUT_MAX", 15*time.Minute),
+
+		MetadataTimeout:  getEnvAsDuration("METADATA_TIMEOUT", time.Minute),
+		TranscodeTimeout: getEnvAsDuration("TRANSCODE_TIMEOUT", 15*time.Minute),
+		DeliveryTimeout:  getEnvAsDuration("DELIVERY_TIMEOUT", 30*time.Minute),
+
+		LoginRetryDelay:    getEnvAsDuration("LOGIN_RETRY_DELAY", 10*time.Minute),
+		LoginRetryAttempts: getEnvAsInt("LOGIN_RETRY_ATTEMPTS", 3),
+
+		YtDlpPath: getEnv("YTDLP_PATH", ""),
+		YtDlpUpdate: YtDlpUpdateConfig{
+			Mode:       strings.ToLower(getEnv("YTDLP_UPDATE_MODE", "off")),
+			ReleaseURL: getEnv("YTDLP_RELEASE_URL", ""),
+			Interval:   getEnvAsDuration("YTDLP_UPDATE_INTERVAL", 24*time.Hour),
+			Timeout:    getEnvAsDuration("YTDLP_UPDATE_TIMEOUT", 5*time.Minute),
+		},
+	}
+	cfg.WorkerPoolMin = getEnvAsInt("WORKER_POOL_MIN", max(cfg.WorkerPoolSize, 1))
+	loadSourceConfig(&cfg)
+	return cfg
+}
+
+// loadSourceConfig читает настройки платформ и сети: включенные платформы, cookies,
+// прокси, субтитры, кэш метаданных, трафик и сжатие
+func loadSourceConfig(cfg *DownloadConfig) {
+	cfg.YouTubePlayerClient = getEnv("YOUTUBE_PLAYER_CLIENT", "")
+	cfg.YouTubePOToken = getEnv("YOUTUBE_PO_TOKEN", "")
+
+	cfg.EnabledPlatforms = splitAndTrim(getEnv("ENABLED_PLATFORMS", ""))
+	cfg.TikTokProviders = getEnvAsTikTokProviders("TIKTOK_API_PROVIDERS", "https://tikwm.com/api")
+	cfg.TwitchMaxDuration = getEnvAsDuration("TWITCH_MAX_DURATION", 10*time.Minute)
+	cfg.KickMaxDuration = getEnvAsDuration("KICK_MAX_DURATION", 10*time.Minute)
+	cfg.MaxVideoDuration = getEnvAsDuration("MAX_VIDEO_DURATION", 3*time.Hour)
+
+	cfg.GenericFallback = getEnvAsBool("GENERIC_FALLBACK", false)
+	cfg.GenericExtensions = splitAndTrim(getEnv("GENERIC_ALLOWED_EXTENSIONS", "mp4,webm,mov,m4a,mp3"))
+	cfg.GenericMaxDuration = getEnvAsDuration("GENERIC_MAX_DURATION", 10*time.Minute)
+
+	cfg.CookiesFile = getEnv("YTDLP_COOKIES_FILE", "")
+	cfg.PlatformCookies = getEnvWithPrefix("YTDLP_COOKIES_FILE_")
+
+	cfg.Proxy = getEnv("DOWNLOAD_PROXY", "")
+	cfg.EgressRoutes = getEnvAsRoutes("PLATFORM_PROXIES")
+	cfg.EgressCheckInterval = getEnvAsDuration("EGRESS_CHECK_INTERVAL", time.Minute)
+	cfg.EgressCheckTimeout = getEnvAsDuration("EGRESS_CHECK_TIMEOUT", 10*time.Second)
+
+	cfg.Subtitles = getEnvAsBool("DOWNLOAD_SUBTITLES", false)
+	cfg.SubtitleLanguage = strings.ToLower(getEnv("SUBTITLE_LANGUAGE", "en"))
+
+	cfg.MaxPlaylistItems = getEnvAsInt("MAX_PLAYLIST_ITEMS", 10)
+
+	cfg.MetadataCacheSize = getEnvAsInt("METADATA_CACHE_SIZE", 256)
+	cfg.MetadataCacheTTL = getEnvAsDuration("METADATA_CACHE_TTL", 10*time.Minute)
+
+	cfg.DailyBandwidthGB = getEnvAsInt("DAILY_BANDWIDTH_GB", 0)
+	cfg.BandwidthWarnPercent = getEnvAsInt("BANDWIDTH_WARN_PERCENT", 80)
+
+	cfg.Compression = CompressionConfig{
+		Enabled:          getEnvAsBool("COMPRESS_OVERSIZED", false),
+		VideoBitrateKbps: getEnvAsInt("COMPRESS_VIDEO_BITRATE_KBPS", 0),
+		CRF:              getEnvAsInt("COMPRESS_CRF", 28),
+		AudioBitrateKbps: getEnvAsInt("COMPRESS_AUDIO_BITRATE_KBPS", 96),
+		Preset:           getEnv("COMPRESS_PRESET", "veryfast"),
+		Timeout:          getEnvAsDuration("COMPRESS_TIMEOUT", 10*time.Minute),
+	}
+}
+
+// validate проверяет значения конфигурации
+func (c *Config) validate() error {
+	if c.Telegram.BotToken == "" {
+		return fmt.Errorf("TELEGRAM_BOT_TOKEN is required")
+	}
+	if err := validateQueueSize("UPDATE_QUEUE_SIZE", c.Telegram.UpdateQueueSize); err != nil {
+		return err
+	}
+	if err := validateQueueSize("DOWNLOAD_QUEUE_SIZE", c.Download.QueueSize); err != nil {
+		return err
+	}
+	if interval := c.Telegram.GroupStatusInterval; interval != 0 && interval < time.Minute {
+		return fmt.Errorf("GROUP_STATUS_INTERVAL must be 0 or at least 1m, got %s", interval)
+	}
+	if c.Download.WorkerPoolMin < 1 || c.Download.WorkerPoolMin > max(c.Download.WorkerPoolSize, 1) {
+		return fmt.Errorf("WORKER_POOL_MIN must be between 1 and WORKER_POOL_MAX (%d), got %d",
+			max(c.Download.WorkerPoolSize, 1), c.Download.WorkerPoolMin)
+	}
+	if c.Download.AutoscaleInterval <= 0 {
+		return fmt.Errorf("WORKER_AUTOSCALE_INTERVAL must be positive, got %s", c.Download.AutoscaleInterval)
+	}
+	if c.Download.MaxVideoDuration <= 0 {
+		return fmt.Errorf("MAX_VIDEO_DURATION must be positive, got %s", c.Download.MaxVideoDuration)
+	}
+	if c.Download.AutoscaleIdle <= 0 {
+		return fmt.Errorf("WORKER_AUTOSCALE_IDLE must be positive, got %s", c.Download.AutoscaleIdle)
+	}
+	if c.Download.MaxConcurrentPerUser < 0 {
+		return fmt.Errorf("MAX_CONCURRENT_PER_USER must not be negative, got %d", c.Download.MaxConcurrentPerUser)
+	}
+	for chatID, weight := range c.Download.QueueChatWeights {
+		if weight <= 0 {
+			return fmt.Errorf("invalid QUEUE_CHAT_WEIGHTS weight %d for chat %d: must be positive", weight, chatID)
+		}
+	}
+	for _, chatType := range c.Telegram.AllowedChatTypes {
+		switch chatType {
+		case "private", "group", "channel":
+		default:
+			return fmt.Errorf("invalid ALLOWED_CHAT_TYPES value %q: expected private, group or channel", chatType)
+		}
+	}
+	if c.Telegram.DefaultTimezone != "" {
+		if _, err := time.LoadLocation(c.Telegram.DefaultTimezone); err != nil {
+			return fmt.Errorf("invalid DEFAULT_TIMEZONE: %w", err)
+		}
+	}
+	switch c.Telegram.DefaultLanguage {
+	case "en", "ru":
+	default:
+		return fmt.Errorf("invalid DEFAULT_LANGUAGE %q: expected en or ru", c.Telegram.DefaultLanguage)
+	}
+	if err := validateWebhook(c.Telegram.Webhook); err != nil {
+		return err
+	}
+	if c.Telegram.HealthListen != "" && c.Telegram.Webhook.URL != "" && c.Telegram.HealthListen == c.Telegram.Webhook.Listen {
+		return fmt.Errorf("HEALTH_LISTEN must differ from WEBHOOK_LISTEN")
+	}
+	if c.Download.Proxy != "" {
+		if err := validateProxy(c.Download.Proxy); err != nil {
+			return fmt.Errorf("invalid DOWNLOAD_PROXY: %w", err)
+		}
+	}
+	if err := validateSharding(c.Telegram.Webhook); err != nil {
+		return err
+	}
+	for _, provider := range c.Download.TikTokProviders {
+		if u, err := url.Parse(provider.Endpoint); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return fmt.Errorf("invalid TIKTOK_API_PROVIDERS endpoint %q: expected http(s) URL", provider.Endpoint)
+		}
+	}
+	if _, err := c.Storage.Keyring(); err != nil {
+		return fmt.Errorf("invalid STORAGE_ENCRYPTION_KEY: %w", err)
+	}
+
+	return nil
+}
+
+// validateProxy проверяет адрес прокси: поддерживаются HTTP, HTTPS и SOCKS5
+func validateProxy(proxy string) error {
+	u, err := url.Parse(proxy)
+	if err != nil {
+		return err
+	}
+	switch u.Scheme {
+	case "http", "https", "socks5", "socks5h":
+	default:
+		return fmt.Errorf("unsupported proxy scheme %q", u.Scheme)
+	}
+	if u.Host == "" {
+		return fmt.Errorf("proxy host is empty")
+	}
+	return nil
+}
+
+// webhookSecretPattern допустимый секрет вебхука: Telegram принимает 1-256 символов
+// A-Z, a-z, 0-9, _ и -
+var webhookSecretPattern = regexp.MustCompile(`^[A-Za-z0-9_-]{1,256}$`)
+
+// validateWebhook проверяет настройки вебхука. Без секрета любой, кто узнал адрес,
+// мог бы прислать поддельный апдейт от имени администратора, поэтому он обязателен
+func validateWebhook(webhook WebhookConfig) error {
+	if webhook.URL == "" {
+		return nil
+	}
+
+	if !strings.HasPrefix(webhook.URL, "https://") {
+		return fmt.Errorf("WEBHOOK_URL must use https")
+	}
+	if !webhookSecretPattern.MatchString(webhook.Secret) {
+		return fmt.Errorf("WEBHOOK_URL requires WEBHOOK_SECRET of 1-256 characters A-Z, a-z, 0-9, _ and -")
+	}
+	if webhook.StallTimeout <= 0 {
+		return fmt.Errorf("WEBHOOK_STALL_TIMEOUT must be positive, got %s", webhook.StallTimeout)
+	}
+	if webhook.CheckInterval <= 0 {
+		return fmt.Errorf("WEBHOOK_CHECK_INTERVAL must be positive, got %s", webhook.CheckInterval)
+	}
+	return nil
+}
+
+// minShardSecretLength минимальная длина SHARD_SECRET
+const minShardSecretLength = 16
+
+// validateSharding проверяет настройки шардирования: оно работает только с вебхуком
+func validateSharding(webhook WebhookConfig) error {
+	s := webhook.Sharding
+	if !s.Enabled {
+		return nil
+	}
+
+	if webhook.URL == "" {
+		return fmt.Errorf("SHARDING_ENABLED requires WEBHOOK_URL")
+	}
+	if s.Dir == "" {
+		return fmt.Errorf("SHARDING_ENABLED requires SHARD_DIR")
+	}
+	if !strings.HasPrefix(s.PeerURL, "http://") && !strings.HasPrefix(s.PeerURL, "https://") {
+		return fmt.Errorf("SHARD_PEER_URL must be an http(s) URL")
+	}
+	if len(s.Secret) < minShardSecretLength {
+		return fmt.Errorf("SHARDING_ENABLED requires SHARD_SECRET of at least %d characters", minShardSecretLength)
+	}
+	if s.InstanceID == "" || strings.ContainsAny(s.InstanceID, `/\ `) {
+		return fmt.Errorf("invalid SHARD_INSTANCE_ID %q", s.InstanceID)
+	}
+	if s.Heartbeat <= 0 || s.MemberTTL <= s.Heartbeat {
+		return fmt.Errorf("SHARD_MEMBER_TTL must be greater than SHARD_HEARTBEAT_INTERVAL")
+	}
+	return nil
+}
+
+// hostname возвращает имя хоста или пустую строку
+func hostname() string {
+	name, err := os.Hostname()
+	if err != nil {
+		return ""
+	}
+	return name
+}
+
+// maxQueueSize верхняя граница емкости очередей: задачи хранятся в памяти
+const maxQueueSize = 10000
+
+// validateQueueSize проверяет емкость очереди (0 — значение по умолчанию)
+func validateQueueSize(key string, size int) error {
+	if size < 0 || size > maxQueueSize {
+		return fmt.Errorf("%s must be between 0 and %d, got %d", key, maxQueueSize, size)
+	}
+	return nil
+}
+
 // getEnv получает значение переменной окружения или возвращает значение по умолчанию
 func getEnv(key, defaultValue string) string {
 	if value := os.Getenv(key); value != "" {
@@ -99,6 +802,72 @@ func getEnvAsInt(key string, defaultValue int) int {
 	return value
 }
 
+// getEnvAsInt64List разбирает переменную вида "1,2,3" в список int64, пропуская некорректные значения
+func getEnvAsInt64List(key string) []int64 {
+	var res []int64
+	for _, part := range splitAndTrim(os.Getenv(key)) {
+		id, err := strconv.ParseInt(part, 10, 64)
+		if err != nil {
+			continue
+		}
+		res = append(res, id)
+	}
+	return res
+}
+
+// getEnvAsInt64Map разбирает переменную вида "id:value,id:value" в map, пропуская некорректные пары
+func getEnvAsInt64Map(key string) map[int64]int {
+	res := make(map[int64]int)
+	for _, pair := range splitAndTrim(os.Getenv(key)) {
+		idStr, valueStr, ok := strings.Cut(pair, ":")
+		if !ok {
+			continue
+		}
+
+		id, err := strconv.ParseInt(strings.TrimSpace(idStr), 10, 64)
+		if err != nil {
+			continue
+		}
+		value, err := strconv.Atoi(strings.TrimSpace(valueStr))
+		if err != nil {
+			continue
+		}
+
+		res[id] = value
+	}
+	return res
+}
+
+// getEnvAsFloat получает значение переменной окружения как float64 или возвращает значение по умолчанию
+func getEnvAsFloat(key string, defaultValue float64) float64 {
+	valueStr := os.Getenv(key)
+	if valueStr == "" {
+		return defaultValue
+	}
+
+	value, err := strconv.ParseFloat(valueStr, 64)
+	if err != nil {
+		return defaultValue
+	}
+
+	return value
+}
+
+// getEnvAsDuration получает значение переменной окружения как time.Duration или возвращает значение по умолчанию
+func getEnvAsDuration(key string, defaultValue time.Duration) time.Duration {
+	valueStr := os.Getenv(key)
+	if valueStr == "" {
+		return defaultValue
+	}
+
+	value, err := time.ParseDuration(valueStr)
+	if err != nil {
+		return defaultValue
+	}
+
+	return value
+}
+
 // getEnvAsBool получает значение переменной окружения как bool или возвращает значение по умолчанию
 func getEnvAsBool(key string, defaultValue bool) bool {
 	valueStr := os.Getenv(key)
@@ -116,6 +885,66 @@ func getEnvAsBool(key string, defaultValue bool) bool {
 	}
 }
 
+// getEnvAsRoutes парсит маршруты вида platform=route1|route2,... (например,
+// tiktok=http://us-proxy:3128|direct,instagram=direct)
+func getEnvAsRoutes(key string) map[string][]string {
+	res := make(map[string][]string)
+	for _, pair := range splitAndTrim(os.Getenv(key)) {
+		name, routesStr, ok := strings.Cut(pair, "=")
+		if !ok {
+			continue
+		}
+
+		name = strings.ToLower(strings.TrimSpace(name))
+		for _, route := range strings.Split(routesStr, "|") {
+			if route = strings.TrimSpace(route); route != "" {
+				res[name] = append(res[name], route)
+			}
+		}
+	}
+	return res
+}
+
+// getEnvAsTikTokProviders парсит провайдеров вида endpoint|key,... (ключ необязателен,
+// например https://tikwm.com/api,https://mirror.example/api|secret)
+func getEnvAsTikTokProviders(key, defaultValue string) []TikTokProvider {
+	var res []TikTokProvider
+	for _, item := range splitAndTrim(getEnv(key, defaultValue)) {
+		endpoint, apiKey, _ := strings.Cut(item, "|")
+		if endpoint = strings.TrimSpace(endpoint); endpoint != "" {
+			res = append(res, TikTokProvider{Endpoint: endpoint, APIKey: strings.TrimSpace(apiKey)})
+		}
+	}
+	return res
+}
+
+// getEnvWithPrefix собирает непустые переменные <prefix><NAME> в map с ключами
+// name в нижнем регистре (например, YTDLP_COOKIES_FILE_YOUTUBE → youtube)
+func getEnvWithPrefix(prefix string) map[string]string {
+	res := make(map[string]string)
+	for _, env := range os.Environ() {
+		key, value, ok := strings.Cut(env, "=")
+		if !ok || value == "" || !strings.HasPrefix(key, prefix) {
+			continue
+		}
+		if name := strings.ToLower(strings.TrimPrefix(key, prefix)); name != "" {
+			res[name] = value
+		}
+	}
+	return res
+}
+
+// getEnvAsRetryPolicy читает политику повторов из переменных <prefix>_RETRIES,
+// <prefix>_TIMEOUT, <prefix>_BACKOFF и <prefix>_MAX_BACKOFF
+func getEnvAsRetryPolicy(prefix string, defaultValue RetryPolicy) RetryPolicy {
+	return RetryPolicy{
+		Attempts:   getEnvAsInt(prefix+"_RETRIES", defaultValue.Attempts-1) + 1,
+		Timeout:    getEnvAsDuration(prefix+"_TIMEOUT", defaultValue.Timeout),
+		Backoff:    getEnvAsDuration(prefix+"_BACKOFF", defaultValue.Backoff),
+		MaxBackoff: getEnvAsDuration(prefix+"_MAX_BACKOFF", defaultValue.MaxBackoff),
+	}
+}
+
 // splitAndTrim разбивает строку по запятой и обрезает пробелы
 func splitAndTrim(s string) []string {
 	if s == "" {
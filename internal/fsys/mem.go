@@ -0,0 +1,222 @@
+package fsys
+
+import (
+	"bytes"
+	"io"
+	"io/fs"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// MemFS файловая система в памяти. Каталоги создаются неявно по путям файлов
+type MemFS struct {
+	mu    sync.Mutex
+	files map[string]*memFile
+	now   func() time.Time
+}
+
+type memFile struct {
+	data    []byte
+	modTime time.Time
+}
+
+// NewMemFS создает пустую файловую систему. now задает время изменения файлов (nil — time.Now)
+func NewMemFS(now func() time.Time) *MemFS {
+	if now == nil {
+		now = time.Now
+	}
+	return &MemFS{files: make(map[string]*memFile), now: now}
+}
+
+// WriteFile создает или перезаписывает файл
+func (m *MemFS) WriteFile(name string, data []byte, modTime time.Time) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.files[filepath.Clean(name)] = &memFile{data: append([]byte(nil), data...), modTime: modTime}
+}
+
+// Stat возвращает сведения о файле или неявном каталоге
+func (m *MemFS) Stat(name string) (fs.FileInfo, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	name = filepath.Clean(name)
+	if f, ok := m.files[name]; ok {
+		return memInfo{name: filepath.Base(name), size: int64(len(f.data)), modTime: f.modTime}, nil
+	}
+	if m.isDir(name) {
+		return memInfo{name: filepath.Base(name), dir: true}, nil
+	}
+	return nil, &fs.PathError{Op: "stat", Path: name, Err: fs.ErrNotExist}
+}
+
+// ReadDir возвращает непосредственное содержимое каталога, отсортированное по имени
+func (m *MemFS) ReadDir(name string) ([]fs.DirEntry, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	name = filepath.Clean(name)
+	if !m.isDir(name) {
+		return nil, &fs.PathError{Op: "readdir", Path: name, Err: fs.ErrNotExist}
+	}
+
+	seen := make(map[string]fs.DirEntry)
+	prefix := name + string(filepath.Separator)
+	for path, f := range m.files {
+		if !strings.HasPrefix(path, prefix) {
+			continue
+		}
+		rest := strings.TrimPrefix(path, prefix)
+		if i := strings.IndexRune(rest, filepath.Separator); i >= 0 {
+			dir := rest[:i]
+			seen[dir] = fs.FileInfoToDirEntry(memInfo{name: dir, dir: true})
+			continue
+		}
+		seen[rest] = fs.FileInfoToDirEntry(memInfo{name: rest, size: int64(len(f.data)), modTime: f.modTime})
+	}
+
+	entries := make([]fs.DirEntry, 0, len(seen))
+	for _, entry := range seen {
+		entries = append(entries, entry)
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Name() < entries[j].Name() })
+	return entries, nil
+}
+
+// WalkDir обходит дерево каталога в лексическом порядке
+func (m *MemFS) WalkDir(root string, fn fs.WalkDirFunc) error {
+	root = filepath.Clean(root)
+	info, err := m.Stat(root)
+	if err != nil {
+		return fn(root, nil, err)
+	}
+	return m.walk(root, fs.FileInfoToDirEntry(info), fn)
+}
+
+func (m *MemFS) walk(path string, entry fs.DirEntry, fn fs.WalkDirFunc) error {
+	if err := fn(path, entry, nil); err != nil || !entry.IsDir() {
+		if err == fs.SkipDir {
+			return nil
+		}
+		return err
+	}
+
+	entries, err := m.ReadDir(path)
+	if err != nil {
+		return fn(path, entry, err)
+	}
+	for _, child := range entries {
+		if err := m.walk(filepath.Join(path, child.Name()), child, fn); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Open открывает файл на чтение
+func (m *MemFS) Open(name string) (io.ReadCloser, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	f, ok := m.files[filepath.Clean(name)]
+	if !ok {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrNotExist}
+	}
+	return io.NopCloser(bytes.NewReader(f.data)), nil
+}
+
+// Create создает файл; содержимое сохраняется при Close
+func (m *MemFS) Create(name string) (io.WriteCloser, error) {
+	return &memWriter{fs: m, name: filepath.Clean(name)}, nil
+}
+
+// Rename перемещает файл
+func (m *MemFS) Rename(oldpath, newpath string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	oldpath, newpath = filepath.Clean(oldpath), filepath.Clean(newpath)
+	f, ok := m.files[oldpath]
+	if !ok {
+		return &fs.PathError{Op: "rename", Path: oldpath, Err: fs.ErrNotExist}
+	}
+	delete(m.files, oldpath)
+	m.files[newpath] = f
+	return nil
+}
+
+// Remove удаляет файл
+func (m *MemFS) Remove(name string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	name = filepath.Clean(name)
+	if _, ok := m.files[name]; !ok {
+		return &fs.PathError{Op: "remove", Path: name, Err: fs.ErrNotExist}
+	}
+	delete(m.files, name)
+	return nil
+}
+
+// RemoveAll удаляет файл или каталог со всем содержимым
+func (m *MemFS) RemoveAll(name string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	name = filepath.Clean(name)
+	prefix := name + string(filepath.Separator)
+	for path := range m.files {
+		if path == name || strings.HasPrefix(path, prefix) {
+			delete(m.files, path)
+		}
+	}
+	return nil
+}
+
+// isDir проверяет, есть ли файлы внутри каталога; вызывается под m.mu
+func (m *MemFS) isDir(name string) bool {
+	prefix := name + string(filepath.Separator)
+	for path := range m.files {
+		if strings.HasPrefix(path, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+type memWriter struct {
+	fs   *MemFS
+	name string
+	buf  bytes.Buffer
+}
+
+func (w *memWriter) Write(p []byte) (int, error) { return w.buf.Write(p) }
+
+func (w *memWriter) Close() error {
+	w.fs.WriteFile(w.name, w.buf.Bytes(), w.fs.now())
+	return nil
+}
+
+type memInfo struct {
+	name    string
+	size    int64
+	modTime time.Time
+	dir     bool
+}
+
+func (i memInfo) Name() string       { return i.name }
+func (i memInfo) Size() int64        { return i.size }
+func (i memInfo) ModTime() time.Time { return i.modTime }
+func (i memInfo) IsDir() bool        { return i.dir }
+func (i memInfo) Sys() any           { return nil }
+
+func (i memInfo) Mode() fs.FileMode {
+	if i.dir {
+		return fs.ModeDir | 0o755
+	}
+	return 0o644
+}
@@ -0,0 +1,52 @@
+package platform
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/netip"
+	"net/url"
+)
+
+// ErrPrivateAddress возвращается, если ссылка ведет на внутренний адрес: loopback,
+// частные сети, link-local (в том числе адреса метаданных облаков) и т.п.
+var ErrPrivateAddress = errors.New("address is not public")
+
+// sharedAddressSpace адреса CGNAT (RFC 6598): снаружи недоступны, как и частные сети
+var sharedAddressSpace = netip.MustParsePrefix("100.64.0.0/10")
+
+// IsPublicIP сообщает, что адрес доступен из интернета
+func IsPublicIP(ip net.IP) bool {
+	addr, ok := netip.AddrFromSlice(ip)
+	if !ok {
+		return false
+	}
+	addr = addr.Unmap()
+	return addr.IsGlobalUnicast() && !addr.IsPrivate() && !sharedAddressSpace.Contains(addr)
+}
+
+// CheckPublicURL разрешает хост ссылки и возвращает ErrPrivateAddress, если хотя бы
+// один из его адресов не публичный. Так пользователь не может направить загрузчик
+// на сервисы внутри сети бота
+func CheckPublicURL(ctx context.Context, rawURL string) error {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return err
+	}
+	host := u.Hostname()
+	if host == "" {
+		return fmt.Errorf("%w: empty host", ErrPrivateAddress)
+	}
+
+	addrs, err := net.DefaultResolver.LookupIPAddr(ctx, host)
+	if err != nil {
+		return fmt.Errorf("failed to resolve %s: %w", host, err)
+	}
+	for _, addr := range addrs {
+		if !IsPublicIP(addr.IP) {
+			return fmt.Errorf("%w: %s resolves to %s", ErrPrivateAddress, host, addr.IP)
+		}
+	}
+	return nil
+}
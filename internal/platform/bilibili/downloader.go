@@ -0,0 +1,74 @@
+package bilibili
+
+import (
+	"context"
+	"log/slog"
+	"strings"
+
+	"github.com/reelser-bot/internal/platform"
+	"github.com/reelser-bot/internal/platform/ytdlp"
+)
+
+// Downloader реализует загрузку видео с Bilibili
+type Downloader struct {
+	logger       *slog.Logger
+	ytdlp        *ytdlp.Client
+	videoQuality string
+}
+
+// NewDownloader создает новый экземпляр Bilibili загрузчика
+func NewDownloader(logger *slog.Logger, client *ytdlp.Client, videoQuality string) *Downloader {
+	return &Downloader{
+		logger:       logger,
+		ytdlp:        client,
+		videoQuality: videoQuality,
+	}
+}
+
+// Download скачивает видео с Bilibili используя yt-dlp.
+// Bilibili отдает видео и звук отдельными DASH-дорожками, yt-dlp склеивает их через ffmpeg.
+// Короткие ссылки b23.tv раскрываются resolver до вызова загрузчика
+func (d *Downloader) Download(ctx context.Context, req platform.Request) (string, error) {
+	url := req.URL
+
+	d.logger.Info("Starting Bilibili video download", slog.String("url", url))
+
+	filePath, err := d.ytdlp.Download(ctx, ytdlp.Options{
+		URL:       url,
+		OutputDir: req.OutputDir,
+		Prefix:    "bilibili",
+		Format:    d.getFormatString(),
+		Quality:   req.Quality,
+		AudioOnly: req.AudioOnly,
+		Language:  req.Language,
+		Section:   req.Section,
+		Args:      []string{"--merge-output-format", "mp4"},
+	})
+	if err != nil {
+		return "", err
+	}
+
+	d.logger.Info("Bilibili video downloaded successfully",
+		slog.String("url", url),
+		slog.String("file", filePath),
+	)
+
+	return filePath, nil
+}
+
+// getFormatString возвращает строку формата для yt-dlp
+func (d *Downloader) getFormatString() string {
+	switch strings.ToLower(d.videoQuality) {
+	case "worst":
+		return "worstvideo+worstaudio/worst"
+	default:
+		return "bestvideo+bestaudio/best"
+	}
+}
+
+// IsValidURL проверяет, является ли URL ссылкой на Bilibili, включая короткие b23.tv
+func IsValidURL(url string) bool {
+	return strings.Contains(url, "bilibili.com/video/") ||
+		strings.Contains(url, "b23.tv/") ||
+		strings.Contains(url, "bili2233.cn/")
+}
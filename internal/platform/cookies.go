@@ -0,0 +1,31 @@
+package platform
+
+import "context"
+
+// Cookies источник файла cookies (формат Netscape) для загрузки
+type Cookies interface {
+	// Acquire возвращает файл для одного запуска загрузчика и функцию, которую нужно
+	// вызвать после его завершения
+	Acquire() (file string, release func(), err error)
+}
+
+// CookiesFile передает загрузчику сам файл: yt-dlp обновляет в нем cookies
+type CookiesFile string
+
+// Acquire возвращает путь к файлу; освобождать нечего
+func (f CookiesFile) Acquire() (string, func(), error) {
+	return string(f), func() {}, nil
+}
+
+type cookiesKey struct{}
+
+// WithCookies привязывает к контексту источник cookies для загрузки
+func WithCookies(ctx context.Context, cookies Cookies) context.Context {
+	return context.WithValue(ctx, cookiesKey{}, cookies)
+}
+
+// CookiesFromContext возвращает источник cookies, выбранный для загрузки
+func CookiesFromContext(ctx context.Context) (Cookies, bool) {
+	cookies, ok := ctx.Value(cookiesKey{}).(Cookies)
+	return cookies, ok && cookies != nil
+}
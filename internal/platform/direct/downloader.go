@@ -0,0 +1,228 @@
+package direct
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"os"
+	"strings"
+)
+
+const defaultUserAgent = "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36"
+
+// probeSize количество первых байт, по которым определяется тип содержимого
+const probeSize = 512
+
+// tsPacketSize размер пакета MPEG-TS; каждый пакет начинается с синхробайта 0x47
+const tsPacketSize = 188
+
+// ErrNotMedia возвращается, если по ссылке отдается не медиа (например, HTML-страница с ошибкой)
+var ErrNotMedia = errors.New("response is not a media file")
+
+// Policy описывает заголовки и cookies, которые требует CDN платформы
+// при скачивании медиа по прямой ссылке
+type Policy struct {
+	UserAgent string
+	Referer   string
+	Headers   map[string]string
+	Cookies   []*http.Cookie
+}
+
+// policies содержит политики по умолчанию для известных платформ
+var policies = map[string]Policy{
+	"tiktok": {
+		UserAgent: defaultUserAgent,
+		Referer:   "https://www.tiktok.com/",
+	},
+	"instagram": {
+		UserAgent: defaultUserAgent,
+		Referer:   "https://www.instagram.com/",
+		Headers: map[string]string{
+			"Origin": "https://www.instagram.com",
+		},
+	},
+	"likee": {
+		UserAgent: defaultUserAgent,
+		Referer:   "https://likee.video/",
+	},
+}
+
+// PolicyFor возвращает политику для платформы или политику по умолчанию
+func PolicyFor(platform string) Policy {
+	if p, ok := policies[strings.ToLower(platform)]; ok {
+		return p
+	}
+	return Policy{UserAgent: defaultUserAgent}
+}
+
+// Apply выставляет заголовки и cookies политики в запрос
+func (p Policy) Apply(req *http.Request) {
+	userAgent := p.UserAgent
+	if userAgent == "" {
+		userAgent = defaultUserAgent
+	}
+	req.Header.Set("User-Agent", userAgent)
+
+	if p.Referer != "" {
+		req.Header.Set("Referer", p.Referer)
+	}
+	for key, value := range p.Headers {
+		req.Header.Set(key, value)
+	}
+	for _, cookie := range p.Cookies {
+		req.AddCookie(cookie)
+	}
+}
+
+// Downloader скачивает медиа по прямым ссылкам с учетом политики платформы
+type Downloader struct {
+	logger *slog.Logger
+	client *http.Client
+}
+
+// NewDownloader создает новый загрузчик прямых ссылок
+func NewDownloader(logger *slog.Logger, client *http.Client) *Downloader {
+	if client == nil {
+		client = http.DefaultClient
+	}
+	return &Downloader{
+		logger: logger,
+		client: client,
+	}
+}
+
+// Download скачивает медиа по ссылке mediaURL в файл outputFile.
+// Перед полной загрузкой проверяются первые байты ответа
+func (d *Downloader) Download(ctx context.Context, mediaURL, outputFile string, policy Policy) error {
+	if err := d.Probe(ctx, mediaURL, policy); err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, mediaURL, nil)
+	if err != nil {
+		return fmt.Errorf("failed to create media request: %w", err)
+	}
+	policy.Apply(req)
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to download media: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("media download returned status code: %d", resp.StatusCode)
+	}
+	if isTextContentType(resp.Header.Get("Content-Type")) {
+		return fmt.Errorf("%w: content type %s", ErrNotMedia, resp.Header.Get("Content-Type"))
+	}
+
+	file, err := os.Create(outputFile)
+	if err != nil {
+		return fmt.Errorf("failed to create output file: %w", err)
+	}
+	defer file.Close()
+
+	if _, err := io.Copy(file, resp.Body); err != nil {
+		os.Remove(outputFile)
+		return fmt.Errorf("failed to save media: %w", err)
+	}
+
+	d.logger.Debug("Direct media downloaded",
+		slog.String("url", mediaURL),
+		slog.String("file", outputFile),
+	)
+
+	return nil
+}
+
+// Probe запрашивает первые байты по ссылке (Range GET) и проверяет, что это медиа
+func (d *Downloader) Probe(ctx context.Context, mediaURL string, policy Policy) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, mediaURL, nil)
+	if err != nil {
+		return fmt.Errorf("failed to create probe request: %w", err)
+	}
+	policy.Apply(req)
+	req.Header.Set("Range", fmt.Sprintf("bytes=0-%d", probeSize-1))
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to probe media: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusPartialContent {
+		return fmt.Errorf("media probe returned status code: %d", resp.StatusCode)
+	}
+
+	contentType := resp.Header.Get("Content-Type")
+	if isTextContentType(contentType) {
+		return fmt.Errorf("%w: content type %s", ErrNotMedia, contentType)
+	}
+
+	head := make([]byte, probeSize)
+	n, err := io.ReadFull(resp.Body, head)
+	if err != nil && !errors.Is(err, io.ErrUnexpectedEOF) && !errors.Is(err, io.EOF) {
+		return fmt.Errorf("failed to read media probe: %w", err)
+	}
+	head = head[:n]
+
+	if !isMediaContentType(contentType) && !hasMediaSignature(head) {
+		return fmt.Errorf("%w: unknown signature (content type %q)", ErrNotMedia, contentType)
+	}
+	return nil
+}
+
+// isTextContentType проверяет, что ответ явно текстовый (HTML/JSON страница ошибки)
+func isTextContentType(contentType string) bool {
+	contentType = strings.ToLower(contentType)
+	return strings.HasPrefix(contentType, "text/") ||
+		strings.Contains(contentType, "json") ||
+		strings.Contains(contentType, "xml")
+}
+
+func isMediaContentType(contentType string) bool {
+	contentType = strings.ToLower(contentType)
+	return strings.HasPrefix(contentType, "video/") ||
+		strings.HasPrefix(contentType, "audio/") ||
+		strings.HasPrefix(contentType, "image/")
+}
+
+// hasMediaSignature проверяет сигнатуры распространенных медиа-контейнеров
+func hasMediaSignature(head []byte) bool {
+	switch {
+	case len(head) >= 8 && bytes.Equal(head[4:8], []byte("ftyp")): // MP4/MOV/M4A
+		return true
+	case bytes.HasPrefix(head, []byte{0x1A, 0x45, 0xDF, 0xA3}): // WebM/MKV
+		return true
+	case isMPEGTS(head):
+		return true
+	case bytes.HasPrefix(head, []byte("ID3")), bytes.HasPrefix(head, []byte{0xFF, 0xFB}): // MP3
+		return true
+	case bytes.HasPrefix(head, []byte{0xFF, 0xD8, 0xFF}): // JPEG
+		return true
+	case bytes.HasPrefix(head, []byte("\x89PNG")): // PNG
+		return true
+	case len(head) >= 12 && bytes.HasPrefix(head, []byte("RIFF")) && bytes.Equal(head[8:12], []byte("WEBP")):
+		return true
+	}
+	return false
+}
+
+// isMPEGTS проверяет синхробайт в начале трех подряд идущих пакетов MPEG-TS:
+// одиночный 0x47 (буква "G") встречается и в обычном тексте
+func isMPEGTS(head []byte) bool {
+	if len(head) < 2*tsPacketSize+1 {
+		return false
+	}
+	for offset := 0; offset <= 2*tsPacketSize; offset += tsPacketSize {
+		if head[offset] != 0x47 {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,32 @@
+package direct
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestHasMediaSignatureMPEGTS(t *testing.T) {
+	ts := make([]byte, probeSize)
+	for offset := 0; offset < len(ts); offset += tsPacketSize {
+		ts[offset] = 0x47
+	}
+	broken := bytes.Clone(ts)
+	broken[2*tsPacketSize] = 0
+
+	tests := []struct {
+		name string
+		head []byte
+		want bool
+	}{
+		{"transport stream", ts, true},
+		{"missing third sync byte", broken, false},
+		{"short transport stream", ts[:2*tsPacketSize], false},
+		{"text starting with G", []byte("GET / HTTP/1.1\r\nHost: example.com\r\n\r\n"), false},
+		{"mp4", []byte("\x00\x00\x00\x18ftypmp42"), true},
+	}
+	for _, tt := range tests {
+		if got := hasMediaSignature(tt.head); got != tt.want {
+			t.Errorf("%s: hasMediaSignature = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,83 @@
+package facebook
+
+import (
+	"context"
+	"log/slog"
+	"strings"
+
+	"github.com/reelser-bot/internal/platform"
+	"github.com/reelser-bot/internal/platform/ytdlp"
+)
+
+// Downloader реализует загрузку видео и Reels с Facebook
+type Downloader struct {
+	logger       *slog.Logger
+	ytdlp        *ytdlp.Client
+	videoQuality string
+}
+
+// NewDownloader создает новый экземпляр Facebook загрузчика
+func NewDownloader(logger *slog.Logger, client *ytdlp.Client, videoQuality string) *Downloader {
+	return &Downloader{
+		logger:       logger,
+		ytdlp:        client,
+		videoQuality: videoQuality,
+	}
+}
+
+// Download скачивает видео с Facebook используя yt-dlp
+func (d *Downloader) Download(ctx context.Context, req platform.Request) (string, error) {
+	url := req.URL
+
+	d.logger.Info("Starting Facebook video download", slog.String("url", url))
+
+	filePath, err := d.ytdlp.Download(ctx, ytdlp.Options{
+		URL:       url,
+		OutputDir: req.OutputDir,
+		Prefix:    "fb",
+		Format:    d.getFormatString(),
+		Quality:   req.Quality,
+		AudioOnly: req.AudioOnly,
+		Language:  req.Language,
+		Section:   req.Section,
+		Args:      []string{"--merge-output-format", "mp4"},
+	})
+	if err != nil {
+		return "", err
+	}
+
+	d.logger.Info("Facebook video downloaded successfully",
+		slog.String("url", url),
+		slog.String("file", filePath),
+	)
+
+	return filePath, nil
+}
+
+// getFormatString возвращает строку формата для yt-dlp
+func (d *Downloader) getFormatString() string {
+	switch strings.ToLower(d.videoQuality) {
+	case "worst":
+		return "worst[ext=mp4]/worst"
+	default:
+		return "bestvideo[ext=mp4]+bestaudio[ext=m4a]/best[ext=mp4]/best"
+	}
+}
+
+// IsValidURL проверяет, является ли URL ссылкой на видео Facebook
+// (fb.watch, facebook.com/reel, facebook.com/watch, facebook.com/.../videos)
+func IsValidURL(url string) bool {
+	if strings.Contains(url, "fb.watch") {
+		return true
+	}
+
+	if !strings.Contains(url, "facebook.com") && !strings.Contains(url, "fb.com") {
+		return false
+	}
+
+	return strings.Contains(url, "/reel") ||
+		strings.Contains(url, "/watch") ||
+		strings.Contains(url, "/videos/") ||
+		strings.Contains(url, "/share/v/") ||
+		strings.Contains(url, "/share/r/")
+}
@@ -0,0 +1,165 @@
+package generic
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/reelser-bot/internal/platform"
+	"github.com/reelser-bot/internal/platform/ytdlp"
+)
+
+// ErrNotAllowed возвращается, если результат по ссылке не входит в разрешенные типы:
+// общий загрузчик не должен пересылать произвольные файлы
+var ErrNotAllowed = errors.New("content type is not allowed")
+
+// Downloader загружает медиа с сайтов без отдельной поддержки через экстракторы yt-dlp.
+// Результат ограничен публичными адресами, списком расширений и известной длительностью
+type Downloader struct {
+	logger       *slog.Logger
+	ytdlp        *ytdlp.Client
+	videoQuality string
+	// extensions разрешенные расширения файлов без точки, в нижнем регистре
+	extensions  map[string]bool
+	maxDuration time.Duration
+}
+
+// NewDownloader создает общий загрузчик. extensions — разрешенные расширения
+// (например, mp4, webm), maxDuration — максимальная длительность (0 — без ограничения)
+func NewDownloader(
+	logger *slog.Logger,
+	client *ytdlp.Client,
+	videoQuality string,
+	extensions []string,
+	maxDuration time.Duration,
+) *Downloader {
+	allowed := make(map[string]bool, len(extensions))
+	for _, ext := range extensions {
+		allowed[strings.ToLower(strings.TrimPrefix(ext, "."))] = true
+	}
+	return &Downloader{
+		logger:       logger,
+		ytdlp:        client,
+		videoQuality: videoQuality,
+		extensions:   allowed,
+		maxDuration:  maxDuration,
+	}
+}
+
+// Download проверяет адрес и метаданные (тип и длительность) и скачивает медиа через
+// yt-dlp. После загрузки расширение файла проверяется еще раз: формат мог смениться
+// при слиянии
+func (d *Downloader) Download(ctx context.Context, req platform.Request) (string, error) {
+	url := req.URL
+
+	d.logger.Info("Starting generic download", slog.String("url", url))
+
+	meta, err := d.probe(ctx, req)
+	if err != nil {
+		return "", err
+	}
+	if err := ytdlp.RequireDuration(meta); err != nil {
+		return "", err
+	}
+	if err := ytdlp.CheckDuration(meta, d.maxDuration); err != nil {
+		return "", err
+	}
+	if !req.AudioOnly && !d.allowed(meta.Ext) {
+		return "", fmt.Errorf("%w: %q", ErrNotAllowed, meta.Ext)
+	}
+
+	filePath, err := d.ytdlp.Download(ctx, ytdlp.Options{
+		URL:       url,
+		OutputDir: req.OutputDir,
+		Prefix:    "generic",
+		Format:    d.getFormatString(),
+		Quality:   req.Quality,
+		AudioOnly: req.AudioOnly,
+		Language:  req.Language,
+		Section:   req.Section,
+		Args:      []string{"--no-playlist"},
+	})
+	if err != nil {
+		return "", err
+	}
+
+	if ext := strings.TrimPrefix(filepath.Ext(filePath), "."); !d.allowed(ext) {
+		os.Remove(filePath)
+		return "", fmt.Errorf("%w: %q", ErrNotAllowed, ext)
+	}
+
+	d.logger.Info("Generic media downloaded successfully",
+		slog.String("url", url),
+		slog.String("file", filePath),
+	)
+
+	return filePath, nil
+}
+
+// Metadata возвращает сведения о медиа без загрузки. Через него же метаданные
+// запрашивают оценка размера, /info и описания ошибок, поэтому адрес проверяется
+// и здесь, до первого запуска yt-dlp
+func (d *Downloader) Metadata(ctx context.Context, req platform.Request) (platform.Metadata, error) {
+	meta, err := d.probe(ctx, req)
+	if err != nil {
+		return platform.Metadata{}, err
+	}
+	return platform.Metadata{
+		Title:    meta.Title,
+		Uploader: meta.Uploader,
+		Duration: meta.DurationValue(),
+		Width:    meta.Width,
+		Height:   meta.Height,
+		Size:     meta.EstimatedSize(),
+		Live:     meta.IsLive,
+		Still:    meta.IsImage(),
+	}, nil
+}
+
+// probe запрашивает метаданные, если ссылка ведет на публичный адрес. Прямые ссылки
+// на медиа из метаданных (после редиректов сайта) проверяются так же
+func (d *Downloader) probe(ctx context.Context, req platform.Request) (*ytdlp.Metadata, error) {
+	if err := platform.CheckPublicURL(ctx, req.URL); err != nil {
+		return nil, err
+	}
+
+	meta, err := d.ytdlp.Probe(ctx, req.URL, ytdlp.LanguageArgs(req.Language)...)
+	if err != nil {
+		return nil, err
+	}
+	for _, mediaURL := range []string{meta.WebpageURL, meta.URL} {
+		if mediaURL == "" {
+			continue
+		}
+		if err := platform.CheckPublicURL(ctx, mediaURL); err != nil {
+			return nil, err
+		}
+	}
+	return meta, nil
+}
+
+// allowed проверяет расширение по списку разрешенных
+func (d *Downloader) allowed(ext string) bool {
+	return d.extensions[strings.ToLower(ext)]
+}
+
+// getFormatString возвращает строку формата для yt-dlp
+func (d *Downloader) getFormatString() string {
+	switch strings.ToLower(d.videoQuality) {
+	case "worst":
+		return "worst[ext=mp4]/worst"
+	default:
+		return "best[ext=mp4]/best"
+	}
+}
+
+// IsValidURL принимает любую http(s) ссылку: общий загрузчик проверяется последним.
+// Адрес хоста проверяется при загрузке: проверка требует DNS-запроса
+func IsValidURL(url string) bool {
+	return strings.HasPrefix(url, "http://") || strings.HasPrefix(url, "https://")
+}
@@ -0,0 +1,142 @@
+package generic
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/reelser-bot/internal/platform"
+	"github.com/reelser-bot/internal/platform/ytdlp"
+)
+
+// publicURL ссылка на публичный адрес: IP-адрес разрешается без запроса к DNS
+const publicURL = "https://93.184.216.34/clip"
+
+func testDownloader(runner *ytdlp.FakeRunner) *Downloader {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	return NewDownloader(logger, ytdlp.NewClient(logger, runner), "best", []string{"mp4", ".WebM"}, 10*time.Minute)
+}
+
+// metadataRunner отвечает на yt-dlp -J заданными метаданными, остальные вызовы
+// обрабатывает как FakeRunner по умолчанию
+func metadataRunner(metadata string) *ytdlp.FakeRunner {
+	runner := &ytdlp.FakeRunner{}
+	runner.Handler = func(cmd ytdlp.Command) ([]byte, []byte, error) {
+		for _, arg := range cmd.Args {
+			if arg == "-J" {
+				return []byte(metadata), nil, nil
+			}
+		}
+		return (&ytdlp.FakeRunner{}).Run(context.Background(), cmd)
+	}
+	return runner
+}
+
+func TestDownload(t *testing.T) {
+	runner := &ytdlp.FakeRunner{}
+	path, err := testDownloader(runner).Download(context.Background(), platform.Request{URL: publicURL, OutputDir: t.TempDir()})
+	if err != nil {
+		t.Fatalf("Download: %v", err)
+	}
+	if !strings.HasSuffix(path, ".mp4") {
+		t.Errorf("Download = %q, want mp4 file", path)
+	}
+	if calls := runner.Calls(); len(calls) != 2 {
+		t.Errorf("yt-dlp was run %d times, want probe and download", len(calls))
+	}
+}
+
+func TestDownloadRejectsPrivateAddresses(t *testing.T) {
+	for _, url := range []string{
+		"http://127.0.0.1:8080/admin",
+		"http://10.0.0.5/video.mp4",
+		"http://192.168.1.1/video.mp4",
+		"http://169.254.169.254/latest/meta-data/",
+		"http://100.64.0.1/video.mp4",
+		"http://[::1]/video.mp4",
+		"http://[fe80::1]/video.mp4",
+		"http://0.0.0.0/video.mp4",
+	} {
+		runner := &ytdlp.FakeRunner{}
+		_, err := testDownloader(runner).Download(context.Background(), platform.Request{URL: url, OutputDir: t.TempDir()})
+		if !errors.Is(err, platform.ErrPrivateAddress) {
+			t.Errorf("Download(%s) error = %v, want ErrPrivateAddress", url, err)
+		}
+		if calls := runner.Calls(); len(calls) != 0 {
+			t.Errorf("Download(%s) ran yt-dlp %d times", url, len(calls))
+		}
+	}
+}
+
+func TestDownloadRejectsPrivateMediaURL(t *testing.T) {
+	runner := metadataRunner(`{"title":"x","duration":10,"ext":"mp4","url":"http://10.1.2.3/internal.mp4"}`)
+	_, err := testDownloader(runner).Download(context.Background(), platform.Request{URL: publicURL, OutputDir: t.TempDir()})
+	if !errors.Is(err, platform.ErrPrivateAddress) {
+		t.Errorf("Download error = %v, want ErrPrivateAddress", err)
+	}
+	if calls := runner.Calls(); len(calls) != 1 {
+		t.Errorf("yt-dlp was run %d times, want only the probe", len(calls))
+	}
+}
+
+func TestDownloadRequiresDuration(t *testing.T) {
+	tests := []struct {
+		name     string
+		metadata string
+		want     error
+	}{
+		{"unknown", `{"title":"x","ext":"mp4"}`, ytdlp.ErrUnknownDuration},
+		{"zero", `{"title":"x","duration":0,"ext":"mp4"}`, ytdlp.ErrUnknownDuration},
+		{"live", `{"title":"x","duration":30,"is_live":true,"ext":"mp4"}`, ytdlp.ErrUnknownDuration},
+		{"not allowed type", `{"title":"x","duration":30,"ext":"exe"}`, ErrNotAllowed},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			runner := metadataRunner(tt.metadata)
+			_, err := testDownloader(runner).Download(context.Background(), platform.Request{URL: publicURL, OutputDir: t.TempDir()})
+			if !errors.Is(err, tt.want) {
+				t.Errorf("Download error = %v, want %v", err, tt.want)
+			}
+		})
+	}
+
+	runner := metadataRunner(`{"title":"x","duration":3600,"ext":"mp4"}`)
+	_, err := testDownloader(runner).Download(context.Background(), platform.Request{URL: publicURL, OutputDir: t.TempDir()})
+	var durationErr *ytdlp.DurationError
+	if !errors.As(err, &durationErr) {
+		t.Errorf("Download of a long video error = %v, want DurationError", err)
+	}
+}
+
+func TestMetadataRejectsPrivateAddress(t *testing.T) {
+	runner := &ytdlp.FakeRunner{}
+	_, err := testDownloader(runner).Metadata(context.Background(), platform.Request{URL: "http://127.0.0.1/"})
+	if !errors.Is(err, platform.ErrPrivateAddress) {
+		t.Errorf("Metadata error = %v, want ErrPrivateAddress", err)
+	}
+	if calls := runner.Calls(); len(calls) != 0 {
+		t.Errorf("Metadata ran yt-dlp %d times", len(calls))
+	}
+}
+
+func TestIsValidURL(t *testing.T) {
+	tests := []struct {
+		url  string
+		want bool
+	}{
+		{"https://example.com/video", true},
+		{"http://example.com/video.mp4", true},
+		{"ftp://example.com/video.mp4", false},
+		{"file:///etc/passwd", false},
+		{"example.com/video", false},
+	}
+	for _, tt := range tests {
+		if got := IsValidURL(tt.url); got != tt.want {
+			t.Errorf("IsValidURL(%q) = %v, want %v", tt.url, got, tt.want)
+		}
+	}
+}
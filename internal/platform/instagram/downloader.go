@@ -2,100 +2,66 @@ package instagram
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log/slog"
-	"os"
-	"os/exec"
-	"path/filepath"
 	"strings"
+
+	"github.com/reelser-bot/internal/platform"
+	"github.com/reelser-bot/internal/platform/ytdlp"
 )
 
+// ErrLoginRequired возвращается, когда Instagram требует авторизацию.
+// Обычно это временное ограничение по rate-limit, и запрос имеет смысл повторить позже
+var ErrLoginRequired = errors.New("instagram login required")
+
 // Downloader реализует загрузку видео с Instagram
 type Downloader struct {
 	logger       *slog.Logger
-	tempDir      string
+	ytdlp        *ytdlp.Client
 	videoQuality string
 }
 
 // NewDownloader создает новый экземпляр Instagram загрузчика
-func NewDownloader(logger *slog.Logger, tempDir, videoQuality string) *Downloader {
+func NewDownloader(logger *slog.Logger, client *ytdlp.Client, videoQuality string) *Downloader {
 	return &Downloader{
 		logger:       logger,
-		tempDir:      tempDir,
+		ytdlp:        client,
 		videoQuality: videoQuality,
 	}
 }
 
 // Download скачивает видео с Instagram используя yt-dlp
 // Возвращает путь к скачанному файлу
-func (d *Downloader) Download(ctx context.Context, url string) (string, error) {
-	d.logger.Info("Starting Instagram video download", slog.String("url", url))
-
-	// Проверяем наличие yt-dlp
-	if _, err := exec.LookPath("yt-dlp"); err != nil {
-		return "", fmt.Errorf("yt-dlp not found. Please install yt-dlp: https://github.com/yt-dlp/yt-dlp")
-	}
-
-	// Создаем временный файл для сохранения видео
-	outputFile := filepath.Join(d.tempDir, "ig_%(title)s.%(ext)s")
+func (d *Downloader) Download(ctx context.Context, req platform.Request) (string, error) {
+	url := req.URL
 
-	// Формируем команду yt-dlp
-	args := []string{
-		url,
-		"-o", outputFile,
-		"-f", d.getFormatString(),
-		"--no-playlist",
-		"--no-warnings",
-		"--quiet",
-	}
-
-	cmd := exec.CommandContext(ctx, "yt-dlp", args...)
-	cmd.Dir = d.tempDir
-
-	output, err := cmd.CombinedOutput()
-	if err != nil {
-		d.logger.Error("Failed to download Instagram video",
-			slog.String("url", url),
-			slog.Any("error", err),
-			slog.String("output", string(output)),
-		)
-		return "", fmt.Errorf("failed to download video: %w", err)
-	}
+	d.logger.Info("Starting Instagram video download", slog.String("url", url))
 
-	// Находим скачанный файл
-	files, err := filepath.Glob(filepath.Join(d.tempDir, "ig_*"))
+	filePath, err := d.ytdlp.Download(ctx, ytdlp.Options{
+		URL:       url,
+		OutputDir: req.OutputDir,
+		Prefix:    "ig",
+		Format:    d.getFormatString(),
+		Quality:   req.Quality,
+		AudioOnly: req.AudioOnly,
+		Language:  req.Language,
+		Section:   req.Section,
+	})
 	if err != nil {
-		return "", fmt.Errorf("failed to find downloaded file: %w", err)
-	}
-
-	if len(files) == 0 {
-		return "", fmt.Errorf("downloaded file not found")
-	}
-
-	// Находим самый новый файл
-	var latestFile string
-	var latestTime int64
-	for _, file := range files {
-		info, err := os.Stat(file)
-		if err != nil {
-			continue
-		}
-		if info.ModTime().Unix() > latestTime {
-			latestTime = info.ModTime().Unix()
-			latestFile = file
+		var ytErr *ytdlp.Error
+		if errors.As(err, &ytErr) && isLoginRequired(ytErr.Output) {
+			return "", ErrLoginRequired
 		}
-	}
-
-	if latestFile == "" {
-		return "", fmt.Errorf("downloaded file not found")
+		return "", fmt.Errorf("failed to download video: %w", err)
 	}
 
 	d.logger.Info("Instagram video downloaded successfully",
 		slog.String("url", url),
-		slog.String("file", latestFile),
+		slog.String("file", filePath),
 	)
 
-	return latestFile, nil
+	return filePath, nil
 }
 
 // getFormatString возвращает строку формата для yt-dlp
@@ -110,8 +76,15 @@ func (d *Downloader) getFormatString() string {
 	}
 }
 
+// isLoginRequired проверяет, сообщил ли yt-dlp о требовании авторизации
+func isLoginRequired(output string) bool {
+	output = strings.ToLower(output)
+	return strings.Contains(output, "login required") ||
+		strings.Contains(output, "rate-limit reached") ||
+		strings.Contains(output, "login_required")
+}
+
 // IsValidURL проверяет, является ли URL валидной ссылкой на Instagram
 func IsValidURL(url string) bool {
 	return strings.Contains(url, "instagram.com")
 }
-
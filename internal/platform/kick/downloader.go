@@ -0,0 +1,106 @@
+package kick
+
+import (
+	"context"
+	"log/slog"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/reelser-bot/internal/platform"
+	"github.com/reelser-bot/internal/platform/ytdlp"
+)
+
+// Downloader реализует загрузку клипов Kick
+type Downloader struct {
+	logger       *slog.Logger
+	ytdlp        *ytdlp.Client
+	videoQuality string
+	maxDuration  time.Duration
+}
+
+// NewDownloader создает новый экземпляр Kick загрузчика
+func NewDownloader(logger *slog.Logger, client *ytdlp.Client, videoQuality string, maxDuration time.Duration) *Downloader {
+	return &Downloader{
+		logger:       logger,
+		ytdlp:        client,
+		videoQuality: videoQuality,
+		maxDuration:  maxDuration,
+	}
+}
+
+// Download скачивает клип Kick используя yt-dlp.
+// Перед загрузкой проверяется длительность, чтобы сразу отклонять длинные VOD
+// и трансляции, запись которых длилась бы до таймаута загрузки
+func (d *Downloader) Download(ctx context.Context, req platform.Request) (string, error) {
+	url := req.URL
+
+	d.logger.Info("Starting Kick clip download", slog.String("url", url))
+
+	meta, err := d.ytdlp.Probe(ctx, url, ytdlp.LanguageArgs(req.Language)...)
+	if err != nil {
+		return "", err
+	}
+	if err := ytdlp.RequireDuration(meta); err != nil {
+		return "", err
+	}
+	if err := ytdlp.CheckDuration(meta, d.maxDuration); err != nil {
+		return "", err
+	}
+
+	filePath, err := d.ytdlp.Download(ctx, ytdlp.Options{
+		URL:       url,
+		OutputDir: req.OutputDir,
+		Prefix:    "kick",
+		Format:    d.getFormatString(),
+		Quality:   req.Quality,
+		AudioOnly: req.AudioOnly,
+		Language:  req.Language,
+		Section:   req.Section,
+	})
+	if err != nil {
+		return "", err
+	}
+
+	d.logger.Info("Kick clip downloaded successfully",
+		slog.String("url", url),
+		slog.String("file", filePath),
+	)
+
+	return filePath, nil
+}
+
+// getFormatString возвращает строку формата для yt-dlp
+func (d *Downloader) getFormatString() string {
+	switch strings.ToLower(d.videoQuality) {
+	case "worst":
+		return "worst[ext=mp4]/worst"
+	default:
+		return "best[ext=mp4]/best"
+	}
+}
+
+// IsValidURL проверяет, является ли URL ссылкой на клип или запись Kick.
+// Клипы: kick.com/<канал>/clips/clip_... или kick.com/<канал>?clip=clip_...,
+// записи: kick.com/video/<id> или kick.com/<канал>/videos/<id>.
+// Ссылки на каналы не принимаются: по ним открывается прямая трансляция
+func IsValidURL(rawURL string) bool {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return false
+	}
+	if host := strings.ToLower(u.Hostname()); host != "kick.com" && host != "www.kick.com" {
+		return false
+	}
+	parts := strings.Split(strings.Trim(u.Path, "/"), "/")
+
+	switch {
+	case len(parts) == 2 && parts[0] == "video":
+		return parts[1] != ""
+	case len(parts) == 3 && (parts[1] == "clips" || parts[1] == "videos"):
+		return parts[2] != ""
+	case len(parts) == 1 && parts[0] != "":
+		return strings.HasPrefix(u.Query().Get("clip"), "clip_")
+	}
+	return false
+}
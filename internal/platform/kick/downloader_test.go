@@ -0,0 +1,57 @@
+package kick
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"testing"
+
+	"github.com/reelser-bot/internal/platform"
+	"github.com/reelser-bot/internal/platform/ytdlp"
+)
+
+func TestDownloadRejectsLiveAndUnknownDuration(t *testing.T) {
+	for name, metadata := range map[string]string{
+		"live":             `{"id":"live","duration":10,"is_live":true,"ext":"mp4"}`,
+		"unknown duration": `{"id":"vod","ext":"mp4"}`,
+	} {
+		t.Run(name, func(t *testing.T) {
+			logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+			runner := &ytdlp.FakeRunner{Handler: func(ytdlp.Command) ([]byte, []byte, error) {
+				return []byte(metadata), nil, nil
+			}}
+			d := NewDownloader(logger, ytdlp.NewClient(logger, runner), "best", 0)
+
+			_, err := d.Download(context.Background(), platform.Request{URL: "https://kick.com/video/0b1c2d3e", OutputDir: t.TempDir()})
+			if !errors.Is(err, ytdlp.ErrUnknownDuration) {
+				t.Fatalf("Download error = %v, want ErrUnknownDuration", err)
+			}
+			if calls := runner.Calls(); len(calls) != 1 {
+				t.Errorf("yt-dlp was run %d times, want only the probe", len(calls))
+			}
+		})
+	}
+}
+
+func TestIsValidURL(t *testing.T) {
+	tests := []struct {
+		url  string
+		want bool
+	}{
+		{"https://kick.com/streamer/clips/clip_01HABCDEF", true},
+		{"https://kick.com/streamer?clip=clip_01HABCDEF", true},
+		{"https://www.kick.com/video/0b1c2d3e-4f50-6172-8394-a5b6c7d8e9f0", true},
+		{"https://kick.com/streamer/videos/0b1c2d3e-4f50-6172-8394-a5b6c7d8e9f0", true},
+		{"https://kick.com/streamer", false},
+		{"https://kick.com/streamer?clip=", false},
+		{"https://kick.com/streamer/clips", false},
+		{"https://kick.com/video/", false},
+		{"https://example.com/kick.com/video/1", false},
+	}
+	for _, tt := range tests {
+		if got := IsValidURL(tt.url); got != tt.want {
+			t.Errorf("IsValidURL(%q) = %v, want %v", tt.url, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,126 @@
+package likee
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/reelser-bot/internal/platform"
+	"github.com/reelser-bot/internal/platform/direct"
+	"github.com/reelser-bot/internal/platform/ytdlp"
+)
+
+// Downloader реализует загрузку видео с Likee
+type Downloader struct {
+	logger       *slog.Logger
+	ytdlp        *ytdlp.Client
+	media        *direct.Downloader
+	videoQuality string
+}
+
+// NewDownloader создает новый экземпляр Likee загрузчика
+func NewDownloader(logger *slog.Logger, client *ytdlp.Client, videoQuality string) *Downloader {
+	return &Downloader{
+		logger:       logger,
+		ytdlp:        client,
+		media:        direct.NewDownloader(logger, platform.NewHTTPClient(5*time.Minute)),
+		videoQuality: videoQuality,
+	}
+}
+
+// Download скачивает видео с Likee. Сначала пробует версию без водяного знака
+// по прямой ссылке CDN, при неудаче скачивает обычную версию через yt-dlp
+func (d *Downloader) Download(ctx context.Context, req platform.Request) (string, error) {
+	url := req.URL
+
+	d.logger.Info("Starting Likee video download", slog.String("url", url))
+
+	// Исходник без водяного знака скачивается целиком, поэтому для фрагмента — только yt-dlp
+	if !req.AudioOnly && req.Section == nil {
+		filePath, err := d.downloadWithoutWatermark(ctx, req)
+		if err == nil {
+			d.logger.Info("Likee video downloaded without watermark",
+				slog.String("url", url),
+				slog.String("file", filePath),
+			)
+			return filePath, nil
+		}
+		d.logger.Info("No-watermark Likee source unavailable, falling back to yt-dlp",
+			slog.String("url", url),
+			slog.Any("error", err),
+		)
+	}
+
+	filePath, err := d.ytdlp.Download(ctx, ytdlp.Options{
+		URL:       url,
+		OutputDir: req.OutputDir,
+		Prefix:    "likee",
+		Format:    d.getFormatString(),
+		Quality:   req.Quality,
+		AudioOnly: req.AudioOnly,
+		Language:  req.Language,
+		Section:   req.Section,
+	})
+	if err != nil {
+		return "", err
+	}
+
+	d.logger.Info("Likee video downloaded successfully",
+		slog.String("url", url),
+		slog.String("file", filePath),
+	)
+
+	return filePath, nil
+}
+
+// downloadWithoutWatermark скачивает исходник без водяного знака, если CDN его отдает
+func (d *Downloader) downloadWithoutWatermark(ctx context.Context, req platform.Request) (string, error) {
+	meta, err := d.ytdlp.Probe(ctx, req.URL, ytdlp.LanguageArgs(req.Language)...)
+	if err != nil {
+		return "", err
+	}
+
+	mediaURL := noWatermarkURL(meta.URL)
+	if mediaURL == "" {
+		return "", fmt.Errorf("no-watermark source not found")
+	}
+
+	outputFile := filepath.Join(req.OutputDir, fmt.Sprintf("likee_%d.mp4", time.Now().UnixNano()))
+	if err := d.media.Download(ctx, mediaURL, outputFile, direct.PolicyFor("likee")); err != nil {
+		return "", err
+	}
+	return outputFile, nil
+}
+
+// noWatermarkURL возвращает ссылку на исходник без водяного знака.
+// Версия с водяным знаком отличается суффиксом "_4" перед расширением
+func noWatermarkURL(mediaURL string) string {
+	base, query, _ := strings.Cut(mediaURL, "?")
+	if !strings.HasSuffix(base, "_4.mp4") {
+		return ""
+	}
+
+	result := strings.TrimSuffix(base, "_4.mp4") + ".mp4"
+	if query != "" {
+		result += "?" + query
+	}
+	return result
+}
+
+// getFormatString возвращает строку формата для yt-dlp
+func (d *Downloader) getFormatString() string {
+	switch strings.ToLower(d.videoQuality) {
+	case "worst":
+		return "worst[ext=mp4]/worst"
+	default:
+		return "best[ext=mp4]/best"
+	}
+}
+
+// IsValidURL проверяет, является ли URL ссылкой на Likee
+func IsValidURL(url string) bool {
+	return strings.Contains(url, "likee.video/")
+}
@@ -0,0 +1,18 @@
+package platform
+
+import "time"
+
+// Metadata сведения о медиа, полученные без загрузки; нулевые поля — неизвестны
+type Metadata struct {
+	Title    string
+	Uploader string
+	Duration time.Duration
+	Width    int
+	Height   int
+	// Size известный или примерный размер файла в байтах
+	Size int64
+	// Live идет прямая трансляция
+	Live bool
+	// Still пост из изображений (фото, слайдшоу): длительности у него нет
+	Still bool
+}
@@ -0,0 +1,72 @@
+package okru
+
+import (
+	"context"
+	"log/slog"
+	"strings"
+
+	"github.com/reelser-bot/internal/platform"
+	"github.com/reelser-bot/internal/platform/ytdlp"
+)
+
+// Downloader реализует загрузку видео с OK.ru
+type Downloader struct {
+	logger       *slog.Logger
+	ytdlp        *ytdlp.Client
+	videoQuality string
+}
+
+// NewDownloader создает новый экземпляр OK.ru загрузчика
+func NewDownloader(logger *slog.Logger, client *ytdlp.Client, videoQuality string) *Downloader {
+	return &Downloader{
+		logger:       logger,
+		ytdlp:        client,
+		videoQuality: videoQuality,
+	}
+}
+
+// Download скачивает видео с OK.ru используя yt-dlp.
+// Качество выбирается из форматов плеера ok.ru тем же способом, что и для остальных платформ
+func (d *Downloader) Download(ctx context.Context, req platform.Request) (string, error) {
+	url := req.URL
+
+	d.logger.Info("Starting OK.ru video download", slog.String("url", url))
+
+	filePath, err := d.ytdlp.Download(ctx, ytdlp.Options{
+		URL:       url,
+		OutputDir: req.OutputDir,
+		Prefix:    "okru",
+		Format:    d.getFormatString(),
+		Quality:   req.Quality,
+		AudioOnly: req.AudioOnly,
+		Language:  req.Language,
+		Section:   req.Section,
+		Args:      []string{"--merge-output-format", "mp4"},
+	})
+	if err != nil {
+		return "", err
+	}
+
+	d.logger.Info("OK.ru video downloaded successfully",
+		slog.String("url", url),
+		slog.String("file", filePath),
+	)
+
+	return filePath, nil
+}
+
+// getFormatString возвращает строку формата для yt-dlp
+func (d *Downloader) getFormatString() string {
+	switch strings.ToLower(d.videoQuality) {
+	case "worst":
+		return "worst[ext=mp4]/worst"
+	default:
+		return "best[ext=mp4]/best"
+	}
+}
+
+// IsValidURL проверяет, является ли URL ссылкой на видео Одноклассников (ok.ru/video, ok.ru/okvideo).
+// Трансляции (ok.ru/live) не принимаются: их запись длилась бы до таймаута загрузки
+func IsValidURL(url string) bool {
+	return strings.Contains(url, "ok.ru/video/") || strings.Contains(url, "ok.ru/okvideo/")
+}
@@ -0,0 +1,78 @@
+package okru
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/reelser-bot/internal/platform"
+	"github.com/reelser-bot/internal/platform/ytdlp"
+)
+
+func TestDownload(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	runner := &ytdlp.FakeRunner{}
+	d := NewDownloader(logger, ytdlp.NewClient(logger, runner), "worst")
+
+	dir := t.TempDir()
+	path, err := d.Download(context.Background(), platform.Request{URL: "https://ok.ru/video/123", OutputDir: dir})
+	if err != nil {
+		t.Fatalf("Download: %v", err)
+	}
+	if filepath.Dir(path) != dir || !strings.HasPrefix(filepath.Base(path), "okru_") {
+		t.Errorf("Download = %q, want okru_* file in %q", path, dir)
+	}
+	if _, err := os.Stat(path); err != nil {
+		t.Errorf("downloaded file: %v", err)
+	}
+
+	calls := runner.Calls()
+	if len(calls) != 1 {
+		t.Fatalf("yt-dlp was run %d times, want 1", len(calls))
+	}
+	args := strings.Join(calls[0].Args, " ")
+	for _, want := range []string{"https://ok.ru/video/123", "-f worst[ext=mp4]/worst", "--merge-output-format mp4"} {
+		if !strings.Contains(args, want) {
+			t.Errorf("args %q do not contain %q", args, want)
+		}
+	}
+}
+
+func TestDownloadAudioOnly(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	runner := &ytdlp.FakeRunner{}
+	d := NewDownloader(logger, ytdlp.NewClient(logger, runner), "best")
+
+	_, err := d.Download(context.Background(), platform.Request{URL: "https://ok.ru/video/123", OutputDir: t.TempDir(), AudioOnly: true})
+	if err != nil {
+		t.Fatalf("Download: %v", err)
+	}
+
+	args := strings.Join(runner.Calls()[0].Args, " ")
+	if !strings.Contains(args, "-x --audio-format mp3") || strings.Contains(args, "best[ext=mp4]") {
+		t.Errorf("args %q do not select audio only", args)
+	}
+}
+
+func TestIsValidURL(t *testing.T) {
+	tests := []struct {
+		url  string
+		want bool
+	}{
+		{"https://ok.ru/video/123456", true},
+		{"https://m.ok.ru/video/123456", true},
+		{"https://ok.ru/okvideo/topic/123", true},
+		{"https://ok.ru/live/987", false},
+		{"https://ok.ru/profile/123", false},
+		{"https://vk.com/video123", false},
+	}
+	for _, tt := range tests {
+		if got := IsValidURL(tt.url); got != tt.want {
+			t.Errorf("IsValidURL(%q) = %v, want %v", tt.url, got, tt.want)
+		}
+	}
+}
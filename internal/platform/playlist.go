@@ -0,0 +1,15 @@
+package platform
+
+// Playlist описывает плейлист, развернутый в список отдельных видео
+type Playlist struct {
+	// Title название плейлиста
+	Title string
+	// Items первые элементы плейлиста в порядке воспроизведения
+	Items []PlaylistItem
+}
+
+// PlaylistItem элемент плейлиста
+type PlaylistItem struct {
+	URL   string
+	Title string
+}
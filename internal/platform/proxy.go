@@ -0,0 +1,45 @@
+package platform
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// DirectRoute обозначает прямое подключение без прокси в настройках маршрутов
+const DirectRoute = "direct"
+
+type proxyKey struct{}
+
+// WithProxy привязывает к контексту прокси для исходящих запросов загрузки.
+// Пустая строка означает прямое подключение (в обход прокси из окружения)
+func WithProxy(ctx context.Context, proxy string) context.Context {
+	return context.WithValue(ctx, proxyKey{}, proxy)
+}
+
+// ProxyFromContext возвращает прокси, выбранный для загрузки
+func ProxyFromContext(ctx context.Context) (string, bool) {
+	proxy, ok := ctx.Value(proxyKey{}).(string)
+	return proxy, ok
+}
+
+// ProxyFromRequest выбирает прокси для HTTP-запроса загрузчика (http.Transport.Proxy):
+// из контекста запроса, а если маршрут не задан — из переменных окружения
+func ProxyFromRequest(req *http.Request) (*url.URL, error) {
+	proxy, ok := ProxyFromContext(req.Context())
+	if !ok {
+		return http.ProxyFromEnvironment(req)
+	}
+	if proxy == "" {
+		return nil, nil
+	}
+	return url.Parse(proxy)
+}
+
+// NewHTTPClient создает HTTP-клиент загрузчика, учитывающий прокси из контекста
+func NewHTTPClient(timeout time.Duration) *http.Client {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = ProxyFromRequest
+	return &http.Client{Timeout: timeout, Transport: transport}
+}
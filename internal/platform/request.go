@@ -0,0 +1,60 @@
+package platform
+
+import (
+	"strconv"
+	"strings"
+)
+
+// Варианты качества загрузки. Кроме констант качество может ограничивать высоту кадра:
+// "720p" (HeightQuality)
+const (
+	// QualityDefault качество по настройке VIDEO_QUALITY
+	QualityDefault = ""
+	// QualityHD лучшее доступное качество
+	QualityHD = "hd"
+	// QualitySD сниженное качество (до 480p) для экономии трафика
+	QualitySD = "sd"
+)
+
+// sdHeight предельная высота кадра для QualitySD
+const sdHeight = 480
+
+// HeightQuality возвращает вариант качества не выше height точек по вертикали
+func HeightQuality(height int) string {
+	return strconv.Itoa(height) + "p"
+}
+
+// MaxHeight возвращает предельную высоту кадра для варианта качества; 0 — без ограничения
+func MaxHeight(quality string) int {
+	if quality == QualitySD {
+		return sdHeight
+	}
+	digits, ok := strings.CutSuffix(quality, "p")
+	if !ok {
+		return 0
+	}
+	height, err := strconv.Atoi(digits)
+	if err != nil || height <= 0 {
+		return 0
+	}
+	return height
+}
+
+// Request описывает параметры одной загрузки, передаваемые платформенному загрузчику
+type Request struct {
+	// URL ссылка на видео
+	URL string
+	// OutputDir директория, в которую нужно сохранить файл
+	OutputDir string
+	// Password пароль для защищенных видео (Vimeo), пусто — без пароля
+	Password string
+	// Quality вариант качества (QualityDefault, QualityHD, QualitySD, HeightQuality)
+	Quality string
+	// AudioOnly скачать только звуковую дорожку
+	AudioOnly bool
+	// Language язык пользователя (код Telegram, например "ru" или "pt-br"), пусто — неизвестен.
+	// Используется для локализации метаданных и выбора субтитров
+	Language string
+	// Section фрагмент видео; nil — ролик целиком
+	Section *Section
+}
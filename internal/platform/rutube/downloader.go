@@ -0,0 +1,71 @@
+package rutube
+
+import (
+	"context"
+	"log/slog"
+	"strings"
+
+	"github.com/reelser-bot/internal/platform"
+	"github.com/reelser-bot/internal/platform/ytdlp"
+)
+
+// Downloader реализует загрузку видео с Rutube
+type Downloader struct {
+	logger       *slog.Logger
+	ytdlp        *ytdlp.Client
+	videoQuality string
+}
+
+// NewDownloader создает новый экземпляр Rutube загрузчика
+func NewDownloader(logger *slog.Logger, client *ytdlp.Client, videoQuality string) *Downloader {
+	return &Downloader{
+		logger:       logger,
+		ytdlp:        client,
+		videoQuality: videoQuality,
+	}
+}
+
+// Download скачивает видео с Rutube используя yt-dlp.
+// Rutube отдает HLS-потоки, yt-dlp собирает их в mp4 через ffmpeg
+func (d *Downloader) Download(ctx context.Context, req platform.Request) (string, error) {
+	url := req.URL
+
+	d.logger.Info("Starting Rutube video download", slog.String("url", url))
+
+	filePath, err := d.ytdlp.Download(ctx, ytdlp.Options{
+		URL:       url,
+		OutputDir: req.OutputDir,
+		Prefix:    "rutube",
+		Format:    d.getFormatString(),
+		Quality:   req.Quality,
+		AudioOnly: req.AudioOnly,
+		Language:  req.Language,
+		Section:   req.Section,
+		Args:      []string{"--merge-output-format", "mp4"},
+	})
+	if err != nil {
+		return "", err
+	}
+
+	d.logger.Info("Rutube video downloaded successfully",
+		slog.String("url", url),
+		slog.String("file", filePath),
+	)
+
+	return filePath, nil
+}
+
+// getFormatString возвращает строку формата для yt-dlp
+func (d *Downloader) getFormatString() string {
+	switch strings.ToLower(d.videoQuality) {
+	case "worst":
+		return "worst"
+	default:
+		return "best"
+	}
+}
+
+// IsValidURL проверяет, является ли URL ссылкой на Rutube (видео и shorts)
+func IsValidURL(url string) bool {
+	return strings.Contains(url, "rutube.ru/")
+}
@@ -0,0 +1,77 @@
+package platform
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Section фрагмент видео, который нужно скачать вместо целого ролика
+type Section struct {
+	Start time.Duration
+	End   time.Duration
+}
+
+// ParseSection разбирает диапазон вида "00:30-01:45" или "1:02:03-1:05:00"
+func ParseSection(s string) (Section, error) {
+	startText, endText, ok := strings.Cut(s, "-")
+	if !ok {
+		return Section{}, fmt.Errorf("invalid section %q: expected START-END", s)
+	}
+
+	start, err := parseTimestamp(startText)
+	if err != nil {
+		return Section{}, err
+	}
+	end, err := parseTimestamp(endText)
+	if err != nil {
+		return Section{}, err
+	}
+	if end <= start {
+		return Section{}, fmt.Errorf("invalid section %q: end must be after start", s)
+	}
+
+	return Section{Start: start, End: end}, nil
+}
+
+// parseTimestamp разбирает отметку времени "MM:SS" или "HH:MM:SS"
+func parseTimestamp(s string) (time.Duration, error) {
+	parts := strings.Split(s, ":")
+	if len(parts) < 2 || len(parts) > 3 {
+		return 0, fmt.Errorf("invalid timestamp %q: expected MM:SS or HH:MM:SS", s)
+	}
+
+	var total time.Duration
+	for i, part := range parts {
+		value, err := strconv.Atoi(part)
+		if err != nil || value < 0 {
+			return 0, fmt.Errorf("invalid timestamp %q", s)
+		}
+		// Минуты и секунды после первого поля не могут быть больше 59
+		if i > 0 && value > 59 {
+			return 0, fmt.Errorf("invalid timestamp %q", s)
+		}
+		total = total*60 + time.Duration(value)
+	}
+	return total * time.Second, nil
+}
+
+// Duration возвращает длительность фрагмента
+func (s Section) Duration() time.Duration {
+	return s.End - s.Start
+}
+
+// String форматирует фрагмент как "00:30–01:45"
+func (s Section) String() string {
+	return formatTimestamp(s.Start) + "–" + formatTimestamp(s.End)
+}
+
+// formatTimestamp форматирует отметку как MM:SS или H:MM:SS для длинных видео
+func formatTimestamp(d time.Duration) string {
+	seconds := int(d / time.Second)
+	if seconds >= 3600 {
+		return fmt.Sprintf("%d:%02d:%02d", seconds/3600, seconds/60%60, seconds%60)
+	}
+	return fmt.Sprintf("%02d:%02d", seconds/60, seconds%60)
+}
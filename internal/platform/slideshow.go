@@ -0,0 +1,66 @@
+package platform
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// SlideshowExt расширение каталога, в который сохраняется пост-слайдшоу
+// (фото и звуковая дорожка) вместо одного файла
+const SlideshowExt = ".slides"
+
+// slideshowAudio имя файла звуковой дорожки в каталоге слайдшоу (без расширения)
+const slideshowAudio = "audio"
+
+// Slideshow содержимое скачанного слайдшоу
+type Slideshow struct {
+	// Images пути к изображениям в порядке показа
+	Images []string
+	// Audio путь к звуковой дорожке, пусто — без звука
+	Audio string
+}
+
+// IsSlideshow проверяет, что путь, возвращенный загрузчиком, указывает на каталог слайдшоу
+func IsSlideshow(path string) bool {
+	return strings.HasSuffix(path, SlideshowExt)
+}
+
+// ImagePath возвращает путь для i-го изображения слайдшоу (нумерация с нуля)
+func ImagePath(dir string, i int, ext string) string {
+	return filepath.Join(dir, fmt.Sprintf("%03d.%s", i+1, ext))
+}
+
+// AudioPath возвращает путь для звуковой дорожки слайдшоу
+func AudioPath(dir, ext string) string {
+	return filepath.Join(dir, slideshowAudio+"."+ext)
+}
+
+// ReadSlideshow читает содержимое каталога слайдшоу
+func ReadSlideshow(dir string) (Slideshow, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return Slideshow{}, fmt.Errorf("failed to read slideshow: %w", err)
+	}
+
+	var show Slideshow
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		name := entry.Name()
+		if strings.TrimSuffix(name, filepath.Ext(name)) == slideshowAudio {
+			show.Audio = filepath.Join(dir, name)
+			continue
+		}
+		show.Images = append(show.Images, filepath.Join(dir, name))
+	}
+	sort.Strings(show.Images)
+
+	if len(show.Images) == 0 {
+		return Slideshow{}, fmt.Errorf("slideshow has no images")
+	}
+	return show, nil
+}
@@ -7,70 +7,235 @@ import (
 	"io"
 	"log/slog"
 	"net/http"
+	neturl "net/url"
 	"os"
 	"path/filepath"
 	"strings"
+	"sync/atomic"
 	"time"
+
+	"github.com/reelser-bot/internal/platform"
+	"github.com/reelser-bot/internal/platform/direct"
 )
 
+// DefaultEndpoint адрес TikWM API, если провайдеры не заданы
+const DefaultEndpoint = "https://tikwm.com/api"
+
+// Provider API, совместимое с TikWM
+type Provider struct {
+	// Endpoint адрес API, ссылка на пост передается параметром url
+	Endpoint string
+	// APIKey ключ провайдера, передается заголовком X-API-Key; пусто — без ключа
+	APIKey string
+}
+
 // Downloader реализует загрузку видео с TikTok
 type Downloader struct {
-	logger  *slog.Logger
-	tempDir string
-	client  *http.Client
+	logger    *slog.Logger
+	client    *http.Client
+	media     *direct.Downloader
+	providers []Provider
+	// preferred индекс провайдера, ответившего последним: с него начинаются запросы,
+	// чтобы не ждать отказа недоступного провайдера каждый раз
+	preferred atomic.Int32
 }
 
-// NewDownloader создает новый экземпляр TikTok загрузчика
-func NewDownloader(logger *slog.Logger, tempDir string) *Downloader {
+// NewDownloader создает новый экземпляр TikTok загрузчика. Провайдеры перебираются
+// по порядку, пока один из них не ответит
+func NewDownloader(logger *slog.Logger, providers []Provider) *Downloader {
+	// Клиент учитывает маршрут (прокси), выбранный для загрузки
+	client := platform.NewHTTPClient(30 * time.Second)
+
+	if len(providers) == 0 {
+		providers = []Provider{{Endpoint: DefaultEndpoint}}
+	}
+
 	return &Downloader{
-		logger:  logger,
-		tempDir: tempDir,
-		client: &http.Client{
-			Timeout: 30 * time.Second,
-		},
+		logger:    logger,
+		client:    client,
+		media:     direct.NewDownloader(logger, client),
+		providers: providers,
 	}
 }
 
 // Download скачивает видео с TikTok используя TikWM API
 // Возвращает путь к скачанному файлу
-func (d *Downloader) Download(ctx context.Context, url string) (string, error) {
-	d.logger.Info("Starting TikTok video download", slog.String("url", url))
+func (d *Downloader) Download(ctx context.Context, req platform.Request) (string, error) {
+	url := req.URL
 
-	// Используем TikWM API для получения прямой ссылки на видео
-	apiURL := fmt.Sprintf("https://tikwm.com/api?url=%s", url)
+	d.logger.Info("Starting TikTok video download", slog.String("url", url))
 
-	req, err := http.NewRequestWithContext(ctx, "GET", apiURL, nil)
+	data, err := d.fetch(ctx, url)
 	if err != nil {
-		return "", fmt.Errorf("failed to create request: %w", err)
+		return "", err
 	}
 
-	req.Header.Set("User-Agent", "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36")
+	// Фото-пост: скачиваем все изображения и музыку. Для варианта «только звук»
+	// подходит обычная ветка — музыка отдается в data.music
+	if len(data.Images) > 0 && !req.AudioOnly {
+		return d.downloadSlideshow(ctx, req, data.Images, data.Music)
+	}
 
-	resp, err := d.client.Do(req)
-	if err != nil {
-		d.logger.Error("Failed to fetch TikTok video info",
+	if data.Play == "" {
+		return "", fmt.Errorf("video URL not found in API response")
+	}
+
+	// Выбираем вариант: HD-версия и звуковая дорожка отдаются API отдельными ссылками
+	playURL := data.Play
+	ext := "mp4"
+	switch {
+	case req.AudioOnly:
+		if data.Music == "" {
+			return "", fmt.Errorf("audio URL not found in API response")
+		}
+		playURL = data.Music
+		ext = "mp3"
+	case req.Quality == platform.QualityHD && data.HDPlay != "":
+		playURL = data.HDPlay
+	}
+
+	// Скачиваем видео с учетом политики CDN TikTok
+	outputFile := filepath.Join(req.OutputDir, fmt.Sprintf("tiktok_%d.%s", time.Now().UnixNano(), ext))
+
+	if err := d.media.Download(ctx, playURL, outputFile, direct.PolicyFor("tiktok")); err != nil {
+		return "", err
+	}
+
+	d.logger.Info("TikTok video downloaded successfully",
+		slog.String("url", url),
+		slog.String("file", outputFile),
+	)
+
+	return outputFile, nil
+}
+
+// downloadSlideshow скачивает изображения и музыку фото-поста в каталог слайдшоу
+func (d *Downloader) downloadSlideshow(ctx context.Context, req platform.Request, images []string, music string) (string, error) {
+	dir := filepath.Join(req.OutputDir, fmt.Sprintf("tiktok_%d%s", time.Now().UnixNano(), platform.SlideshowExt))
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return "", fmt.Errorf("failed to create slideshow dir: %w", err)
+	}
+
+	policy := direct.PolicyFor("tiktok")
+	for i, imageURL := range images {
+		if err := d.media.Download(ctx, imageURL, platform.ImagePath(dir, i, "jpg"), policy); err != nil {
+			os.RemoveAll(dir)
+			return "", fmt.Errorf("failed to download slideshow image %d: %w", i+1, err)
+		}
+	}
+
+	// Слайдшоу без музыки все равно отправляется, поэтому ошибка звука не критична
+	if music != "" {
+		if err := d.media.Download(ctx, music, platform.AudioPath(dir, "mp3"), policy); err != nil {
+			d.logger.Warn("Failed to download slideshow music",
+				slog.String("url", req.URL),
+				slog.Any("error", err),
+			)
+		}
+	}
+
+	d.logger.Info("TikTok slideshow downloaded successfully",
+		slog.String("url", req.URL),
+		slog.String("dir", dir),
+		slog.Int("images", len(images)),
+	)
+
+	return dir, nil
+}
+
+// apiData данные о посте из ответа TikWM API
+type apiData struct {
+	Play     string `json:"play"`
+	HDPlay   string `json:"hdplay"`
+	Music    string `json:"music"`
+	Title    string `json:"title"`
+	Duration int    `json:"duration"`
+	Size     int64  `json:"size"`
+	HDSize   int64  `json:"hd_size"`
+	Author   struct {
+		Nickname string `json:"nickname"`
+	} `json:"author"`
+	// Images заполнен для фото-постов (слайдшоу)
+	Images []string `json:"images"`
+}
+
+// fetch запрашивает сведения о посте и прямые ссылки на медиа, переключаясь
+// на следующего провайдера при ошибке
+func (d *Downloader) fetch(ctx context.Context, url string) (apiData, error) {
+	start := int(d.preferred.Load())
+
+	var lastErr error
+	for i := range d.providers {
+		index := (start + i) % len(d.providers)
+		provider := d.providers[index]
+
+		data, err := d.fetchFrom(ctx, provider, url)
+		if err == nil {
+			if index != start {
+				d.preferred.Store(int32(index))
+				d.logger.Info("Switched TikTok API provider", slog.String("endpoint", provider.Endpoint))
+			}
+			return data, nil
+		}
+		if ctx.Err() != nil {
+			return apiData{}, err
+		}
+
+		d.logger.Warn("TikTok API provider failed",
+			slog.String("endpoint", provider.Endpoint),
 			slog.String("url", url),
 			slog.Any("error", err),
 		)
-		return "", fmt.Errorf("failed to fetch video info: %w", err)
+		lastErr = err
+	}
+
+	d.logger.Error("Failed to fetch TikTok video info",
+		slog.String("url", url),
+		slog.Int("providers", len(d.providers)),
+		slog.Any("error", lastErr),
+	)
+	return apiData{}, lastErr
+}
+
+// fetchFrom запрашивает сведения о посте у одного провайдера
+func (d *Downloader) fetchFrom(ctx context.Context, provider Provider, url string) (apiData, error) {
+	apiURL, err := neturl.Parse(provider.Endpoint)
+	if err != nil {
+		return apiData{}, fmt.Errorf("invalid API endpoint: %w", err)
+	}
+	query := apiURL.Query()
+	query.Set("url", url)
+	apiURL.RawQuery = query.Encode()
+
+	httpReq, err := http.NewRequestWithContext(ctx, "GET", apiURL.String(), nil)
+	if err != nil {
+		return apiData{}, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	direct.Policy{}.Apply(httpReq)
+	if provider.APIKey != "" {
+		httpReq.Header.Set("X-API-Key", provider.APIKey)
+	}
+
+	resp, err := d.client.Do(httpReq)
+	if err != nil {
+		return apiData{}, fmt.Errorf("failed to fetch video info: %w", err)
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		return "", fmt.Errorf("API returned status code: %d", resp.StatusCode)
+		return apiData{}, fmt.Errorf("API returned status code: %d", resp.StatusCode)
 	}
 
-	// Парсим JSON ответ
 	var apiResponse struct {
-		Code int `json:"code"`
-		Data struct {
-			Play string `json:"play"`
-		} `json:"data"`
+		Code int     `json:"code"`
+		Msg  string  `json:"msg"`
+		Data apiData `json:"data"`
 	}
 
 	body, err := io.ReadAll(resp.Body)
 	if err != nil {
-		return "", fmt.Errorf("failed to read response: %w", err)
+		return apiData{}, fmt.Errorf("failed to read response: %w", err)
 	}
 
 	// Парсим JSON ответ
@@ -78,58 +243,38 @@ func (d *Downloader) Download(ctx context.Context, url string) (string, error) {
 		// Если не удалось распарсить JSON, пробуем извлечь URL вручную
 		playURL := extractPlayURL(string(body))
 		if playURL == "" {
-			return "", fmt.Errorf("failed to parse API response: %w", err)
+			return apiData{}, fmt.Errorf("failed to parse API response: %w", err)
 		}
 		apiResponse.Data.Play = playURL
 	}
-
-	if apiResponse.Data.Play == "" {
-		return "", fmt.Errorf("video URL not found in API response")
+	// TikWM сообщает об ошибке кодом, отличным от нуля, при статусе 200
+	if apiResponse.Code != 0 {
+		return apiData{}, fmt.Errorf("API returned error code %d: %s", apiResponse.Code, apiResponse.Msg)
 	}
-
-	playURL := apiResponse.Data.Play
-
-	// Скачиваем видео
-	videoReq, err := http.NewRequestWithContext(ctx, "GET", playURL, nil)
-	if err != nil {
-		return "", fmt.Errorf("failed to create video request: %w", err)
+	if apiResponse.Data.Play == "" && len(apiResponse.Data.Images) == 0 {
+		return apiData{}, fmt.Errorf("video not found in API response")
 	}
 
-	videoReq.Header.Set("User-Agent", "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36")
-	videoReq.Header.Set("Referer", "https://www.tiktok.com/")
+	return apiResponse.Data, nil
+}
 
-	videoResp, err := d.client.Do(videoReq)
+// Metadata возвращает сведения о посте из TikWM API без загрузки видео
+func (d *Downloader) Metadata(ctx context.Context, req platform.Request) (platform.Metadata, error) {
+	data, err := d.fetch(ctx, req.URL)
 	if err != nil {
-		return "", fmt.Errorf("failed to download video: %w", err)
-	}
-	defer videoResp.Body.Close()
-
-	if videoResp.StatusCode != http.StatusOK {
-		return "", fmt.Errorf("video download returned status code: %d", videoResp.StatusCode)
+		return platform.Metadata{}, err
 	}
-
-	// Создаем временный файл
-	outputFile := filepath.Join(d.tempDir, fmt.Sprintf("tiktok_%d.mp4", time.Now().Unix()))
-
-	file, err := os.Create(outputFile)
-	if err != nil {
-		return "", fmt.Errorf("failed to create output file: %w", err)
+	meta := platform.Metadata{
+		Title:    data.Title,
+		Uploader: data.Author.Nickname,
+		Duration: time.Duration(data.Duration) * time.Second,
+		Size:     data.Size,
+		Still:    len(data.Images) > 0,
 	}
-	defer file.Close()
-
-	// Копируем данные
-	_, err = io.Copy(file, videoResp.Body)
-	if err != nil {
-		os.Remove(outputFile)
-		return "", fmt.Errorf("failed to save video: %w", err)
+	if req.Quality == platform.QualityHD && data.HDSize > 0 {
+		meta.Size = data.HDSize
 	}
-
-	d.logger.Info("TikTok video downloaded successfully",
-		slog.String("url", url),
-		slog.String("file", outputFile),
-	)
-
-	return outputFile, nil
+	return meta, nil
 }
 
 // extractPlayURL извлекает URL видео из JSON ответа API
@@ -0,0 +1,107 @@
+package twitch
+
+import (
+	"context"
+	"log/slog"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/reelser-bot/internal/platform"
+	"github.com/reelser-bot/internal/platform/ytdlp"
+)
+
+// Downloader реализует загрузку клипов Twitch
+type Downloader struct {
+	logger       *slog.Logger
+	ytdlp        *ytdlp.Client
+	videoQuality string
+	maxDuration  time.Duration
+}
+
+// NewDownloader создает новый экземпляр Twitch загрузчика
+func NewDownloader(logger *slog.Logger, client *ytdlp.Client, videoQuality string, maxDuration time.Duration) *Downloader {
+	return &Downloader{
+		logger:       logger,
+		ytdlp:        client,
+		videoQuality: videoQuality,
+		maxDuration:  maxDuration,
+	}
+}
+
+// Download скачивает клип Twitch используя yt-dlp.
+// Перед загрузкой проверяется длительность, чтобы сразу отклонять длинные VOD
+// и трансляции, запись которых длилась бы до таймаута загрузки
+func (d *Downloader) Download(ctx context.Context, req platform.Request) (string, error) {
+	url := req.URL
+
+	d.logger.Info("Starting Twitch clip download", slog.String("url", url))
+
+	meta, err := d.ytdlp.Probe(ctx, url, ytdlp.LanguageArgs(req.Language)...)
+	if err != nil {
+		return "", err
+	}
+	if err := ytdlp.RequireDuration(meta); err != nil {
+		return "", err
+	}
+	if err := ytdlp.CheckDuration(meta, d.maxDuration); err != nil {
+		return "", err
+	}
+
+	filePath, err := d.ytdlp.Download(ctx, ytdlp.Options{
+		URL:       url,
+		OutputDir: req.OutputDir,
+		Prefix:    "twitch",
+		Format:    d.getFormatString(),
+		Quality:   req.Quality,
+		AudioOnly: req.AudioOnly,
+		Language:  req.Language,
+		Section:   req.Section,
+	})
+	if err != nil {
+		return "", err
+	}
+
+	d.logger.Info("Twitch clip downloaded successfully",
+		slog.String("url", url),
+		slog.String("file", filePath),
+	)
+
+	return filePath, nil
+}
+
+// getFormatString возвращает строку формата для yt-dlp
+func (d *Downloader) getFormatString() string {
+	switch strings.ToLower(d.videoQuality) {
+	case "worst":
+		return "worst[ext=mp4]/worst"
+	default:
+		return "best[ext=mp4]/best"
+	}
+}
+
+// IsValidURL проверяет, является ли URL ссылкой на клип или запись Twitch:
+// clips.twitch.tv/<клип>, twitch.tv/<канал>/clip/<клип> или twitch.tv/videos/<id>.
+// Ссылки на каналы не принимаются: по ним открывается прямая трансляция
+func IsValidURL(rawURL string) bool {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return false
+	}
+	host := strings.ToLower(u.Hostname())
+	host = strings.TrimPrefix(strings.TrimPrefix(host, "www."), "m.")
+	parts := strings.Split(strings.Trim(u.Path, "/"), "/")
+
+	switch host {
+	case "clips.twitch.tv":
+		return len(parts) == 1 && parts[0] != ""
+	case "twitch.tv":
+		switch {
+		case len(parts) == 2 && parts[0] == "videos":
+			return parts[1] != ""
+		case len(parts) == 3 && parts[1] == "clip":
+			return parts[2] != ""
+		}
+	}
+	return false
+}
@@ -0,0 +1,57 @@
+package twitch
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"testing"
+
+	"github.com/reelser-bot/internal/platform"
+	"github.com/reelser-bot/internal/platform/ytdlp"
+)
+
+func TestDownloadRejectsLiveAndUnknownDuration(t *testing.T) {
+	for name, metadata := range map[string]string{
+		"live":             `{"id":"live","duration":10,"is_live":true,"ext":"mp4"}`,
+		"unknown duration": `{"id":"vod","ext":"mp4"}`,
+	} {
+		t.Run(name, func(t *testing.T) {
+			logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+			runner := &ytdlp.FakeRunner{Handler: func(ytdlp.Command) ([]byte, []byte, error) {
+				return []byte(metadata), nil, nil
+			}}
+			d := NewDownloader(logger, ytdlp.NewClient(logger, runner), "best", 0)
+
+			_, err := d.Download(context.Background(), platform.Request{URL: "https://www.twitch.tv/videos/123", OutputDir: t.TempDir()})
+			if !errors.Is(err, ytdlp.ErrUnknownDuration) {
+				t.Fatalf("Download error = %v, want ErrUnknownDuration", err)
+			}
+			if calls := runner.Calls(); len(calls) != 1 {
+				t.Errorf("yt-dlp was run %d times, want only the probe", len(calls))
+			}
+		})
+	}
+}
+
+func TestIsValidURL(t *testing.T) {
+	tests := []struct {
+		url  string
+		want bool
+	}{
+		{"https://clips.twitch.tv/FunnyClipSlug", true},
+		{"https://www.twitch.tv/streamer/clip/FunnyClipSlug", true},
+		{"https://m.twitch.tv/streamer/clip/FunnyClipSlug?filter=clips", true},
+		{"https://www.twitch.tv/videos/123456789", true},
+		{"https://www.twitch.tv/streamer", false},
+		{"https://www.twitch.tv/streamer/videos", false},
+		{"https://clips.twitch.tv/", false},
+		{"https://www.twitch.tv/videos/", false},
+		{"https://example.com/twitch.tv/videos/1", false},
+	}
+	for _, tt := range tests {
+		if got := IsValidURL(tt.url); got != tt.want {
+			t.Errorf("IsValidURL(%q) = %v, want %v", tt.url, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,95 @@
+package vimeo
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"strings"
+
+	"github.com/reelser-bot/internal/platform"
+	"github.com/reelser-bot/internal/platform/ytdlp"
+)
+
+// ErrPasswordRequired возвращается, если видео защищено паролем, а пароль не передан или неверен
+var ErrPasswordRequired = errors.New("vimeo video password required")
+
+// Downloader реализует загрузку видео с Vimeo
+type Downloader struct {
+	logger       *slog.Logger
+	ytdlp        *ytdlp.Client
+	videoQuality string
+}
+
+// NewDownloader создает новый экземпляр Vimeo загрузчика
+func NewDownloader(logger *slog.Logger, client *ytdlp.Client, videoQuality string) *Downloader {
+	return &Downloader{
+		logger:       logger,
+		ytdlp:        client,
+		videoQuality: videoQuality,
+	}
+}
+
+// Download скачивает видео с Vimeo используя yt-dlp.
+// Для видео, защищенных паролем, используется req.Password
+func (d *Downloader) Download(ctx context.Context, req platform.Request) (string, error) {
+	url := req.URL
+
+	d.logger.Info("Starting Vimeo video download",
+		slog.String("url", url),
+		slog.Bool("with_password", req.Password != ""),
+	)
+
+	var args []string
+	if req.Password != "" {
+		args = append(args, "--video-password", req.Password)
+	}
+
+	filePath, err := d.ytdlp.Download(ctx, ytdlp.Options{
+		URL:       url,
+		OutputDir: req.OutputDir,
+		Prefix:    "vimeo",
+		Format:    d.getFormatString(),
+		Quality:   req.Quality,
+		AudioOnly: req.AudioOnly,
+		Language:  req.Language,
+		Section:   req.Section,
+		Args:      append(args, "--merge-output-format", "mp4"),
+	})
+	if err != nil {
+		var ytErr *ytdlp.Error
+		if errors.As(err, &ytErr) && isPasswordRequired(ytErr.Output) {
+			return "", ErrPasswordRequired
+		}
+		return "", err
+	}
+
+	d.logger.Info("Vimeo video downloaded successfully",
+		slog.String("url", url),
+		slog.String("file", filePath),
+	)
+
+	return filePath, nil
+}
+
+// getFormatString возвращает строку формата для yt-dlp
+func (d *Downloader) getFormatString() string {
+	switch strings.ToLower(d.videoQuality) {
+	case "worst":
+		return "worstvideo+worstaudio/worst"
+	default:
+		return "bestvideo[ext=mp4]+bestaudio[ext=m4a]/bestvideo+bestaudio/best"
+	}
+}
+
+// isPasswordRequired проверяет вывод yt-dlp на признаки защиты паролем
+func isPasswordRequired(output string) bool {
+	output = strings.ToLower(output)
+	return strings.Contains(output, "video-password") ||
+		strings.Contains(output, "wrong password") ||
+		strings.Contains(output, "protected by a password")
+}
+
+// IsValidURL проверяет, является ли URL ссылкой на Vimeo
+func IsValidURL(url string) bool {
+	return strings.Contains(url, "vimeo.com/")
+}
@@ -2,101 +2,146 @@ package yt
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log/slog"
-	"os"
-	"os/exec"
-	"path/filepath"
 	"strings"
+
+	"github.com/reelser-bot/internal/platform"
+	"github.com/reelser-bot/internal/platform/ytdlp"
 )
 
+// ErrSignInRequired возвращается, когда YouTube требует подтвердить вход ("Sign in to confirm").
+// Обычно лечится настройкой player_client/po_token
+var ErrSignInRequired = errors.New("youtube sign in required")
+
+// ExtractorOptions содержит параметры extractor-args yt-dlp для YouTube
+type ExtractorOptions struct {
+	PlayerClient string
+	POToken      string
+}
+
 // Downloader реализует загрузку видео с YouTube
 type Downloader struct {
 	logger       *slog.Logger
-	tempDir      string
+	ytdlp        *ytdlp.Client
 	videoQuality string
+	extractor    ExtractorOptions
 }
 
 // NewDownloader создает новый экземпляр YouTube загрузчика
-func NewDownloader(logger *slog.Logger, tempDir, videoQuality string) *Downloader {
+func NewDownloader(logger *slog.Logger, client *ytdlp.Client, videoQuality string, extractor ExtractorOptions) *Downloader {
 	return &Downloader{
 		logger:       logger,
-		tempDir:      tempDir,
+		ytdlp:        client,
 		videoQuality: videoQuality,
+		extractor:    extractor,
 	}
 }
 
 // Download скачивает видео с YouTube используя yt-dlp
 // Возвращает путь к скачанному файлу
-func (d *Downloader) Download(ctx context.Context, url string) (string, error) {
-	d.logger.Info("Starting YouTube video download", slog.String("url", url))
+func (d *Downloader) Download(ctx context.Context, req platform.Request) (string, error) {
+	url := req.URL
 
-	// Проверяем наличие yt-dlp
-	if _, err := exec.LookPath("yt-dlp"); err != nil {
-		return "", fmt.Errorf("yt-dlp not found. Please install yt-dlp: https://github.com/yt-dlp/yt-dlp")
-	}
+	d.logger.Info("Starting YouTube video download", slog.String("url", url))
 
-	// Создаем временный файл для сохранения видео
-	outputFile := filepath.Join(d.tempDir, "yt_%(title)s.%(ext)s")
-
-	// Формируем команду yt-dlp
-	args := []string{
-		url,
-		"-o", outputFile,
-		"-f", d.getFormatString(),
-		"--no-playlist",
-		"--no-warnings",
-		"--quiet",
+	var args []string
+	if extractorArgs := d.extractorArgs(req.Language); extractorArgs != "" {
+		args = append(args, "--extractor-args", extractorArgs)
 	}
 
-	cmd := exec.CommandContext(ctx, "yt-dlp", args...)
-	cmd.Dir = d.tempDir
-
-	output, err := cmd.CombinedOutput()
+	filePath, err := d.ytdlp.Download(ctx, ytdlp.Options{
+		URL:       url,
+		OutputDir: req.OutputDir,
+		Prefix:    "yt",
+		Format:    d.getFormatString(),
+		Quality:   req.Quality,
+		AudioOnly: req.AudioOnly,
+		Language:  req.Language,
+		Section:   req.Section,
+		Args:      args,
+	})
 	if err != nil {
-		d.logger.Error("Failed to download YouTube video",
-			slog.String("url", url),
-			slog.Any("error", err),
-			slog.String("output", string(output)),
-		)
+		var ytErr *ytdlp.Error
+		if errors.As(err, &ytErr) && strings.Contains(ytErr.Output, "Sign in to confirm") {
+			return "", ErrSignInRequired
+		}
 		return "", fmt.Errorf("failed to download video: %w", err)
 	}
 
-	// Находим скачанный файл
-	// yt-dlp выводит путь к файлу, но мы можем найти его по паттерну
-	files, err := filepath.Glob(filepath.Join(d.tempDir, "yt_*"))
-	if err != nil {
-		return "", fmt.Errorf("failed to find downloaded file: %w", err)
-	}
+	d.logger.Info("YouTube video downloaded successfully",
+		slog.String("url", url),
+		slog.String("file", filePath),
+	)
+
+	return filePath, nil
+}
 
-	if len(files) == 0 {
-		return "", fmt.Errorf("downloaded file not found")
+// DownloadChapters скачивает видео YouTube и разрезает его по главам.
+// Возвращает файлы глав по порядку
+func (d *Downloader) DownloadChapters(ctx context.Context, req platform.Request) ([]string, error) {
+	d.logger.Info("Starting YouTube chapters download", slog.String("url", req.URL))
+
+	var args []string
+	if extractorArgs := d.extractorArgs(req.Language); extractorArgs != "" {
+		args = append(args, "--extractor-args", extractorArgs)
 	}
 
-	// Находим самый новый файл
-	var latestFile string
-	var latestTime int64
-	for _, file := range files {
-		info, err := os.Stat(file)
-		if err != nil {
-			continue
-		}
-		if info.ModTime().Unix() > latestTime {
-			latestTime = info.ModTime().Unix()
-			latestFile = file
+	files, err := d.ytdlp.DownloadChapters(ctx, ytdlp.Options{
+		URL:       req.URL,
+		OutputDir: req.OutputDir,
+		Prefix:    "yt",
+		Format:    d.getFormatString(),
+		Quality:   req.Quality,
+		Language:  req.Language,
+		Args:      args,
+	})
+	if err != nil {
+		var ytErr *ytdlp.Error
+		if errors.As(err, &ytErr) && strings.Contains(ytErr.Output, "Sign in to confirm") {
+			return nil, ErrSignInRequired
 		}
+		return nil, fmt.Errorf("failed to download chapters: %w", err)
 	}
 
-	if latestFile == "" {
-		return "", fmt.Errorf("downloaded file not found")
+	d.logger.Info("YouTube chapters downloaded successfully",
+		slog.String("url", req.URL),
+		slog.Int("chapters", len(files)),
+	)
+	return files, nil
+}
+
+// IsPlaylist проверяет, что ссылка ведет на плейлист, а не на отдельное видео.
+// Ссылка на видео из плейлиста (watch?v=...&list=...) скачивается как одно видео
+func (d *Downloader) IsPlaylist(url string) bool {
+	return strings.Contains(url, "/playlist?") && strings.Contains(url, "list=")
+}
+
+// Playlist возвращает первые limit видео плейлиста
+func (d *Downloader) Playlist(ctx context.Context, req platform.Request, limit int) (platform.Playlist, error) {
+	args := ytdlp.LanguageArgs(req.Language)
+	if extractorArgs := d.extractorArgs(req.Language); extractorArgs != "" {
+		args = append(args, "--extractor-args", extractorArgs)
 	}
 
-	d.logger.Info("YouTube video downloaded successfully",
-		slog.String("url", url),
-		slog.String("file", latestFile),
-	)
+	meta, err := d.ytdlp.Playlist(ctx, req.URL, limit, args...)
+	if err != nil {
+		return platform.Playlist{}, fmt.Errorf("failed to fetch playlist: %w", err)
+	}
 
-	return latestFile, nil
+	playlist := platform.Playlist{Title: meta.Title}
+	for _, entry := range meta.Entries {
+		url := entry.URL
+		if url == "" && entry.ID != "" {
+			url = "https://www.youtube.com/watch?v=" + entry.ID
+		}
+		if url == "" {
+			continue
+		}
+		playlist.Items = append(playlist.Items, platform.PlaylistItem{URL: url, Title: entry.Title})
+	}
+	return playlist, nil
 }
 
 // getFormatString возвращает строку формата для yt-dlp в зависимости от качества
@@ -111,8 +156,26 @@ func (d *Downloader) getFormatString() string {
 	}
 }
 
+// extractorArgs формирует значение --extractor-args для YouTube.
+// lang выбирает перевод названия и описания, если автор его добавил
+func (d *Downloader) extractorArgs(lang string) string {
+	var parts []string
+	if d.extractor.PlayerClient != "" {
+		parts = append(parts, "player_client="+d.extractor.PlayerClient)
+	}
+	if d.extractor.POToken != "" {
+		parts = append(parts, "po_token="+d.extractor.POToken)
+	}
+	if base := ytdlp.BaseLanguage(lang); base != "" {
+		parts = append(parts, "lang="+base)
+	}
+	if len(parts) == 0 {
+		return ""
+	}
+	return "youtube:" + strings.Join(parts, ";")
+}
+
 // IsValidURL проверяет, является ли URL валидной ссылкой на YouTube
 func IsValidURL(url string) bool {
 	return strings.Contains(url, "youtube.com") || strings.Contains(url, "youtu.be")
 }
-
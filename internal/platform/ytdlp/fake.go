@@ -0,0 +1,77 @@
+package ytdlp
+
+import (
+	"context"
+	"os"
+	"strings"
+	"sync"
+)
+
+// FakeRunner имитирует yt-dlp без запуска процесса: записывает вызовы и по умолчанию
+// создает файл по шаблону -o (или возвращает метаданные для -J).
+// Поведение можно переопределить через Handler
+type FakeRunner struct {
+	// Handler формирует ответ на вызов; nil — поведение по умолчанию
+	Handler func(cmd Command) (stdout, stderr []byte, err error)
+	// Missing имитирует отсутствие программы в PATH
+	Missing bool
+
+	mu    sync.Mutex
+	calls []Command
+}
+
+// fakeMetadata ответ по умолчанию на запрос метаданных
+const fakeMetadata = `{"id":"fake","title":"Fake video","duration":10,"ext":"mp4","filesize":1024}`
+
+// LookPath возвращает имя программы или ошибку, если задан Missing
+func (f *FakeRunner) LookPath(name string) (string, error) {
+	if f.Missing {
+		return "", os.ErrNotExist
+	}
+	return name, nil
+}
+
+// Run записывает вызов и возвращает ответ Handler или ответ по умолчанию
+func (f *FakeRunner) Run(_ context.Context, cmd Command) ([]byte, []byte, error) {
+	f.mu.Lock()
+	f.calls = append(f.calls, cmd)
+	f.mu.Unlock()
+
+	if f.Handler != nil {
+		return f.Handler(cmd)
+	}
+
+	for i, arg := range cmd.Args {
+		switch arg {
+		case "-J":
+			return []byte(fakeMetadata), nil, nil
+		case "-o":
+			if i+1 < len(cmd.Args) {
+				path := fakeOutputPath(cmd.Args[i+1])
+				if err := os.WriteFile(path, []byte("fake media"), 0o644); err != nil {
+					return nil, []byte(err.Error()), err
+				}
+			}
+		}
+	}
+	return nil, nil, nil
+}
+
+// Calls возвращает копию записанных вызовов
+func (f *FakeRunner) Calls() []Command {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	calls := make([]Command, len(f.calls))
+	copy(calls, f.calls)
+	return calls
+}
+
+// fakeOutputPath подставляет значения в шаблон имени файла yt-dlp
+func fakeOutputPath(template string) string {
+	return strings.NewReplacer(
+		"%(id)s", "fake",
+		"%(title)s", "fake",
+		"%(ext)s", "mp4",
+	).Replace(template)
+}
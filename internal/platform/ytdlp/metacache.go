@@ -0,0 +1,90 @@
+package ytdlp
+
+import (
+	"container/list"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/reelser-bot/internal/clock"
+)
+
+// metadataCache LRU-кэш результатов Probe (yt-dlp -J) с ограниченным временем жизни.
+// Кэш живет в памяти клиента и совпадает только по URL и аргументам: /info и проверка
+// перед загрузкой с теми же качеством и языком запускают yt-dlp -J один раз. Сама
+// загрузка, развертывание плейлистов и платформы со своим API кэш не используют
+type metadataCache struct {
+	clock clock.Clock
+	size  int
+	ttl   time.Duration
+
+	mu      sync.Mutex
+	order   *list.List
+	entries map[string]*list.Element
+}
+
+// metadataEntry элемент кэша; хранится в metadataCache.order
+type metadataEntry struct {
+	key       string
+	meta      Metadata
+	expiresAt time.Time
+}
+
+func newMetadataCache(size int, ttl time.Duration, clk clock.Clock) *metadataCache {
+	return &metadataCache{
+		clock:   clk,
+		size:    size,
+		ttl:     ttl,
+		order:   list.New(),
+		entries: make(map[string]*list.Element),
+	}
+}
+
+// metadataKey ключ кэша: аргументы влияют на выбранный формат и язык метаданных
+func metadataKey(url string, args []string) string {
+	return url + "\x00" + strings.Join(args, "\x00")
+}
+
+// get возвращает копию метаданных, если запись есть и не устарела
+func (c *metadataCache) get(key string) (*Metadata, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	elem, ok := c.entries[key]
+	if !ok {
+		return nil, false
+	}
+
+	entry := elem.Value.(*metadataEntry)
+	if !c.clock.Now().Before(entry.expiresAt) {
+		c.order.Remove(elem)
+		delete(c.entries, key)
+		return nil, false
+	}
+
+	c.order.MoveToFront(elem)
+	meta := entry.meta
+	return &meta, true
+}
+
+// put запоминает метаданные и вытесняет давно не использованные записи сверх size
+func (c *metadataCache) put(key string, meta *Metadata) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	expiresAt := c.clock.Now().Add(c.ttl)
+	if elem, ok := c.entries[key]; ok {
+		entry := elem.Value.(*metadataEntry)
+		entry.meta = *meta
+		entry.expiresAt = expiresAt
+		c.order.MoveToFront(elem)
+		return
+	}
+
+	c.entries[key] = c.order.PushFront(&metadataEntry{key: key, meta: *meta, expiresAt: expiresAt})
+	for c.order.Len() > c.size {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.entries, oldest.Value.(*metadataEntry).key)
+	}
+}
@@ -0,0 +1,107 @@
+package ytdlp
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/reelser-bot/internal/clock"
+)
+
+func TestProbeCacheTTL(t *testing.T) {
+	clk := clock.NewFake(time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC))
+	runner := &FakeRunner{}
+	client := NewClient(testLogger(), runner, WithMetadataCache(10, time.Minute, clk))
+	ctx := context.Background()
+
+	probe := func() {
+		t.Helper()
+		meta, err := client.Probe(ctx, "https://example.com/v")
+		if err != nil {
+			t.Fatalf("Probe: %v", err)
+		}
+		if meta.Title != "Fake video" {
+			t.Fatalf("Probe title = %q", meta.Title)
+		}
+	}
+
+	probe()
+	probe()
+	if calls := len(runner.Calls()); calls != 1 {
+		t.Errorf("yt-dlp ran %d times within TTL, want 1", calls)
+	}
+
+	clk.Advance(59 * time.Second)
+	probe()
+	if calls := len(runner.Calls()); calls != 1 {
+		t.Errorf("yt-dlp ran %d times before TTL expired, want 1", calls)
+	}
+
+	clk.Advance(time.Second)
+	probe()
+	if calls := len(runner.Calls()); calls != 2 {
+		t.Errorf("yt-dlp ran %d times after TTL expired, want 2", calls)
+	}
+}
+
+func TestProbeCacheKeyIncludesArgs(t *testing.T) {
+	clk := clock.NewFake(time.Now())
+	runner := &FakeRunner{}
+	client := NewClient(testLogger(), runner, WithMetadataCache(10, time.Minute, clk))
+	ctx := context.Background()
+
+	for _, args := range [][]string{nil, LanguageArgs("ru"), LanguageArgs("ru"), nil} {
+		if _, err := client.Probe(ctx, "https://example.com/v", args...); err != nil {
+			t.Fatalf("Probe: %v", err)
+		}
+	}
+	if calls := len(runner.Calls()); calls != 2 {
+		t.Errorf("yt-dlp ran %d times, want one run per argument set", calls)
+	}
+}
+
+func TestMetadataCacheEvictsLeastRecentlyUsed(t *testing.T) {
+	clk := clock.NewFake(time.Now())
+	cache := newMetadataCache(2, time.Hour, clk)
+
+	cache.put("a", &Metadata{Title: "a"})
+	cache.put("b", &Metadata{Title: "b"})
+	if _, ok := cache.get("a"); !ok {
+		t.Fatal("a missing before eviction")
+	}
+	cache.put("c", &Metadata{Title: "c"})
+
+	if _, ok := cache.get("b"); ok {
+		t.Error("least recently used entry b was not evicted")
+	}
+	for _, key := range []string{"a", "c"} {
+		if meta, ok := cache.get(key); !ok || meta.Title != key {
+			t.Errorf("get(%q) = %v, %v", key, meta, ok)
+		}
+	}
+}
+
+func TestMetadataCacheReturnsCopy(t *testing.T) {
+	cache := newMetadataCache(2, time.Hour, clock.NewFake(time.Now()))
+	cache.put("a", &Metadata{Title: "a"})
+
+	meta, _ := cache.get("a")
+	meta.Title = "changed"
+	if again, _ := cache.get("a"); again.Title != "a" {
+		t.Errorf("cached entry changed through returned copy: %q", again.Title)
+	}
+}
+
+func TestMetadataCacheDisabled(t *testing.T) {
+	runner := &FakeRunner{}
+	client := NewClient(testLogger(), runner, WithMetadataCache(10, 0, clock.NewFake(time.Now())))
+
+	for i := 0; i < 2; i++ {
+		if _, err := client.Probe(context.Background(), "https://example.com/v"); err != nil {
+			t.Fatalf("Probe: %v", err)
+		}
+	}
+	if calls := len(runner.Calls()); calls != 2 {
+		t.Errorf("yt-dlp ran %d times with cache disabled, want 2", calls)
+	}
+}
@@ -0,0 +1,185 @@
+package ytdlp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/reelser-bot/internal/trace"
+)
+
+// Metadata содержит сведения о медиа из yt-dlp -J
+type Metadata struct {
+	ID             string  `json:"id"`
+	Title          string  `json:"title"`
+	Uploader       string  `json:"uploader"`
+	Duration       float64 `json:"duration"`
+	Width          int     `json:"width"`
+	Height         int     `json:"height"`
+	Filesize       int64   `json:"filesize"`
+	FilesizeApprox int64   `json:"filesize_approx"`
+	Ext            string  `json:"ext"`
+	Thumbnail      string  `json:"thumbnail"`
+	WebpageURL     string  `json:"webpage_url"`
+	// IsLive идет прямая трансляция: ее запись длилась бы до таймаута загрузки
+	IsLive bool `json:"is_live"`
+	// URL прямая ссылка на медиа, если у видео один формат
+	URL string `json:"url"`
+	// Chapters главы видео, если автор их разметил
+	Chapters []Chapter `json:"chapters"`
+}
+
+// Chapter глава видео из yt-dlp -J
+type Chapter struct {
+	Title     string  `json:"title"`
+	StartTime float64 `json:"start_time"`
+	EndTime   float64 `json:"end_time"`
+}
+
+// DurationValue возвращает длительность как time.Duration
+func (m *Metadata) DurationValue() time.Duration {
+	return time.Duration(m.Duration * float64(time.Second))
+}
+
+// EstimatedSize возвращает известный или примерный размер файла в байтах (0 — неизвестно)
+func (m *Metadata) EstimatedSize() int64 {
+	if m.Filesize > 0 {
+		return m.Filesize
+	}
+	return m.FilesizeApprox
+}
+
+// IsImage сообщает, что медиа — изображение, а не видео: длительности у него нет
+func (m *Metadata) IsImage() bool {
+	switch strings.ToLower(m.Ext) {
+	case "jpg", "jpeg", "png", "webp", "heic":
+		return true
+	}
+	return false
+}
+
+// Probe получает метаданные медиа без скачивания (yt-dlp -J). Если включен кэш,
+// повторные запросы того же URL с теми же аргументами отвечаются из него
+func (c *Client) Probe(ctx context.Context, url string, args ...string) (*Metadata, error) {
+	if err := c.ensureInstalled(); err != nil {
+		return nil, err
+	}
+
+	key := metadataKey(url, args)
+	if c.metadata != nil {
+		if meta, ok := c.metadata.get(key); ok {
+			c.logger.Debug("Metadata served from cache", slog.String("url", url))
+			trace.Count(ctx, "metadata_cache_hits")
+			return meta, nil
+		}
+		trace.Count(ctx, "metadata_cache_misses")
+	}
+
+	cmdArgs := append([]string{url, "-J", "--no-playlist", "--no-warnings"}, args...)
+	cmdArgs = append(cmdArgs, proxyArgs(ctx)...)
+	cookies, release, err := cookiesArgs(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+	cmdArgs = append(cmdArgs, cookies...)
+
+	output, stderr, err := c.runner.Run(ctx, Command{Name: c.binary, Args: cmdArgs})
+	trace.Record(ctx, binaryName, redactArgs(cmdArgs), stderr, err)
+	if err != nil {
+		return nil, &Error{Err: err, Output: string(stderr)}
+	}
+
+	var meta Metadata
+	if err := json.Unmarshal(output, &meta); err != nil {
+		return nil, fmt.Errorf("failed to parse yt-dlp metadata: %w", err)
+	}
+
+	if c.metadata != nil {
+		c.metadata.put(key, &meta)
+	}
+	return &meta, nil
+}
+
+// DurationError возвращается, если медиа длиннее допустимого
+type DurationError struct {
+	Duration time.Duration
+	Limit    time.Duration
+}
+
+func (e *DurationError) Error() string {
+	return fmt.Sprintf("media duration %s exceeds limit %s", e.Duration, e.Limit)
+}
+
+// ErrUnknownDuration возвращается для прямой трансляции или медиа без известной
+// длительности там, где длительность обязана быть известна до загрузки
+var ErrUnknownDuration = errors.New("live stream or unknown duration")
+
+// RequireDuration возвращает ErrUnknownDuration для трансляций и медиа без длительности
+func RequireDuration(meta *Metadata) error {
+	if meta == nil || meta.IsLive || meta.DurationValue() <= 0 {
+		return ErrUnknownDuration
+	}
+	return nil
+}
+
+// CheckDuration возвращает DurationError, если длительность превышает лимит (0 — без лимита)
+func CheckDuration(meta *Metadata, limit time.Duration) error {
+	if limit <= 0 || meta == nil {
+		return nil
+	}
+	if d := meta.DurationValue(); d > limit {
+		return &DurationError{Duration: d, Limit: limit}
+	}
+	return nil
+}
+
+// PlaylistEntry элемент плейлиста из yt-dlp --flat-playlist -J
+type PlaylistEntry struct {
+	ID    string `json:"id"`
+	Title string `json:"title"`
+	URL   string `json:"url"`
+}
+
+// PlaylistMetadata сведения о плейлисте без метаданных отдельных видео
+type PlaylistMetadata struct {
+	Title   string          `json:"title"`
+	Entries []PlaylistEntry `json:"entries"`
+}
+
+// Playlist получает первые limit элементов плейлиста без скачивания
+func (c *Client) Playlist(ctx context.Context, url string, limit int, args ...string) (*PlaylistMetadata, error) {
+	if err := c.ensureInstalled(); err != nil {
+		return nil, err
+	}
+
+	cmdArgs := append([]string{url, "-J", "--flat-playlist", "--no-warnings", "--playlist-end", strconv.Itoa(limit)}, args...)
+	cmdArgs = append(cmdArgs, proxyArgs(ctx)...)
+	cookies, release, err := cookiesArgs(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+	cmdArgs = append(cmdArgs, cookies...)
+
+	output, stderr, err := c.runner.Run(ctx, Command{Name: c.binary, Args: cmdArgs})
+	trace.Record(ctx, binaryName, redactArgs(cmdArgs), stderr, err)
+	if err != nil {
+		return nil, &Error{Err: err, Output: string(stderr)}
+	}
+
+	var playlist PlaylistMetadata
+	if err := json.Unmarshal(output, &playlist); err != nil {
+		return nil, fmt.Errorf("failed to parse yt-dlp playlist: %w", err)
+	}
+	// --playlist-end соблюдается не всеми экстракторами
+	if len(playlist.Entries) > limit {
+		playlist.Entries = playlist.Entries[:limit]
+	}
+	return &playlist, nil
+}
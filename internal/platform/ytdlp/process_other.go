@@ -0,0 +1,14 @@
+//go:build !unix
+
+package ytdlp
+
+import "os/exec"
+
+// setProcessGroup вне Unix групп процессов нет: при отмене завершается только сама
+// команда, а WaitDelay не дает зависнуть на потомках, унаследовавших вывод
+func setProcessGroup(c *exec.Cmd) {
+	c.WaitDelay = processGracePeriod
+}
+
+// killProcessGroup вне Unix ничего не делает
+func killProcessGroup(*exec.Cmd) {}
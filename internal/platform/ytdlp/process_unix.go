@@ -0,0 +1,49 @@
+//go:build unix
+
+package ytdlp
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+	"syscall"
+	"time"
+)
+
+// setProcessGroup запускает команду в отдельной группе процессов. При отмене контекста
+// SIGTERM получает вся группа, в том числе запущенный yt-dlp ffmpeg; не завершившиеся
+// за processGracePeriod процессы добивает killProcessGroup
+func setProcessGroup(c *exec.Cmd) {
+	c.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+	c.Cancel = func() error {
+		err := syscall.Kill(-c.Process.Pid, syscall.SIGTERM)
+		if errors.Is(err, syscall.ESRCH) {
+			return os.ErrProcessDone
+		}
+		return err
+	}
+	// Без WaitDelay Wait ждал бы потомков, унаследовавших stdout и stderr
+	c.WaitDelay = processGracePeriod
+}
+
+// killProcessGroup убивает оставшиеся процессы группы и ждет их выхода, чтобы
+// временные файлы можно было удалить: иначе ffmpeg продолжил бы писать в них
+func killProcessGroup(c *exec.Cmd) {
+	if c.Process == nil {
+		return
+	}
+
+	pgid := c.Process.Pid
+	if err := syscall.Kill(-pgid, syscall.SIGKILL); err != nil {
+		// ESRCH: в группе не осталось процессов
+		return
+	}
+
+	deadline := time.Now().Add(processGracePeriod)
+	for time.Now().Before(deadline) {
+		if err := syscall.Kill(-pgid, 0); errors.Is(err, syscall.ESRCH) {
+			return
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+}
@@ -0,0 +1,55 @@
+package ytdlp
+
+import (
+	"bytes"
+	"context"
+	"os/exec"
+	"time"
+)
+
+// processGracePeriod сколько команде дается на завершение после отмены, прежде чем
+// ее процессы будут убиты
+const processGracePeriod = 5 * time.Second
+
+// Command описывает запуск внешней программы
+type Command struct {
+	Name string
+	Args []string
+	// Dir рабочая директория, пусто — текущая
+	Dir string
+}
+
+// CommandRunner запускает внешние команды. Позволяет подменять yt-dlp
+// (например, FakeRunner), чтобы платформенные модули работали без реального процесса
+type CommandRunner interface {
+	// LookPath проверяет наличие программы и возвращает путь к ней
+	LookPath(name string) (string, error)
+	// Run запускает команду и возвращает stdout и stderr
+	Run(ctx context.Context, cmd Command) (stdout, stderr []byte, err error)
+}
+
+// ExecRunner запускает команды через os/exec
+type ExecRunner struct{}
+
+// LookPath ищет программу в PATH
+func (ExecRunner) LookPath(name string) (string, error) {
+	return exec.LookPath(name)
+}
+
+// Run запускает команду с привязкой к контексту. При отмене останавливается вся группа
+// процессов команды, и Run возвращается только после выхода всех ее процессов
+func (ExecRunner) Run(ctx context.Context, cmd Command) ([]byte, []byte, error) {
+	c := exec.CommandContext(ctx, cmd.Name, cmd.Args...)
+	c.Dir = cmd.Dir
+	setProcessGroup(c)
+
+	var stdout, stderr bytes.Buffer
+	c.Stdout = &stdout
+	c.Stderr = &stderr
+
+	err := c.Run()
+	if err != nil {
+		killProcessGroup(c)
+	}
+	return stdout.Bytes(), stderr.Bytes(), err
+}
@@ -0,0 +1,386 @@
+package ytdlp
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/reelser-bot/internal/clock"
+	"github.com/reelser-bot/internal/platform"
+	"github.com/reelser-bot/internal/trace"
+)
+
+// Options описывает параметры загрузки через yt-dlp
+type Options struct {
+	URL       string
+	OutputDir string
+	// Prefix префикс имени файла, по которому отличаются платформы (например, "reddit")
+	Prefix string
+	// Format строка формата yt-dlp (-f)
+	Format string
+	// Args дополнительные аргументы yt-dlp
+	Args []string
+	// Quality и AudioOnly переопределяют Format для выбранного пользователем варианта
+	Quality   string
+	AudioOnly bool
+	// Language язык пользователя для локализованных метаданных и субтитров
+	Language string
+	// Section скачать только фрагмент; nil — ролик целиком
+	Section *platform.Section
+}
+
+// sdFormat выбирает видео не выше 480p
+const sdFormat = "best[height<=480][ext=mp4]/best[height<=480]/bestvideo[height<=480]+bestaudio/worst"
+
+// heightFormat выбирает лучшее видео не выше height точек по вертикали. Раздельные
+// дорожки идут первыми: готовые форматы на YouTube бывают только до 360p
+func heightFormat(height int) string {
+	return fmt.Sprintf("bestvideo[height<=%[1]d][ext=mp4]+bestaudio[ext=m4a]/"+
+		"bestvideo[height<=%[1]d]+bestaudio/best[height<=%[1]d]/worst", height)
+}
+
+// FormatArgs возвращает аргументы выбора формата с учетом варианта запроса.
+// format — формат платформы для обычного качества
+func FormatArgs(format, quality string, audioOnly bool) []string {
+	switch {
+	case audioOnly:
+		return []string{"-f", "bestaudio/best", "-x", "--audio-format", "mp3"}
+	case quality == platform.QualitySD:
+		return []string{"-f", sdFormat}
+	case platform.MaxHeight(quality) > 0:
+		return []string{"-f", heightFormat(platform.MaxHeight(quality))}
+	case quality == platform.QualityHD:
+		return []string{"-f", "bestvideo[ext=mp4]+bestaudio[ext=m4a]/bestvideo+bestaudio/best"}
+	case format != "":
+		return []string{"-f", format}
+	}
+	return nil
+}
+
+// SectionArgs возвращает аргументы загрузки только фрагмента видео. Разрез проходит
+// по ключевым кадрам, поэтому границы могут сдвинуться на пару секунд
+func SectionArgs(section *platform.Section) []string {
+	if section == nil {
+		return nil
+	}
+	return []string{"--download-sections", fmt.Sprintf("*%d-%d", int(section.Start.Seconds()), int(section.End.Seconds()))}
+}
+
+// LanguageArgs возвращает аргументы, запрашивающие метаданные на языке пользователя
+func LanguageArgs(lang string) []string {
+	if lang == "" {
+		return nil
+	}
+	return []string{"--add-header", "Accept-Language:" + lang}
+}
+
+// BaseLanguage возвращает основной код языка без региона: "pt-br" → "pt"
+func BaseLanguage(lang string) string {
+	base, _, _ := strings.Cut(strings.ToLower(lang), "-")
+	return base
+}
+
+// subtitleArgs возвращает аргументы встраивания субтитров на языке пользователя
+func (c *Client) subtitleArgs(lang string) []string {
+	base := BaseLanguage(lang)
+	if base == "" {
+		base = c.subtitleLang
+	}
+	if base == "" {
+		return nil
+	}
+	// Совпадают и региональные варианты дорожек (en-US, en-GB)
+	return []string{"--write-subs", "--sub-langs", base + ".*", "--embed-subs"}
+}
+
+// binaryName имя исполняемого файла yt-dlp по умолчанию и метка его вызовов в трассировках
+const binaryName = "yt-dlp"
+
+// Client запускает yt-dlp через CommandRunner
+type Client struct {
+	logger *slog.Logger
+	runner CommandRunner
+	// binary путь к yt-dlp или имя для поиска в PATH (YTDLP_PATH)
+	binary string
+
+	// subtitles встраивать субтитры; subtitleLang язык, если язык пользователя неизвестен
+	subtitles    bool
+	subtitleLang string
+
+	// metadata кэш результатов Probe; nil — кэш отключен
+	metadata *metadataCache
+}
+
+// ClientOption настраивает клиент yt-dlp
+type ClientOption func(*Client)
+
+// WithBinary задает путь к исполняемому файлу yt-dlp; пусто — yt-dlp из PATH
+func WithBinary(path string) ClientOption {
+	return func(c *Client) {
+		if path != "" {
+			c.binary = path
+		}
+	}
+}
+
+// WithSubtitles включает встраивание субтитров на языке пользователя, а если он
+// неизвестен — на языке fallback
+func WithSubtitles(fallback string) ClientOption {
+	return func(c *Client) {
+		c.subtitles = true
+		c.subtitleLang = fallback
+	}
+}
+
+// WithMetadataCache кэширует метаданные Probe для size последних URL на время ttl
+func WithMetadataCache(size int, ttl time.Duration, clk clock.Clock) ClientOption {
+	return func(c *Client) {
+		if size > 0 && ttl > 0 {
+			c.metadata = newMetadataCache(size, ttl, clk)
+		}
+	}
+}
+
+// NewClient создает клиент yt-dlp. При runner == nil используется ExecRunner
+func NewClient(logger *slog.Logger, runner CommandRunner, opts ...ClientOption) *Client {
+	if runner == nil {
+		runner = ExecRunner{}
+	}
+	c := &Client{
+		logger: logger,
+		runner: runner,
+		binary: binaryName,
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// ensureInstalled проверяет наличие yt-dlp
+func (c *Client) ensureInstalled() error {
+	if _, err := c.runner.LookPath(c.binary); err != nil {
+		return fmt.Errorf("yt-dlp not found at %q. Please install yt-dlp (https://github.com/yt-dlp/yt-dlp) or set YTDLP_PATH", c.binary)
+	}
+	return nil
+}
+
+// Download скачивает медиа через yt-dlp и возвращает путь к файлу.
+// Каждой загрузке присваивается уникальный префикс, чтобы параллельные
+// загрузки одной платформы не путали файлы друг друга
+func (c *Client) Download(ctx context.Context, opts Options) (string, error) {
+	base, err := newBase(opts.Prefix)
+	if err != nil {
+		return "", err
+	}
+	if err := c.run(ctx, opts, base); err != nil {
+		c.removeFiles(opts.OutputDir, base)
+		return "", err
+	}
+
+	files, err := filepath.Glob(filepath.Join(opts.OutputDir, base+"_*"))
+	if err != nil {
+		return "", fmt.Errorf("failed to find downloaded file: %w", err)
+	}
+
+	// Берем самый большой файл: промежуточные файлы дорожек yt-dlp удаляет после склейки
+	var result string
+	var resultSize int64 = -1
+	for _, file := range files {
+		info, err := os.Stat(file)
+		if err != nil {
+			continue
+		}
+		if info.Size() > resultSize {
+			resultSize = info.Size()
+			result = file
+		}
+	}
+
+	if result == "" {
+		return "", fmt.Errorf("downloaded file not found")
+	}
+
+	return result, nil
+}
+
+// chapterMarker отличает файлы глав от целого видео в имени файла
+const chapterMarker = "_chapter"
+
+// DownloadChapters скачивает видео и разрезает его по главам (--split-chapters).
+// Возвращает файлы глав по порядку; целое видео удаляется
+func (c *Client) DownloadChapters(ctx context.Context, opts Options) ([]string, error) {
+	base, err := newBase(opts.Prefix)
+	if err != nil {
+		return nil, err
+	}
+	chapterTemplate := "chapter:" + filepath.Join(opts.OutputDir, base+chapterMarker+"%(section_number)03d.%(ext)s")
+	if err := c.run(ctx, opts, base, "--split-chapters", "-o", chapterTemplate); err != nil {
+		c.removeFiles(opts.OutputDir, base)
+		return nil, err
+	}
+
+	files, err := filepath.Glob(filepath.Join(opts.OutputDir, base+"_*"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to find downloaded files: %w", err)
+	}
+
+	var chapters []string
+	for _, file := range files {
+		if strings.HasPrefix(filepath.Base(file), base+chapterMarker) {
+			chapters = append(chapters, file)
+			continue
+		}
+		if err := os.Remove(file); err != nil {
+			c.logger.Warn("Failed to remove full video after splitting", slog.String("file", file), slog.Any("error", err))
+		}
+	}
+	if len(chapters) == 0 {
+		return nil, fmt.Errorf("chapter files not found")
+	}
+
+	// Номер главы дополнен нулями, поэтому порядок имен совпадает с порядком глав
+	sort.Strings(chapters)
+	return chapters, nil
+}
+
+// removeFiles удаляет файлы незавершенной загрузки (.part, дорожки до склейки). Runner
+// возвращается после выхода yt-dlp и его ffmpeg, поэтому файлы уже никем не заняты
+func (c *Client) removeFiles(dir, base string) {
+	files, err := filepath.Glob(filepath.Join(dir, base+"_*"))
+	if err != nil {
+		return
+	}
+	for _, file := range files {
+		if err := os.Remove(file); err != nil && !os.IsNotExist(err) {
+			c.logger.Warn("Failed to remove partial download", slog.String("file", file), slog.Any("error", err))
+		}
+	}
+}
+
+// newBase возвращает уникальный префикс имен файлов загрузки, чтобы параллельные
+// загрузки одной платформы не путали файлы друг друга
+func newBase(prefix string) (string, error) {
+	token, err := randomToken()
+	if err != nil {
+		return "", fmt.Errorf("failed to generate file name: %w", err)
+	}
+	return fmt.Sprintf("%s_%s", prefix, token), nil
+}
+
+// run запускает yt-dlp с общими аргументами загрузки; файлы получают префикс base
+func (c *Client) run(ctx context.Context, opts Options, base string, extra ...string) error {
+	if err := c.ensureInstalled(); err != nil {
+		return err
+	}
+
+	args := []string{
+		opts.URL,
+		"-o", filepath.Join(opts.OutputDir, base+"_%(id)s.%(ext)s"),
+		"--no-playlist",
+		"--no-warnings",
+		"--quiet",
+	}
+	args = append(args, FormatArgs(opts.Format, opts.Quality, opts.AudioOnly)...)
+	args = append(args, LanguageArgs(opts.Language)...)
+	args = append(args, SectionArgs(opts.Section)...)
+	if c.subtitles && !opts.AudioOnly {
+		args = append(args, c.subtitleArgs(opts.Language)...)
+	}
+	args = append(args, opts.Args...)
+	args = append(args, extra...)
+	args = append(args, proxyArgs(ctx)...)
+	cookies, release, err := cookiesArgs(ctx)
+	if err != nil {
+		return err
+	}
+	defer release()
+	args = append(args, cookies...)
+
+	stdout, stderr, err := c.runner.Run(ctx, Command{Name: c.binary, Args: args, Dir: opts.OutputDir})
+	output := append(stdout, stderr...)
+	trace.Record(ctx, binaryName, redactArgs(args), output, err)
+	if err != nil {
+		c.logger.Error("yt-dlp failed",
+			slog.String("url", opts.URL),
+			slog.Any("error", err),
+			slog.String("output", string(output)),
+		)
+		return &Error{Err: err, Output: string(output)}
+	}
+	return nil
+}
+
+// Error содержит ошибку запуска yt-dlp и его вывод
+type Error struct {
+	Err    error
+	Output string
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("yt-dlp failed: %v", e.Err)
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// secretFlags флаги yt-dlp, значения которых не должны попадать в трассировки
+var secretFlags = map[string]bool{
+	"--video-password": true,
+	"--password":       true,
+	// URL прокси может содержать логин и пароль
+	"--proxy": true,
+}
+
+// proxyArgs возвращает аргументы маршрута, выбранного для загрузки (platform.WithProxy)
+func proxyArgs(ctx context.Context) []string {
+	proxy, ok := platform.ProxyFromContext(ctx)
+	if !ok {
+		return nil
+	}
+	// Пустое значение --proxy отключает прокси из окружения
+	return []string{"--proxy", proxy}
+}
+
+// cookiesArgs возвращает аргументы файла cookies, выбранного для загрузки
+// (platform.WithCookies), и функцию, освобождающую файл после запуска yt-dlp
+func cookiesArgs(ctx context.Context) ([]string, func(), error) {
+	cookies, ok := platform.CookiesFromContext(ctx)
+	if !ok {
+		return nil, func() {}, nil
+	}
+	file, release, err := cookies.Acquire()
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to prepare cookies: %w", err)
+	}
+	return []string{"--cookies", file}, release, nil
+}
+
+// redactArgs возвращает копию аргументов со скрытыми значениями секретных флагов
+func redactArgs(args []string) []string {
+	redacted := make([]string, len(args))
+	copy(redacted, args)
+	for i := 0; i < len(redacted)-1; i++ {
+		if secretFlags[redacted[i]] {
+			redacted[i+1] = "***"
+			i++
+		}
+	}
+	return redacted
+}
+
+func randomToken() (string, error) {
+	b := make([]byte, 4)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
@@ -0,0 +1,197 @@
+package ytdlp
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func testLogger() *slog.Logger {
+	return slog.New(slog.NewTextHandler(io.Discard, nil))
+}
+
+// outputTemplate возвращает шаблон -o, заданный для основного файла загрузки
+func outputTemplate(t *testing.T, cmd Command) string {
+	t.Helper()
+	for i, arg := range cmd.Args {
+		if arg == "-o" && i+1 < len(cmd.Args) && !strings.HasPrefix(cmd.Args[i+1], "chapter:") {
+			return cmd.Args[i+1]
+		}
+	}
+	t.Fatalf("no -o argument in %v", cmd.Args)
+	return ""
+}
+
+// writeOutput создает файл по шаблону yt-dlp с подставленным id и расширением
+func writeOutput(t *testing.T, template, id, ext string, size int) string {
+	t.Helper()
+	path := strings.NewReplacer("%(id)s", id, "%(ext)s", ext).Replace(template)
+	if err := os.WriteFile(path, make([]byte, size), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestDownloadPicksLargestFile(t *testing.T) {
+	dir := t.TempDir()
+	var merged string
+	runner := &FakeRunner{Handler: func(cmd Command) ([]byte, []byte, error) {
+		template := outputTemplate(t, cmd)
+		writeOutput(t, template, "abc.f137", "mp4", 10)
+		writeOutput(t, template, "abc.f140", "m4a", 5)
+		merged = writeOutput(t, template, "abc", "mp4", 100)
+		return nil, nil, nil
+	}}
+	client := NewClient(testLogger(), runner)
+
+	path, err := client.Download(context.Background(), Options{URL: "https://example.com/v", OutputDir: dir, Prefix: "test"})
+	if err != nil {
+		t.Fatalf("Download: %v", err)
+	}
+	if path != merged {
+		t.Errorf("Download = %q, want largest file %q", path, merged)
+	}
+}
+
+func TestDownloadIgnoresOtherDownloads(t *testing.T) {
+	dir := t.TempDir()
+	// Файл параллельной загрузки той же платформы с другим префиксом
+	other := filepath.Join(dir, "test_othertoken_xyz.mp4")
+	if err := os.WriteFile(other, make([]byte, 1000), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	client := NewClient(testLogger(), &FakeRunner{})
+	path, err := client.Download(context.Background(), Options{URL: "https://example.com/v", OutputDir: dir, Prefix: "test"})
+	if err != nil {
+		t.Fatalf("Download: %v", err)
+	}
+	if path == other {
+		t.Errorf("Download returned a file of another download")
+	}
+}
+
+func TestDownloadRemovesPartialFilesOnError(t *testing.T) {
+	dir := t.TempDir()
+	runErr := errors.New("exit status 1")
+	runner := &FakeRunner{Handler: func(cmd Command) ([]byte, []byte, error) {
+		template := outputTemplate(t, cmd)
+		writeOutput(t, template, "abc", "mp4.part", 10)
+		writeOutput(t, template, "abc.f140", "m4a", 5)
+		return nil, []byte("ERROR: interrupted"), runErr
+	}}
+	client := NewClient(testLogger(), runner)
+
+	_, err := client.Download(context.Background(), Options{URL: "https://example.com/v", OutputDir: dir, Prefix: "test"})
+	var ytErr *Error
+	if !errors.As(err, &ytErr) || !errors.Is(err, runErr) {
+		t.Fatalf("Download error = %v, want *Error wrapping %v", err, runErr)
+	}
+	if !strings.Contains(ytErr.Output, "interrupted") {
+		t.Errorf("Error.Output = %q, want yt-dlp output", ytErr.Output)
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 0 {
+		t.Errorf("partial files left after failed download: %v", entries)
+	}
+}
+
+func TestDownloadChaptersRemovesFullVideo(t *testing.T) {
+	dir := t.TempDir()
+	runner := &FakeRunner{Handler: func(cmd Command) ([]byte, []byte, error) {
+		writeOutput(t, outputTemplate(t, cmd), "abc", "mp4", 100)
+
+		var chapterTemplate string
+		for i, arg := range cmd.Args {
+			if arg == "-o" && strings.HasPrefix(cmd.Args[i+1], "chapter:") {
+				chapterTemplate = strings.TrimPrefix(cmd.Args[i+1], "chapter:")
+			}
+		}
+		if chapterTemplate == "" {
+			t.Fatalf("no chapter template in %v", cmd.Args)
+		}
+		for _, number := range []string{"002", "001", "010"} {
+			path := strings.NewReplacer("%(section_number)03d", number, "%(ext)s", "mp4").Replace(chapterTemplate)
+			if err := os.WriteFile(path, []byte(number), 0o600); err != nil {
+				t.Fatal(err)
+			}
+		}
+		return nil, nil, nil
+	}}
+	client := NewClient(testLogger(), runner)
+
+	chapters, err := client.DownloadChapters(context.Background(), Options{URL: "https://example.com/v", OutputDir: dir, Prefix: "test"})
+	if err != nil {
+		t.Fatalf("DownloadChapters: %v", err)
+	}
+	if len(chapters) != 3 {
+		t.Fatalf("DownloadChapters returned %d files, want 3", len(chapters))
+	}
+	for i, want := range []string{"001", "002", "010"} {
+		if !strings.Contains(filepath.Base(chapters[i]), chapterMarker+want) {
+			t.Errorf("chapter %d = %q, want chapter %s", i, chapters[i], want)
+		}
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 3 {
+		t.Errorf("full video not removed: %d files in output dir", len(entries))
+	}
+}
+
+func TestDownloadMissingBinary(t *testing.T) {
+	runner := &FakeRunner{Missing: true}
+	client := NewClient(testLogger(), runner, WithBinary("/opt/yt-dlp"))
+
+	_, err := client.Download(context.Background(), Options{URL: "https://example.com/v", OutputDir: t.TempDir()})
+	if err == nil || !strings.Contains(err.Error(), "/opt/yt-dlp") {
+		t.Fatalf("Download error = %v, want missing binary error", err)
+	}
+	if calls := runner.Calls(); len(calls) != 0 {
+		t.Errorf("yt-dlp was run %d times without binary", len(calls))
+	}
+}
+
+func TestDownloadArgs(t *testing.T) {
+	runner := &FakeRunner{}
+	client := NewClient(testLogger(), runner, WithBinary("/opt/yt-dlp"), WithSubtitles("en"))
+
+	_, err := client.Download(context.Background(), Options{
+		URL:       "https://example.com/v",
+		OutputDir: t.TempDir(),
+		Prefix:    "test",
+		Format:    "best",
+		Language:  "ru-RU",
+		Args:      []string{"--merge-output-format", "mp4"},
+	})
+	if err != nil {
+		t.Fatalf("Download: %v", err)
+	}
+
+	calls := runner.Calls()
+	if len(calls) != 1 {
+		t.Fatalf("yt-dlp was run %d times, want 1", len(calls))
+	}
+	cmd := calls[0]
+	if cmd.Name != "/opt/yt-dlp" {
+		t.Errorf("command name = %q, want configured binary", cmd.Name)
+	}
+	args := strings.Join(cmd.Args, " ")
+	for _, want := range []string{"https://example.com/v", "-f best", "--sub-langs ru.*", "--merge-output-format mp4"} {
+		if !strings.Contains(args, want) {
+			t.Errorf("args %q do not contain %q", args, want)
+		}
+	}
+}
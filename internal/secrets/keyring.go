@@ -0,0 +1,158 @@
+package secrets
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// header начало зашифрованных данных: "reelser-sealed v1 <id ключа>\n", далее base64(nonce + шифротекст)
+const header = "reelser-sealed v1 "
+
+// KeySize длина ключа AES-256 в байтах
+const KeySize = 32
+
+// ErrUnknownKey возвращается, если данные зашифрованы ключом, которого нет в связке
+var ErrUnknownKey = errors.New("data is sealed with an unknown key")
+
+// key ключ связки; id — короткий отпечаток ключа, по которому выбирается ключ при расшифровке
+type key struct {
+	id   string
+	aead cipher.AEAD
+}
+
+// Keyring связка ключей AES-256-GCM. Первый ключ шифрует, остальные только
+// расшифровывают: так старые файлы читаются после ротации ключа
+type Keyring struct {
+	keys []key
+}
+
+// NewKeyring создает связку из текущего ключа и предыдущих ключей (base64, 32 байта).
+// Пустой current отключает шифрование: возвращается nil
+func NewKeyring(current string, previous ...string) (*Keyring, error) {
+	if strings.TrimSpace(current) == "" {
+		if len(previous) > 0 {
+			return nil, fmt.Errorf("previous keys are set without a current key")
+		}
+		return nil, nil
+	}
+
+	k := &Keyring{}
+	for i, encoded := range append([]string{current}, previous...) {
+		parsed, err := parseKey(encoded)
+		if err != nil {
+			return nil, fmt.Errorf("key %d: %w", i+1, err)
+		}
+		k.keys = append(k.keys, parsed)
+	}
+	return k, nil
+}
+
+// GenerateKey возвращает новый случайный ключ в base64
+func GenerateKey() (string, error) {
+	raw := make([]byte, KeySize)
+	if _, err := rand.Read(raw); err != nil {
+		return "", fmt.Errorf("failed to generate key: %w", err)
+	}
+	return base64.StdEncoding.EncodeToString(raw), nil
+}
+
+// parseKey разбирает ключ в base64
+func parseKey(encoded string) (key, error) {
+	raw, err := base64.StdEncoding.DecodeString(strings.TrimSpace(encoded))
+	if err != nil {
+		return key{}, fmt.Errorf("invalid base64: %w", err)
+	}
+	if len(raw) != KeySize {
+		return key{}, fmt.Errorf("expected %d bytes, got %d", KeySize, len(raw))
+	}
+
+	block, err := aes.NewCipher(raw)
+	if err != nil {
+		return key{}, err
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return key{}, err
+	}
+
+	sum := sha256.Sum256(raw)
+	return key{id: hex.EncodeToString(sum[:4]), aead: aead}, nil
+}
+
+// IsSealed проверяет, что данные зашифрованы Seal
+func IsSealed(data []byte) bool {
+	return bytes.HasPrefix(data, []byte(header))
+}
+
+// Seal шифрует данные текущим ключом
+func (k *Keyring) Seal(plaintext []byte) ([]byte, error) {
+	current := k.keys[0]
+	prefix := header + current.id + "\n"
+
+	nonce := make([]byte, current.aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, fmt.Errorf("failed to generate nonce: %w", err)
+	}
+	sealed := current.aead.Seal(nonce, nonce, plaintext, []byte(prefix))
+
+	out := make([]byte, 0, len(prefix)+base64.StdEncoding.EncodedLen(len(sealed))+1)
+	out = append(out, prefix...)
+	out = base64.StdEncoding.AppendEncode(out, sealed)
+	return append(out, '\n'), nil
+}
+
+// Open расшифровывает данные тем ключом связки, которым они были зашифрованы
+func (k *Keyring) Open(data []byte) ([]byte, error) {
+	id, payload, err := split(data)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, candidate := range k.keys {
+		if candidate.id != id {
+			continue
+		}
+
+		sealed, err := base64.StdEncoding.DecodeString(strings.TrimSpace(payload))
+		if err != nil {
+			return nil, fmt.Errorf("invalid sealed payload: %w", err)
+		}
+		size := candidate.aead.NonceSize()
+		if len(sealed) < size {
+			return nil, fmt.Errorf("sealed payload is too short")
+		}
+
+		plaintext, err := candidate.aead.Open(nil, sealed[:size], sealed[size:], []byte(header+id+"\n"))
+		if err != nil {
+			return nil, fmt.Errorf("failed to decrypt: %w", err)
+		}
+		return plaintext, nil
+	}
+	return nil, fmt.Errorf("%w %s", ErrUnknownKey, id)
+}
+
+// NeedsRotation проверяет, что данные зашифрованы не текущим ключом
+func (k *Keyring) NeedsRotation(data []byte) bool {
+	id, _, err := split(data)
+	return err == nil && id != k.keys[0].id
+}
+
+// split отделяет id ключа от зашифрованной части
+func split(data []byte) (string, string, error) {
+	if !IsSealed(data) {
+		return "", "", fmt.Errorf("data is not sealed")
+	}
+	line, payload, ok := strings.Cut(string(data[len(header):]), "\n")
+	if !ok {
+		return "", "", fmt.Errorf("invalid sealed header")
+	}
+	return strings.TrimSpace(line), payload, nil
+}
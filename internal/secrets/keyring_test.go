@@ -0,0 +1,130 @@
+package secrets
+
+import (
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func newTestKey(t *testing.T) string {
+	t.Helper()
+	encoded, err := GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return encoded
+}
+
+func newTestKeyring(t *testing.T, current string, previous ...string) *Keyring {
+	t.Helper()
+	k, err := NewKeyring(current, previous...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return k
+}
+
+func TestSealOpen(t *testing.T) {
+	k := newTestKeyring(t, newTestKey(t))
+	plaintext := []byte(`{"token":"secret"}`)
+
+	sealed, err := k.Seal(plaintext)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !IsSealed(sealed) {
+		t.Fatal("Seal output is not recognized as sealed")
+	}
+	if bytes.Contains(sealed, plaintext) {
+		t.Fatal("sealed data contains the plaintext")
+	}
+
+	opened, err := k.Open(sealed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(opened, plaintext) {
+		t.Errorf("Open = %q, want %q", opened, plaintext)
+	}
+	if k.NeedsRotation(sealed) {
+		t.Error("data sealed with the current key needs rotation")
+	}
+}
+
+func TestOpenWrongKey(t *testing.T) {
+	sealed, err := newTestKeyring(t, newTestKey(t)).Seal([]byte("data"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = newTestKeyring(t, newTestKey(t)).Open(sealed)
+	if !errors.Is(err, ErrUnknownKey) {
+		t.Errorf("Open with another key: err = %v, want ErrUnknownKey", err)
+	}
+}
+
+func TestOpenRotatedKey(t *testing.T) {
+	oldKey := newTestKey(t)
+	sealed, err := newTestKeyring(t, oldKey).Seal([]byte("data"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rotated := newTestKeyring(t, newTestKey(t), oldKey)
+	opened, err := rotated.Open(sealed)
+	if err != nil {
+		t.Fatalf("Open with the previous key: %v", err)
+	}
+	if string(opened) != "data" {
+		t.Errorf("Open = %q, want %q", opened, "data")
+	}
+	if !rotated.NeedsRotation(sealed) {
+		t.Error("data sealed with the previous key does not need rotation")
+	}
+
+	resealed, err := rotated.Seal(opened)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rotated.NeedsRotation(resealed) {
+		t.Error("resealed data still needs rotation")
+	}
+}
+
+func TestOpenTampered(t *testing.T) {
+	k := newTestKeyring(t, newTestKey(t))
+	sealed, err := k.Seal([]byte("data"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	id, payload, err := split(sealed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	raw, err := base64.StdEncoding.DecodeString(strings.TrimSpace(payload))
+	if err != nil {
+		t.Fatal(err)
+	}
+	raw[len(raw)-1] ^= 1
+	tampered := []byte(header + id + "\n" + base64.StdEncoding.EncodeToString(raw) + "\n")
+
+	if _, err := k.Open(tampered); err == nil {
+		t.Error("Open accepted tampered ciphertext")
+	}
+}
+
+func TestNewKeyringInvalid(t *testing.T) {
+	if k, err := NewKeyring(""); k != nil || err != nil {
+		t.Errorf("NewKeyring without a key = %v, %v, want nil, nil", k, err)
+	}
+	if _, err := NewKeyring("", newTestKey(t)); err == nil {
+		t.Error("previous keys without a current key accepted")
+	}
+	short := base64.StdEncoding.EncodeToString(make([]byte, KeySize-1))
+	if _, err := NewKeyring(short); err == nil {
+		t.Error("short key accepted")
+	}
+}
@@ -2,6 +2,7 @@ package auth
 
 import (
 	"bufio"
+	"context"
 	"fmt"
 	"log/slog"
 	"os"
@@ -11,17 +12,24 @@ import (
 	"sync"
 
 	"github.com/reelser-bot/internal/config"
+	"github.com/reelser-bot/internal/storage/redis"
 )
 
 // Service отвечает за авторизацию пользователей по токенам
 type Service struct {
-	logger  *slog.Logger
-	enabled bool
+	logger *slog.Logger
 
 	mu               sync.RWMutex
+	enabled          bool
+	admins           map[int64]struct{}
 	validTokens      map[string]struct{}
 	allowedUsers     map[int64]struct{}
 	allowedUsersFile string
+	// configAdmins администраторы из ADMIN_IDS: их права не отзываются
+	configAdmins map[int64]struct{}
+
+	// shared общее состояние экземпляров бота (REDIS_URL); nil — только локальное
+	shared *redis.Cache
 }
 
 // NewService создает новый сервис авторизации
@@ -31,9 +39,18 @@ func NewService(logger *slog.Logger, cfg config.AuthConfig) *Service {
 		tokens[t] = struct{}{}
 	}
 
+	admins := make(map[int64]struct{})
+	configAdmins := make(map[int64]struct{})
+	for _, id := range cfg.AdminIDs {
+		admins[id] = struct{}{}
+		configAdmins[id] = struct{}{}
+	}
+
 	svc := &Service{
 		logger:           logger,
 		enabled:          cfg.Enabled,
+		admins:           admins,
+		configAdmins:     configAdmins,
 		validTokens:      tokens,
 		allowedUsers:     make(map[int64]struct{}),
 		allowedUsersFile: strings.TrimSpace(cfg.AllowedUsersFile),
@@ -46,7 +63,29 @@ func NewService(logger *slog.Logger, cfg config.AuthConfig) *Service {
 
 // IsEnabled возвращает, включена ли авторизация
 func (s *Service) IsEnabled() bool {
-	return s != nil && s.enabled
+	if s == nil {
+		return false
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.enabled
+}
+
+// SetEnabled включает или выключает авторизацию во время работы (первичная настройка)
+func (s *Service) SetEnabled(enabled bool) {
+	s.mu.Lock()
+	s.enabled = enabled
+	s.mu.Unlock()
+
+	value := "0"
+	if enabled {
+		value = "1"
+	}
+	s.publish(func(ctx context.Context, shared *redis.Cache) error {
+		return shared.Set(ctx, sharedEnabledKey, []byte(value))
+	})
 }
 
 // IsAuthorized проверяет, авторизован ли пользователь
@@ -62,6 +101,86 @@ func (s *Service) IsAuthorized(userID int64) bool {
 	return ok
 }
 
+// IsAdmin проверяет, является ли пользователь администратором бота
+func (s *Service) IsAdmin(userID int64) bool {
+	if s == nil {
+		return false
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	_, ok := s.admins[userID]
+	return ok
+}
+
+// HasAdmins проверяет, назначен ли хотя бы один администратор
+func (s *Service) HasAdmins() bool {
+	if s == nil {
+		return false
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return len(s.admins) > 0
+}
+
+// Admins возвращает ID администраторов бота
+func (s *Service) Admins() []int64 {
+	if s == nil {
+		return nil
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	admins := make([]int64, 0, len(s.admins))
+	for id := range s.admins {
+		admins = append(admins, id)
+	}
+	return admins
+}
+
+// AddAdmin назначает пользователя администратором бота
+func (s *Service) AddAdmin(userID int64) {
+	s.mu.Lock()
+	s.admins[userID] = struct{}{}
+	s.mu.Unlock()
+
+	s.publish(func(ctx context.Context, shared *redis.Cache) error {
+		return shared.SAdd(ctx, sharedAdminsKey, strconv.FormatInt(userID, 10))
+	})
+}
+
+// RemoveAdmin отзывает права администратора. Администраторов из ADMIN_IDS отозвать
+// нельзя — для них возвращается false
+func (s *Service) RemoveAdmin(userID int64) bool {
+	s.mu.Lock()
+	if _, ok := s.configAdmins[userID]; ok {
+		s.mu.Unlock()
+		return false
+	}
+	delete(s.admins, userID)
+	s.mu.Unlock()
+
+	s.publish(func(ctx context.Context, shared *redis.Cache) error {
+		return shared.SRem(ctx, sharedAdminsKey, strconv.FormatInt(userID, 10))
+	})
+	return true
+}
+
+// Allow авторизует пользователя без токена и сохраняет его в файл разрешенных
+func (s *Service) Allow(userID int64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, exists := s.allowedUsers[userID]; exists {
+		return
+	}
+	s.addAllowedUser(userID)
+}
+
 // TryAuthorize пытается авторизовать пользователя по токену
 // Возвращает true, если токен валиден и пользователь авторизован
 func (s *Service) TryAuthorize(userID int64, token string) bool {
@@ -82,7 +201,18 @@ func (s *Service) TryAuthorize(userID int64, token string) bool {
 	if _, exists := s.allowedUsers[userID]; exists {
 		return true
 	}
+	s.addAllowedUser(userID)
+
+	s.logger.Info("User authorized successfully",
+		slog.Int64("user_id", userID),
+	)
 
+	return true
+}
+
+// addAllowedUser добавляет пользователя в разрешенные, файл и общее состояние;
+// вызывается под s.mu. Общее состояние обновляется в фоне, чтобы не держать блокировку
+func (s *Service) addAllowedUser(userID int64) {
 	s.allowedUsers[userID] = struct{}{}
 	if err := s.appendAllowedUserToFile(userID); err != nil {
 		s.logger.Warn("Failed to persist allowed user",
@@ -91,11 +221,9 @@ func (s *Service) TryAuthorize(userID int64, token string) bool {
 		)
 	}
 
-	s.logger.Info("User authorized successfully",
-		slog.Int64("user_id", userID),
-	)
-
-	return true
+	go s.publish(func(ctx context.Context, shared *redis.Cache) error {
+		return shared.SAdd(ctx, sharedAllowedUsersKey, strconv.FormatInt(userID, 10))
+	})
 }
 
 func (s *Service) loadAllowedUsersFromFile() {
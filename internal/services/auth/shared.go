@@ -0,0 +1,117 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"strconv"
+
+	"github.com/reelser-bot/internal/storage/redis"
+)
+
+// Ключи общего состояния авторизации в Redis
+const (
+	sharedTopic           = "auth"
+	sharedAllowedUsersKey = "auth:allowed_users"
+	sharedAdminsKey       = "auth:admins"
+	sharedEnabledKey      = "auth:enabled"
+)
+
+// Share подключает общее состояние нескольких экземпляров: разрешенные пользователи
+// и администраторы этого экземпляра добавляются в Redis, после чего объединяются
+// с уже сохраненными там. Изменения других экземпляров применяются по уведомлениям
+func (s *Service) Share(ctx context.Context, cache *redis.Cache) error {
+	s.mu.RLock()
+	allowed := idStrings(s.allowedUsers)
+	admins := idStrings(s.admins)
+	s.mu.RUnlock()
+
+	if err := cache.SAdd(ctx, sharedAllowedUsersKey, allowed...); err != nil {
+		return err
+	}
+	if err := cache.SAdd(ctx, sharedAdminsKey, admins...); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	s.shared = cache
+	s.mu.Unlock()
+
+	cache.OnInvalidate(sharedTopic, func(string) { s.reload(context.Background()) })
+	return s.reload(ctx)
+}
+
+// reload перечитывает общее состояние. Пользователи только добавляются: отзыв доступа
+// не поддерживается и локально. Администраторы заменяются общим списком, чтобы отзыв
+// прав на одном экземпляре действовал на всех; ADMIN_IDS остаются всегда
+func (s *Service) reload(ctx context.Context) error {
+	allowed, err := s.shared.SMembers(ctx, sharedAllowedUsersKey)
+	if err != nil {
+		return s.reloadFailed(err)
+	}
+	admins, err := s.shared.SMembers(ctx, sharedAdminsKey)
+	if err != nil {
+		return s.reloadFailed(err)
+	}
+	enabled, enabledErr := s.shared.Get(ctx, sharedEnabledKey)
+	if enabledErr != nil && !errors.Is(enabledErr, redis.ErrNil) {
+		return s.reloadFailed(enabledErr)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	addIDs(s.allowedUsers, allowed)
+	s.admins = make(map[int64]struct{}, len(admins)+len(s.configAdmins))
+	for id := range s.configAdmins {
+		s.admins[id] = struct{}{}
+	}
+	addIDs(s.admins, admins)
+	if enabledErr == nil {
+		s.enabled = string(enabled) == "1"
+	}
+	return nil
+}
+
+// reloadFailed логирует ошибку чтения общего состояния
+func (s *Service) reloadFailed(err error) error {
+	s.logger.Warn("Failed to reload shared auth state", slog.Any("error", err))
+	return err
+}
+
+// publish записывает изменение в общее состояние и уведомляет остальные экземпляры
+func (s *Service) publish(write func(ctx context.Context, shared *redis.Cache) error) {
+	s.mu.RLock()
+	shared := s.shared
+	s.mu.RUnlock()
+	if shared == nil {
+		return
+	}
+
+	ctx := context.Background()
+	err := write(ctx, shared)
+	if err == nil {
+		err = shared.Invalidate(ctx, sharedTopic, "")
+	}
+	if err != nil {
+		s.logger.Warn("Failed to publish shared auth state", slog.Any("error", err))
+	}
+}
+
+// idStrings возвращает ID множества строками
+func idStrings(ids map[int64]struct{}) []string {
+	out := make([]string, 0, len(ids))
+	for id := range ids {
+		out = append(out, strconv.FormatInt(id, 10))
+	}
+	return out
+}
+
+// addIDs добавляет в множество ID из строк, пропуская некорректные
+func addIDs(ids map[int64]struct{}, values []string) {
+	for _, v := range values {
+		if id, err := strconv.ParseInt(v, 10, 64); err == nil {
+			ids[id] = struct{}{}
+		}
+	}
+}
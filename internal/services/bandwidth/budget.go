@@ -0,0 +1,153 @@
+package bandwidth
+
+import (
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/reelser-bot/internal/clock"
+	"github.com/reelser-bot/internal/storage"
+)
+
+// day период, за который бюджет восполняется полностью
+const day = 24 * time.Hour
+
+// state сохраняемое состояние бюджета
+type state struct {
+	Tokens    int64     `json:"tokens"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// Budget суточный бюджет трафика по схеме token bucket: емкость равна суточному
+// лимиту, байты восполняются равномерно в течение суток. Баланс может уйти в минус
+// на размер последнего файла — новые загрузки разрешаются, когда он снова положителен
+type Budget struct {
+	logger *slog.Logger
+	path   string
+	clock  clock.Clock
+	// perDay суточный лимит в байтах; 0 — без ограничения
+	perDay int64
+	// warnPercent доля израсходованного лимита, после которой бюджет считается почти
+	// исчерпанным; 0 — без предупреждений
+	warnPercent int
+
+	mu    sync.Mutex
+	state state
+}
+
+// NewBudget создает бюджет на perDay байт в сутки и загружает сохраненный остаток.
+// warnPercent задает порог предупреждения в процентах израсходованного лимита
+func NewBudget(logger *slog.Logger, perDay int64, warnPercent int, path string, clk clock.Clock) *Budget {
+	b := &Budget{
+		logger:      logger,
+		path:        path,
+		clock:       clk,
+		perDay:      perDay,
+		warnPercent: warnPercent,
+		state:       state{Tokens: perDay, UpdatedAt: clk.Now()},
+	}
+
+	if perDay <= 0 || path == "" {
+		return b
+	}
+
+	var saved state
+	if err := storage.LoadJSON(path, &saved); err != nil {
+		logger.Warn("Failed to load bandwidth budget",
+			slog.String("file", path),
+			slog.Any("error", err),
+		)
+		return b
+	}
+	if !saved.UpdatedAt.IsZero() {
+		b.state = saved
+	}
+
+	return b
+}
+
+// Enabled проверяет, что суточный лимит задан
+func (b *Budget) Enabled() bool {
+	return b.perDay > 0
+}
+
+// PerDay возвращает суточный лимит в байтах
+func (b *Budget) PerDay() int64 {
+	return b.perDay
+}
+
+// Allow проверяет, что бюджет не исчерпан и можно начинать новую загрузку
+func (b *Budget) Allow() bool {
+	return !b.Enabled() || b.Remaining() > 0
+}
+
+// Remaining возвращает остаток бюджета в байтах (отрицательный — перерасход)
+func (b *Budget) Remaining() int64 {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.refill()
+	return b.state.Tokens
+}
+
+// Low проверяет, что израсходовано не меньше warnPercent суточного лимита, но бюджет
+// еще не исчерпан: пользователей стоит предупредить до отказа в загрузках
+func (b *Budget) Low() bool {
+	if !b.Enabled() || b.warnPercent <= 0 || b.warnPercent >= 100 {
+		return false
+	}
+	remaining := b.Remaining()
+	return remaining > 0 && remaining <= b.perDay*int64(100-b.warnPercent)/100
+}
+
+// RetryAfter возвращает, через сколько бюджет снова станет положительным
+func (b *Budget) RetryAfter() time.Duration {
+	remaining := b.Remaining()
+	if !b.Enabled() || remaining > 0 {
+		return 0
+	}
+	return time.Duration(float64(-remaining+1) / float64(b.perDay) * float64(day))
+}
+
+// Consume списывает переданные байты и сохраняет остаток
+func (b *Budget) Consume(n int64) {
+	if !b.Enabled() || n <= 0 {
+		return
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.refill()
+	b.state.Tokens -= n
+	b.persist()
+}
+
+// refill восполняет бюджет за время, прошедшее с последнего обновления; вызывается под b.mu
+func (b *Budget) refill() {
+	now := b.clock.Now()
+	elapsed := now.Sub(b.state.UpdatedAt)
+	if elapsed <= 0 {
+		return
+	}
+
+	b.state.Tokens += int64(float64(b.perDay) * (float64(elapsed) / float64(day)))
+	if b.state.Tokens > b.perDay {
+		b.state.Tokens = b.perDay
+	}
+	b.state.UpdatedAt = now
+}
+
+// persist записывает состояние на диск; вызывается под b.mu
+func (b *Budget) persist() {
+	if b.path == "" {
+		return
+	}
+
+	if err := storage.SaveJSON(b.path, b.state); err != nil {
+		b.logger.Warn("Failed to persist bandwidth budget",
+			slog.String("file", b.path),
+			slog.Any("error", err),
+		)
+	}
+}
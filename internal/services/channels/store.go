@@ -0,0 +1,95 @@
+package channels
+
+import (
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/reelser-bot/internal/storage"
+)
+
+// Link описывает личный канал пользователя для архивации видео
+type Link struct {
+	ChannelID int64     `json:"channel_id"`
+	Title     string    `json:"title"`
+	LinkedAt  time.Time `json:"linked_at"`
+}
+
+// Store хранит привязки пользователей к личным каналам
+type Store struct {
+	logger *slog.Logger
+	path   string
+
+	mu    sync.RWMutex
+	links map[int64]Link
+}
+
+// NewStore создает хранилище привязок и загружает сохраненные данные
+func NewStore(logger *slog.Logger, path string) *Store {
+	s := &Store{
+		logger: logger,
+		path:   path,
+		links:  make(map[int64]Link),
+	}
+
+	if path == "" {
+		return s
+	}
+
+	if err := storage.LoadJSON(path, &s.links); err != nil {
+		logger.Warn("Failed to load channel links",
+			slog.String("file", path),
+			slog.Any("error", err),
+		)
+	}
+	if s.links == nil {
+		s.links = make(map[int64]Link)
+	}
+
+	return s
+}
+
+// Get возвращает привязанный канал пользователя
+func (s *Store) Get(userID int64) (Link, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	link, ok := s.links[userID]
+	return link, ok
+}
+
+// Set привязывает канал к пользователю, заменяя предыдущий
+func (s *Store) Set(userID int64, link Link) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.links[userID] = link
+	s.persist()
+}
+
+// Remove отвязывает канал пользователя. Возвращает false, если привязки не было
+func (s *Store) Remove(userID int64) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.links[userID]; !ok {
+		return false
+	}
+	delete(s.links, userID)
+	s.persist()
+	return true
+}
+
+// persist записывает привязки на диск; вызывается под s.mu
+func (s *Store) persist() {
+	if s.path == "" {
+		return
+	}
+
+	if err := storage.SaveJSON(s.path, s.links); err != nil {
+		s.logger.Warn("Failed to persist channel links",
+			slog.String("file", s.path),
+			slog.Any("error", err),
+		)
+	}
+}
@@ -0,0 +1,71 @@
+package chatsettings
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// QuietHours ежедневное окно тишины в минутах от полуночи. Start > End означает
+// окно через полночь (например, 23:00-08:00)
+type QuietHours struct {
+	Start int `json:"start"`
+	End   int `json:"end"`
+}
+
+// ParseQuietHours разбирает окно в формате ЧЧ:ММ-ЧЧ:ММ
+func ParseQuietHours(value string) (QuietHours, error) {
+	startStr, endStr, ok := strings.Cut(strings.TrimSpace(value), "-")
+	if !ok {
+		return QuietHours{}, fmt.Errorf("ожидается формат ЧЧ:ММ-ЧЧ:ММ")
+	}
+
+	start, err := parseClock(startStr)
+	if err != nil {
+		return QuietHours{}, err
+	}
+	end, err := parseClock(endStr)
+	if err != nil {
+		return QuietHours{}, err
+	}
+	if start == end {
+		return QuietHours{}, fmt.Errorf("начало и конец окна совпадают")
+	}
+
+	return QuietHours{Start: start, End: end}, nil
+}
+
+// parseClock переводит ЧЧ:ММ в минуты от полуночи
+func parseClock(value string) (int, error) {
+	t, err := time.Parse("15:04", strings.TrimSpace(value))
+	if err != nil {
+		return 0, fmt.Errorf("не удалось разобрать время %q", value)
+	}
+	return t.Hour()*60 + t.Minute(), nil
+}
+
+// Until возвращает момент окончания окна, если now попадает в него
+func (q QuietHours) Until(now time.Time) (time.Time, bool) {
+	minute := now.Hour()*60 + now.Minute()
+
+	var active bool
+	if q.Start < q.End {
+		active = minute >= q.Start && minute < q.End
+	} else {
+		active = minute >= q.Start || minute < q.End
+	}
+	if !active {
+		return time.Time{}, false
+	}
+
+	end := time.Date(now.Year(), now.Month(), now.Day(), q.End/60, q.End%60, 0, 0, now.Location())
+	if !end.After(now) {
+		end = end.AddDate(0, 0, 1)
+	}
+	return end, true
+}
+
+// String возвращает окно в формате ЧЧ:ММ-ЧЧ:ММ
+func (q QuietHours) String() string {
+	return fmt.Sprintf("%02d:%02d-%02d:%02d", q.Start/60, q.Start%60, q.End/60, q.End%60)
+}
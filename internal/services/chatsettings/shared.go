@@ -0,0 +1,153 @@
+package chatsettings
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strconv"
+
+	"github.com/reelser-bot/internal/storage/redis"
+)
+
+// Ключи общих настроек чатов в Redis: хеш "ID чата → JSON настроек"
+const (
+	sharedTopic = "chat_settings"
+	sharedKey   = "chat_settings"
+)
+
+// Share подключает общие настройки нескольких экземпляров. Настройки чатов, которых
+// еще нет в Redis, переносятся туда из локального файла; затем локальные данные
+// заменяются общими. Изменения других экземпляров применяются по уведомлениям
+func (s *Store) Share(ctx context.Context, cache *redis.Cache) error {
+	remote, err := cache.HGetAll(ctx, sharedKey)
+	if err != nil {
+		return err
+	}
+
+	s.mu.RLock()
+	local := make(map[int64]Settings, len(s.chats))
+	for chatID, settings := range s.chats {
+		local[chatID] = settings
+	}
+	s.mu.RUnlock()
+
+	for chatID, settings := range local {
+		field := strconv.FormatInt(chatID, 10)
+		if _, ok := remote[field]; ok {
+			continue
+		}
+		data, err := json.Marshal(settings)
+		if err != nil {
+			return fmt.Errorf("failed to encode chat settings: %w", err)
+		}
+		if err := cache.HSet(ctx, sharedKey, field, data); err != nil {
+			return err
+		}
+	}
+
+	s.mu.Lock()
+	s.shared = cache
+	s.mu.Unlock()
+
+	cache.OnInvalidate(sharedTopic, s.reload)
+	s.reload("")
+	return nil
+}
+
+// reload перечитывает настройки чата из Redis; пустой ключ — все чаты
+func (s *Store) reload(key string) {
+	ctx := context.Background()
+
+	if key == "" {
+		remote, err := s.shared.HGetAll(ctx, sharedKey)
+		if err != nil {
+			s.logger.Warn("Failed to reload shared chat settings", slog.Any("error", err))
+			return
+		}
+
+		chats := make(map[int64]Settings, len(remote))
+		for field, data := range remote {
+			chatID, settings, ok := s.decode(field, data)
+			if ok && !settings.empty() {
+				chats[chatID] = settings
+			}
+		}
+
+		s.mu.Lock()
+		s.chats = chats
+		s.persist()
+		s.mu.Unlock()
+		return
+	}
+
+	data, err := s.shared.HGet(ctx, sharedKey, key)
+	if err != nil && !errors.Is(err, redis.ErrNil) {
+		s.logger.Warn("Failed to reload shared chat settings",
+			slog.String("chat_id", key),
+			slog.Any("error", err),
+		)
+		return
+	}
+
+	var settings Settings
+	chatID, parsed, ok := s.decode(key, data)
+	if !ok {
+		return
+	}
+	if err == nil {
+		settings = parsed
+	}
+
+	s.mu.Lock()
+	s.set(chatID, settings)
+	s.persist()
+	s.mu.Unlock()
+}
+
+// decode разбирает поле хеша; пустые данные — нулевые настройки
+func (s *Store) decode(field string, data []byte) (int64, Settings, bool) {
+	chatID, err := strconv.ParseInt(field, 10, 64)
+	if err != nil {
+		s.logger.Warn("Invalid chat ID in shared chat settings", slog.String("field", field))
+		return 0, Settings{}, false
+	}
+
+	var settings Settings
+	if len(data) > 0 {
+		if err := json.Unmarshal(data, &settings); err != nil {
+			s.logger.Warn("Failed to decode shared chat settings",
+				slog.Int64("chat_id", chatID),
+				slog.Any("error", err),
+			)
+			return 0, Settings{}, false
+		}
+	}
+	return chatID, settings, true
+}
+
+// publish записывает настройки чата в Redis и уведомляет остальные экземпляры
+func (s *Store) publish(shared *redis.Cache, chatID int64, settings Settings) {
+	ctx := context.Background()
+	field := strconv.FormatInt(chatID, 10)
+
+	var err error
+	if settings.empty() {
+		err = shared.HDel(ctx, sharedKey, field)
+	} else {
+		var data []byte
+		if data, err = json.Marshal(settings); err == nil {
+			err = shared.HSet(ctx, sharedKey, field, data)
+		}
+	}
+	if err == nil {
+		err = shared.Invalidate(ctx, sharedTopic, field)
+	}
+	if err != nil {
+		s.logger.Warn("Failed to publish shared chat settings",
+			slog.Int64("chat_id", chatID),
+			slog.Any("error", err),
+		)
+	}
+}
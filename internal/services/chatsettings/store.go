@@ -0,0 +1,125 @@
+package chatsettings
+
+import (
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/reelser-bot/internal/storage"
+	"github.com/reelser-bot/internal/storage/redis"
+)
+
+// Settings настройки чата, которые задают его администраторы
+type Settings struct {
+	// QuietHours окно, в которое бот принимает ссылки, но откладывает ответы; nil — не задано
+	QuietHours *QuietHours `json:"quiet_hours,omitempty"`
+	// CleanupAfter через сколько удалять служебные сообщения бота; 0 — не удалять
+	CleanupAfter time.Duration `json:"cleanup_after,omitempty"`
+	// Timezone часовой пояс чата (IANA, например Europe/Moscow); пусто — DEFAULT_TIMEZONE
+	Timezone string `json:"timezone,omitempty"`
+	// AsDocument присылать видео файлом, чтобы Telegram не пережимал его
+	AsDocument bool `json:"as_document,omitempty"`
+	// StatusMessageID закрепленное сообщение со статусом бота (/status); 0 — не ведется
+	StatusMessageID int `json:"status_message_id,omitempty"`
+}
+
+// empty проверяет, что в настройках нет ни одного заданного значения
+func (s Settings) empty() bool {
+	return s.QuietHours == nil && s.CleanupAfter == 0 && s.Timezone == "" && !s.AsDocument &&
+		s.StatusMessageID == 0
+}
+
+// Store хранит настройки чатов на диске
+type Store struct {
+	logger *slog.Logger
+	path   string
+
+	mu    sync.RWMutex
+	chats map[int64]Settings
+
+	// shared общие настройки экземпляров бота (REDIS_URL); nil — только локальные
+	shared *redis.Cache
+}
+
+// NewStore создает хранилище настроек и загружает сохраненные данные
+func NewStore(logger *slog.Logger, path string) *Store {
+	s := &Store{
+		logger: logger,
+		path:   path,
+		chats:  make(map[int64]Settings),
+	}
+
+	if path == "" {
+		return s
+	}
+
+	if err := storage.LoadJSON(path, &s.chats); err != nil {
+		logger.Warn("Failed to load chat settings",
+			slog.String("file", path),
+			slog.Any("error", err),
+		)
+	}
+	if s.chats == nil {
+		s.chats = make(map[int64]Settings)
+	}
+
+	return s
+}
+
+// Get возвращает настройки чата (нулевые, если чат ничего не настраивал)
+func (s *Store) Get(chatID int64) Settings {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.chats[chatID]
+}
+
+// All возвращает копию настроек всех чатов
+func (s *Store) All() map[int64]Settings {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	chats := make(map[int64]Settings, len(s.chats))
+	for chatID, settings := range s.chats {
+		chats[chatID] = settings
+	}
+	return chats
+}
+
+// Update изменяет настройки чата через fn и сохраняет их
+func (s *Store) Update(chatID int64, fn func(*Settings)) {
+	s.mu.Lock()
+	settings := s.chats[chatID]
+	fn(&settings)
+	s.set(chatID, settings)
+	s.persist()
+	shared := s.shared
+	s.mu.Unlock()
+
+	if shared != nil {
+		s.publish(shared, chatID, settings)
+	}
+}
+
+// set заменяет настройки чата, удаляя пустые; вызывается под s.mu
+func (s *Store) set(chatID int64, settings Settings) {
+	if settings.empty() {
+		delete(s.chats, chatID)
+	} else {
+		s.chats[chatID] = settings
+	}
+}
+
+// persist записывает настройки на диск; вызывается под s.mu
+func (s *Store) persist() {
+	if s.path == "" {
+		return
+	}
+
+	if err := storage.SaveJSON(s.path, s.chats); err != nil {
+		s.logger.Warn("Failed to persist chat settings",
+			slog.String("file", s.path),
+			slog.Any("error", err),
+		)
+	}
+}
@@ -0,0 +1,142 @@
+package compress
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/reelser-bot/internal/config"
+	"github.com/reelser-bot/internal/platform/ytdlp"
+)
+
+const (
+	// sizeMargin доля лимита, на которую рассчитывается битрейт: оставляет запас
+	// на контейнер и неточность кодировщика
+	sizeMargin = 0.92
+	// minVideoKbps битрейт, ниже которого сжатие не имеет смысла
+	minVideoKbps = 150
+)
+
+// ErrTooLong возвращается, если видео настолько длинное, что в лимит
+// помещается только битрейт ниже minVideoKbps
+var ErrTooLong = errors.New("video is too long to fit the size limit")
+
+// Compressor пережимает видео через ffmpeg, чтобы оно поместилось в лимит Telegram
+type Compressor struct {
+	logger *slog.Logger
+	runner ytdlp.CommandRunner
+	cfg    config.CompressionConfig
+}
+
+// NewCompressor создает компрессор; runner запускает ffmpeg и ffprobe
+func NewCompressor(logger *slog.Logger, runner ytdlp.CommandRunner, cfg config.CompressionConfig) *Compressor {
+	return &Compressor{
+		logger: logger,
+		runner: runner,
+		cfg:    cfg,
+	}
+}
+
+// Enabled возвращает true, если сжатие включено (COMPRESS_OVERSIZED)
+func (c *Compressor) Enabled() bool {
+	return c != nil && c.cfg.Enabled
+}
+
+// Compress сжимает видео до limit байт и возвращает путь к новому файлу рядом с исходным.
+// Битрейт берется из COMPRESS_VIDEO_BITRATE_KBPS, иначе рассчитывается по длительности,
+// а если она неизвестна — используется COMPRESS_CRF
+func (c *Compressor) Compress(ctx context.Context, input string, limit int64) (string, error) {
+	if _, err := c.runner.LookPath("ffmpeg"); err != nil {
+		return "", fmt.Errorf("ffmpeg not found: %w", err)
+	}
+
+	if c.cfg.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, c.cfg.Timeout)
+		defer cancel()
+	}
+
+	rateArgs, err := c.rateArgs(ctx, input, limit)
+	if err != nil {
+		return "", err
+	}
+
+	output := strings.TrimSuffix(input, filepath.Ext(input)) + "_compressed.mp4"
+	args := []string{"-y", "-v", "error", "-i", input, "-c:v", "libx264", "-preset", c.cfg.Preset}
+	args = append(args, rateArgs...)
+	args = append(args,
+		"-c:a", "aac", "-b:a", strconv.Itoa(c.cfg.AudioBitrateKbps)+"k",
+		"-movflags", "+faststart",
+		output,
+	)
+
+	startedAt := time.Now()
+	if _, stderr, err := c.runner.Run(ctx, ytdlp.Command{Name: "ffmpeg", Args: args}); err != nil {
+		os.Remove(output)
+		return "", fmt.Errorf("ffmpeg failed: %w: %s", err, strings.TrimSpace(string(stderr)))
+	}
+
+	info, err := os.Stat(output)
+	if err != nil {
+		return "", fmt.Errorf("failed to stat compressed file: %w", err)
+	}
+	if info.Size() > limit {
+		os.Remove(output)
+		return "", fmt.Errorf("compressed file size %d still exceeds limit %d", info.Size(), limit)
+	}
+
+	c.logger.Info("Video compressed",
+		slog.String("file", output),
+		slog.Int64("size", info.Size()),
+		slog.Int64("limit", limit),
+		slog.Duration("took", time.Since(startedAt)),
+	)
+	return output, nil
+}
+
+// rateArgs возвращает аргументы ffmpeg, задающие битрейт или качество видео
+func (c *Compressor) rateArgs(ctx context.Context, input string, limit int64) ([]string, error) {
+	kbps := c.cfg.VideoBitrateKbps
+	if kbps <= 0 {
+		duration, err := c.duration(ctx, input)
+		if err != nil {
+			c.logger.Warn("Failed to get video duration, compressing with CRF",
+				slog.String("file", input),
+				slog.Any("error", err),
+			)
+			return []string{"-crf", strconv.Itoa(c.cfg.CRF)}, nil
+		}
+
+		totalKbps := float64(limit) * 8 * sizeMargin / duration.Seconds() / 1000
+		kbps = int(totalKbps) - c.cfg.AudioBitrateKbps
+		if kbps < minVideoKbps {
+			return nil, ErrTooLong
+		}
+	}
+
+	rate := strconv.Itoa(kbps) + "k"
+	return []string{"-b:v", rate, "-maxrate", rate, "-bufsize", strconv.Itoa(kbps*2) + "k"}, nil
+}
+
+// duration получает длительность видео через ffprobe
+func (c *Compressor) duration(ctx context.Context, input string) (time.Duration, error) {
+	stdout, _, err := c.runner.Run(ctx, ytdlp.Command{
+		Name: "ffprobe",
+		Args: []string{"-v", "error", "-show_entries", "format=duration", "-of", "default=noprint_wrappers=1:nokey=1", input},
+	})
+	if err != nil {
+		return 0, fmt.Errorf("ffprobe failed: %w", err)
+	}
+
+	seconds, err := strconv.ParseFloat(strings.TrimSpace(string(stdout)), 64)
+	if err != nil || seconds <= 0 {
+		return 0, fmt.Errorf("invalid duration %q", strings.TrimSpace(string(stdout)))
+	}
+	return time.Duration(seconds * float64(time.Second)), nil
+}
@@ -0,0 +1,235 @@
+package delivery
+
+import (
+	"log/slog"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/reelser-bot/internal/storage"
+)
+
+// Task описывает отложенную отправку уже скачанного файла. Запись работает как outbox:
+// сначала сохраняется намерение отправки, затем в ту же запись результат отправки,
+// а эффекты по нему (статистика, история, кэш file_id) отмечаются по мере применения.
+// Запись удаляется, только когда применены все эффекты
+type Task struct {
+	ID        string    `json:"id"`
+	ChatID    int64     `json:"chat_id"`
+	ChatType  string    `json:"chat_type"`
+	UserID    int64     `json:"user_id,omitempty"`
+	UserName  string    `json:"user_name,omitempty"`
+	URL       string    `json:"url"`
+	FilePath  string    `json:"file_path"`
+	Source    string    `json:"source"`
+	Quality   string    `json:"quality,omitempty"`
+	Audio     bool      `json:"audio,omitempty"`
+	Animation bool      `json:"animation,omitempty"`
+	VideoNote bool      `json:"video_note,omitempty"`
+	Document  bool      `json:"document,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+
+	// BusinessConnectionID бизнес-подключение, через которое пришел запрос
+	BusinessConnectionID string `json:"business_connection_id,omitempty"`
+	// PaidStars цена платного медиа в Telegram Stars (для постов каналов)
+	PaidStars int `json:"paid_stars,omitempty"`
+	// Compressed файл пережат под лимит чата, его file_id не кэшируется
+	Compressed bool `json:"compressed,omitempty"`
+
+	// Sent результат отправки; nil — файл еще не доставлен
+	Sent *Sent `json:"sent,omitempty"`
+	// Applied эффекты, уже примененные по результату отправки
+	Applied []string `json:"applied,omitempty"`
+}
+
+// Sent результат доставки файла в Telegram
+type Sent struct {
+	MessageID int `json:"message_id,omitempty"`
+	// FileID file_id отправленного видео (пусто для других типов сообщений)
+	FileID string    `json:"file_id,omitempty"`
+	At     time.Time `json:"at"`
+}
+
+// IsApplied проверяет, что эффект уже применен
+func (t Task) IsApplied(effect string) bool {
+	for _, applied := range t.Applied {
+		if applied == effect {
+			return true
+		}
+	}
+	return false
+}
+
+// Store хранит незавершенные отправки на диске, чтобы их можно было
+// возобновить после перезапуска
+type Store struct {
+	logger *slog.Logger
+	path   string
+
+	mu     sync.Mutex
+	tasks  map[string]Task
+	writes *storage.Debouncer
+}
+
+// persistDelay за сколько отметки эффектов и удаления задач собираются в одну запись
+// файла. Намерение и результат отправки записываются сразу: от них зависит, будет ли
+// файл отправлен повторно
+const persistDelay = time.Second
+
+// NewStore создает хранилище отправок и загружает сохраненные задачи
+func NewStore(logger *slog.Logger, path string) *Store {
+	s := &Store{
+		logger: logger,
+		path:   path,
+		tasks:  make(map[string]Task),
+	}
+	s.writes = storage.NewDebouncer(persistDelay, s.save)
+
+	if path == "" {
+		return s
+	}
+
+	var tasks []Task
+	if err := storage.LoadJSON(path, &tasks); err != nil {
+		logger.Warn("Failed to load pending deliveries",
+			slog.String("file", path),
+			slog.Any("error", err),
+		)
+		return s
+	}
+	for _, task := range tasks {
+		s.tasks[task.ID] = task
+	}
+
+	return s
+}
+
+// Add сохраняет задачу на отправку
+func (s *Store) Add(task Task) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.tasks[task.ID] = task
+	s.persist()
+}
+
+// Complete сохраняет результат отправки: после перезапуска задача не отправляется
+// повторно, а только применяются ее оставшиеся эффекты
+func (s *Store) Complete(id string, sent Sent) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	task, ok := s.tasks[id]
+	if !ok {
+		return
+	}
+	task.Sent = &sent
+	s.tasks[id] = task
+	s.persist()
+}
+
+// MarkApplied отмечает эффект отправки примененным
+func (s *Store) MarkApplied(id, effect string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	task, ok := s.tasks[id]
+	if !ok || task.IsApplied(effect) {
+		return
+	}
+	task.Applied = append(task.Applied, effect)
+	s.tasks[id] = task
+	s.persistLater()
+}
+
+// Get возвращает задачу по ID
+func (s *Store) Get(id string) (Task, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	task, ok := s.tasks[id]
+	return task, ok
+}
+
+// Remove удаляет задачу после завершения отправки
+func (s *Store) Remove(id string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.tasks[id]; !ok {
+		return
+	}
+	delete(s.tasks, id)
+	s.persistLater()
+}
+
+// Pending возвращает незавершенные задачи в порядке создания
+func (s *Store) Pending() []Task {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	tasks := make([]Task, 0, len(s.tasks))
+	for _, task := range s.tasks {
+		tasks = append(tasks, task)
+	}
+	sort.Slice(tasks, func(i, j int) bool { return tasks[i].CreatedAt.Before(tasks[j].CreatedAt) })
+	return tasks
+}
+
+// Files возвращает пути файлов незавершенных отправок. Файлы уже доставленных
+// задач не нужны для восстановления и в список не входят
+func (s *Store) Files() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	files := make([]string, 0, len(s.tasks))
+	for _, task := range s.tasks {
+		if task.Sent != nil {
+			continue
+		}
+		files = append(files, task.FilePath)
+	}
+	return files
+}
+
+// Close записывает на диск изменения, ожидающие отложенной записи
+func (s *Store) Close() {
+	s.writes.Flush()
+}
+
+// persistLater планирует запись задач на диск; вызывается под s.mu. Если эффекты,
+// отмеченные за последнюю секунду, не записались из-за сбоя, после перезапуска они
+// применяются повторно
+func (s *Store) persistLater() {
+	if s.path == "" {
+		return
+	}
+	s.writes.Schedule()
+}
+
+// save записывает задачи на диск
+func (s *Store) save() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.persist()
+}
+
+// persist записывает задачи на диск; вызывается под s.mu
+func (s *Store) persist() {
+	if s.path == "" {
+		return
+	}
+
+	tasks := make([]Task, 0, len(s.tasks))
+	for _, task := range s.tasks {
+		tasks = append(tasks, task)
+	}
+
+	if err := storage.SaveJSON(s.path, tasks); err != nil {
+		s.logger.Warn("Failed to persist pending deliveries",
+			slog.String("file", s.path),
+			slog.Any("error", err),
+		)
+	}
+}
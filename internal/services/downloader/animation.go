@@ -0,0 +1,63 @@
+package downloader
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/reelser-bot/internal/platform/ytdlp"
+)
+
+const (
+	// MaxAnimationDuration самый длинный ролик, который конвертируется в анимацию
+	MaxAnimationDuration = time.Minute
+	// animationWidth максимальная ширина анимации: больше для GIF в чате не нужно
+	animationWidth = 720
+)
+
+// ErrAnimationTooLong возвращается, если ролик длиннее MaxAnimationDuration
+var ErrAnimationTooLong = errors.New("video is too long for an animation")
+
+// ToAnimation конвертирует видео в MP4 без звука, который Telegram показывает как
+// GIF-анимацию, и удаляет исходный файл
+func (s *Service) ToAnimation(ctx context.Context, filePath string) (string, error) {
+	if _, err := s.runner.LookPath("ffmpeg"); err != nil {
+		return "", fmt.Errorf("ffmpeg not found: %w", err)
+	}
+
+	var props VideoProps
+	if err := s.probeVideo(ctx, filePath, &props); err != nil {
+		s.logger.Warn("Failed to probe video before animation", slog.String("file", filePath), slog.Any("error", err))
+	}
+	if props.Duration > MaxAnimationDuration {
+		return "", ErrAnimationTooLong
+	}
+
+	output := strings.TrimSuffix(filePath, filepath.Ext(filePath)) + "_animation.mp4"
+	args := []string{
+		"-y", "-v", "error",
+		"-i", filePath,
+		"-t", fmt.Sprintf("%.0f", MaxAnimationDuration.Seconds()),
+		"-an",
+		// Четная ширина и высота обязательны для yuv420p
+		"-vf", fmt.Sprintf("scale=trunc(min(iw\\,%d)/2)*2:-2", animationWidth),
+		"-c:v", "libx264", "-preset", "veryfast", "-crf", "26",
+		"-pix_fmt", "yuv420p",
+		"-movflags", "+faststart",
+		output,
+	}
+
+	if _, stderr, err := s.runner.Run(ctx, ytdlp.Command{Name: "ffmpeg", Args: args}); err != nil {
+		s.fs.Remove(output)
+		return "", fmt.Errorf("ffmpeg animation failed: %w: %s", err, strings.TrimSpace(string(stderr)))
+	}
+
+	if err := s.fs.Remove(filePath); err != nil {
+		s.logger.Warn("Failed to remove source video", slog.String("file", filePath), slog.Any("error", err))
+	}
+	return output, nil
+}
@@ -0,0 +1,91 @@
+package downloader
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/reelser-bot/internal/platform"
+)
+
+// ErrChaptersUnsupported возвращается, если платформа не умеет делить видео на главы
+var ErrChaptersUnsupported = errors.New("chapters are not supported for this platform")
+
+// ErrNoChapters возвращается, если у видео нет разметки глав
+var ErrNoChapters = errors.New("video has no chapters")
+
+// ChapterDownloader загрузчик платформы, умеющий скачивать видео по главам
+type ChapterDownloader interface {
+	DownloadChapters(ctx context.Context, req platform.Request) ([]string, error)
+}
+
+// Chapter глава видео, скачанная отдельным файлом
+type Chapter struct {
+	Title    string
+	Duration time.Duration
+	File     string
+}
+
+// Chapters скачивает видео и делит его на главы по разметке автора. Перед загрузкой
+// проверяются наличие глав и MAX_VIDEO_DURATION; лимит размера проверяет вызывающий
+// для каждой главы отдельно
+func (s *Service) Chapters(ctx context.Context, url string, opts Options) ([]Chapter, MediaInfo, error) {
+	url = s.resolver.Resolve(ctx, url)
+	platformName, downloader := s.getDownloader(url)
+	splitter, ok := downloader.(ChapterDownloader)
+	if !ok {
+		return nil, MediaInfo{Platform: s.platformTitle(platformName)}, ErrChaptersUnsupported
+	}
+
+	req := platform.Request{URL: url, Quality: opts.Quality, Language: opts.Language}
+	ctx = s.platformContext(ctx, platformName)
+
+	probeCtx, cancel := context.WithTimeout(ctx, estimateTimeout)
+	meta, err := s.ytdlp.Probe(probeCtx, url, probeArgs(req)...)
+	cancel()
+	if err != nil {
+		return nil, MediaInfo{Platform: s.platformTitle(platformName)}, fmt.Errorf("failed to fetch media info: %w", err)
+	}
+
+	info := MediaInfo{
+		Platform: s.platformTitle(platformName),
+		Title:    meta.Title,
+		Uploader: meta.Uploader,
+		Duration: meta.DurationValue(),
+	}
+	if len(meta.Chapters) < 2 {
+		return nil, info, ErrNoChapters
+	}
+	if s.maxDuration > 0 && info.Duration > s.maxDuration {
+		return nil, info, &DurationError{Duration: info.Duration, Limit: s.maxDuration}
+	}
+
+	outputDir, release := s.tempDirs.choose(req, meta.EstimatedSize())
+	defer release()
+	req.OutputDir = outputDir
+
+	files, err := splitter.DownloadChapters(ctx, req)
+	if err != nil {
+		return nil, info, err
+	}
+
+	chapters := make([]Chapter, len(files))
+	for i, file := range files {
+		chapters[i].File = s.tempDirs.spill(file)
+		// Файлы нумеруются по разметке из метаданных
+		if i < len(meta.Chapters) {
+			chapter := meta.Chapters[i]
+			chapters[i].Title = chapter.Title
+			chapters[i].Duration = time.Duration((chapter.EndTime - chapter.StartTime) * float64(time.Second))
+		}
+	}
+
+	s.logger.Info("Video split into chapters",
+		slog.String("url", url),
+		slog.String("platform", platformName),
+		slog.Int("chapters", len(chapters)),
+	)
+	return chapters, info, nil
+}
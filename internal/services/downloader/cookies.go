@@ -0,0 +1,147 @@
+package downloader
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+
+	"github.com/reelser-bot/internal/platform"
+	"github.com/reelser-bot/internal/secrets"
+	"github.com/reelser-bot/internal/services/egress"
+	"github.com/reelser-bot/internal/storage"
+)
+
+// cookieJar файлы cookies yt-dlp по платформам (--cookies). Зашифрованные файлы
+// расшифровываются в память, а на диск попадают только копии на время одного запуска
+// yt-dlp: в каталоге dir с правами 0700, каждая копия с правами 0600
+type cookieJar struct {
+	// fallback файл для платформ без собственного (YTDLP_COOKIES_FILE)
+	fallback platform.Cookies
+	files    map[string]platform.Cookies
+	// dir каталог копий расшифрованных cookies; пусто — зашифрованных файлов нет
+	dir string
+}
+
+// newCookieJar проверяет файлы cookies и расшифровывает зашифрованные связкой keys.
+// Файлы, которые не удалось прочитать, пропускаются с ошибкой в логе
+func newCookieJar(logger *slog.Logger, fallback string, files map[string]string, keys *secrets.Keyring) *cookieJar {
+	jar := &cookieJar{files: make(map[string]platform.Cookies, len(files))}
+
+	prepare := func(name, path string) platform.Cookies {
+		if path == "" {
+			return nil
+		}
+		cookies, err := jar.load(path, keys)
+		if err != nil {
+			logger.Error("Failed to load cookies file, downloading without it",
+				slog.String("platform", name),
+				slog.String("file", path),
+				slog.Any("error", err),
+			)
+			return nil
+		}
+		logger.Info("Cookies file enabled", slog.String("platform", name), slog.String("file", path))
+		return cookies
+	}
+
+	jar.fallback = prepare(egress.DefaultPlatform, fallback)
+	for name, path := range files {
+		if cookies := prepare(name, path); cookies != nil {
+			jar.files[name] = cookies
+		}
+	}
+	return jar
+}
+
+// cookies возвращает cookies платформы; nil — cookies не заданы
+func (j *cookieJar) cookies(platformName string) platform.Cookies {
+	if j == nil {
+		return nil
+	}
+	if cookies, ok := j.files[platformName]; ok {
+		return cookies
+	}
+	return j.fallback
+}
+
+// load возвращает cookies файла: сам файл, если он не зашифрован (yt-dlp обновляет
+// в нем cookies), или расшифрованные данные, которые копируются на каждый запуск
+func (j *cookieJar) load(path string, keys *secrets.Keyring) (platform.Cookies, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read cookies: %w", err)
+	}
+	if !secrets.IsSealed(data) {
+		return platform.CookiesFile(path), nil
+	}
+
+	plaintext, err := storage.LoadSecret(path, keys)
+	if err != nil {
+		return nil, err
+	}
+	if j.dir == "" {
+		dir, err := os.MkdirTemp("", "reelser-cookies-")
+		if err != nil {
+			return nil, fmt.Errorf("failed to create cookies directory: %w", err)
+		}
+		j.dir = dir
+	}
+	return &sealedCookies{dir: j.dir, name: filepath.Base(path), data: plaintext}, nil
+}
+
+// close удаляет каталог копий расшифрованных cookies
+func (j *cookieJar) close() error {
+	if j == nil || j.dir == "" {
+		return nil
+	}
+	return os.RemoveAll(j.dir)
+}
+
+// sealedCookies расшифрованные cookies в памяти. Каждый запуск yt-dlp получает свою
+// копию: yt-dlp перезаписывает файл при выходе, и общий файл параллельные процессы
+// портили бы друг другу. Изменения копий не сохраняются — исходный файл зашифрован
+type sealedCookies struct {
+	dir  string
+	name string
+	data []byte
+}
+
+// Acquire записывает копию cookies для одного запуска; release удаляет ее
+func (c *sealedCookies) Acquire() (string, func(), error) {
+	plain, err := os.CreateTemp(c.dir, c.name+"-*")
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to create decrypted cookies: %w", err)
+	}
+	path := plain.Name()
+	release := func() { os.Remove(path) }
+
+	if err := plain.Chmod(0o600); err != nil {
+		plain.Close()
+		release()
+		return "", nil, fmt.Errorf("failed to chmod decrypted cookies: %w", err)
+	}
+	if _, err := plain.Write(c.data); err != nil {
+		plain.Close()
+		release()
+		return "", nil, fmt.Errorf("failed to write decrypted cookies: %w", err)
+	}
+	if err := plain.Close(); err != nil {
+		release()
+		return "", nil, fmt.Errorf("failed to write decrypted cookies: %w", err)
+	}
+	return path, release, nil
+}
+
+// platformContext привязывает к контексту cookies платформы и ее первый маршрут
+// исходящего трафика — для запросов, которые не перебирают маршруты
+func (s *Service) platformContext(ctx context.Context, platformName string) context.Context {
+	if cookies := s.cookies.cookies(platformName); cookies != nil {
+		ctx = platform.WithCookies(ctx, cookies)
+	}
+	if routes := s.egress.Candidates(platformName); len(routes) > 0 {
+		ctx = platform.WithProxy(ctx, routes[0])
+	}
+	return ctx
+}
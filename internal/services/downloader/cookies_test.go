@@ -0,0 +1,95 @@
+package downloader
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/reelser-bot/internal/secrets"
+	"github.com/reelser-bot/internal/storage"
+)
+
+const testCookies = "# Netscape HTTP Cookie File\n.example.com\tTRUE\t/\tTRUE\t0\tsid\tsecret\n"
+
+func TestCookieJarSealedCopiesPerRun(t *testing.T) {
+	key, err := secrets.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	keys, err := secrets.NewKeyring(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(t.TempDir(), "cookies.txt")
+	if err := storage.SaveSecret(path, []byte(testCookies), keys); err != nil {
+		t.Fatal(err)
+	}
+
+	jar := newCookieJar(testLogger(), path, nil, keys)
+	cookies := jar.cookies("youtube")
+	if cookies == nil {
+		t.Fatal("sealed cookies were not loaded")
+	}
+
+	first, releaseFirst, err := cookies.Acquire()
+	if err != nil {
+		t.Fatalf("Acquire: %v", err)
+	}
+	second, releaseSecond, err := cookies.Acquire()
+	if err != nil {
+		t.Fatalf("Acquire: %v", err)
+	}
+	if first == second {
+		t.Fatal("concurrent runs share one decrypted file")
+	}
+
+	for _, file := range []string{first, second} {
+		info, err := os.Stat(file)
+		if err != nil {
+			t.Fatalf("decrypted copy: %v", err)
+		}
+		if perm := info.Mode().Perm(); perm != 0o600 {
+			t.Errorf("decrypted copy mode = %o, want 600", perm)
+		}
+		if data, _ := os.ReadFile(file); !bytes.Equal(data, []byte(testCookies)) {
+			t.Errorf("decrypted copy = %q", data)
+		}
+	}
+
+	releaseFirst()
+	if _, err := os.Stat(first); !os.IsNotExist(err) {
+		t.Errorf("copy after release: %v, want removed", err)
+	}
+
+	if err := jar.close(); err != nil {
+		t.Fatalf("close: %v", err)
+	}
+	releaseSecond()
+	if _, err := os.Stat(jar.dir); !os.IsNotExist(err) {
+		t.Errorf("cookies directory after close: %v, want removed", err)
+	}
+}
+
+func TestCookieJarPlainFileIsUsedInPlace(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cookies.txt")
+	if err := os.WriteFile(path, []byte(testCookies), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	jar := newCookieJar(testLogger(), "", map[string]string{"instagram": path}, nil)
+	if jar.cookies("youtube") != nil {
+		t.Error("platform without cookies got a file")
+	}
+	file, release, err := jar.cookies("instagram").Acquire()
+	if err != nil {
+		t.Fatalf("Acquire: %v", err)
+	}
+	release()
+	if file != path {
+		t.Errorf("Acquire = %q, want the original file %q", file, path)
+	}
+	if jar.dir != "" {
+		t.Errorf("plain cookies created a decrypted copies directory %q", jar.dir)
+	}
+}
@@ -0,0 +1,109 @@
+package downloader
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net"
+	"strings"
+
+	"github.com/reelser-bot/internal/platform"
+	"github.com/reelser-bot/internal/platform/ytdlp"
+)
+
+// egressErrorMarkers фрагменты вывода yt-dlp, указывающие на недоступность маршрута,
+// а не на проблему с конкретным видео
+var egressErrorMarkers = []string{
+	"unable to connect to proxy",
+	"proxyerror",
+	"tunnel connection failed",
+	"connection refused",
+	"connection reset",
+	"network is unreachable",
+	"timed out",
+	"temporary failure in name resolution",
+}
+
+// downloadVia скачивает видео через маршруты платформы: при сетевой ошибке маршрут
+// помечается неисправным и загрузка повторяется через следующий
+func (s *Service) downloadVia(
+	ctx context.Context, platformName string, downloader VideoDownloader, req platform.Request, estimate int64,
+) (string, error) {
+	if cookies := s.cookies.cookies(platformName); cookies != nil {
+		ctx = platform.WithCookies(ctx, cookies)
+	}
+
+	routes := s.egress.Candidates(platformName)
+	if len(routes) == 0 {
+		return s.downloadOnce(ctx, downloader, req, estimate)
+	}
+
+	var err error
+	for i, proxy := range routes {
+		var filePath string
+		filePath, err = s.downloadOnce(platform.WithProxy(ctx, proxy), downloader, req, estimate)
+		if err == nil {
+			s.egress.MarkHealthy(platformName, proxy)
+			return filePath, nil
+		}
+
+		if ctx.Err() != nil || !isEgressError(err) {
+			return "", err
+		}
+
+		s.egress.MarkFailed(platformName, proxy, err)
+		if i < len(routes)-1 {
+			s.logger.Warn("Egress route failed, switching to the next one",
+				slog.String("platform", platformName),
+				slog.String("url", req.URL),
+				slog.Any("error", err),
+			)
+		}
+	}
+	return "", err
+}
+
+// downloadOnce выбирает временный каталог по оценке размера estimate и скачивает видео
+func (s *Service) downloadOnce(ctx context.Context, downloader VideoDownloader, req platform.Request, estimate int64) (string, error) {
+	// Выбираем каталог: небольшие файлы — в быстрый tmpfs, крупные — на диск
+	outputDir, release := s.tempDirs.choose(req, estimate)
+	defer release()
+
+	req.OutputDir = outputDir
+	return downloader.Download(ctx, req)
+}
+
+// RunEgressChecks периодически проверяет маршруты исходящего трафика до отмены ctx
+func (s *Service) RunEgressChecks(ctx context.Context) {
+	targets := make(map[string]string, len(s.platforms))
+	for _, p := range s.platforms {
+		if len(p.Hosts) == 0 {
+			continue
+		}
+		host, _, _ := strings.Cut(p.Hosts[0], "/...")
+		targets[p.Name] = "https://" + host
+	}
+
+	s.egress.Run(ctx, s.egressInterval, targets)
+}
+
+// isEgressError проверяет, вызвана ли ошибка недоступностью сети или прокси
+func isEgressError(err error) bool {
+	var netErr net.Error
+	if errors.As(err, &netErr) {
+		return true
+	}
+
+	text := strings.ToLower(err.Error())
+	var ytErr *ytdlp.Error
+	if errors.As(err, &ytErr) {
+		text = strings.ToLower(ytErr.Output)
+	}
+
+	for _, marker := range egressErrorMarkers {
+		if strings.Contains(text, marker) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,133 @@
+package downloader
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/reelser-bot/internal/platform"
+	"github.com/reelser-bot/internal/platform/ytdlp"
+)
+
+// MediaInfo сведения о медиа, известные к моменту ошибки; пустые поля — неизвестны
+type MediaInfo struct {
+	// Platform название платформы для пользователей
+	Platform string
+	// Title название видео
+	Title string
+	// Size известный или примерный размер файла в байтах
+	Size int64
+	// Uploader автор видео
+	Uploader string
+	// Duration длительность
+	Duration time.Duration
+	// Width и Height разрешение видео
+	Width  int
+	Height int
+}
+
+// MetadataDownloader загрузчик платформы, получающий метаданные своим способом
+// (например, через API), а не через yt-dlp -J
+type MetadataDownloader interface {
+	Metadata(ctx context.Context, req platform.Request) (platform.Metadata, error)
+}
+
+// Error ошибка загрузки со сведениями о медиа для сообщений пользователю
+type Error struct {
+	Info MediaInfo
+	Err  error
+}
+
+func (e *Error) Error() string {
+	return e.Err.Error()
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// MediaInfoFromError извлекает сведения о медиа из ошибки Download
+func MediaInfoFromError(err error) MediaInfo {
+	var downloadErr *Error
+	if errors.As(err, &downloadErr) {
+		return downloadErr.Info
+	}
+	return MediaInfo{}
+}
+
+// Info получает сведения о медиа без загрузки для /info и возвращает ошибку,
+// если платформа не поддерживается или метаданные получить не удалось
+func (s *Service) Info(ctx context.Context, url string, opts Options) (MediaInfo, error) {
+	url = s.resolver.Resolve(ctx, url)
+	name, downloader := s.getDownloader(url)
+	if downloader == nil {
+		return MediaInfo{}, fmt.Errorf("unsupported platform or invalid URL: %s", url)
+	}
+
+	ctx = s.platformContext(ctx, name)
+
+	meta, err := s.metadata(ctx, downloader, platform.Request{URL: url, Quality: opts.Quality, Language: opts.Language})
+	if err != nil {
+		return MediaInfo{Platform: s.platformTitle(name)}, fmt.Errorf("failed to fetch media info: %w", err)
+	}
+	return mediaInfo(s.platformTitle(name), meta), nil
+}
+
+// metadata получает метаданные через API платформы, если загрузчик его поддерживает,
+// иначе через yt-dlp -J
+func (s *Service) metadata(ctx context.Context, downloader VideoDownloader, req platform.Request) (platform.Metadata, error) {
+	if provider, ok := downloader.(MetadataDownloader); ok {
+		return provider.Metadata(ctx, req)
+	}
+
+	meta, err := s.ytdlp.Probe(ctx, req.URL, probeArgs(req)...)
+	if err != nil {
+		return platform.Metadata{}, err
+	}
+	return platform.Metadata{
+		Title:    meta.Title,
+		Uploader: meta.Uploader,
+		Duration: meta.DurationValue(),
+		Width:    meta.Width,
+		Height:   meta.Height,
+		Size:     meta.EstimatedSize(),
+		Live:     meta.IsLive,
+		Still:    meta.IsImage(),
+	}, nil
+}
+
+// probeArgs возвращает аргументы yt-dlp -J для запроса. Одинаковые аргументы у /info,
+// оценки размера и загрузки позволяют переиспользовать закэшированные метаданные
+func probeArgs(req platform.Request) []string {
+	return append(ytdlp.FormatArgs("", req.Quality, false), ytdlp.LanguageArgs(req.Language)...)
+}
+
+// mediaInfo переводит метаданные платформы в сведения для сообщений пользователю
+func mediaInfo(platformTitle string, meta platform.Metadata) MediaInfo {
+	return MediaInfo{
+		Platform: platformTitle,
+		Title:    meta.Title,
+		Size:     meta.Size,
+		Uploader: meta.Uploader,
+		Duration: meta.Duration,
+		Width:    meta.Width,
+		Height:   meta.Height,
+	}
+}
+
+// platformTitle возвращает название платформы для пользователей, пусто — платформа не найдена
+func (s *Service) platformTitle(name string) string {
+	for _, p := range s.platforms {
+		if p.Name == name {
+			return p.Title
+		}
+	}
+	return ""
+}
+
+// PlatformTitle возвращает название платформы для URL, пусто — платформа не поддерживается
+func (s *Service) PlatformTitle(url string) string {
+	name, _ := s.getDownloader(url)
+	return s.platformTitle(name)
+}
@@ -0,0 +1,48 @@
+package downloader
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+
+	"github.com/reelser-bot/internal/platform"
+)
+
+// PlaylistDownloader загрузчик платформы, умеющий разворачивать плейлисты
+type PlaylistDownloader interface {
+	IsPlaylist(url string) bool
+	Playlist(ctx context.Context, req platform.Request, limit int) (platform.Playlist, error)
+}
+
+// Playlist разворачивает ссылку на плейлист в первые MAX_PLAYLIST_ITEMS видео.
+// Второе значение false — ссылка не на плейлист (или плейлисты отключены),
+// и ее нужно скачивать как одно видео
+func (s *Service) Playlist(ctx context.Context, url string, opts Options) (platform.Playlist, bool, error) {
+	if s.maxPlaylistItems <= 0 {
+		return platform.Playlist{}, false, nil
+	}
+
+	resolved := s.resolver.Resolve(ctx, url)
+	platformName, downloader := s.getDownloader(resolved)
+	expander, ok := downloader.(PlaylistDownloader)
+	if !ok || !expander.IsPlaylist(resolved) {
+		return platform.Playlist{}, false, nil
+	}
+
+	ctx = s.platformContext(ctx, platformName)
+
+	playlist, err := expander.Playlist(ctx, platform.Request{URL: resolved, Language: opts.Language}, s.maxPlaylistItems)
+	if err != nil {
+		return platform.Playlist{}, true, err
+	}
+	if len(playlist.Items) == 0 {
+		return platform.Playlist{}, true, fmt.Errorf("playlist is empty")
+	}
+
+	s.logger.Info("Playlist expanded",
+		slog.String("url", resolved),
+		slog.String("platform", platformName),
+		slog.Int("items", len(playlist.Items)),
+	)
+	return playlist, true, nil
+}
@@ -0,0 +1,78 @@
+package downloader
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/reelser-bot/internal/platform"
+)
+
+// estimateTimeout ограничивает время получения метаданных перед загрузкой
+const estimateTimeout = 20 * time.Second
+
+// estimateMargin во сколько раз оценка размера должна превышать лимит, чтобы отклонить
+// видео до загрузки: filesize_approx бывает неточным, а отказ от подходящего видео хуже
+const estimateMargin = 1.25
+
+// SizeError возвращается, если по метаданным видео явно больше лимита чата
+type SizeError struct {
+	// Size оценка размера по метаданным платформы
+	Size  int64
+	Limit int64
+}
+
+func (e *SizeError) Error() string {
+	return fmt.Sprintf("estimated size %d exceeds limit %d", e.Size, e.Limit)
+}
+
+// preflight отклоняет видео до начала загрузки: трансляции и видео без известной
+// длительности, длиннее maxDuration или явно больше maxSize по оценке платформы
+// (yt-dlp -J, TikWM). Для фрагмента проверяется только его длительность. Если
+// метаданные получить не удалось, загрузка не блокируется: ее все равно ограничивают
+// таймаут и проверка размера файла.
+// Возвращает сведения о медиа: по ним выбирается временный каталог и составляются
+// сообщения, чтобы не запрашивать метаданные повторно
+func (s *Service) preflight(
+	ctx context.Context, platformName string, downloader VideoDownloader, req platform.Request,
+	maxSize int64, maxDuration time.Duration,
+) (MediaInfo, error) {
+	info := MediaInfo{Platform: s.platformTitle(platformName)}
+	if req.Section != nil {
+		if d := req.Section.Duration(); maxDuration > 0 && d > maxDuration {
+			return info, &DurationError{Duration: d, Limit: maxDuration}
+		}
+	}
+
+	// Оценка размера дается для видео, а не для звуковой дорожки
+	if req.AudioOnly {
+		maxSize = 0
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, estimateTimeout)
+	defer cancel()
+	ctx = s.platformContext(ctx, platformName)
+
+	meta, err := s.metadata(ctx, downloader, req)
+	if err != nil {
+		s.logger.Debug("Failed to fetch metadata before download", slog.String("url", req.URL), slog.Any("error", err))
+		return info, nil
+	}
+	info = mediaInfo(info.Platform, meta)
+
+	var limitErr error
+	switch {
+	case req.Section != nil:
+		return info, nil
+	case meta.Live || (meta.Duration <= 0 && !meta.Still):
+		limitErr = ErrUnknownDuration
+	case maxDuration > 0 && meta.Duration > maxDuration:
+		limitErr = &DurationError{Duration: meta.Duration, Limit: maxDuration}
+	case maxSize > 0 && float64(meta.Size) > float64(maxSize)*estimateMargin:
+		limitErr = &SizeError{Size: meta.Size, Limit: maxSize}
+	default:
+		return info, nil
+	}
+	return info, &Error{Info: info, Err: limitErr}
+}
@@ -0,0 +1,70 @@
+package downloader
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/reelser-bot/internal/config"
+	"github.com/reelser-bot/internal/platform"
+	"github.com/reelser-bot/internal/platform/ytdlp"
+)
+
+// preflightService возвращает сервис, которому yt-dlp -J отвечает метаданными metadata
+func preflightService(t *testing.T, metadata string) *Service {
+	t.Helper()
+	runner := &ytdlp.FakeRunner{Handler: func(ytdlp.Command) ([]byte, []byte, error) {
+		return []byte(metadata), nil, nil
+	}}
+	return NewService(testLogger(), config.DownloadConfig{
+		TempDir:          t.TempDir(),
+		MaxVideoDuration: time.Hour,
+	}, WithRunner(runner))
+}
+
+func TestPreflight(t *testing.T) {
+	tests := []struct {
+		name        string
+		metadata    string
+		maxSize     int64
+		maxDuration time.Duration
+		want        error
+	}{
+		{"known duration", `{"id":"x","duration":60,"ext":"mp4","filesize":1024}`, 0, 0, nil},
+		{"live", `{"id":"x","duration":60,"is_live":true,"ext":"mp4"}`, 0, 0, ErrUnknownDuration},
+		{"unknown duration", `{"id":"x","ext":"mp4"}`, 0, 0, ErrUnknownDuration},
+		{"image", `{"id":"x","ext":"jpg"}`, 0, 0, nil},
+		{"too long", `{"id":"x","duration":7200,"ext":"mp4"}`, 0, 0, &DurationError{}},
+		{"too long for a stricter limit", `{"id":"x","duration":120,"ext":"mp4"}`, 0, time.Minute, &DurationError{}},
+		{"too big", `{"id":"x","duration":60,"ext":"mp4","filesize":104857600}`, 10 * mb, 0, &SizeError{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := preflightService(t, tt.metadata)
+			const url = "https://www.youtube.com/watch?v=x"
+			name, downloader := s.getDownloader(url)
+
+			opts := Options{MaxDuration: tt.maxDuration}
+			_, err := s.preflight(context.Background(), name, downloader, platform.Request{URL: url}, tt.maxSize, s.durationLimit(opts))
+			switch want := tt.want.(type) {
+			case nil:
+				if err != nil {
+					t.Errorf("preflight = %v, want nil", err)
+				}
+			case *DurationError:
+				if !errors.As(err, &want) {
+					t.Errorf("preflight = %v, want DurationError", err)
+				}
+			case *SizeError:
+				if !errors.As(err, &want) {
+					t.Errorf("preflight = %v, want SizeError", err)
+				}
+			default:
+				if !errors.Is(err, want) {
+					t.Errorf("preflight = %v, want %v", err, want)
+				}
+			}
+		})
+	}
+}
@@ -2,97 +2,489 @@ package downloader
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"io/fs"
 	"log/slog"
-	"os"
-	"path/filepath"
 	"strings"
+	"time"
 
+	"github.com/reelser-bot/internal/clock"
+	"github.com/reelser-bot/internal/config"
+	"github.com/reelser-bot/internal/fsys"
+	"github.com/reelser-bot/internal/platform"
+	"github.com/reelser-bot/internal/platform/bilibili"
+	"github.com/reelser-bot/internal/platform/facebook"
+	"github.com/reelser-bot/internal/platform/generic"
 	"github.com/reelser-bot/internal/platform/instagram"
+	"github.com/reelser-bot/internal/platform/kick"
+	"github.com/reelser-bot/internal/platform/likee"
+	"github.com/reelser-bot/internal/platform/okru"
+	"github.com/reelser-bot/internal/platform/reddit"
+	"github.com/reelser-bot/internal/platform/rutube"
 	"github.com/reelser-bot/internal/platform/tiktok"
+	"github.com/reelser-bot/internal/platform/twitch"
+	"github.com/reelser-bot/internal/platform/twitter"
+	"github.com/reelser-bot/internal/platform/vimeo"
 	"github.com/reelser-bot/internal/platform/yt"
+	"github.com/reelser-bot/internal/platform/ytdlp"
+	"github.com/reelser-bot/internal/secrets"
+	"github.com/reelser-bot/internal/services/egress"
+	"github.com/reelser-bot/internal/services/resolver"
+)
+
+// ErrLoginRequired возвращается, когда платформа временно требует авторизацию
+var ErrLoginRequired = instagram.ErrLoginRequired
+
+// ErrSignInRequired возвращается, когда YouTube требует подтверждение входа
+var ErrSignInRequired = yt.ErrSignInRequired
+
+// ErrPasswordRequired возвращается, когда видео защищено паролем
+var ErrPasswordRequired = vimeo.ErrPasswordRequired
+
+// ErrContentNotAllowed возвращается, если результат с другого сайта не входит
+// в разрешенные типы (GENERIC_ALLOWED_EXTENSIONS)
+var ErrContentNotAllowed = generic.ErrNotAllowed
+
+// ErrPrivateAddress возвращается, если ссылка ведет на внутренний адрес
+var ErrPrivateAddress = platform.ErrPrivateAddress
+
+// ErrUnknownDuration возвращается для прямой трансляции или видео без известной длительности
+var ErrUnknownDuration = ytdlp.ErrUnknownDuration
+
+// DurationError возвращается, если видео длиннее допустимого для платформы
+type DurationError = ytdlp.DurationError
+
+// Options содержит дополнительные параметры запроса на загрузку
+type Options struct {
+	// Password пароль для защищенных видео
+	Password string
+	// Quality вариант качества (QualityHD, QualitySD), пусто — по настройке VIDEO_QUALITY
+	Quality string
+	// AudioOnly скачать только звуковую дорожку
+	AudioOnly bool
+	// Language язык пользователя для локализованных метаданных и субтитров
+	Language string
+	// Section скачать только фрагмент видео; nil — ролик целиком
+	Section *platform.Section
+	// MaxSize лимит размера файла: видео, которое по оценке платформы явно больше,
+	// отклоняется до загрузки с SizeError (0 — не проверять)
+	MaxSize int64
+	// MaxDuration лимит длительности строже MAX_VIDEO_DURATION (например, для GIF):
+	// более длинное видео отклоняется до загрузки с DurationError (0 — только общий лимит)
+	MaxDuration time.Duration
+}
+
+// Варианты качества загрузки
+const (
+	QualityHD = platform.QualityHD
+	QualitySD = platform.QualitySD
 )
 
 // VideoDownloader интерфейс для загрузки видео
 type VideoDownloader interface {
-	Download(ctx context.Context, url string) (string, error) // путь к файлу
+	Download(ctx context.Context, req platform.Request) (string, error) // путь к файлу
+}
+
+// PlatformInfo описывает подключенную платформу для справки и описаний
+type PlatformInfo struct {
+	// Name внутренний идентификатор (используется в ENABLED_PLATFORMS)
+	Name string
+	// Title название для пользователей
+	Title string
+	// Note уточнение к названию (например, какие виды видео поддерживаются)
+	Note string
+	// Hosts домены и формы ссылок, которые принимает платформа
+	Hosts []string
+}
+
+// platformEntry описывает зарегистрированную платформу
+type platformEntry struct {
+	PlatformInfo
+	match      func(url string) bool
+	downloader VideoDownloader
+	// trimAfter фрагмент вырезается после загрузки (загрузчик не умеет скачивать его сам)
+	trimAfter bool
 }
 
 // Service управляет загрузкой видео с разных платформ
 type Service struct {
-	logger           *slog.Logger
-	tempDir          string
-	ytDownloader     *yt.Downloader
-	tiktokDownloader *tiktok.Downloader
-	igDownloader     *instagram.Downloader
+	logger    *slog.Logger
+	tempDirs  *tempDirs
+	platforms []platformEntry
+	resolver  *resolver.Resolver
+	// ytdlp получает метаданные для сообщений об ошибках
+	ytdlp *ytdlp.Client
+	// runner запускает ffmpeg для обрезки фрагментов
+	runner ytdlp.CommandRunner
+
+	latency    *latencyTracker
+	timeout    time.Duration
+	minTimeout time.Duration
+	maxTimeout time.Duration
+
+	fs    fsys.FS
+	clock clock.Clock
+
+	// cookies файлы cookies yt-dlp по платформам
+	cookies *cookieJar
+
+	// egress выбирает маршрут (прокси) исходящего трафика по платформе
+	egress         *egress.Selector
+	egressInterval time.Duration
+
+	// maxPlaylistItems сколько видео плейлиста скачивать (0 — плейлисты отключены)
+	maxPlaylistItems int
+
+	// maxDuration максимальная длительность видео (0 — без ограничения)
+	maxDuration time.Duration
+}
+
+// serviceDeps внешние зависимости сервиса, заменяемые через Option
+type serviceDeps struct {
+	runner ytdlp.CommandRunner
+	fs     fsys.FS
+	clock  clock.Clock
+	// keys расшифровывает зашифрованные файлы cookies; nil — шифрование выключено
+	keys *secrets.Keyring
+}
+
+// Option настраивает зависимости сервиса загрузки
+type Option func(*serviceDeps)
+
+// WithRunner задает запуск yt-dlp (например, ytdlp.FakeRunner вместо реального процесса)
+func WithRunner(runner ytdlp.CommandRunner) Option {
+	return func(d *serviceDeps) { d.runner = runner }
+}
+
+// WithFS задает файловую систему временных каталогов (например, fsys.MemFS)
+func WithFS(fs fsys.FS) Option {
+	return func(d *serviceDeps) { d.fs = fs }
+}
+
+// WithClock задает часы для замеров длительности загрузок
+func WithClock(c clock.Clock) Option {
+	return func(d *serviceDeps) { d.clock = c }
+}
+
+// WithKeyring задает связку ключей для зашифрованных файлов cookies
+func WithKeyring(keys *secrets.Keyring) Option {
+	return func(d *serviceDeps) { d.keys = keys }
 }
 
 // NewService создает новый сервис загрузки видео
-func NewService(
-	logger *slog.Logger,
-	tempDir string,
-	videoQuality string,
-) *Service {
+func NewService(logger *slog.Logger, cfg config.DownloadConfig, opts ...Option) *Service {
+	deps := serviceDeps{
+		runner: ytdlp.ExecRunner{},
+		fs:     fsys.OS{},
+		clock:  clock.Real{},
+	}
+	for _, opt := range opts {
+		opt(&deps)
+	}
+	runner := deps.runner
+
+	ytExtractor := yt.ExtractorOptions{
+		PlayerClient: cfg.YouTubePlayerClient,
+		POToken:      cfg.YouTubePOToken,
+	}
+
+	ytdlpOpts := []ytdlp.ClientOption{ytdlp.WithBinary(cfg.YtDlpPath)}
+	if cfg.Subtitles {
+		ytdlpOpts = append(ytdlpOpts, ytdlp.WithSubtitles(cfg.SubtitleLanguage))
+	}
+	ytdlpOpts = append(ytdlpOpts, ytdlp.WithMetadataCache(cfg.MetadataCacheSize, cfg.MetadataCacheTTL, deps.clock))
+	ytdlpClient := ytdlp.NewClient(logger, runner, ytdlpOpts...)
+
+	platforms := enabledPlatforms(logger, cfg, platformRegistry(logger, cfg, ytdlpClient, ytExtractor), ytdlpClient)
+
+	routes, err := egress.New(logger, cfg.EgressRoutes, cfg.EgressCheckTimeout)
+	if err != nil {
+		logger.Error("Invalid PLATFORM_PROXIES, downloading without egress routes", slog.Any("error", err))
+	}
+
 	return &Service{
-		logger:           logger,
-		tempDir:          tempDir,
-		ytDownloader:     yt.NewDownloader(logger, tempDir, videoQuality),
-		tiktokDownloader: tiktok.NewDownloader(logger, tempDir),
-		igDownloader:     instagram.NewDownloader(logger, tempDir, videoQuality),
+		logger:    logger,
+		tempDirs:  newTempDirs(logger, deps.fs, cfg.TempDir, cfg.FastTempDir, cfg.FastTempMaxFileMB, cfg.FastTempMaxTotalMB),
+		platforms: platforms,
+		resolver:  resolver.New(logger),
+		ytdlp:     ytdlpClient,
+		runner:    runner,
+
+		latency:    newLatencyTracker(),
+		timeout:    cfg.Timeout,
+		minTimeout: cfg.MinTimeout,
+		maxTimeout: cfg.MaxTimeout,
+
+		fs:    deps.fs,
+		clock: deps.clock,
+
+		cookies: newCookieJar(logger, cfg.CookiesFile, cfg.PlatformCookies, deps.keys),
+
+		egress:         routes,
+		egressInterval: cfg.EgressCheckInterval,
+
+		maxPlaylistItems: cfg.MaxPlaylistItems,
+		maxDuration:      cfg.MaxVideoDuration,
 	}
 }
 
-// Download определяет платформу по URL и скачивает видео
-func (s *Service) Download(ctx context.Context, url string) (string, error) {
+// platformRegistry возвращает все встроенные платформы.
+// Порядок важен: URL проверяется платформами по очереди
+func platformRegistry(
+	logger *slog.Logger,
+	cfg config.DownloadConfig,
+	ytdlpClient *ytdlp.Client,
+	ytExtractor yt.ExtractorOptions,
+) []platformEntry {
+	return []platformEntry{
+		{
+			PlatformInfo: PlatformInfo{Name: "youtube", Title: "YouTube", Hosts: []string{"youtube.com", "youtu.be"}},
+			match:        yt.IsValidURL,
+			downloader:   yt.NewDownloader(logger, ytdlpClient, cfg.VideoQuality, ytExtractor),
+		},
+		{
+			PlatformInfo: PlatformInfo{Name: "tiktok", Title: "TikTok", Note: "видео и фото-слайдшоу", Hosts: []string{"tiktok.com"}},
+			match:        tiktok.IsValidURL,
+			downloader:   tiktok.NewDownloader(logger, tiktokProviders(cfg.TikTokProviders)),
+			trimAfter:    true,
+		},
+		{
+			PlatformInfo: PlatformInfo{Name: "instagram", Title: "Instagram", Note: "Reels и обычные видео", Hosts: []string{"instagram.com"}},
+			match:        instagram.IsValidURL,
+			downloader:   instagram.NewDownloader(logger, ytdlpClient, cfg.VideoQuality),
+		},
+		{
+			PlatformInfo: PlatformInfo{Name: "reddit", Title: "Reddit", Hosts: []string{"reddit.com", "v.redd.it"}},
+			match:        reddit.IsValidURL,
+			downloader:   reddit.NewDownloader(logger, ytdlpClient, cfg.VideoQuality),
+		},
+		{
+			PlatformInfo: PlatformInfo{Name: "facebook", Title: "Facebook", Note: "видео и Reels", Hosts: []string{"facebook.com", "fb.watch"}},
+			match:        facebook.IsValidURL,
+			downloader:   facebook.NewDownloader(logger, ytdlpClient, cfg.VideoQuality),
+		},
+		{
+			PlatformInfo: PlatformInfo{
+				Name:  "x",
+				Title: "X / Twitter",
+				Hosts: []string{"x.com", "twitter.com", "fxtwitter.com", "vxtwitter.com", "t.co"},
+			},
+			match:      twitter.IsValidURL,
+			downloader: twitter.NewDownloader(logger, ytdlpClient, cfg.VideoQuality),
+		},
+		{
+			PlatformInfo: PlatformInfo{Name: "vimeo", Title: "Vimeo", Hosts: []string{"vimeo.com"}},
+			match:        vimeo.IsValidURL,
+			downloader:   vimeo.NewDownloader(logger, ytdlpClient, cfg.VideoQuality),
+		},
+		{
+			PlatformInfo: PlatformInfo{Name: "rutube", Title: "Rutube", Hosts: []string{"rutube.ru"}},
+			match:        rutube.IsValidURL,
+			downloader:   rutube.NewDownloader(logger, ytdlpClient, cfg.VideoQuality),
+		},
+		{
+			PlatformInfo: PlatformInfo{Name: "okru", Title: "Одноклассники", Note: "OK.ru", Hosts: []string{"ok.ru/video", "ok.ru/okvideo"}},
+			match:        okru.IsValidURL,
+			downloader:   okru.NewDownloader(logger, ytdlpClient, cfg.VideoQuality),
+		},
+		{
+			PlatformInfo: PlatformInfo{Name: "bilibili", Title: "Bilibili", Hosts: []string{"bilibili.com", "b23.tv"}},
+			match:        bilibili.IsValidURL,
+			downloader:   bilibili.NewDownloader(logger, ytdlpClient, cfg.VideoQuality),
+		},
+		{
+			PlatformInfo: PlatformInfo{Name: "likee", Title: "Likee", Hosts: []string{"likee.video", "l.likee.video"}},
+			match:        likee.IsValidURL,
+			downloader:   likee.NewDownloader(logger, ytdlpClient, cfg.VideoQuality),
+		},
+		{
+			PlatformInfo: PlatformInfo{
+				Name: "twitch", Title: "Twitch", Note: "клипы и записи",
+				Hosts: []string{"clips.twitch.tv", "twitch.tv/.../clip/...", "twitch.tv/videos/..."},
+			},
+			match:      twitch.IsValidURL,
+			downloader: twitch.NewDownloader(logger, ytdlpClient, cfg.VideoQuality, cfg.TwitchMaxDuration),
+		},
+		{
+			PlatformInfo: PlatformInfo{
+				Name: "kick", Title: "Kick", Note: "клипы и записи",
+				Hosts: []string{"kick.com/.../clips/...", "kick.com/video/..."},
+			},
+			match:      kick.IsValidURL,
+			downloader: kick.NewDownloader(logger, ytdlpClient, cfg.VideoQuality, cfg.KickMaxDuration),
+		},
+	}
+}
+
+// enabledPlatforms оставляет платформы, включенные в конфигурации (пустой список — все),
+// и добавляет общий загрузчик, если он включен
+func enabledPlatforms(logger *slog.Logger, cfg config.DownloadConfig, registry []platformEntry, ytdlpClient *ytdlp.Client) []platformEntry {
+	enabled := make(map[string]bool, len(cfg.EnabledPlatforms))
+	for _, name := range cfg.EnabledPlatforms {
+		enabled[strings.ToLower(name)] = true
+	}
+	all := len(enabled) == 0
+	var platforms []platformEntry
+	for _, p := range registry {
+		if all || enabled[p.Name] {
+			platforms = append(platforms, p)
+		}
+		delete(enabled, p.Name)
+	}
+	for name := range enabled {
+		logger.Warn("Unknown platform in ENABLED_PLATFORMS", slog.String("platform", name))
+	}
+
+	// Общий загрузчик проверяется последним и принимает ссылки остальных сайтов
+	if cfg.GenericFallback {
+		platforms = append(platforms, platformEntry{
+			PlatformInfo: PlatformInfo{
+				Name:  "generic",
+				Title: "Другие сайты",
+				Note:  "через yt-dlp, только " + strings.Join(cfg.GenericExtensions, ", "),
+			},
+			match:      generic.IsValidURL,
+			downloader: generic.NewDownloader(logger, ytdlpClient, cfg.VideoQuality, cfg.GenericExtensions, cfg.GenericMaxDuration),
+		})
+	}
+	return platforms
+}
+
+// tiktokProviders переводит провайдеров TikTok API из конфигурации
+func tiktokProviders(cfg []config.TikTokProvider) []tiktok.Provider {
+	providers := make([]tiktok.Provider, 0, len(cfg))
+	for _, p := range cfg {
+		providers = append(providers, tiktok.Provider{Endpoint: p.Endpoint, APIKey: p.APIKey})
+	}
+	return providers
+}
+
+// durationLimit возвращает лимит длительности загрузки: MAX_VIDEO_DURATION или более
+// строгий лимит из opts
+func (s *Service) durationLimit(opts Options) time.Duration {
+	if opts.MaxDuration > 0 && (s.maxDuration <= 0 || opts.MaxDuration < s.maxDuration) {
+		return opts.MaxDuration
+	}
+	return s.maxDuration
+}
+
+// Download определяет платформу по URL и скачивает видео. Возвращает сведения о медиа
+// из метаданных, полученных перед загрузкой: для подписи аудио и сообщений пользователю
+func (s *Service) Download(ctx context.Context, url string, opts Options) (string, MediaInfo, error) {
 	s.logger.Info("Processing download request", slog.String("url", url))
 
+	// Раскрываем короткие ссылки и приводим зеркала к каноническим доменам
+	if resolved := s.resolver.Resolve(ctx, url); resolved != url {
+		s.logger.Info("URL resolved", slog.String("url", url), slog.String("resolved", resolved))
+		url = resolved
+	}
+
 	// Определяем платформу
-	platform, downloader := s.getDownloader(url)
+	platformName, downloader := s.getDownloader(url)
 	if downloader == nil {
-		return "", fmt.Errorf("unsupported platform or invalid URL: %s", url)
+		return "", MediaInfo{}, fmt.Errorf("unsupported platform or invalid URL: %s", url)
 	}
 
-	s.logger.Info("Platform detected", slog.String("platform", platform))
+	s.logger.Info("Platform detected", slog.String("platform", platformName))
+
+	req := platform.Request{
+		URL:       url,
+		Password:  opts.Password,
+		Quality:   opts.Quality,
+		AudioOnly: opts.AudioOnly,
+		Language:  opts.Language,
+		Section:   opts.Section,
+	}
 
-	// Скачиваем видео
-	filePath, err := downloader.Download(ctx, url)
+	// Слишком длинные и большие видео отклоняем сразу, а не после загрузки или по таймауту
+	info, err := s.preflight(ctx, platformName, downloader, req, opts.MaxSize, s.durationLimit(opts))
 	if err != nil {
+		s.logger.Warn("Video rejected before download",
+			slog.String("url", url),
+			slog.String("platform", platformName),
+			slog.Any("error", err),
+		)
+		return "", info, err
+	}
+
+	// Скачиваем видео, при сетевых ошибках переключаясь на следующий маршрут
+	startedAt := s.clock.Now()
+	filePath, err := s.downloadVia(ctx, platformName, downloader, req, info.Size)
+	if err != nil {
+		// Неудачные попытки тоже учитываются, иначе таймауты не поднимали бы p95.
+		// Отмененная загрузка ничего не говорит о скорости платформы
+		if !errors.Is(ctx.Err(), context.Canceled) {
+			s.latency.record(platformName, s.clock.Since(startedAt))
+		}
 		s.logger.Error("Failed to download video",
 			slog.String("url", url),
-			slog.String("platform", platform),
+			slog.String("platform", platformName),
 			slog.Any("error", err),
 		)
-		return "", fmt.Errorf("failed to download video: %w", err)
+		return "", info, &Error{Info: info, Err: fmt.Errorf("failed to download video: %w", err)}
 	}
 
 	// Проверяем существование файла
-	if _, err := os.Stat(filePath); os.IsNotExist(err) {
-		return "", fmt.Errorf("downloaded file does not exist: %s", filePath)
+	if _, err := s.fs.Stat(filePath); errors.Is(err, fs.ErrNotExist) {
+		return "", info, fmt.Errorf("downloaded file does not exist: %s", filePath)
+	}
+
+	// Фото-слайдшоу не обрезаются: у них нет временной шкалы
+	if opts.Section != nil && s.trimAfterDownload(platformName) && !platform.IsSlideshow(filePath) {
+		trimmed, err := s.trim(ctx, filePath, *opts.Section)
+		if err != nil {
+			s.Cleanup(filePath)
+			return "", info, fmt.Errorf("failed to trim video: %w", err)
+		}
+		filePath = trimmed
 	}
 
+	elapsed := s.clock.Since(startedAt)
+	s.latency.record(platformName, elapsed)
+
+	// Если оценка размера ошиблась, освобождаем быстрый каталог
+	filePath = s.tempDirs.spill(filePath)
+
 	s.logger.Info("Video downloaded successfully",
 		slog.String("url", url),
-		slog.String("platform", platform),
+		slog.String("platform", platformName),
 		slog.String("file", filePath),
+		slog.Duration("elapsed", elapsed),
 	)
 
-	return filePath, nil
+	return filePath, info, nil
 }
 
-// getDownloader возвращает соответствующий загрузчик для URL
-func (s *Service) getDownloader(url string) (string, VideoDownloader) {
-	urlLower := strings.ToLower(url)
+// CanonicalURL возвращает канонический URL для ключей кэшей без сетевых запросов
+// (короткая ссылка заменяется раскрытием, если оно уже известно)
+func (s *Service) CanonicalURL(url string) string {
+	return s.resolver.Canonical(url)
+}
 
-	if yt.IsValidURL(urlLower) {
-		return "youtube", s.ytDownloader
-	}
+// Platform возвращает название платформы для URL или "unknown"
+func (s *Service) Platform(url string) string {
+	name, _ := s.getDownloader(url)
+	return name
+}
 
-	if tiktok.IsValidURL(urlLower) {
-		return "tiktok", s.tiktokDownloader
+// Platforms возвращает включенные платформы в порядке регистрации
+func (s *Service) Platforms() []PlatformInfo {
+	infos := make([]PlatformInfo, 0, len(s.platforms))
+	for _, p := range s.platforms {
+		infos = append(infos, p.PlatformInfo)
 	}
+	return infos
+}
+
+// getDownloader возвращает соответствующий загрузчик для URL
+func (s *Service) getDownloader(url string) (string, VideoDownloader) {
+	urlLower := strings.ToLower(resolver.Canonicalize(url))
 
-	if instagram.IsValidURL(urlLower) {
-		return "instagram", s.igDownloader
+	for _, p := range s.platforms {
+		if p.match(urlLower) {
+			return p.Name, p.downloader
+		}
 	}
 
 	return "unknown", nil
@@ -104,22 +496,22 @@ func (s *Service) Cleanup(filePath string) error {
 		return nil
 	}
 
-	// Проверяем, что файл находится в tempDir для безопасности
-	absTempDir, err := filepath.Abs(s.tempDir)
-	if err != nil {
-		return fmt.Errorf("failed to get absolute temp dir: %w", err)
-	}
-
-	absFilePath, err := filepath.Abs(filePath)
-	if err != nil {
-		return fmt.Errorf("failed to get absolute file path: %w", err)
+	// Проверяем, что файл находится во временном каталоге для безопасности
+	if !s.tempDirs.contains(filePath) {
+		return fmt.Errorf("file path is outside temp directory")
 	}
 
-	if !strings.HasPrefix(absFilePath, absTempDir) {
-		return fmt.Errorf("file path is outside temp directory")
+	remove := s.fs.Remove
+	if platform.IsSlideshow(filePath) {
+		remove = s.fs.RemoveAll
 	}
 
-	if err := os.Remove(filePath); err != nil {
+	if err := remove(filePath); err != nil {
+		// Файл уже удален, например конвертацией в GIF или «кружок»
+		if errors.Is(err, fs.ErrNotExist) {
+			s.tempDirs.forget(filePath)
+			return nil
+		}
 		s.logger.Warn("Failed to remove temporary file",
 			slog.String("file", filePath),
 			slog.Any("error", err),
@@ -127,15 +519,26 @@ func (s *Service) Cleanup(filePath string) error {
 		return err
 	}
 
+	s.tempDirs.forget(filePath)
 	s.logger.Info("Temporary file removed", slog.String("file", filePath))
 	return nil
 }
 
-// GetFileSize возвращает размер файла в байтах
+// Close удаляет расшифрованные cookies, оставшиеся на диске после остановки загрузок
+func (s *Service) Close() {
+	if err := s.cookies.close(); err != nil {
+		s.logger.Warn("Failed to remove decrypted cookies", slog.Any("error", err))
+	}
+}
+
+// GetFileSize возвращает размер файла в байтах; для слайдшоу — суммарный размер файлов
 func (s *Service) GetFileSize(filePath string) (int64, error) {
-	info, err := os.Stat(filePath)
+	info, err := s.fs.Stat(filePath)
 	if err != nil {
 		return 0, err
 	}
+	if info.IsDir() {
+		return s.tempDirs.dirSize(filePath), nil
+	}
 	return info.Size(), nil
 }
@@ -0,0 +1,231 @@
+package downloader
+
+import (
+	"fmt"
+	"io"
+	"io/fs"
+	"log/slog"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/reelser-bot/internal/fsys"
+	"github.com/reelser-bot/internal/platform"
+)
+
+// tempDirs реализует двухуровневое размещение временных файлов:
+// небольшие файлы пишутся в быстрый каталог (tmpfs), крупные — на диск
+type tempDirs struct {
+	logger *slog.Logger
+	fs     fsys.FS
+	disk   string
+	fast   string
+
+	maxFileBytes  int64
+	maxTotalBytes int64
+
+	mu       sync.Mutex
+	reserved int64
+	// stored скачанные файлы в быстром каталоге и их размеры до Cleanup: занятое место
+	// считается по ним, а не обходом каталога перед каждой загрузкой
+	stored      map[string]int64
+	storedBytes int64
+	// synced учет хотя бы раз сверен с содержимым каталога
+	synced bool
+}
+
+// newTempDirs создает размещение временных файлов. Без fast используется только диск
+func newTempDirs(logger *slog.Logger, fs fsys.FS, disk, fast string, maxFileMB, maxTotalMB int) *tempDirs {
+	return &tempDirs{
+		logger:        logger,
+		fs:            fs,
+		disk:          disk,
+		fast:          fast,
+		maxFileBytes:  int64(maxFileMB) * 1024 * 1024,
+		maxTotalBytes: int64(maxTotalMB) * 1024 * 1024,
+		stored:        make(map[string]int64),
+	}
+}
+
+// choose выбирает каталог для загрузки по оценке размера из метаданных (0 — неизвестен).
+// Возвращает каталог и функцию освобождения резерва в быстром каталоге, которую нужно
+// вызвать после завершения загрузки
+func (t *tempDirs) choose(req platform.Request, estimate int64) (string, func()) {
+	noop := func() {}
+	if t.fast == "" || t.maxFileBytes <= 0 {
+		return t.disk, noop
+	}
+	if estimate <= 0 || estimate > t.maxFileBytes {
+		return t.disk, noop
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if used, ok := t.fitsLocked(estimate); !ok {
+		t.logger.Info("Fast temp dir budget exhausted, using disk",
+			slog.String("url", req.URL),
+			slog.Int64("used_bytes", used),
+		)
+		return t.disk, noop
+	}
+
+	t.reserved += estimate
+	var once sync.Once
+	return t.fast, func() {
+		once.Do(func() {
+			t.mu.Lock()
+			t.reserved -= estimate
+			t.mu.Unlock()
+		})
+	}
+}
+
+// fitsLocked проверяет, что файл размером estimate помещается в TEMP_FAST_MAX_TOTAL_MB,
+// и возвращает занятое место. Если учет еще не сверялся с каталогом или по нему места
+// нет, каталог обходится заново: файлы могли остаться после перезапуска или быть
+// удалены фоновой очисткой. Вызывается под mu
+func (t *tempDirs) fitsLocked(estimate int64) (int64, bool) {
+	if t.maxTotalBytes <= 0 {
+		return 0, true
+	}
+
+	used := t.storedBytes + t.reserved
+	if !t.synced || used+estimate > t.maxTotalBytes {
+		t.resyncLocked()
+		used = t.storedBytes + t.reserved
+	}
+	return used, used+estimate <= t.maxTotalBytes
+}
+
+// resyncLocked пересчитывает учет по содержимому быстрого каталога. Вызывается под mu
+func (t *tempDirs) resyncLocked() {
+	t.stored = make(map[string]int64)
+	t.storedBytes = 0
+	entries, _ := t.fs.ReadDir(t.fast)
+	for _, entry := range entries {
+		path := filepath.Join(t.fast, entry.Name())
+		size := t.dirSize(path)
+		t.stored[path] = size
+		t.storedBytes += size
+	}
+	t.synced = true
+}
+
+// track учитывает файл, оставшийся в быстром каталоге
+func (t *tempDirs) track(filePath string, size int64) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.storedBytes += size - t.stored[filePath]
+	t.stored[filePath] = size
+}
+
+// forget снимает с учета удаленный файл
+func (t *tempDirs) forget(filePath string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.storedBytes -= t.stored[filePath]
+	delete(t.stored, filePath)
+}
+
+// spill переносит файл из быстрого каталога на диск, если он оказался крупнее порога
+func (t *tempDirs) spill(filePath string) string {
+	if t.fast == "" || !within(t.fast, filePath) {
+		return filePath
+	}
+
+	info, err := t.fs.Stat(filePath)
+	if err != nil {
+		return filePath
+	}
+	if info.IsDir() {
+		t.track(filePath, t.dirSize(filePath))
+		return filePath
+	}
+	if info.Size() <= t.maxFileBytes {
+		t.track(filePath, info.Size())
+		return filePath
+	}
+
+	target := filepath.Join(t.disk, filepath.Base(filePath))
+	if err := t.moveFile(filePath, target); err != nil {
+		t.logger.Warn("Failed to spill file to disk",
+			slog.String("file", filePath),
+			slog.Any("error", err),
+		)
+		return filePath
+	}
+
+	t.logger.Info("File spilled from fast temp dir to disk",
+		slog.String("file", target),
+		slog.Int64("size", info.Size()),
+	)
+	return target
+}
+
+// contains проверяет, что путь находится в одном из временных каталогов
+func (t *tempDirs) contains(filePath string) bool {
+	if within(t.disk, filePath) {
+		return true
+	}
+	return t.fast != "" && within(t.fast, filePath)
+}
+
+// within проверяет, что path находится внутри dir
+func within(dir, path string) bool {
+	absDir, err := filepath.Abs(dir)
+	if err != nil {
+		return false
+	}
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		return false
+	}
+	return strings.HasPrefix(absPath, absDir+string(filepath.Separator))
+}
+
+// dirSize возвращает суммарный размер файлов в каталоге
+func (t *tempDirs) dirSize(dir string) int64 {
+	var total int64
+	_ = t.fs.WalkDir(dir, func(_ string, entry fs.DirEntry, err error) error {
+		if err != nil || entry.IsDir() {
+			return nil
+		}
+		if info, err := entry.Info(); err == nil {
+			total += info.Size()
+		}
+		return nil
+	})
+	return total
+}
+
+// moveFile перемещает файл; между файловыми системами копирует и удаляет исходный
+func (t *tempDirs) moveFile(src, dst string) error {
+	if err := t.fs.Rename(src, dst); err == nil {
+		return nil
+	}
+
+	in, err := t.fs.Open(src)
+	if err != nil {
+		return fmt.Errorf("failed to open source: %w", err)
+	}
+	defer in.Close()
+
+	out, err := t.fs.Create(dst)
+	if err != nil {
+		return fmt.Errorf("failed to create target: %w", err)
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		t.fs.Remove(dst)
+		return fmt.Errorf("failed to copy file: %w", err)
+	}
+	if err := out.Close(); err != nil {
+		t.fs.Remove(dst)
+		return fmt.Errorf("failed to close target: %w", err)
+	}
+
+	return t.fs.Remove(src)
+}
@@ -0,0 +1,158 @@
+package downloader
+
+import (
+	"io"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/reelser-bot/internal/fsys"
+	"github.com/reelser-bot/internal/platform"
+)
+
+const mb = 1024 * 1024
+
+func testLogger() *slog.Logger {
+	return slog.New(slog.NewTextHandler(io.Discard, nil))
+}
+
+func TestSpill(t *testing.T) {
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	fs := fsys.NewMemFS(func() time.Time { return now })
+	fs.WriteFile("/fast/big.mp4", make([]byte, 2*mb), now)
+	fs.WriteFile("/fast/small.mp4", make([]byte, mb/2), now)
+	fs.WriteFile("/disk/other.mp4", make([]byte, 2*mb), now)
+	dirs := newTempDirs(testLogger(), fs, "/disk", "/fast", 1, 10)
+
+	if got := dirs.spill("/fast/big.mp4"); got != "/disk/big.mp4" {
+		t.Errorf("spill(big) = %q, want /disk/big.mp4", got)
+	}
+	if _, err := fs.Stat("/fast/big.mp4"); err == nil {
+		t.Error("spilled file left in fast dir")
+	}
+	if info, err := fs.Stat("/disk/big.mp4"); err != nil || info.Size() != 2*mb {
+		t.Errorf("spilled file on disk: %v, %v", info, err)
+	}
+
+	if got := dirs.spill("/fast/small.mp4"); got != "/fast/small.mp4" {
+		t.Errorf("spill(small) = %q, want file to stay in fast dir", got)
+	}
+	if got := dirs.spill("/disk/other.mp4"); got != "/disk/other.mp4" {
+		t.Errorf("spill(disk file) = %q, want file to stay on disk", got)
+	}
+}
+
+func TestSpillWithoutFastDir(t *testing.T) {
+	fs := fsys.NewMemFS(nil)
+	fs.WriteFile("/disk/big.mp4", make([]byte, 2*mb), time.Now())
+	dirs := newTempDirs(testLogger(), fs, "/disk", "", 1, 10)
+
+	if got := dirs.spill("/disk/big.mp4"); got != "/disk/big.mp4" {
+		t.Errorf("spill = %q, want unchanged path", got)
+	}
+}
+
+func TestContains(t *testing.T) {
+	dirs := newTempDirs(testLogger(), fsys.NewMemFS(nil), "/tmp/downloads", "/dev/shm/reelser", 1, 10)
+
+	tests := []struct {
+		path string
+		want bool
+	}{
+		{"/tmp/downloads/video.mp4", true},
+		{"/tmp/downloads/job/video.mp4", true},
+		{"/dev/shm/reelser/video.mp4", true},
+		{"/tmp/downloads", false},
+		{"/tmp/downloads-other/video.mp4", false},
+		{"/tmp/downloads/../secret", false},
+		{"/etc/passwd", false},
+	}
+	for _, tt := range tests {
+		if got := dirs.contains(tt.path); got != tt.want {
+			t.Errorf("contains(%q) = %v, want %v", tt.path, got, tt.want)
+		}
+	}
+}
+
+func TestChoose(t *testing.T) {
+	req := platform.Request{URL: "https://example.com/v"}
+
+	t.Run("small file goes to fast dir", func(t *testing.T) {
+		dirs := newTempDirs(testLogger(), fsys.NewMemFS(nil), "/disk", "/fast", 1, 10)
+		dir, release := dirs.choose(req, mb/2)
+		defer release()
+		if dir != "/fast" {
+			t.Errorf("choose = %q, want /fast", dir)
+		}
+	})
+
+	t.Run("large file goes to disk", func(t *testing.T) {
+		dirs := newTempDirs(testLogger(), fsys.NewMemFS(nil), "/disk", "/fast", 1, 10)
+		if dir, _ := dirs.choose(req, 2*mb); dir != "/disk" {
+			t.Errorf("choose = %q, want /disk", dir)
+		}
+	})
+
+	t.Run("unknown size goes to disk", func(t *testing.T) {
+		dirs := newTempDirs(testLogger(), fsys.NewMemFS(nil), "/disk", "/fast", 1, 10)
+		if dir, _ := dirs.choose(req, 0); dir != "/disk" {
+			t.Errorf("choose = %q, want /disk", dir)
+		}
+	})
+
+	t.Run("budget counts files and reservations", func(t *testing.T) {
+		fs := fsys.NewMemFS(nil)
+		fs.WriteFile("/fast/existing.mp4", make([]byte, mb), time.Now())
+		dirs := newTempDirs(testLogger(), fs, "/disk", "/fast", 1, 2)
+
+		first, release := dirs.choose(req, 3*mb/4)
+		if first != "/fast" {
+			t.Fatalf("first choose = %q, want /fast", first)
+		}
+		if second, _ := dirs.choose(req, 3*mb/4); second != "/disk" {
+			t.Errorf("second choose = %q, want /disk while budget is reserved", second)
+		}
+
+		release()
+		release() // повторный вызов не освобождает резерв дважды
+		third, releaseThird := dirs.choose(req, 3*mb/4)
+		defer releaseThird()
+		if third != "/fast" {
+			t.Errorf("choose after release = %q, want /fast", third)
+		}
+		if dirs.reserved != 3*mb/4 {
+			t.Errorf("reserved = %d, want %d", dirs.reserved, 3*mb/4)
+		}
+	})
+
+	t.Run("stored files count until removed", func(t *testing.T) {
+		now := time.Now()
+		fs := fsys.NewMemFS(func() time.Time { return now })
+		dirs := newTempDirs(testLogger(), fs, "/disk", "/fast", 1, 1)
+
+		dir, release := dirs.choose(req, 3*mb/4)
+		fs.WriteFile(dir+"/video.mp4", make([]byte, 3*mb/4), now)
+		release()
+		dirs.spill(dir + "/video.mp4")
+		if dir, _ := dirs.choose(req, 3*mb/4); dir != "/disk" {
+			t.Errorf("choose = %q, want /disk while the downloaded file is kept", dir)
+		}
+
+		dirs.forget("/fast/video.mp4")
+		if dir, _ := dirs.choose(req, 3*mb/4); dir != "/fast" {
+			t.Errorf("choose after forget = %q, want /fast", dir)
+		}
+	})
+	t.Run("files removed by the janitor are resynced", func(t *testing.T) {
+		now := time.Now()
+		fs := fsys.NewMemFS(func() time.Time { return now })
+		dirs := newTempDirs(testLogger(), fs, "/disk", "/fast", 1, 1)
+
+		fs.WriteFile("/fast/stale.mp4", make([]byte, 3*mb/4), now)
+		dirs.spill("/fast/stale.mp4")
+		fs.Remove("/fast/stale.mp4")
+		if dir, _ := dirs.choose(req, 3*mb/4); dir != "/fast" {
+			t.Errorf("choose = %q, want /fast after the stored file was removed", dir)
+		}
+	})
+}
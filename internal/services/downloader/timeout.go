@@ -0,0 +1,77 @@
+package downloader
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+const (
+	// latencyWindow количество последних загрузок (включая неудачные), по которым считается p95
+	latencyWindow = 50
+	// latencyMinSamples минимальное количество замеров для адаптивного таймаута
+	latencyMinSamples = 5
+	// timeoutFactor запас относительно p95
+	timeoutFactor = 2
+)
+
+// latencyTracker хранит последние длительности загрузок по платформам
+type latencyTracker struct {
+	mu      sync.Mutex
+	samples map[string][]time.Duration
+}
+
+func newLatencyTracker() *latencyTracker {
+	return &latencyTracker{
+		samples: make(map[string][]time.Duration),
+	}
+}
+
+// record добавляет замер длительности загрузки; для неудачной попытки — время до ошибки
+// или таймаута
+func (t *latencyTracker) record(platform string, d time.Duration) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	samples := append(t.samples[platform], d)
+	if len(samples) > latencyWindow {
+		samples = samples[len(samples)-latencyWindow:]
+	}
+	t.samples[platform] = samples
+}
+
+// p95 возвращает 95-й перцентиль длительности загрузок платформы
+func (t *latencyTracker) p95(platform string) (time.Duration, bool) {
+	t.mu.Lock()
+	samples := make([]time.Duration, len(t.samples[platform]))
+	copy(samples, t.samples[platform])
+	t.mu.Unlock()
+
+	if len(samples) < latencyMinSamples {
+		return 0, false
+	}
+
+	sort.Slice(samples, func(i, j int) bool { return samples[i] < samples[j] })
+	idx := (len(samples)*95+99)/100 - 1
+	return samples[idx], true
+}
+
+// Timeout возвращает таймаут загрузки для URL с учетом p95 длительности по платформе.
+// Пока замеров мало, используется глобальный таймаут
+func (s *Service) Timeout(url string) time.Duration {
+	platform, _ := s.getDownloader(url)
+
+	p95, ok := s.latency.p95(platform)
+	if !ok {
+		return s.timeout
+	}
+
+	timeout := p95 * timeoutFactor
+	if timeout < s.minTimeout {
+		timeout = s.minTimeout
+	}
+	if timeout > s.maxTimeout {
+		timeout = s.maxTimeout
+	}
+	return timeout
+}
@@ -0,0 +1,42 @@
+package downloader
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/reelser-bot/internal/clock"
+	"github.com/reelser-bot/internal/config"
+	"github.com/reelser-bot/internal/platform/ytdlp"
+)
+
+func TestTimeoutCountsFailedAttempts(t *testing.T) {
+	clk := clock.NewFake(time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC))
+	// Метаданные отдаются сразу, а загрузка падает через 4 минуты
+	runner := &ytdlp.FakeRunner{Handler: func(cmd ytdlp.Command) ([]byte, []byte, error) {
+		if slices.Contains(cmd.Args, "-J") {
+			return []byte(`{"id":"x","duration":60,"ext":"mp4"}`), nil, nil
+		}
+		clk.Advance(4 * time.Minute)
+		return nil, []byte("ERROR: read timed out"), errors.New("exit status 1")
+	}}
+	s := NewService(testLogger(), config.DownloadConfig{
+		TempDir:    t.TempDir(),
+		Timeout:    5 * time.Minute,
+		MinTimeout: time.Minute,
+		MaxTimeout: time.Hour,
+	}, WithRunner(runner), WithClock(clk))
+
+	const url = "https://www.youtube.com/watch?v=x"
+	for range latencyMinSamples {
+		if _, _, err := s.Download(context.Background(), url, Options{}); err == nil {
+			t.Fatal("Download succeeded, want error")
+		}
+	}
+
+	if got, want := s.Timeout(url), 8*time.Minute; got != want {
+		t.Errorf("Timeout after failed attempts = %v, want %v", got, want)
+	}
+}
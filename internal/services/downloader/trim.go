@@ -0,0 +1,53 @@
+package downloader
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/reelser-bot/internal/platform"
+	"github.com/reelser-bot/internal/platform/ytdlp"
+)
+
+// trimAfterDownload проверяет, что платформа скачивает ролик целиком и фрагмент
+// нужно вырезать после загрузки (yt-dlp-платформы скачивают только фрагмент сами)
+func (s *Service) trimAfterDownload(platformName string) bool {
+	for _, p := range s.platforms {
+		if p.Name == platformName {
+			return p.trimAfter
+		}
+	}
+	return false
+}
+
+// trim вырезает фрагмент из скачанного файла через ffmpeg без перекодирования и
+// удаляет исходный файл. Разрез проходит по ключевым кадрам
+func (s *Service) trim(ctx context.Context, filePath string, section platform.Section) (string, error) {
+	if _, err := s.runner.LookPath("ffmpeg"); err != nil {
+		return "", fmt.Errorf("ffmpeg not found: %w", err)
+	}
+
+	ext := filepath.Ext(filePath)
+	output := strings.TrimSuffix(filePath, ext) + "_trim" + ext
+	args := []string{
+		"-y", "-v", "error",
+		"-ss", strconv.FormatFloat(section.Start.Seconds(), 'f', 3, 64),
+		"-i", filePath,
+		"-t", strconv.FormatFloat(section.Duration().Seconds(), 'f', 3, 64),
+		"-c", "copy",
+		output,
+	}
+
+	if _, stderr, err := s.runner.Run(ctx, ytdlp.Command{Name: "ffmpeg", Args: args}); err != nil {
+		s.fs.Remove(output)
+		return "", fmt.Errorf("ffmpeg trim failed: %w: %s", err, strings.TrimSpace(string(stderr)))
+	}
+
+	if err := s.fs.Remove(filePath); err != nil {
+		s.logger.Warn("Failed to remove untrimmed file", slog.String("file", filePath), slog.Any("error", err))
+	}
+	return output, nil
+}
@@ -0,0 +1,143 @@
+package downloader
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/reelser-bot/internal/platform/ytdlp"
+)
+
+// thumbnailSize максимальная сторона превью: Telegram принимает JPEG до 320 px
+const thumbnailSize = 320
+
+// VideoProps параметры скачанного видео для плеера Telegram
+type VideoProps struct {
+	Width    int
+	Height   int
+	Duration time.Duration
+	// Thumbnail путь к превью рядом с видео; пусто — превью не создано.
+	// Файл удаляется вызывающим через Cleanup
+	Thumbnail string
+}
+
+// ffprobeOutput фрагмент вывода ffprobe -of json
+type ffprobeOutput struct {
+	Streams []struct {
+		Width    int               `json:"width"`
+		Height   int               `json:"height"`
+		Tags     map[string]string `json:"tags"`
+		SideData []struct {
+			Rotation float64 `json:"rotation"`
+		} `json:"side_data_list"`
+	} `json:"streams"`
+	Format struct {
+		Duration string `json:"duration"`
+	} `json:"format"`
+}
+
+// VideoProps получает размеры и длительность видео через ffprobe и создает превью
+// через ffmpeg. Без них Telegram показывает вместо видео серую плашку без перемотки.
+// Ошибки не возвращаются: все, что не удалось получить, остается пустым
+func (s *Service) VideoProps(ctx context.Context, filePath string) VideoProps {
+	var props VideoProps
+
+	if err := s.probeVideo(ctx, filePath, &props); err != nil {
+		s.logger.Warn("Failed to probe video", slog.String("file", filePath), slog.Any("error", err))
+	}
+
+	thumbnail, err := s.thumbnail(ctx, filePath, props.Duration)
+	if err != nil {
+		s.logger.Warn("Failed to create video thumbnail", slog.String("file", filePath), slog.Any("error", err))
+	}
+	props.Thumbnail = thumbnail
+
+	return props
+}
+
+// probeVideo заполняет размеры и длительность видео; размеры учитывают поворот
+func (s *Service) probeVideo(ctx context.Context, filePath string, props *VideoProps) error {
+	if _, err := s.runner.LookPath("ffprobe"); err != nil {
+		return fmt.Errorf("ffprobe not found: %w", err)
+	}
+
+	stdout, _, err := s.runner.Run(ctx, ytdlp.Command{
+		Name: "ffprobe",
+		Args: []string{
+			"-v", "error",
+			"-select_streams", "v:0",
+			"-show_entries", "stream=width,height:stream_tags=rotate:stream_side_data=rotation:format=duration",
+			"-of", "json",
+			filePath,
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("ffprobe failed: %w", err)
+	}
+
+	var output ffprobeOutput
+	if err := json.Unmarshal(stdout, &output); err != nil {
+		return fmt.Errorf("failed to decode ffprobe output: %w", err)
+	}
+
+	if seconds, err := strconv.ParseFloat(output.Format.Duration, 64); err == nil && seconds > 0 {
+		props.Duration = time.Duration(seconds * float64(time.Second))
+	}
+	if len(output.Streams) == 0 {
+		return nil
+	}
+
+	stream := output.Streams[0]
+	props.Width, props.Height = stream.Width, stream.Height
+
+	// Видео с телефона часто записано горизонтально с пометкой о повороте
+	rotation, _ := strconv.Atoi(stream.Tags["rotate"])
+	for _, side := range stream.SideData {
+		if side.Rotation != 0 {
+			rotation = int(side.Rotation)
+		}
+	}
+	if rotation%180 != 0 {
+		props.Width, props.Height = props.Height, props.Width
+	}
+	return nil
+}
+
+// thumbnail сохраняет кадр из начала видео как JPEG рядом с файлом
+func (s *Service) thumbnail(ctx context.Context, filePath string, duration time.Duration) (string, error) {
+	if _, err := s.runner.LookPath("ffmpeg"); err != nil {
+		return "", fmt.Errorf("ffmpeg not found: %w", err)
+	}
+
+	// Первый кадр часто черный, поэтому берем кадр на первой секунде (у коротких — середину)
+	at := time.Second
+	if duration > 0 && duration < 2*time.Second {
+		at = duration / 2
+	}
+
+	output := strings.TrimSuffix(filePath, filepath.Ext(filePath)) + "_thumb.jpg"
+	size := strconv.Itoa(thumbnailSize)
+	args := []string{
+		"-y", "-v", "error",
+		"-ss", strconv.FormatFloat(at.Seconds(), 'f', 3, 64),
+		"-i", filePath,
+		"-frames:v", "1",
+		"-vf", "scale=" + size + ":" + size + ":force_original_aspect_ratio=decrease",
+		"-q:v", "5",
+		output,
+	}
+
+	if _, stderr, err := s.runner.Run(ctx, ytdlp.Command{Name: "ffmpeg", Args: args}); err != nil {
+		s.fs.Remove(output)
+		return "", fmt.Errorf("ffmpeg failed: %w: %s", err, strings.TrimSpace(string(stderr)))
+	}
+	if _, err := s.fs.Stat(output); err != nil {
+		return "", fmt.Errorf("thumbnail was not created: %w", err)
+	}
+	return output, nil
+}
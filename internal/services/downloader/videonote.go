@@ -0,0 +1,53 @@
+package downloader
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/reelser-bot/internal/platform/ytdlp"
+)
+
+const (
+	// MaxVideoNoteDuration ограничение Telegram на длительность видеосообщения
+	MaxVideoNoteDuration = time.Minute
+	// VideoNoteSize сторона квадрата видеосообщения в пикселях
+	VideoNoteSize = 384
+)
+
+// ToVideoNote обрезает видео до квадрата по центру, уменьшает до VideoNoteSize и
+// ограничивает MaxVideoNoteDuration, чтобы отправить его видеосообщением («кружком»).
+// Исходный файл удаляется
+func (s *Service) ToVideoNote(ctx context.Context, filePath string) (string, error) {
+	if _, err := s.runner.LookPath("ffmpeg"); err != nil {
+		return "", fmt.Errorf("ffmpeg not found: %w", err)
+	}
+
+	output := strings.TrimSuffix(filePath, filepath.Ext(filePath)) + "_note.mp4"
+	size := strconv.Itoa(VideoNoteSize)
+	args := []string{
+		"-y", "-v", "error",
+		"-i", filePath,
+		"-t", fmt.Sprintf("%.0f", MaxVideoNoteDuration.Seconds()),
+		"-vf", "crop=min(iw\\,ih):min(iw\\,ih),scale=" + size + ":" + size,
+		"-c:v", "libx264", "-preset", "veryfast", "-crf", "26",
+		"-pix_fmt", "yuv420p",
+		"-c:a", "aac", "-b:a", "64k",
+		"-movflags", "+faststart",
+		output,
+	}
+
+	if _, stderr, err := s.runner.Run(ctx, ytdlp.Command{Name: "ffmpeg", Args: args}); err != nil {
+		s.fs.Remove(output)
+		return "", fmt.Errorf("ffmpeg video note failed: %w: %s", err, strings.TrimSpace(string(stderr)))
+	}
+
+	if err := s.fs.Remove(filePath); err != nil {
+		s.logger.Warn("Failed to remove source video", slog.String("file", filePath), slog.Any("error", err))
+	}
+	return output, nil
+}
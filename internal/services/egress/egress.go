@@ -0,0 +1,222 @@
+package egress
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/reelser-bot/internal/platform"
+)
+
+// DefaultPlatform ключ маршрутов, применяемых к платформам без собственной настройки
+const DefaultPlatform = "*"
+
+// route один маршрут исходящего трафика: прокси или прямое подключение ("")
+type route struct {
+	proxy   string
+	healthy bool
+}
+
+// Selector выбирает маршрут исходящего трафика для платформы. Маршруты перебираются
+// в порядке из конфигурации; неисправные пропускаются, пока проверка не покажет,
+// что они снова доступны
+type Selector struct {
+	logger  *slog.Logger
+	timeout time.Duration
+
+	mu     sync.Mutex
+	routes map[string][]*route
+}
+
+// New создает селектор маршрутов. routes сопоставляет платформе список прокси
+// в порядке приоритета; "direct" означает прямое подключение
+func New(logger *slog.Logger, routes map[string][]string, timeout time.Duration) (*Selector, error) {
+	s := &Selector{
+		logger:  logger,
+		timeout: timeout,
+		routes:  make(map[string][]*route, len(routes)),
+	}
+
+	for name, proxies := range routes {
+		for _, proxy := range proxies {
+			if strings.EqualFold(proxy, platform.DirectRoute) {
+				proxy = ""
+			} else if _, err := url.Parse(proxy); err != nil {
+				return nil, fmt.Errorf("invalid proxy for %s: %w", name, err)
+			}
+			s.routes[name] = append(s.routes[name], &route{proxy: proxy, healthy: true})
+		}
+	}
+
+	return s, nil
+}
+
+// Enabled сообщает, настроены ли маршруты хотя бы для одной платформы
+func (s *Selector) Enabled() bool {
+	return s != nil && len(s.routes) > 0
+}
+
+// Candidates возвращает маршруты платформы для попыток загрузки: сначала исправные
+// в порядке приоритета, затем неисправные. Пусто — маршруты не настроены
+func (s *Selector) Candidates(name string) []string {
+	if s == nil {
+		return nil
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	routes := s.platformRoutes(name)
+	candidates := make([]string, 0, len(routes))
+	for _, r := range routes {
+		if r.healthy {
+			candidates = append(candidates, r.proxy)
+		}
+	}
+	for _, r := range routes {
+		if !r.healthy {
+			candidates = append(candidates, r.proxy)
+		}
+	}
+	return candidates
+}
+
+// MarkFailed помечает маршрут неисправным после сетевой ошибки загрузки
+func (s *Selector) MarkFailed(name, proxy string, err error) {
+	s.setHealth(name, proxy, err)
+}
+
+// MarkHealthy помечает маршрут исправным после успешной загрузки
+func (s *Selector) MarkHealthy(name, proxy string) {
+	s.setHealth(name, proxy, nil)
+}
+
+// Run периодически проверяет доступность targets (URL по платформам) через каждый маршрут
+func (s *Selector) Run(ctx context.Context, interval time.Duration, targets map[string]string) {
+	if !s.Enabled() || interval <= 0 {
+		return
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		s.checkAll(ctx, targets)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// checkAll проверяет маршруты каждой платформы, для которой они настроены
+func (s *Selector) checkAll(ctx context.Context, targets map[string]string) {
+	names := make([]string, 0, len(targets))
+	for name := range targets {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		for _, proxy := range s.Candidates(name) {
+			if ctx.Err() != nil {
+				return
+			}
+			s.setHealth(name, proxy, s.check(ctx, proxy, targets[name]))
+		}
+	}
+}
+
+// check выполняет запрос к target через маршрут. Любой HTTP-ответ считается успехом
+func (s *Selector) check(ctx context.Context, proxy, target string) error {
+	ctx, cancel := context.WithTimeout(platform.WithProxy(ctx, proxy), s.timeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodHead, target, nil)
+	if err != nil {
+		return err
+	}
+
+	client := platform.NewHTTPClient(s.timeout)
+	client.CheckRedirect = func(*http.Request, []*http.Request) error { return http.ErrUseLastResponse }
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	return nil
+}
+
+// setHealth обновляет состояние маршрута и логирует смену состояния
+func (s *Selector) setHealth(name, proxy string, err error) {
+	if s == nil {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, r := range s.platformRoutes(name) {
+		if r.proxy != proxy {
+			continue
+		}
+
+		healthy := err == nil
+		if r.healthy != healthy {
+			attrs := []any{
+				slog.String("platform", name),
+				slog.String("route", redact(proxy)),
+			}
+			if healthy {
+				s.logger.Info("Egress route recovered", attrs...)
+			} else {
+				s.logger.Warn("Egress route marked unhealthy", append(attrs, slog.Any("error", err))...)
+			}
+		}
+
+		r.healthy = healthy
+		return
+	}
+}
+
+// platformRoutes возвращает маршруты платформы. Платформа без собственной настройки
+// получает копию маршрутов по умолчанию, чтобы их состояние отслеживалось отдельно;
+// вызывается под s.mu
+func (s *Selector) platformRoutes(name string) []*route {
+	if routes, ok := s.routes[name]; ok {
+		return routes
+	}
+
+	defaults := s.routes[DefaultPlatform]
+	if len(defaults) == 0 {
+		return nil
+	}
+
+	routes := make([]*route, 0, len(defaults))
+	for _, r := range defaults {
+		routes = append(routes, &route{proxy: r.proxy, healthy: true})
+	}
+	s.routes[name] = routes
+	return routes
+}
+
+// redact скрывает учетные данные прокси для логов
+func redact(proxy string) string {
+	if proxy == "" {
+		return platform.DirectRoute
+	}
+	u, err := url.Parse(proxy)
+	if err != nil {
+		return "invalid"
+	}
+	return u.Redacted()
+}
@@ -0,0 +1,172 @@
+package fileids
+
+import (
+	"container/list"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/reelser-bot/internal/storage"
+)
+
+// Entry file_id видео, уже загруженного в Telegram
+type Entry struct {
+	// Key канонический URL видео
+	Key     string    `json:"key"`
+	FileID  string    `json:"file_id"`
+	SavedAt time.Time `json:"saved_at"`
+}
+
+// Store LRU-кэш file_id по каноническому URL: повторные запросы той же ссылки
+// отправляются по file_id мгновенно и без трафика. Если задан путь, кэш переживает
+// перезапуск бота
+type Store struct {
+	logger *slog.Logger
+	path   string
+	size   int
+
+	mu      sync.Mutex
+	order   *list.List
+	entries map[string]*list.Element
+	writes  *storage.Debouncer
+}
+
+// persistDelay за сколько изменения кэша собираются в одну запись файла: кэш
+// записывается целиком, а потеря последних записей при сбое только замедлит повторы
+const persistDelay = 10 * time.Second
+
+// NewStore создает кэш не больше чем на size записей и загружает сохраненные записи.
+// Пустой path — кэш только в памяти; size <= 0 — кэш выключен
+func NewStore(logger *slog.Logger, path string, size int) *Store {
+	s := &Store{
+		logger:  logger,
+		path:    path,
+		size:    size,
+		order:   list.New(),
+		entries: make(map[string]*list.Element),
+	}
+	s.writes = storage.NewDebouncer(persistDelay, s.save)
+
+	if path == "" || size <= 0 {
+		return s
+	}
+
+	// На диске записи хранятся от самой свежей к самой старой
+	var saved []Entry
+	if err := storage.LoadJSON(path, &saved); err != nil {
+		logger.Warn("Failed to load file_id cache",
+			slog.String("file", path),
+			slog.Any("error", err),
+		)
+	}
+	for _, entry := range saved {
+		if s.order.Len() >= size {
+			break
+		}
+		if entry.Key == "" || entry.FileID == "" {
+			continue
+		}
+		if _, ok := s.entries[entry.Key]; ok {
+			continue
+		}
+		s.entries[entry.Key] = s.order.PushBack(&entry)
+	}
+
+	return s
+}
+
+// Get возвращает file_id для ключа и отмечает запись использованной
+func (s *Store) Get(key string) (string, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	elem, ok := s.entries[key]
+	if !ok {
+		return "", false
+	}
+	s.order.MoveToFront(elem)
+	return elem.Value.(*Entry).FileID, true
+}
+
+// Put запоминает file_id для ключа и вытесняет давно не использованные записи сверх размера
+func (s *Store) Put(key, fileID string) {
+	if s.size <= 0 || key == "" || fileID == "" {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	entry := &Entry{Key: key, FileID: fileID, SavedAt: time.Now()}
+	if elem, ok := s.entries[key]; ok {
+		if elem.Value.(*Entry).FileID == fileID {
+			s.order.MoveToFront(elem)
+			return
+		}
+		elem.Value = entry
+		s.order.MoveToFront(elem)
+	} else {
+		s.entries[key] = s.order.PushFront(entry)
+	}
+
+	for s.order.Len() > s.size {
+		oldest := s.order.Back()
+		s.order.Remove(oldest)
+		delete(s.entries, oldest.Value.(*Entry).Key)
+	}
+	s.persist()
+}
+
+// Forget удаляет file_id для ключа и возвращает true, если он был сохранен
+func (s *Store) Forget(key string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	elem, ok := s.entries[key]
+	if !ok {
+		return false
+	}
+	s.order.Remove(elem)
+	delete(s.entries, key)
+	s.persist()
+	return true
+}
+
+// Len возвращает число сохраненных file_id
+func (s *Store) Len() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.order.Len()
+}
+
+// Close записывает на диск изменения, ожидающие отложенной записи
+func (s *Store) Close() {
+	s.writes.Flush()
+}
+
+// persist планирует запись кэша на диск; вызывается под s.mu. Порядок использования
+// при чтении (Get) сохраняется вместе со следующим изменением
+func (s *Store) persist() {
+	if s.path == "" || s.size <= 0 {
+		return
+	}
+	s.writes.Schedule()
+}
+
+// save записывает кэш на диск
+func (s *Store) save() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	entries := make([]Entry, 0, s.order.Len())
+	for elem := s.order.Front(); elem != nil; elem = elem.Next() {
+		entries = append(entries, *elem.Value.(*Entry))
+	}
+	if err := storage.SaveJSON(s.path, entries); err != nil {
+		s.logger.Warn("Failed to persist file_id cache",
+			slog.String("file", s.path),
+			slog.Any("error", err),
+		)
+	}
+}
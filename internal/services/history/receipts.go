@@ -0,0 +1,124 @@
+package history
+
+import (
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/reelser-bot/internal/storage"
+)
+
+// Receipt сообщение с видео, опубликованное ботом в канале. По нему администратор
+// может удалить пост или изменить подпись командой /post
+type Receipt struct {
+	// ID код запроса, по которому опубликован пост
+	ID          string    `json:"id"`
+	ChatID      int64     `json:"chat_id"`
+	MessageID   int       `json:"message_id"`
+	URL         string    `json:"url"`
+	Source      string    `json:"source"`
+	DeliveredAt time.Time `json:"delivered_at"`
+}
+
+// ReceiptStore хранит последние публикации в каналах на диске
+type ReceiptStore struct {
+	logger  *slog.Logger
+	path    string
+	maxSize int
+
+	mu       sync.Mutex
+	receipts []Receipt
+}
+
+// NewReceiptStore создает хранилище публикаций и загружает сохраненные записи.
+// Хранится не более maxSize последних записей
+func NewReceiptStore(logger *slog.Logger, path string, maxSize int) *ReceiptStore {
+	s := &ReceiptStore{
+		logger:  logger,
+		path:    path,
+		maxSize: maxSize,
+	}
+
+	if path == "" {
+		return s
+	}
+
+	if err := storage.LoadJSON(path, &s.receipts); err != nil {
+		logger.Warn("Failed to load delivery receipts",
+			slog.String("file", path),
+			slog.Any("error", err),
+		)
+	}
+
+	return s
+}
+
+// Record сохраняет публикацию, вытесняя самые старые записи сверх лимита
+func (s *ReceiptStore) Record(receipt Receipt) {
+	if s.maxSize <= 0 {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.receipts = append(s.receipts, receipt)
+	if extra := len(s.receipts) - s.maxSize; extra > 0 {
+		s.receipts = append([]Receipt(nil), s.receipts[extra:]...)
+	}
+	s.persist()
+}
+
+// Get возвращает публикации по коду запроса (видео могло попасть в несколько каналов)
+func (s *ReceiptStore) Get(id string) []Receipt {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var receipts []Receipt
+	for _, receipt := range s.receipts {
+		if receipt.ID == id {
+			receipts = append(receipts, receipt)
+		}
+	}
+	return receipts
+}
+
+// Recent возвращает не больше limit последних публикаций, начиная с самой новой
+func (s *ReceiptStore) Recent(limit int) []Receipt {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	receipts := make([]Receipt, 0, min(limit, len(s.receipts)))
+	for i := len(s.receipts) - 1; i >= 0 && len(receipts) < limit; i-- {
+		receipts = append(receipts, s.receipts[i])
+	}
+	return receipts
+}
+
+// Remove удаляет запись о публикации (например, после удаления поста)
+func (s *ReceiptStore) Remove(chatID int64, messageID int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for i, receipt := range s.receipts {
+		if receipt.ChatID == chatID && receipt.MessageID == messageID {
+			s.receipts = append(s.receipts[:i], s.receipts[i+1:]...)
+			s.persist()
+			return
+		}
+	}
+}
+
+// persist записывает публикации на диск; вызывается под s.mu
+func (s *ReceiptStore) persist() {
+	if s.path == "" {
+		return
+	}
+
+	if err := storage.SaveJSON(s.path, s.receipts); err != nil {
+		s.logger.Warn("Failed to persist delivery receipts",
+			slog.String("file", s.path),
+			slog.Any("error", err),
+		)
+	}
+}
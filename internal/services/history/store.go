@@ -0,0 +1,140 @@
+package history
+
+import (
+	"log/slog"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/reelser-bot/internal/storage"
+)
+
+// Failure описывает неудавшуюся загрузку, которую можно повторить командой /replay.
+// Пароли видео не сохраняются
+type Failure struct {
+	ID        string `json:"id"`
+	ChatID    int64  `json:"chat_id"`
+	ChatType  string `json:"chat_type"`
+	UserID    int64  `json:"user_id,omitempty"`
+	URL       string `json:"url"`
+	Source    string `json:"source"`
+	Quality   string `json:"quality,omitempty"`
+	AudioOnly bool   `json:"audio_only,omitempty"`
+	Animation bool   `json:"animation,omitempty"`
+	VideoNote bool   `json:"video_note,omitempty"`
+	Document  bool   `json:"document,omitempty"`
+	Chapters  bool   `json:"chapters,omitempty"`
+	Language  string `json:"language,omitempty"`
+
+	BusinessConnectionID string `json:"business_connection_id,omitempty"`
+	// PaidStars цена платного медиа в Telegram Stars (для постов каналов)
+	PaidStars int `json:"paid_stars,omitempty"`
+
+	Error    string    `json:"error"`
+	FailedAt time.Time `json:"failed_at"`
+}
+
+// Store хранит последние неудавшиеся загрузки на диске
+type Store struct {
+	logger  *slog.Logger
+	path    string
+	maxSize int
+
+	mu       sync.Mutex
+	failures []Failure
+}
+
+// NewStore создает хранилище неудавшихся загрузок и загружает сохраненные записи.
+// Хранится не более maxSize последних записей
+func NewStore(logger *slog.Logger, path string, maxSize int) *Store {
+	s := &Store{
+		logger:  logger,
+		path:    path,
+		maxSize: maxSize,
+	}
+
+	if path == "" {
+		return s
+	}
+
+	if err := storage.LoadJSON(path, &s.failures); err != nil {
+		logger.Warn("Failed to load failure history",
+			slog.String("file", path),
+			slog.Any("error", err),
+		)
+	}
+	sort.Slice(s.failures, func(i, j int) bool { return s.failures[i].FailedAt.Before(s.failures[j].FailedAt) })
+
+	return s
+}
+
+// Record сохраняет неудавшуюся загрузку, вытесняя самые старые записи сверх лимита
+func (s *Store) Record(failure Failure) {
+	if s.maxSize <= 0 {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.failures = append(s.failures, failure)
+	if extra := len(s.failures) - s.maxSize; extra > 0 {
+		s.failures = append([]Failure(nil), s.failures[extra:]...)
+	}
+	s.persist()
+}
+
+// Get возвращает запись по ID запроса
+func (s *Store) Get(id string) (Failure, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, failure := range s.failures {
+		if failure.ID == id {
+			return failure, true
+		}
+	}
+	return Failure{}, false
+}
+
+// Between возвращает записи с моментом ошибки в интервале [from, to] в порядке времени
+func (s *Store) Between(from, to time.Time) []Failure {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var failures []Failure
+	for _, failure := range s.failures {
+		if !failure.FailedAt.Before(from) && !failure.FailedAt.After(to) {
+			failures = append(failures, failure)
+		}
+	}
+	return failures
+}
+
+// Remove удаляет запись (например, после повторной постановки в очередь)
+func (s *Store) Remove(id string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for i, failure := range s.failures {
+		if failure.ID == id {
+			s.failures = append(s.failures[:i], s.failures[i+1:]...)
+			s.persist()
+			return
+		}
+	}
+}
+
+// persist записывает историю на диск; вызывается под s.mu
+func (s *Store) persist() {
+	if s.path == "" {
+		return
+	}
+
+	if err := storage.SaveJSON(s.path, s.failures); err != nil {
+		s.logger.Warn("Failed to persist failure history",
+			slog.String("file", s.path),
+			slog.Any("error", err),
+		)
+	}
+}
@@ -0,0 +1,115 @@
+package hooks
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/reelser-bot/internal/config"
+)
+
+// PolicyInput описывает запрос, который проверяется перед постановкой в очередь
+type PolicyInput struct {
+	UserID   int64  `json:"user_id"`
+	ChatID   int64  `json:"chat_id"`
+	ChatType string `json:"chat_type"`
+	URL      string `json:"url"`
+	Platform string `json:"platform"`
+}
+
+// PolicyDecision решение политики
+type PolicyDecision struct {
+	Allow  bool   `json:"allow"`
+	Reason string `json:"reason,omitempty"`
+}
+
+// PolicyHook проверяет запросы внешним HTTP-сервисом перед постановкой в очередь
+type PolicyHook struct {
+	logger   *slog.Logger
+	url      string
+	failOpen bool
+	client   *http.Client
+}
+
+// NewPolicyHook создает хук политики приема запросов
+func NewPolicyHook(logger *slog.Logger, cfg config.HooksConfig) *PolicyHook {
+	timeout := cfg.PolicyTimeout
+	if timeout <= 0 {
+		timeout = 5 * time.Second
+	}
+
+	return &PolicyHook{
+		logger:   logger,
+		url:      strings.TrimSpace(cfg.PolicyURL),
+		failOpen: cfg.PolicyFailOpen,
+		client:   &http.Client{Timeout: timeout},
+	}
+}
+
+// Enabled возвращает true, если хук политики настроен
+func (p *PolicyHook) Enabled() bool {
+	return p != nil && p.url != ""
+}
+
+// Evaluate возвращает решение политики. Если хук недоступен, решение
+// определяется настройкой fail-open
+func (p *PolicyHook) Evaluate(ctx context.Context, input PolicyInput) PolicyDecision {
+	if !p.Enabled() {
+		return PolicyDecision{Allow: true}
+	}
+
+	decision, err := p.evaluate(ctx, input)
+	if err != nil {
+		p.logger.Warn("Policy hook failed",
+			slog.String("url", input.URL),
+			slog.Bool("fail_open", p.failOpen),
+			slog.Any("error", err),
+		)
+		return PolicyDecision{Allow: p.failOpen}
+	}
+
+	if !decision.Allow {
+		p.logger.Info("Request rejected by policy hook",
+			slog.Int64("user_id", input.UserID),
+			slog.Int64("chat_id", input.ChatID),
+			slog.String("url", input.URL),
+			slog.String("reason", decision.Reason),
+		)
+	}
+
+	return decision
+}
+
+func (p *PolicyHook) evaluate(ctx context.Context, input PolicyInput) (PolicyDecision, error) {
+	body, err := json.Marshal(input)
+	if err != nil {
+		return PolicyDecision{}, fmt.Errorf("failed to encode policy payload: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.url, bytes.NewReader(body))
+	if err != nil {
+		return PolicyDecision{}, fmt.Errorf("failed to create policy request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return PolicyDecision{}, fmt.Errorf("policy request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return PolicyDecision{}, fmt.Errorf("policy hook returned status code: %d", resp.StatusCode)
+	}
+
+	var decision PolicyDecision
+	if err := json.NewDecoder(resp.Body).Decode(&decision); err != nil {
+		return PolicyDecision{}, fmt.Errorf("failed to decode policy response: %w", err)
+	}
+	return decision, nil
+}
@@ -0,0 +1,159 @@
+package hooks
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"os"
+	"os/exec"
+	"runtime"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/reelser-bot/internal/config"
+)
+
+const (
+	// FailurePolicyIgnore — ошибка хука логируется, файл отправляется как есть
+	FailurePolicyIgnore = "ignore"
+	// FailurePolicyFail — ошибка хука прерывает отправку
+	FailurePolicyFail = "fail"
+)
+
+// PostProcessInput описывает файл, переданный в хук постобработки
+type PostProcessInput struct {
+	FilePath string `json:"file_path"`
+	URL      string `json:"url"`
+	ChatID   int64  `json:"chat_id"`
+}
+
+// PostProcessor запускает пользовательские хуки постобработки скачанного файла
+type PostProcessor struct {
+	logger        *slog.Logger
+	command       string
+	url           string
+	timeout       time.Duration
+	failurePolicy string
+	client        *http.Client
+}
+
+// NewPostProcessor создает обработчик хуков постобработки
+func NewPostProcessor(logger *slog.Logger, cfg config.HooksConfig) *PostProcessor {
+	policy := strings.ToLower(cfg.PostProcessFailurePolicy)
+	if policy != FailurePolicyFail {
+		policy = FailurePolicyIgnore
+	}
+
+	return &PostProcessor{
+		logger:        logger,
+		command:       strings.TrimSpace(cfg.PostProcessCommand),
+		url:           strings.TrimSpace(cfg.PostProcessURL),
+		timeout:       cfg.PostProcessTimeout,
+		failurePolicy: policy,
+		client:        &http.Client{},
+	}
+}
+
+// Enabled возвращает true, если настроен хотя бы один хук
+func (p *PostProcessor) Enabled() bool {
+	return p != nil && (p.command != "" || p.url != "")
+}
+
+// Run запускает настроенные хуки. Ошибка возвращается только при политике "fail"
+func (p *PostProcessor) Run(ctx context.Context, input PostProcessInput) error {
+	if !p.Enabled() {
+		return nil
+	}
+
+	if p.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, p.timeout)
+		defer cancel()
+	}
+
+	startedAt := time.Now()
+	err := p.run(ctx, input)
+	if err == nil {
+		p.logger.Info("Post-processing hook finished",
+			slog.String("file", input.FilePath),
+			slog.Duration("elapsed", time.Since(startedAt)),
+		)
+		return nil
+	}
+
+	p.logger.Warn("Post-processing hook failed",
+		slog.String("file", input.FilePath),
+		slog.String("policy", p.failurePolicy),
+		slog.Any("error", err),
+	)
+
+	if p.failurePolicy == FailurePolicyFail {
+		return fmt.Errorf("post-processing failed: %w", err)
+	}
+	return nil
+}
+
+func (p *PostProcessor) run(ctx context.Context, input PostProcessInput) error {
+	if p.command != "" {
+		if err := p.runCommand(ctx, input); err != nil {
+			return err
+		}
+	}
+	if p.url != "" {
+		if err := p.runHTTP(ctx, input); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// runCommand запускает shell-команду; путь к файлу передается через переменные окружения
+func (p *PostProcessor) runCommand(ctx context.Context, input PostProcessInput) error {
+	var cmd *exec.Cmd
+	if runtime.GOOS == "windows" {
+		cmd = exec.CommandContext(ctx, "cmd", "/C", p.command)
+	} else {
+		cmd = exec.CommandContext(ctx, "sh", "-c", p.command)
+	}
+
+	cmd.Env = append(os.Environ(),
+		"REELSER_FILE="+input.FilePath,
+		"REELSER_URL="+input.URL,
+		"REELSER_CHAT_ID="+strconv.FormatInt(input.ChatID, 10),
+	)
+
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("hook command failed: %w (%s)", err, strings.TrimSpace(string(output)))
+	}
+	return nil
+}
+
+// runHTTP отправляет описание файла POST-запросом; ответ не 2xx считается ошибкой
+func (p *PostProcessor) runHTTP(ctx context.Context, input PostProcessInput) error {
+	body, err := json.Marshal(input)
+	if err != nil {
+		return fmt.Errorf("failed to encode hook payload: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create hook request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("hook request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("hook returned status code: %d", resp.StatusCode)
+	}
+	return nil
+}
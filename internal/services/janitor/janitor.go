@@ -0,0 +1,183 @@
+package janitor
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/reelser-bot/internal/clock"
+	"github.com/reelser-bot/internal/fsys"
+)
+
+// Политики очистки временных каталогов
+const (
+	WipeNever    = "never"
+	WipeStartup  = "startup"
+	WipeShutdown = "shutdown"
+	WipeBoth     = "both"
+)
+
+// KeepFunc возвращает пути файлов, которые нельзя удалять
+// (например, файлы незавершенных отправок, ожидающих восстановления)
+type KeepFunc func() []string
+
+// Janitor очищает временные каталоги: при старте/остановке согласно политике
+// и периодически удаляет устаревшие файлы
+type Janitor struct {
+	logger   *slog.Logger
+	dirs     []string
+	policy   string
+	maxAge   time.Duration
+	interval time.Duration
+	keep     KeepFunc
+
+	fs    fsys.FS
+	clock clock.Clock
+}
+
+// Option настраивает зависимости janitor
+type Option func(*Janitor)
+
+// WithFS задает файловую систему (например, fsys.MemFS)
+func WithFS(fs fsys.FS) Option {
+	return func(j *Janitor) { j.fs = fs }
+}
+
+// WithClock задает часы для определения возраста файлов и периодической очистки
+func WithClock(c clock.Clock) Option {
+	return func(j *Janitor) { j.clock = c }
+}
+
+// New создает janitor для указанных каталогов. Пустые пути пропускаются
+func New(
+	logger *slog.Logger, policy string, maxAge, interval time.Duration, keep KeepFunc, dirs []string, opts ...Option,
+) (*Janitor, error) {
+	switch policy {
+	case WipeNever, WipeStartup, WipeShutdown, WipeBoth:
+	default:
+		return nil, fmt.Errorf("unknown temp wipe policy %q", policy)
+	}
+
+	var nonEmpty []string
+	for _, dir := range dirs {
+		if dir != "" {
+			nonEmpty = append(nonEmpty, dir)
+		}
+	}
+
+	j := &Janitor{
+		logger:   logger,
+		dirs:     nonEmpty,
+		policy:   policy,
+		maxAge:   maxAge,
+		interval: interval,
+		keep:     keep,
+		fs:       fsys.OS{},
+		clock:    clock.Real{},
+	}
+	for _, opt := range opts {
+		opt(j)
+	}
+	return j, nil
+}
+
+// OnStartup очищает каталоги, если политика требует очистки при старте
+func (j *Janitor) OnStartup() {
+	if j.policy == WipeStartup || j.policy == WipeBoth {
+		j.sweep("startup", 0)
+	}
+}
+
+// OnShutdown очищает каталоги, если политика требует очистки при остановке
+func (j *Janitor) OnShutdown() {
+	if j.policy == WipeShutdown || j.policy == WipeBoth {
+		j.sweep("shutdown", 0)
+	}
+}
+
+// Run периодически удаляет файлы старше maxAge до отмены контекста
+func (j *Janitor) Run(ctx context.Context) {
+	if j.maxAge <= 0 || j.interval <= 0 {
+		return
+	}
+
+	ticker := j.clock.NewTicker(j.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C():
+			j.sweep("periodic", j.maxAge)
+		}
+	}
+}
+
+// sweep удаляет файлы старше olderThan (0 — все файлы), кроме защищенных
+func (j *Janitor) sweep(reason string, olderThan time.Duration) {
+	keep := make(map[string]struct{})
+	if j.keep != nil {
+		for _, path := range j.keep() {
+			if abs, err := filepath.Abs(path); err == nil {
+				keep[abs] = struct{}{}
+			}
+		}
+	}
+
+	var removed, kept int
+	var freed int64
+	for _, dir := range j.dirs {
+		entries, err := j.fs.ReadDir(dir)
+		if err != nil {
+			j.logger.Warn("Failed to read temp directory",
+				slog.String("dir", dir),
+				slog.Any("error", err),
+			)
+			continue
+		}
+
+		for _, entry := range entries {
+			path := filepath.Join(dir, entry.Name())
+			info, err := entry.Info()
+			if err != nil {
+				continue
+			}
+			if olderThan > 0 && j.clock.Since(info.ModTime()) < olderThan {
+				continue
+			}
+			if abs, err := filepath.Abs(path); err == nil {
+				if _, ok := keep[abs]; ok {
+					kept++
+					continue
+				}
+			}
+			// Служебные файлы (.gitkeep и т.п.) не трогаем
+			if strings.HasPrefix(entry.Name(), ".") {
+				continue
+			}
+
+			if err := j.fs.RemoveAll(path); err != nil {
+				j.logger.Warn("Failed to remove temp file",
+					slog.String("file", path),
+					slog.Any("error", err),
+				)
+				continue
+			}
+			removed++
+			freed += info.Size()
+		}
+	}
+
+	if removed > 0 || kept > 0 {
+		j.logger.Info("Temp directories cleaned",
+			slog.String("reason", reason),
+			slog.Int("removed", removed),
+			slog.Int("kept_for_recovery", kept),
+			slog.Int64("freed_bytes", freed),
+		)
+	}
+}
@@ -0,0 +1,139 @@
+package janitor
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/reelser-bot/internal/clock"
+	"github.com/reelser-bot/internal/fsys"
+)
+
+var start = time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+
+func testLogger() *slog.Logger {
+	return slog.New(slog.NewTextHandler(io.Discard, nil))
+}
+
+// newTestFS создает каталог загрузок со старыми и свежими файлами
+func newTestFS() *fsys.MemFS {
+	fs := fsys.NewMemFS(nil)
+	fs.WriteFile("/tmp/downloads/old.mp4", []byte("old"), start.Add(-2*time.Hour))
+	fs.WriteFile("/tmp/downloads/pending.mp4", []byte("pending"), start.Add(-2*time.Hour))
+	fs.WriteFile("/tmp/downloads/fresh.mp4", []byte("fresh"), start.Add(-10*time.Minute))
+	fs.WriteFile("/tmp/downloads/.gitkeep", nil, start.Add(-24*time.Hour))
+	fs.WriteFile("/tmp/downloads/job/part.m4a", []byte("part"), start.Add(-3*time.Hour))
+	return fs
+}
+
+func exists(fs *fsys.MemFS, name string) bool {
+	_, err := fs.Stat(name)
+	return err == nil
+}
+
+func TestSweepRemovesOldFiles(t *testing.T) {
+	fs := newTestFS()
+	keep := func() []string { return []string{"/tmp/downloads/pending.mp4"} }
+	j, err := New(testLogger(), WipeNever, time.Hour, time.Minute, keep, []string{"/tmp/downloads", ""},
+		WithFS(fs), WithClock(clock.NewFake(start)))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	j.sweep("periodic", time.Hour)
+
+	for name, want := range map[string]bool{
+		"/tmp/downloads/old.mp4":      false,
+		"/tmp/downloads/job/part.m4a": false,
+		"/tmp/downloads/pending.mp4":  true,
+		"/tmp/downloads/fresh.mp4":    true,
+		"/tmp/downloads/.gitkeep":     true,
+	} {
+		if got := exists(fs, name); got != want {
+			t.Errorf("%s exists = %v, want %v", name, got, want)
+		}
+	}
+}
+
+func TestWipePolicies(t *testing.T) {
+	tests := []struct {
+		policy            string
+		startup, shutdown bool
+	}{
+		{WipeNever, false, false},
+		{WipeStartup, true, false},
+		{WipeShutdown, false, true},
+		{WipeBoth, true, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.policy, func(t *testing.T) {
+			for _, phase := range []string{"startup", "shutdown"} {
+				fs := newTestFS()
+				j, err := New(testLogger(), tt.policy, 0, 0, nil, []string{"/tmp/downloads"}, WithFS(fs), WithClock(clock.NewFake(start)))
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				want := tt.startup
+				if phase == "startup" {
+					j.OnStartup()
+				} else {
+					j.OnShutdown()
+					want = tt.shutdown
+				}
+
+				// Очистка при старте и остановке удаляет и свежие файлы, и каталоги
+				wiped := !exists(fs, "/tmp/downloads/fresh.mp4") && !exists(fs, "/tmp/downloads/job/part.m4a")
+				if wiped != want {
+					t.Errorf("%s wiped = %v, want %v", phase, wiped, want)
+				}
+				if !exists(fs, "/tmp/downloads/.gitkeep") {
+					t.Errorf("%s removed .gitkeep", phase)
+				}
+			}
+		})
+	}
+}
+
+func TestRunSweepsOnTick(t *testing.T) {
+	fs := newTestFS()
+	clk := clock.NewFake(start)
+	j, err := New(testLogger(), WipeNever, time.Hour, time.Minute, nil, []string{"/tmp/downloads"}, WithFS(fs), WithClock(clk))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		j.Run(ctx)
+		close(done)
+	}()
+	defer func() {
+		cancel()
+		<-done
+	}()
+
+	// Тикер создается в горутине Run: двигаем часы, пока очистка не сработает
+	deadline := time.Now().Add(5 * time.Second)
+	for exists(fs, "/tmp/downloads/old.mp4") {
+		if time.Now().After(deadline) {
+			t.Fatal("old file was not removed by periodic sweep")
+		}
+		clk.Advance(time.Minute)
+		time.Sleep(time.Millisecond)
+	}
+
+	// Старый файл удаляется первым же тиком, свежему до maxAge еще далеко
+	if !exists(fs, "/tmp/downloads/fresh.mp4") {
+		t.Error("fresh file removed before reaching max age")
+	}
+}
+
+func TestNewRejectsUnknownPolicy(t *testing.T) {
+	if _, err := New(testLogger(), "sometimes", 0, 0, nil, nil); err == nil {
+		t.Error("New accepted unknown policy")
+	}
+}
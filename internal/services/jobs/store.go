@@ -0,0 +1,205 @@
+package jobs
+
+import (
+	"log/slog"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/reelser-bot/internal/platform"
+	"github.com/reelser-bot/internal/storage"
+)
+
+// Job запрос на загрузку, принятый ботом, но еще не обработанный. Пароль видео не
+// сохраняется: защищенные видео после перезапуска придется запросить заново
+type Job struct {
+	ID       string `json:"id"`
+	ChatID   int64  `json:"chat_id"`
+	ChatType string `json:"chat_type"`
+	UserID   int64  `json:"user_id,omitempty"`
+	UserName string `json:"user_name,omitempty"`
+	URL      string `json:"url"`
+	Source   string `json:"source"`
+	Language string `json:"language,omitempty"`
+	Attempt  int    `json:"attempt,omitempty"`
+
+	Quality   string            `json:"quality,omitempty"`
+	Audio     bool              `json:"audio,omitempty"`
+	Animation bool              `json:"animation,omitempty"`
+	VideoNote bool              `json:"video_note,omitempty"`
+	Document  bool              `json:"document,omitempty"`
+	Chapters  bool              `json:"chapters,omitempty"`
+	NoCaption bool              `json:"no_caption,omitempty"`
+	Section   *platform.Section `json:"section,omitempty"`
+
+	BusinessConnectionID string `json:"business_connection_id,omitempty"`
+	PaidStars            int    `json:"paid_stars,omitempty"`
+	// QuotaTaken ссылка учтена в суточной квоте пользователя
+	QuotaTaken bool `json:"quota_taken,omitempty"`
+
+	// StatusMessageID сообщение "Запрос принят", которое удаляется после обработки
+	StatusMessageID int `json:"status_message_id,omitempty"`
+	// OriginalMessage сообщение со ссылкой (для удаления после доставки)
+	OriginalMessage int `json:"original_message,omitempty"`
+
+	CreatedAt time.Time `json:"created_at"`
+	// RunAt время запуска отложенной задачи (тихие часы, повтор); нулевое — сразу
+	RunAt time.Time `json:"run_at"`
+	// Starts сколько раз начиналась обработка задачи: больше нуля — задачу прервал
+	// перезапуск бота
+	Starts int `json:"starts,omitempty"`
+	// Delivered ссылки уже отправленных видео плейлиста: продолженная задача их пропускает
+	Delivered []string `json:"delivered,omitempty"`
+}
+
+// persistDelay за сколько изменения задач собираются в одну запись файла: быстрая
+// задача сохраняется, запускается и удаляется за одну запись
+const persistDelay = time.Second
+
+// Store хранит принятые, но не обработанные запросы на диске, чтобы после перезапуска
+// бот продолжил их, а не потерял молча
+type Store struct {
+	logger *slog.Logger
+	path   string
+
+	mu     sync.Mutex
+	jobs   map[string]Job
+	writes *storage.Debouncer
+}
+
+// NewStore создает хранилище задач и загружает сохраненные задачи
+func NewStore(logger *slog.Logger, path string) *Store {
+	s := &Store{
+		logger: logger,
+		path:   path,
+		jobs:   make(map[string]Job),
+	}
+	s.writes = storage.NewDebouncer(persistDelay, s.save)
+
+	if path == "" {
+		return s
+	}
+
+	var jobs []Job
+	if err := storage.LoadJSON(path, &jobs); err != nil {
+		logger.Warn("Failed to load pending jobs",
+			slog.String("file", path),
+			slog.Any("error", err),
+		)
+		return s
+	}
+	for _, job := range jobs {
+		s.jobs[job.ID] = job
+	}
+
+	return s
+}
+
+// Save сохраняет задачу или обновляет ее (например, после переноса из отложенных
+// в очередь). Нулевое CreatedAt заменяется временем первого сохранения, а счетчик
+// запусков и отправленные видео плейлиста сохраняются от прежней записи
+func (s *Store) Save(job Job) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	old, ok := s.jobs[job.ID]
+	if job.CreatedAt.IsZero() {
+		job.CreatedAt = time.Now()
+		if ok {
+			job.CreatedAt = old.CreatedAt
+		}
+	}
+	if ok {
+		job.Starts = max(job.Starts, old.Starts)
+		if job.Delivered == nil {
+			job.Delivered = old.Delivered
+		}
+	}
+	s.jobs[job.ID] = job
+	s.persist()
+}
+
+// MarkStarted отмечает, что обработка задачи началась, и считает запуски
+func (s *Store) MarkStarted(id string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	job, ok := s.jobs[id]
+	if !ok {
+		return
+	}
+	job.Starts++
+	s.jobs[id] = job
+	s.persist()
+}
+
+// MarkDelivered отмечает видео плейлиста задачи отправленным
+func (s *Store) MarkDelivered(id, itemURL string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	job, ok := s.jobs[id]
+	if !ok {
+		return
+	}
+	job.Delivered = append(job.Delivered, itemURL)
+	s.jobs[id] = job
+	s.persist()
+}
+
+// Remove удаляет обработанную или отмененную задачу
+func (s *Store) Remove(id string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.jobs[id]; !ok {
+		return
+	}
+	delete(s.jobs, id)
+	s.persist()
+}
+
+// Pending возвращает сохраненные задачи в порядке поступления
+func (s *Store) Pending() []Job {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	jobs := make([]Job, 0, len(s.jobs))
+	for _, job := range s.jobs {
+		jobs = append(jobs, job)
+	}
+	sort.Slice(jobs, func(i, j int) bool { return jobs[i].CreatedAt.Before(jobs[j].CreatedAt) })
+	return jobs
+}
+
+// Close записывает на диск изменения, ожидающие отложенной записи
+func (s *Store) Close() {
+	s.writes.Flush()
+}
+
+// persist планирует запись задач на диск; вызывается под s.mu
+func (s *Store) persist() {
+	if s.path == "" {
+		return
+	}
+	s.writes.Schedule()
+}
+
+// save записывает задачи на диск
+func (s *Store) save() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	jobs := make([]Job, 0, len(s.jobs))
+	for _, job := range s.jobs {
+		jobs = append(jobs, job)
+	}
+	sort.Slice(jobs, func(i, j int) bool { return jobs[i].CreatedAt.Before(jobs[j].CreatedAt) })
+
+	if err := storage.SaveJSON(s.path, jobs); err != nil {
+		s.logger.Warn("Failed to persist pending jobs",
+			slog.String("file", s.path),
+			slog.Any("error", err),
+		)
+	}
+}
@@ -0,0 +1,210 @@
+package pressure
+
+import (
+	"context"
+	"log/slog"
+	"runtime"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/reelser-bot/internal/clock"
+)
+
+// recoveryMargin запас, с которым метрики должны вернуться за пороги, чтобы нагрузка
+// считалась спавшей: без него режим переключался бы туда-обратно на каждой проверке
+const recoveryMargin = 0.1
+
+// Превышенные пороги (Reasons)
+const (
+	ReasonDisk   = "disk"
+	ReasonLoad   = "load"
+	ReasonMemory = "memory"
+)
+
+// Config пороги давления на систему; нулевой порог не проверяется
+type Config struct {
+	// Interval период проверки (0 — мониторинг выключен)
+	Interval time.Duration
+	// MinFreeDiskMB минимум свободного места во временных каталогах
+	MinFreeDiskMB int64
+	// MaxLoadPerCPU предельная средняя загрузка за минуту в расчете на одно ядро
+	MaxLoadPerCPU float64
+	// MinFreeMemoryMB минимум доступной памяти
+	MinFreeMemoryMB int64
+}
+
+// Sample показания системы; отрицательное значение — метрика недоступна
+type Sample struct {
+	FreeDiskMB   int64
+	Load1        float64
+	FreeMemoryMB int64
+}
+
+// Sampler снимает показания системы для каталогов dirs (свободное место — минимум по ним)
+type Sampler func(dirs []string) Sample
+
+// Monitor периодически проверяет свободное место, загрузку и память и сообщает, когда
+// система под давлением и когда давление спало
+type Monitor struct {
+	logger *slog.Logger
+	cfg    Config
+	dirs   []string
+	cpus   int
+
+	sample Sampler
+	clock  clock.Clock
+
+	mu       sync.RWMutex
+	active   bool
+	reasons  []string
+	handlers []func(active bool)
+}
+
+// Option настраивает зависимости монитора
+type Option func(*Monitor)
+
+// WithSampler задает источник показаний (например, фиксированные значения)
+func WithSampler(s Sampler) Option {
+	return func(m *Monitor) { m.sample = s }
+}
+
+// WithClock задает часы для периодических проверок
+func WithClock(c clock.Clock) Option {
+	return func(m *Monitor) { m.clock = c }
+}
+
+// New создает монитор для временных каталогов dirs. Пустые пути пропускаются
+func New(logger *slog.Logger, cfg Config, dirs []string, opts ...Option) *Monitor {
+	var nonEmpty []string
+	for _, dir := range dirs {
+		if dir != "" {
+			nonEmpty = append(nonEmpty, dir)
+		}
+	}
+
+	m := &Monitor{
+		logger: logger,
+		cfg:    cfg,
+		dirs:   nonEmpty,
+		cpus:   runtime.NumCPU(),
+		sample: readSample,
+		clock:  clock.Real{},
+	}
+	for _, opt := range opts {
+		opt(m)
+	}
+	return m
+}
+
+// OnChange подписывает fn на смену режима; fn вызывается из горутины монитора
+func (m *Monitor) OnChange(fn func(active bool)) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.handlers = append(m.handlers, fn)
+}
+
+// Active сообщает, находится ли система под давлением
+func (m *Monitor) Active() bool {
+	if m == nil {
+		return false
+	}
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	return m.active
+}
+
+// Reasons возвращает превышенные пороги: ReasonDisk, ReasonLoad, ReasonMemory
+// (пусто, если давления нет)
+func (m *Monitor) Reasons() []string {
+	if m == nil {
+		return nil
+	}
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	return append([]string(nil), m.reasons...)
+}
+
+// Run проверяет систему сразу и затем с периодом Interval до отмены контекста
+func (m *Monitor) Run(ctx context.Context) {
+	if m.cfg.Interval <= 0 {
+		return
+	}
+
+	m.Check()
+
+	ticker := m.clock.NewTicker(m.cfg.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C():
+			m.Check()
+		}
+	}
+}
+
+// Check снимает показания и переключает режим, если пороги пересечены
+func (m *Monitor) Check() {
+	sample := m.sample(m.dirs)
+
+	m.mu.Lock()
+	wasActive := m.active
+	// Под давлением пороги выхода сдвинуты на recoveryMargin
+	margin := 0.0
+	if wasActive {
+		margin = recoveryMargin
+	}
+	reasons := m.exceeded(sample, margin)
+	active := len(reasons) > 0
+	m.active = active
+	m.reasons = reasons
+	handlers := m.handlers
+	m.mu.Unlock()
+
+	if active == wasActive {
+		return
+	}
+
+	if active {
+		m.logger.Warn("System under pressure, shedding load",
+			slog.String("reason", strings.Join(reasons, ", ")),
+			slog.Int64("free_disk_mb", sample.FreeDiskMB),
+			slog.Float64("load1", sample.Load1),
+			slog.Int64("free_memory_mb", sample.FreeMemoryMB),
+		)
+	} else {
+		m.logger.Info("System pressure relieved, restoring full capacity",
+			slog.Int64("free_disk_mb", sample.FreeDiskMB),
+			slog.Float64("load1", sample.Load1),
+			slog.Int64("free_memory_mb", sample.FreeMemoryMB),
+		)
+	}
+	for _, fn := range handlers {
+		fn(active)
+	}
+}
+
+// exceeded возвращает превышенные пороги; margin ужесточает пороги (доля от порога),
+// пока система под давлением
+func (m *Monitor) exceeded(s Sample, margin float64) []string {
+	var reasons []string
+	diskLimit := float64(m.cfg.MinFreeDiskMB) * (1 + margin)
+	if m.cfg.MinFreeDiskMB > 0 && s.FreeDiskMB >= 0 && float64(s.FreeDiskMB) < diskLimit {
+		reasons = append(reasons, ReasonDisk)
+	}
+	loadLimit := m.cfg.MaxLoadPerCPU * float64(m.cpus) * (1 - margin)
+	if m.cfg.MaxLoadPerCPU > 0 && s.Load1 >= 0 && s.Load1 > loadLimit {
+		reasons = append(reasons, ReasonLoad)
+	}
+	memoryLimit := float64(m.cfg.MinFreeMemoryMB) * (1 + margin)
+	if m.cfg.MinFreeMemoryMB > 0 && s.FreeMemoryMB >= 0 && float64(s.FreeMemoryMB) < memoryLimit {
+		reasons = append(reasons, ReasonMemory)
+	}
+	return reasons
+}
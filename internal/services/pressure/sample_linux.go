@@ -0,0 +1,76 @@
+//go:build linux
+
+package pressure
+
+import (
+	"bufio"
+	"os"
+	"strconv"
+	"strings"
+	"syscall"
+)
+
+// readSample читает показания из statfs, /proc/loadavg и /proc/meminfo
+func readSample(dirs []string) Sample {
+	return Sample{
+		FreeDiskMB:   freeDiskMB(dirs),
+		Load1:        load1(),
+		FreeMemoryMB: freeMemoryMB(),
+	}
+}
+
+// freeDiskMB возвращает наименьшее свободное место среди каталогов
+func freeDiskMB(dirs []string) int64 {
+	free := int64(-1)
+	for _, dir := range dirs {
+		var st syscall.Statfs_t
+		if err := syscall.Statfs(dir, &st); err != nil {
+			continue
+		}
+		mb := int64(st.Bavail) * int64(st.Bsize) / (1024 * 1024)
+		if free < 0 || mb < free {
+			free = mb
+		}
+	}
+	return free
+}
+
+// load1 возвращает среднюю загрузку за минуту
+func load1() float64 {
+	data, err := os.ReadFile("/proc/loadavg")
+	if err != nil {
+		return -1
+	}
+	fields := strings.Fields(string(data))
+	if len(fields) == 0 {
+		return -1
+	}
+	load, err := strconv.ParseFloat(fields[0], 64)
+	if err != nil {
+		return -1
+	}
+	return load
+}
+
+// freeMemoryMB возвращает доступную память (MemAvailable)
+func freeMemoryMB() int64 {
+	file, err := os.Open("/proc/meminfo")
+	if err != nil {
+		return -1
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 2 || fields[0] != "MemAvailable:" {
+			continue
+		}
+		kb, err := strconv.ParseInt(fields[1], 10, 64)
+		if err != nil {
+			return -1
+		}
+		return kb / 1024
+	}
+	return -1
+}
@@ -0,0 +1,8 @@
+//go:build !linux
+
+package pressure
+
+// readSample вне Linux показания недоступны, и давление не определяется
+func readSample([]string) Sample {
+	return Sample{FreeDiskMB: -1, Load1: -1, FreeMemoryMB: -1}
+}
@@ -0,0 +1,172 @@
+package probe
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net"
+	"os/exec"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Check описывает одну стартовую проверку
+type Check struct {
+	Name string
+	Run  func(ctx context.Context) error
+}
+
+// Result содержит результат одной проверки
+type Result struct {
+	Name    string
+	Latency time.Duration
+	Err     error
+}
+
+// Report содержит результаты всех проверок
+type Report struct {
+	Results  []Result
+	Attempts int
+}
+
+// OK возвращает true, если все проверки прошли
+func (r Report) OK() bool {
+	for _, res := range r.Results {
+		if res.Err != nil {
+			return false
+		}
+	}
+	return true
+}
+
+// Prober выполняет стартовые проверки окружения
+type Prober struct {
+	logger   *slog.Logger
+	checks   []Check
+	interval time.Duration
+}
+
+// NewProber создает новый набор стартовых проверок
+func NewProber(logger *slog.Logger, interval time.Duration, checks ...Check) *Prober {
+	return &Prober{
+		logger:   logger,
+		checks:   checks,
+		interval: interval,
+	}
+}
+
+// Run выполняет все проверки параллельно один раз
+func (p *Prober) Run(ctx context.Context) Report {
+	results := make([]Result, len(p.checks))
+
+	var wg sync.WaitGroup
+	for i, check := range p.checks {
+		wg.Add(1)
+		go func(i int, check Check) {
+			defer wg.Done()
+
+			startedAt := time.Now()
+			err := check.Run(ctx)
+			results[i] = Result{
+				Name:    check.Name,
+				Latency: time.Since(startedAt),
+				Err:     err,
+			}
+		}(i, check)
+	}
+	wg.Wait()
+
+	return Report{Results: results, Attempts: 1}
+}
+
+// WaitReady повторяет проверки, пока все не пройдут или не истечет контекст
+func (p *Prober) WaitReady(ctx context.Context) Report {
+	attempts := 0
+	for {
+		report := p.Run(ctx)
+		attempts++
+		report.Attempts = attempts
+
+		if report.OK() || ctx.Err() != nil {
+			return report
+		}
+
+		p.logger.Warn("Startup probes failed, retrying",
+			slog.Int("attempt", attempts),
+			slog.Duration("retry_in", p.interval),
+		)
+
+		select {
+		case <-ctx.Done():
+			return report
+		case <-time.After(p.interval):
+		}
+	}
+}
+
+// Log пишет структурированный стартовый отчет
+func (p *Prober) Log(report Report) {
+	attrs := []any{
+		slog.Bool("ok", report.OK()),
+		slog.Int("attempts", report.Attempts),
+	}
+	for _, res := range report.Results {
+		status := "ok"
+		if res.Err != nil {
+			status = res.Err.Error()
+		}
+		attrs = append(attrs, slog.Group(res.Name,
+			slog.Duration("latency", res.Latency),
+			slog.String("status", status),
+		))
+	}
+
+	if report.OK() {
+		p.logger.Info("Startup report", attrs...)
+	} else {
+		p.logger.Warn("Startup report", attrs...)
+	}
+}
+
+// DNSCheck проверяет разрешение DNS-имени хоста
+func DNSCheck(host string) Check {
+	return Check{
+		Name: "dns_" + strings.ReplaceAll(host, ".", "_"),
+		Run: func(ctx context.Context) error {
+			if _, err := net.DefaultResolver.LookupHost(ctx, host); err != nil {
+				return fmt.Errorf("failed to resolve %s: %w", host, err)
+			}
+			return nil
+		},
+	}
+}
+
+// BinaryVersion находит утилиту (путь или имя в PATH) и возвращает ее полный путь
+// и первую строку вывода команды версии
+func BinaryVersion(ctx context.Context, binary string, args ...string) (string, string, error) {
+	path, err := exec.LookPath(binary)
+	if err != nil {
+		return "", "", fmt.Errorf("%s not found: %w", binary, err)
+	}
+
+	out, err := exec.CommandContext(ctx, path, args...).CombinedOutput()
+	if err != nil {
+		return path, "", fmt.Errorf("%s failed: %w (%s)", path, err, strings.TrimSpace(string(out)))
+	}
+	version, _, _ := strings.Cut(strings.TrimSpace(string(out)), "\n")
+	return path, version, nil
+}
+
+// CommandCheck проверяет, что внешняя утилита запускается
+func CommandCheck(name, binary string, args ...string) Check {
+	return Check{
+		Name: name,
+		Run: func(ctx context.Context) error {
+			if out, err := exec.CommandContext(ctx, binary, args...).CombinedOutput(); err != nil {
+				return fmt.Errorf("%s failed: %w (%s)", binary, err, strings.TrimSpace(string(out)))
+			}
+			return nil
+		},
+	}
+}
@@ -0,0 +1,319 @@
+package resolver
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/reelser-bot/internal/platform"
+)
+
+const (
+	// expansionTTL сколько помнить раскрытие короткой ссылки
+	expansionTTL = time.Hour
+	// maxExpansions сколько раскрытий хранить в памяти
+	maxExpansions = 1024
+	// maxRedirects сколько редиректов проходить при раскрытии короткой ссылки
+	maxRedirects = 10
+)
+
+// mirrorHosts сопоставляет домены-зеркала (embed-фиксеры) каноническим доменам платформ
+var mirrorHosts = map[string]string{
+	"twitter.com":        "x.com",
+	"mobile.twitter.com": "x.com",
+	"mobile.x.com":       "x.com",
+	"fxtwitter.com":      "x.com",
+	"vxtwitter.com":      "x.com",
+	"fixupx.com":         "x.com",
+	"fixvx.com":          "x.com",
+	"twittpr.com":        "x.com",
+	"d.fxtwitter.com":    "x.com",
+
+	"instagram.com":     "www.instagram.com",
+	"ddinstagram.com":   "www.instagram.com",
+	"d.ddinstagram.com": "www.instagram.com",
+	"kkinstagram.com":   "www.instagram.com",
+	"instagramez.com":   "www.instagram.com",
+
+	"tiktok.com":   "www.tiktok.com",
+	"vxtiktok.com": "www.tiktok.com",
+	"tiktxk.com":   "www.tiktok.com",
+	"tnktok.com":   "www.tiktok.com",
+	"tfxktok.com":  "www.tiktok.com",
+
+	"bilibili.com":   "www.bilibili.com",
+	"m.bilibili.com": "www.bilibili.com",
+
+	"youtube.com":   "www.youtube.com",
+	"m.youtube.com": "www.youtube.com",
+}
+
+// keepParams для доменов, ссылки которых обрастают трекинговыми параметрами,
+// перечисляет параметры запроса, которые нужно сохранить; остальные удаляются
+var keepParams = map[string][]string{
+	"www.bilibili.com": {"p", "t"},
+}
+
+// trackingParams параметры запроса, которые добавляют приложения и рекламные системы
+// при шеринге; на содержимое не влияют и удаляются у всех ссылок
+var trackingParams = map[string]bool{
+	"fbclid":   true,
+	"gclid":    true,
+	"igsh":     true,
+	"igshid":   true,
+	"mibextid": true,
+	"ref_src":  true,
+	"ref_url":  true,
+}
+
+// hostTrackingParams трекинговые параметры отдельных платформ: у других сайтов
+// параметры с теми же именами могут быть значимыми (например, t у YouTube — время)
+var hostTrackingParams = map[string][]string{
+	"www.youtube.com": {"si", "feature", "pp"},
+	"x.com":           {"s", "t"},
+	"www.tiktok.com": {
+		"_r", "_t", "is_from_webapp", "sender_device", "share_app_id", "share_link_id",
+		"social_share_type", "source", "u_code", "user_id", "sec_user_id", "timestamp",
+		"tt_from", "preview_pb", "web_id", "checksum",
+	},
+	"www.instagram.com": {"utm_source", "img_index"},
+}
+
+// shortenerHosts домены сокращателей ссылок, которые раскрываются HTTP-запросом
+var shortenerHosts = map[string]bool{
+	"t.co":          true,
+	"b23.tv":        true,
+	"bili2233.cn":   true,
+	"l.likee.video": true,
+	"vm.tiktok.com": true,
+	"vt.tiktok.com": true,
+	"pin.it":        true,
+	"bit.ly":        true,
+	"tinyurl.com":   true,
+	"is.gd":         true,
+	"cutt.ly":       true,
+}
+
+// Resolver раскрывает короткие ссылки и приводит URL к каноническому виду
+type Resolver struct {
+	logger *slog.Logger
+	client *http.Client
+
+	// expansions недавно раскрытые короткие ссылки, чтобы каждый этап обработки
+	// одного запроса не повторял HTTP-запрос к сокращателю
+	mu         sync.Mutex
+	expansions map[string]expansion
+}
+
+// expansion раскрытая короткая ссылка
+type expansion struct {
+	url       string
+	expiresAt time.Time
+}
+
+// New создает новый resolver
+func New(logger *slog.Logger) *Resolver {
+	return &Resolver{
+		logger:     logger,
+		client:     &http.Client{Timeout: 10 * time.Second, CheckRedirect: checkRedirect},
+		expansions: make(map[string]expansion),
+	}
+}
+
+// Resolve раскрывает сокращенную ссылку (если это сокращатель) и канонизирует ее.
+// При ошибке раскрытия возвращается канонизированный исходный URL
+func (r *Resolver) Resolve(ctx context.Context, rawURL string) string {
+	if isShortener(rawURL) {
+		if expanded, ok := r.cached(rawURL); ok {
+			return expanded
+		}
+
+		expanded, err := r.expand(ctx, rawURL)
+		if err != nil {
+			r.logger.Warn("Failed to expand short URL",
+				slog.String("url", rawURL),
+				slog.Any("error", err),
+			)
+			return Canonicalize(rawURL)
+		}
+
+		canonical := Canonicalize(expanded)
+		r.remember(rawURL, canonical)
+		return canonical
+	}
+
+	return Canonicalize(rawURL)
+}
+
+// Canonical возвращает канонический URL без сетевых запросов: для короткой ссылки —
+// ее раскрытие, если оно уже известно. Подходит для ключей кэшей, чтобы короткая
+// и полная ссылки на одно видео совпадали
+func (r *Resolver) Canonical(rawURL string) string {
+	if isShortener(rawURL) {
+		if expanded, ok := r.cached(rawURL); ok {
+			return expanded
+		}
+	}
+	return Canonicalize(rawURL)
+}
+
+// cached возвращает сохраненное раскрытие короткой ссылки
+func (r *Resolver) cached(shortURL string) (string, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	entry, ok := r.expansions[shortURL]
+	if !ok || time.Now().After(entry.expiresAt) {
+		return "", false
+	}
+	return entry.url, true
+}
+
+// remember сохраняет раскрытие короткой ссылки, вытесняя устаревшие записи
+func (r *Resolver) remember(shortURL, expanded string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := time.Now()
+	if len(r.expansions) >= maxExpansions {
+		for key, entry := range r.expansions {
+			if now.After(entry.expiresAt) {
+				delete(r.expansions, key)
+			}
+		}
+	}
+	// Если устаревших нет, освобождаем место под новую запись
+	for key := range r.expansions {
+		if len(r.expansions) < maxExpansions {
+			break
+		}
+		delete(r.expansions, key)
+	}
+	r.expansions[shortURL] = expansion{url: expanded, expiresAt: now.Add(expansionTTL)}
+}
+
+// expand следует редиректам и возвращает конечный URL.
+// Некоторые сокращатели (b23.tv) не отвечают на HEAD, тогда повторяем запрос через GET
+func (r *Resolver) expand(ctx context.Context, rawURL string) (string, error) {
+	expanded, err := r.follow(ctx, http.MethodHead, rawURL)
+	if err != nil || isShortener(expanded) {
+		return r.follow(ctx, http.MethodGet, rawURL)
+	}
+	return expanded, nil
+}
+
+// follow выполняет запрос указанным методом и возвращает URL после редиректов
+func (r *Resolver) follow(ctx context.Context, method, rawURL string) (string, error) {
+	req, err := http.NewRequestWithContext(ctx, method, rawURL, nil)
+	if err != nil {
+		return "", err
+	}
+
+	resp, err := r.client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode >= http.StatusBadRequest {
+		return "", fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+	}
+
+	return resp.Request.URL.String(), nil
+}
+
+// checkRedirect не дает сокращателю увести запрос бота во внутреннюю сеть: редирект
+// на loopback, частные и link-local адреса (в том числе метаданные облаков) прерывает
+// раскрытие, и ссылка остается короткой
+func checkRedirect(req *http.Request, via []*http.Request) error {
+	if len(via) >= maxRedirects {
+		return fmt.Errorf("stopped after %d redirects", maxRedirects)
+	}
+	if req.URL.Scheme != "http" && req.URL.Scheme != "https" {
+		return fmt.Errorf("redirect to unsupported scheme %q", req.URL.Scheme)
+	}
+	return platform.CheckPublicURL(req.Context(), req.URL.String())
+}
+
+// Canonicalize приводит URL к каноническому виду до определения платформы и
+// использования в ключах кэшей: заменяет домены-зеркала каноническими, раскрывает
+// youtu.be без сетевого запроса и удаляет трекинговые параметры
+func Canonicalize(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil || u.Host == "" {
+		return rawURL
+	}
+
+	host := strings.TrimPrefix(strings.ToLower(u.Hostname()), "www.")
+	changed := false
+
+	if canonical, ok := mirrorHosts[host]; ok {
+		u.Host = canonical
+		u.Scheme = "https"
+		changed = true
+	}
+
+	// youtu.be/<id>?t=30 → www.youtube.com/watch?v=<id>&t=30
+	if id := strings.Trim(u.Path, "/"); host == "youtu.be" && id != "" && !strings.Contains(id, "/") {
+		query := u.Query()
+		query.Set("v", id)
+		u.Scheme, u.Host, u.Path = "https", "www.youtube.com", "/watch"
+		u.RawQuery = query.Encode()
+		changed = true
+	}
+
+	if u.RawQuery != "" && stripParams(u) {
+		changed = true
+	}
+
+	if !changed {
+		return rawURL
+	}
+	return u.String()
+}
+
+// stripParams удаляет трекинговые параметры, а для доменов из keepParams — все,
+// кроме перечисленных. Возвращает true, если запрос изменился
+func stripParams(u *url.URL) bool {
+	query := u.Query()
+	host := strings.ToLower(u.Host)
+
+	filtered := url.Values{}
+	if keep, ok := keepParams[host]; ok {
+		for _, name := range keep {
+			if value := query.Get(name); value != "" {
+				filtered.Set(name, value)
+			}
+		}
+	} else {
+		drop := make(map[string]bool)
+		for _, name := range hostTrackingParams[host] {
+			drop[name] = true
+		}
+		for name, values := range query {
+			if trackingParams[name] || drop[name] || strings.HasPrefix(name, "utm_") {
+				continue
+			}
+			filtered[name] = values
+		}
+	}
+
+	if len(filtered) == len(query) {
+		return false
+	}
+	u.RawQuery = filtered.Encode()
+	return true
+}
+
+func isShortener(rawURL string) bool {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return false
+	}
+	return shortenerHosts[strings.TrimPrefix(strings.ToLower(u.Hostname()), "www.")]
+}
@@ -0,0 +1,32 @@
+package resolver
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/reelser-bot/internal/platform"
+)
+
+func TestFollowRejectsRedirectToPrivateAddress(t *testing.T) {
+	// Тестовый сервер слушает loopback: сокращатель уводит запрос на него же
+	mux := http.NewServeMux()
+	mux.HandleFunc("/short", func(w http.ResponseWriter, req *http.Request) {
+		http.Redirect(w, req, "/internal", http.StatusFound)
+	})
+	mux.HandleFunc("/internal", func(http.ResponseWriter, *http.Request) {
+		t.Error("redirect to a loopback address was followed")
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	r := New(slog.New(slog.NewTextHandler(io.Discard, nil)))
+	_, err := r.follow(context.Background(), http.MethodGet, server.URL+"/short")
+	if !errors.Is(err, platform.ErrPrivateAddress) {
+		t.Fatalf("follow error = %v, want ErrPrivateAddress", err)
+	}
+}
@@ -0,0 +1,86 @@
+package setup
+
+import (
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/reelser-bot/internal/storage"
+)
+
+// Result настройки, выбранные администратором при первом запуске бота
+type Result struct {
+	// AdminID пользователь, прошедший настройку и ставший администратором
+	AdminID int64 `json:"admin_id"`
+	// AuthEnabled включен ли доступ только по токену
+	AuthEnabled bool `json:"auth_enabled"`
+	// MaxVideoSizeMB лимит размера видео; 0 — лимит Bot API
+	MaxVideoSizeMB int `json:"max_video_size_mb"`
+	// Quality качество по умолчанию (platform.QualityHD, platform.QualitySD или пусто)
+	Quality     string    `json:"quality,omitempty"`
+	CompletedAt time.Time `json:"completed_at"`
+}
+
+// Store хранит результат первичной настройки на диске
+type Store struct {
+	logger *slog.Logger
+	path   string
+
+	mu     sync.RWMutex
+	result *Result
+}
+
+// NewStore создает хранилище и загружает сохраненный результат настройки
+func NewStore(logger *slog.Logger, path string) *Store {
+	s := &Store{
+		logger: logger,
+		path:   path,
+	}
+
+	if path == "" {
+		return s
+	}
+
+	var result Result
+	if err := storage.LoadJSON(path, &result); err != nil {
+		logger.Warn("Failed to load setup result",
+			slog.String("file", path),
+			slog.Any("error", err),
+		)
+		return s
+	}
+	if result.AdminID != 0 {
+		s.result = &result
+	}
+
+	return s
+}
+
+// Result возвращает сохраненный результат настройки, если она уже пройдена
+func (s *Store) Result() (Result, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if s.result == nil {
+		return Result{}, false
+	}
+	return *s.result, true
+}
+
+// Save сохраняет результат настройки
+func (s *Store) Save(result Result) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.result = &result
+
+	if s.path == "" {
+		return
+	}
+	if err := storage.SaveJSON(s.path, result); err != nil {
+		s.logger.Warn("Failed to persist setup result",
+			slog.String("file", s.path),
+			slog.Any("error", err),
+		)
+	}
+}
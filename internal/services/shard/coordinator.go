@@ -0,0 +1,168 @@
+package shard
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/reelser-bot/internal/clock"
+	"github.com/reelser-bot/internal/storage"
+)
+
+// Member экземпляр бота, участвующий в шардировании
+type Member struct {
+	ID string `json:"id"`
+	// URL внутренний адрес, на который другие экземпляры пересылают апдейты его чатов
+	URL    string    `json:"url"`
+	SeenAt time.Time `json:"seen_at"`
+}
+
+// Coordinator поддерживает список живых экземпляров в общем каталоге: каждый
+// экземпляр периодически обновляет свой файл, а файлы без обновления дольше ttl
+// не попадают в кольцо
+type Coordinator struct {
+	logger    *slog.Logger
+	dir       string
+	self      Member
+	heartbeat time.Duration
+	ttl       time.Duration
+	clock     clock.Clock
+
+	mu   sync.RWMutex
+	ring *Ring
+	ids  []string
+}
+
+// NewCoordinator создает координатор экземпляра self; dir — каталог, общий для всех экземпляров
+func NewCoordinator(logger *slog.Logger, dir string, self Member, heartbeat, ttl time.Duration, clk clock.Clock) *Coordinator {
+	return &Coordinator{
+		logger:    logger,
+		dir:       dir,
+		self:      self,
+		heartbeat: heartbeat,
+		ttl:       ttl,
+		clock:     clk,
+		ring:      NewRing([]Member{self}),
+		ids:       []string{self.ID},
+	}
+}
+
+// Self возвращает текущий экземпляр
+func (c *Coordinator) Self() Member {
+	return c.self
+}
+
+// Owner возвращает экземпляр, обрабатывающий чат
+func (c *Coordinator) Owner(chatID int64) Member {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	if member, ok := c.ring.Owner(chatID); ok {
+		return member
+	}
+	return c.self
+}
+
+// Run регистрирует экземпляр и обновляет кольцо каждые heartbeat, пока не отменен ctx.
+// При остановке экземпляр удаляет свой файл, и его чаты сразу переходят к остальным
+func (c *Coordinator) Run(ctx context.Context) {
+	c.refresh()
+
+	ticker := c.clock.NewTicker(c.heartbeat)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			if err := os.Remove(c.memberFile(c.self.ID)); err != nil && !errors.Is(err, os.ErrNotExist) {
+				c.logger.Warn("Failed to leave shard ring", slog.Any("error", err))
+			}
+			return
+		case <-ticker.C():
+			c.refresh()
+		}
+	}
+}
+
+// refresh записывает свой heartbeat и перестраивает кольцо по живым экземплярам
+func (c *Coordinator) refresh() {
+	self := c.self
+	self.SeenAt = c.clock.Now()
+	if err := storage.SaveJSON(c.memberFile(self.ID), self); err != nil {
+		c.logger.Warn("Failed to write shard heartbeat", slog.String("dir", c.dir), slog.Any("error", err))
+	}
+
+	members, err := c.liveMembers()
+	if err != nil {
+		c.logger.Warn("Failed to list shard members", slog.String("dir", c.dir), slog.Any("error", err))
+		return
+	}
+
+	ids := make([]string, len(members))
+	for i, member := range members {
+		ids[i] = member.ID
+	}
+
+	c.mu.Lock()
+	changed := !equal(c.ids, ids)
+	c.ring = NewRing(members)
+	c.ids = ids
+	c.mu.Unlock()
+
+	if changed {
+		c.logger.Info("Shard ring changed", slog.Any("members", ids))
+	}
+}
+
+// liveMembers читает файлы экземпляров и возвращает обновлявшиеся не дольше ttl назад.
+// Текущий экземпляр входит в список всегда
+func (c *Coordinator) liveMembers() ([]Member, error) {
+	files, err := filepath.Glob(filepath.Join(c.dir, "*.json"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list %s: %w", c.dir, err)
+	}
+
+	now := c.clock.Now()
+	members := []Member{c.self}
+	for _, file := range files {
+		var member Member
+		if err := storage.LoadJSON(file, &member); err != nil {
+			c.logger.Warn("Failed to read shard member", slog.String("file", file), slog.Any("error", err))
+			continue
+		}
+		if member.ID == "" || member.ID == c.self.ID || member.URL == "" {
+			continue
+		}
+		if now.Sub(member.SeenAt) > c.ttl {
+			continue
+		}
+		members = append(members, member)
+	}
+
+	sort.Slice(members, func(i, j int) bool { return members[i].ID < members[j].ID })
+	return members, nil
+}
+
+// memberFile путь к файлу экземпляра в общем каталоге
+func (c *Coordinator) memberFile(id string) string {
+	return filepath.Join(c.dir, id+".json")
+}
+
+// equal сравнивает отсортированные списки ID
+func equal(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,146 @@
+package shard
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/reelser-bot/internal/clock"
+	"github.com/reelser-bot/internal/storage"
+)
+
+func testCoordinator(t *testing.T, clk *clock.Fake) *Coordinator {
+	t.Helper()
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	self := Member{ID: "self", URL: "http://self:8443/hook"}
+	return NewCoordinator(logger, t.TempDir(), self, 10*time.Second, 30*time.Second, clk)
+}
+
+// writeMember регистрирует экземпляр в каталоге так, как это делает его координатор
+func writeMember(t *testing.T, dir string, member Member) {
+	t.Helper()
+	if err := storage.SaveJSON(filepath.Join(dir, member.ID+".json"), member); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// owners возвращает множество владельцев чатов 0..999
+func owners(c *Coordinator) map[string]bool {
+	ids := make(map[string]bool)
+	for chatID := int64(0); chatID < 1000; chatID++ {
+		ids[c.Owner(chatID).ID] = true
+	}
+	return ids
+}
+
+func TestCoordinatorJoinsLiveMembers(t *testing.T) {
+	clk := clock.NewFake(time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC))
+	c := testCoordinator(t, clk)
+
+	writeMember(t, c.dir, Member{ID: "peer", URL: "http://peer:8443/hook", SeenAt: clk.Now()})
+	c.refresh()
+
+	got := owners(c)
+	if !got["self"] || !got["peer"] || len(got) != 2 {
+		t.Fatalf("owners = %v, want self and peer", got)
+	}
+
+	var self Member
+	if err := storage.LoadJSON(c.memberFile("self"), &self); err != nil {
+		t.Fatalf("heartbeat file: %v", err)
+	}
+	if !self.SeenAt.Equal(clk.Now()) || self.URL != "http://self:8443/hook" {
+		t.Errorf("heartbeat = %+v", self)
+	}
+}
+
+func TestCoordinatorDropsExpiredMembers(t *testing.T) {
+	clk := clock.NewFake(time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC))
+	c := testCoordinator(t, clk)
+
+	writeMember(t, c.dir, Member{ID: "peer", URL: "http://peer:8443/hook", SeenAt: clk.Now()})
+	c.refresh()
+
+	clk.Advance(30 * time.Second)
+	c.refresh()
+	if !owners(c)["peer"] {
+		t.Fatal("peer dropped at exactly the TTL")
+	}
+
+	clk.Advance(time.Second)
+	c.refresh()
+	if got := owners(c); got["peer"] || !got["self"] {
+		t.Fatalf("owners after TTL = %v, want only self", got)
+	}
+}
+
+func TestCoordinatorSkipsInvalidMembers(t *testing.T) {
+	clk := clock.NewFake(time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC))
+	c := testCoordinator(t, clk)
+
+	writeMember(t, c.dir, Member{ID: "no-url", SeenAt: clk.Now()})
+	if err := os.WriteFile(filepath.Join(c.dir, "broken.json"), []byte("{"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	c.refresh()
+
+	if got := owners(c); len(got) != 1 || !got["self"] {
+		t.Errorf("owners = %v, want only self", got)
+	}
+}
+
+func TestCoordinatorRunLeavesRingOnShutdown(t *testing.T) {
+	clk := clock.NewFake(time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC))
+	c := testCoordinator(t, clk)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		c.Run(ctx)
+		close(done)
+	}()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		if _, err := os.Stat(c.memberFile("self")); err == nil {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("Run did not register the instance")
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	cancel()
+	<-done
+	if _, err := os.Stat(c.memberFile("self")); !os.IsNotExist(err) {
+		t.Errorf("member file after shutdown: %v, want removed", err)
+	}
+}
+
+func TestSignature(t *testing.T) {
+	body := []byte(`{"update_id":1}`)
+	signature := Sign("shared-secret-123", body)
+
+	if !Verify("shared-secret-123", body, signature) {
+		t.Error("valid signature rejected")
+	}
+	for name, tc := range map[string]struct {
+		secret, signature string
+		body              []byte
+	}{
+		"wrong secret":  {"other-secret-1234", signature, body},
+		"tampered body": {"shared-secret-123", signature, []byte(`{"update_id":2}`)},
+		"missing":       {"shared-secret-123", "", body},
+		"not hex":       {"shared-secret-123", "zz", body},
+		"empty secret":  {"", Sign("", body), body},
+	} {
+		if Verify(tc.secret, tc.body, tc.signature) {
+			t.Errorf("%s: signature accepted", name)
+		}
+	}
+}
@@ -0,0 +1,54 @@
+package shard
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"sort"
+	"strconv"
+)
+
+// virtualNodes количество точек каждого экземпляра на кольце: сглаживает
+// распределение чатов при небольшом числе экземпляров
+const virtualNodes = 64
+
+// Ring кольцо консистентного хеширования: при добавлении или уходе экземпляра
+// переезжают только чаты, попадавшие на его участки кольца
+type Ring struct {
+	points  []uint64
+	members map[uint64]Member
+}
+
+// NewRing строит кольцо из экземпляров
+func NewRing(members []Member) *Ring {
+	r := &Ring{members: make(map[uint64]Member, len(members)*virtualNodes)}
+	for _, member := range members {
+		for i := 0; i < virtualNodes; i++ {
+			point := hash(member.ID + "#" + strconv.Itoa(i))
+			r.points = append(r.points, point)
+			r.members[point] = member
+		}
+	}
+	sort.Slice(r.points, func(i, j int) bool { return r.points[i] < r.points[j] })
+	return r
+}
+
+// Owner возвращает экземпляр, обрабатывающий чат; false — кольцо пустое
+func (r *Ring) Owner(chatID int64) (Member, bool) {
+	if len(r.points) == 0 {
+		return Member{}, false
+	}
+
+	key := hash(strconv.FormatInt(chatID, 10))
+	i := sort.Search(len(r.points), func(i int) bool { return r.points[i] >= key })
+	if i == len(r.points) {
+		i = 0
+	}
+	return r.members[r.points[i]], true
+}
+
+// hash первые 8 байт SHA-256 от строки. FNV для коротких похожих ключей ("a#1", "a#2")
+// дает близкие значения, и точки экземпляра собирались на одном участке кольца
+func hash(s string) uint64 {
+	sum := sha256.Sum256([]byte(s))
+	return binary.BigEndian.Uint64(sum[:8])
+}
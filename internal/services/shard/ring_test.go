@@ -0,0 +1,59 @@
+package shard
+
+import (
+	"testing"
+)
+
+func TestRingOwnerIsStable(t *testing.T) {
+	members := []Member{{ID: "a"}, {ID: "b"}, {ID: "c"}}
+	ring := NewRing(members)
+	// Порядок экземпляров не влияет на распределение
+	reversed := NewRing([]Member{members[2], members[1], members[0]})
+
+	for chatID := int64(-1000); chatID < 1000; chatID++ {
+		owner, ok := ring.Owner(chatID)
+		if !ok {
+			t.Fatalf("Owner(%d) on a non-empty ring returned false", chatID)
+		}
+		if again, _ := reversed.Owner(chatID); again.ID != owner.ID {
+			t.Fatalf("Owner(%d) = %s, with reversed members %s", chatID, owner.ID, again.ID)
+		}
+	}
+}
+
+func TestRingSpreadsChatsAcrossMembers(t *testing.T) {
+	ring := NewRing([]Member{{ID: "a"}, {ID: "b"}, {ID: "c"}})
+
+	counts := make(map[string]int)
+	for chatID := int64(0); chatID < 3000; chatID++ {
+		owner, _ := ring.Owner(chatID)
+		counts[owner.ID]++
+	}
+	for _, id := range []string{"a", "b", "c"} {
+		if counts[id] < 500 {
+			t.Errorf("member %s owns %d of 3000 chats, distribution is too skewed: %v", id, counts[id], counts)
+		}
+	}
+}
+
+func TestRingMovesOnlyChatsOfLeavingMember(t *testing.T) {
+	before := NewRing([]Member{{ID: "a"}, {ID: "b"}, {ID: "c"}})
+	after := NewRing([]Member{{ID: "a"}, {ID: "c"}})
+
+	for chatID := int64(0); chatID < 3000; chatID++ {
+		was, _ := before.Owner(chatID)
+		now, _ := after.Owner(chatID)
+		if was.ID != "b" && now.ID != was.ID {
+			t.Fatalf("chat %d moved from %s to %s although %s stayed", chatID, was.ID, now.ID, was.ID)
+		}
+		if now.ID == "b" {
+			t.Fatalf("chat %d is still owned by the removed member", chatID)
+		}
+	}
+}
+
+func TestRingEmpty(t *testing.T) {
+	if _, ok := NewRing(nil).Owner(42); ok {
+		t.Error("Owner on an empty ring returned true")
+	}
+}
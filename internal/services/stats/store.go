@@ -0,0 +1,239 @@
+package stats
+
+import (
+	"log/slog"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/reelser-bot/internal/clock"
+	"github.com/reelser-bot/internal/storage"
+)
+
+const (
+	// retentionDays сколько дней хранится статистика (самый длинный период /top)
+	retentionDays = 30
+	// dateLayout формат ключа дня
+	dateLayout = "2006-01-02"
+)
+
+// Download успешная загрузка, учитываемая в статистике чата
+type Download struct {
+	ChatID   int64
+	UserID   int64
+	UserName string
+	Platform string
+	// Location часовой пояс чата, по которому определяется день; nil — UTC
+	Location *time.Location
+}
+
+// UserCount количество загрузок пользователя за период
+type UserCount struct {
+	UserID int64
+	Name   string
+	Count  int
+}
+
+// PlatformCount количество загрузок с платформы за период
+type PlatformCount struct {
+	Platform string
+	Count    int
+}
+
+// Top рейтинг чата за период
+type Top struct {
+	Total     int
+	Users     []UserCount
+	Platforms []PlatformCount
+}
+
+// day счетчики загрузок одного чата за один день по часовому поясу чата
+type day struct {
+	ChatID    int64          `json:"chat_id"`
+	Date      string         `json:"date"`
+	Users     map[int64]int  `json:"users,omitempty"`
+	Platforms map[string]int `json:"platforms,omitempty"`
+}
+
+// state сохраняемое состояние статистики
+type state struct {
+	Days []*day `json:"days"`
+	// Names последние известные имена пользователей
+	Names map[int64]string `json:"names,omitempty"`
+}
+
+// Store хранит счетчики загрузок по чатам и дням на диске. Счетчики агрегируются
+// по дням, поэтому размер файла зависит от числа чатов, а не загрузок
+type Store struct {
+	logger *slog.Logger
+	path   string
+	clock  clock.Clock
+
+	mu    sync.Mutex
+	state state
+}
+
+// NewStore создает хранилище статистики и загружает сохраненные счетчики
+func NewStore(logger *slog.Logger, path string, clk clock.Clock) *Store {
+	s := &Store{
+		logger: logger,
+		path:   path,
+		clock:  clk,
+		state:  state{Names: make(map[int64]string)},
+	}
+
+	if path == "" {
+		return s
+	}
+
+	if err := storage.LoadJSON(path, &s.state); err != nil {
+		logger.Warn("Failed to load download stats",
+			slog.String("file", path),
+			slog.Any("error", err),
+		)
+	}
+	if s.state.Names == nil {
+		s.state.Names = make(map[int64]string)
+	}
+
+	return s
+}
+
+// Record учитывает успешную загрузку и удаляет счетчики старше retentionDays
+func (s *Store) Record(download Download) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	date := s.clock.Now().In(locationOrUTC(download.Location)).Format(dateLayout)
+
+	var current *day
+	for _, d := range s.state.Days {
+		if d.ChatID == download.ChatID && d.Date == date {
+			current = d
+			break
+		}
+	}
+	if current == nil {
+		current = &day{ChatID: download.ChatID, Date: date}
+		s.state.Days = append(s.state.Days, current)
+	}
+
+	if download.UserID != 0 {
+		if current.Users == nil {
+			current.Users = make(map[int64]int)
+		}
+		current.Users[download.UserID]++
+		if download.UserName != "" {
+			s.state.Names[download.UserID] = download.UserName
+		}
+	}
+	if download.Platform != "" {
+		if current.Platforms == nil {
+			current.Platforms = make(map[string]int)
+		}
+		current.Platforms[download.Platform]++
+	}
+
+	s.prune()
+	s.persist()
+}
+
+// Top возвращает рейтинг чата за последние days дней (включая сегодня по часовому
+// поясу loc): не больше limit самых активных пользователей и платформ
+func (s *Store) Top(chatID int64, days, limit int, loc *time.Location) Top {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	since := s.clock.Now().In(locationOrUTC(loc)).AddDate(0, 0, -(days - 1)).Format(dateLayout)
+
+	users := make(map[int64]int)
+	platforms := make(map[string]int)
+	var top Top
+	for _, d := range s.state.Days {
+		if d.ChatID != chatID || d.Date < since {
+			continue
+		}
+		for userID, count := range d.Users {
+			users[userID] += count
+		}
+		for platform, count := range d.Platforms {
+			platforms[platform] += count
+			top.Total += count
+		}
+	}
+
+	for userID, count := range users {
+		top.Users = append(top.Users, UserCount{UserID: userID, Name: s.state.Names[userID], Count: count})
+	}
+	sort.Slice(top.Users, func(i, j int) bool {
+		if top.Users[i].Count != top.Users[j].Count {
+			return top.Users[i].Count > top.Users[j].Count
+		}
+		return top.Users[i].UserID < top.Users[j].UserID
+	})
+
+	for platform, count := range platforms {
+		top.Platforms = append(top.Platforms, PlatformCount{Platform: platform, Count: count})
+	}
+	sort.Slice(top.Platforms, func(i, j int) bool {
+		if top.Platforms[i].Count != top.Platforms[j].Count {
+			return top.Platforms[i].Count > top.Platforms[j].Count
+		}
+		return top.Platforms[i].Platform < top.Platforms[j].Platform
+	})
+
+	if len(top.Users) > limit {
+		top.Users = top.Users[:limit]
+	}
+	if len(top.Platforms) > limit {
+		top.Platforms = top.Platforms[:limit]
+	}
+	return top
+}
+
+// prune удаляет счетчики старше retentionDays и имена пользователей без загрузок;
+// вызывается под s.mu
+func (s *Store) prune() {
+	cutoff := s.clock.Now().UTC().AddDate(0, 0, -retentionDays).Format(dateLayout)
+
+	days := s.state.Days[:0]
+	active := make(map[int64]struct{})
+	for _, d := range s.state.Days {
+		if d.Date < cutoff {
+			continue
+		}
+		days = append(days, d)
+		for userID := range d.Users {
+			active[userID] = struct{}{}
+		}
+	}
+	s.state.Days = days
+
+	for userID := range s.state.Names {
+		if _, ok := active[userID]; !ok {
+			delete(s.state.Names, userID)
+		}
+	}
+}
+
+// locationOrUTC возвращает loc или UTC, если часовой пояс не задан
+func locationOrUTC(loc *time.Location) *time.Location {
+	if loc == nil {
+		return time.UTC
+	}
+	return loc
+}
+
+// persist записывает статистику на диск; вызывается под s.mu
+func (s *Store) persist() {
+	if s.path == "" {
+		return
+	}
+
+	if err := storage.SaveJSON(s.path, s.state); err != nil {
+		s.logger.Warn("Failed to persist download stats",
+			slog.String("file", s.path),
+			slog.Any("error", err),
+		)
+	}
+}
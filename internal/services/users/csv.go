@@ -0,0 +1,135 @@
+package users
+
+import (
+	"bufio"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// maxCSVErrors сколько ошибок разбора перечислять, прежде чем остановиться
+const maxCSVErrors = 10
+
+// ParseCSV разбирает CSV со столбцами user_id, role, daily_quota. Строка заголовка,
+// пустые строки и комментарии (#) пропускаются; роль по умолчанию user, пустая квота —
+// без ограничения. Разделитель — запятая или точка с запятой (как сохраняет Excel).
+// Если хотя бы одна строка некорректна, возвращается ошибка со списком строк, и
+// ничего не импортируется
+func ParseCSV(r io.Reader) ([]Record, error) {
+	br := bufio.NewReader(r)
+	reader := csv.NewReader(br)
+	reader.Comment = '#'
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+	if first, _ := br.Peek(br.Size()); isSemicolonSeparated(first) {
+		reader.Comma = ';'
+	}
+
+	var records []Record
+	var errs []error
+	seen := make(map[int64]int)
+	for {
+		fields, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			var parseErr *csv.ParseError
+			if !errors.As(err, &parseErr) {
+				errs = append(errs, err)
+				break
+			}
+			errs = append(errs, fmt.Errorf("строка %d: %w", parseErr.Line, parseErr.Err))
+			if len(errs) >= maxCSVErrors {
+				break
+			}
+			continue
+		}
+		line, _ := reader.FieldPos(0)
+
+		if len(records) == 0 && len(errs) == 0 && isHeader(fields) {
+			continue
+		}
+
+		record, err := parseRecord(fields)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("строка %d: %w", line, err))
+			if len(errs) >= maxCSVErrors {
+				break
+			}
+			continue
+		}
+
+		// Повтор ID заменяет прежнюю запись
+		if i, ok := seen[record.UserID]; ok {
+			records[i] = record
+			continue
+		}
+		seen[record.UserID] = len(records)
+		records = append(records, record)
+	}
+
+	if len(errs) > 0 {
+		return nil, errors.Join(errs...)
+	}
+	if len(records) == 0 {
+		return nil, errors.New("в файле нет ни одного пользователя")
+	}
+	return records, nil
+}
+
+// parseRecord разбирает одну строку: user_id[, role[, daily_quota]]
+func parseRecord(fields []string) (Record, error) {
+	for i := range fields {
+		fields[i] = strings.TrimSpace(fields[i])
+	}
+	if len(fields) == 0 || fields[0] == "" {
+		return Record{}, errors.New("не указан user_id")
+	}
+	if len(fields) > 3 {
+		return Record{}, fmt.Errorf("ожидается не больше 3 столбцов, получено %d", len(fields))
+	}
+
+	id, err := strconv.ParseInt(fields[0], 10, 64)
+	if err != nil || id <= 0 {
+		return Record{}, fmt.Errorf("некорректный user_id %q", fields[0])
+	}
+	record := Record{UserID: id, Role: RoleUser}
+
+	if len(fields) > 1 && fields[1] != "" {
+		switch role := Role(strings.ToLower(fields[1])); role {
+		case RoleUser, RoleAdmin:
+			record.Role = role
+		default:
+			return Record{}, fmt.Errorf("неизвестная роль %q (ожидается user или admin)", fields[1])
+		}
+	}
+
+	if len(fields) > 2 && fields[2] != "" {
+		quota, err := strconv.Atoi(fields[2])
+		if err != nil || quota < 0 {
+			return Record{}, fmt.Errorf("некорректная квота %q", fields[2])
+		}
+		record.DailyQuota = quota
+	}
+
+	return record, nil
+}
+
+// isHeader проверяет, что строка — заголовок, а не запись
+func isHeader(fields []string) bool {
+	if len(fields) == 0 {
+		return false
+	}
+	_, err := strconv.ParseInt(strings.TrimSpace(fields[0]), 10, 64)
+	return err != nil
+}
+
+// isSemicolonSeparated определяет разделитель по первой строке
+func isSemicolonSeparated(data []byte) bool {
+	line, _, _ := strings.Cut(string(data), "\n")
+	return strings.Contains(line, ";") && !strings.Contains(line, ",")
+}
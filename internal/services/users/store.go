@@ -0,0 +1,211 @@
+package users
+
+import (
+	"log/slog"
+	"sort"
+	"sync"
+
+	"github.com/reelser-bot/internal/clock"
+	"github.com/reelser-bot/internal/storage"
+)
+
+// Role роль пользователя бота
+type Role string
+
+const (
+	// RoleUser пользователь с доступом к загрузкам
+	RoleUser Role = "user"
+	// RoleAdmin администратор бота
+	RoleAdmin Role = "admin"
+)
+
+// Record пользователь, заведенный импортом
+type Record struct {
+	UserID int64 `json:"user_id"`
+	Role   Role  `json:"role"`
+	// DailyQuota сколько ссылок в сутки пользователь может отправить (0 — без ограничения)
+	DailyQuota int `json:"daily_quota,omitempty"`
+}
+
+// usage запросы пользователей за текущие сутки
+type usage struct {
+	// Day сутки в формате 2006-01-02 (по часам бота)
+	Day    string        `json:"day"`
+	Counts map[int64]int `json:"counts"`
+}
+
+// state сохраняемое состояние хранилища
+type state struct {
+	Users []Record `json:"users"`
+	Usage usage    `json:"usage"`
+}
+
+// Store хранит пользователей, перенесенных импортом из CSV (роли и суточные квоты),
+// и расход квот за текущие сутки
+type Store struct {
+	logger *slog.Logger
+	path   string
+	clock  clock.Clock
+	// warnPercent доля израсходованной квоты, после которой пользователя стоит
+	// предупредить (BANDWIDTH_WARN_PERCENT); 0 — без предупреждений
+	warnPercent int
+
+	mu      sync.Mutex
+	records map[int64]Record
+	usage   usage
+}
+
+// NewStore создает хранилище и загружает сохраненное состояние. warnPercent задает
+// порог предупреждения в процентах израсходованной суточной квоты
+func NewStore(logger *slog.Logger, path string, warnPercent int, clk clock.Clock) *Store {
+	s := &Store{
+		logger:      logger,
+		path:        path,
+		clock:       clk,
+		warnPercent: warnPercent,
+		records:     make(map[int64]Record),
+		usage:       usage{Counts: make(map[int64]int)},
+	}
+
+	if path == "" {
+		return s
+	}
+
+	var saved state
+	if err := storage.LoadJSON(path, &saved); err != nil {
+		logger.Warn("Failed to load imported users",
+			slog.String("file", path),
+			slog.Any("error", err),
+		)
+		return s
+	}
+	for _, record := range saved.Users {
+		s.records[record.UserID] = record
+	}
+	if saved.Usage.Counts != nil {
+		s.usage = saved.Usage
+	}
+
+	return s
+}
+
+// ImportResult итог импорта пользователей
+type ImportResult struct {
+	Added   int
+	Updated int
+	// Demoted пользователи, которых прошлый импорт сделал администраторами, а этот — нет
+	Demoted []int64
+}
+
+// Import добавляет или обновляет пользователей
+func (s *Store) Import(records []Record) ImportResult {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var result ImportResult
+	for _, record := range records {
+		old, exists := s.records[record.UserID]
+		switch {
+		case !exists:
+			result.Added++
+		case old != record:
+			result.Updated++
+		}
+		if exists && old.Role == RoleAdmin && record.Role != RoleAdmin {
+			result.Demoted = append(result.Demoted, record.UserID)
+		}
+		s.records[record.UserID] = record
+	}
+	s.persist()
+	return result
+}
+
+// All возвращает импортированных пользователей по возрастанию ID
+func (s *Store) All() []Record {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.sorted()
+}
+
+// Take учитывает запрос пользователя в его суточной квоте. Возвращает false и квоту,
+// если она уже исчерпана; пользователи без квоты не ограничиваются
+func (s *Store) Take(userID int64) (quota int, ok bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	record, exists := s.records[userID]
+	if !exists || record.DailyQuota <= 0 {
+		return 0, true
+	}
+
+	if today := s.clock.Now().Format("2006-01-02"); s.usage.Day != today {
+		s.usage = usage{Day: today, Counts: make(map[int64]int)}
+	}
+	if s.usage.Counts[userID] >= record.DailyQuota {
+		return record.DailyQuota, false
+	}
+	s.usage.Counts[userID]++
+	s.persist()
+	return record.DailyQuota, true
+}
+
+// Low проверяет, что пользователь израсходовал не меньше warnPercent суточной квоты,
+// и возвращает, сколько ссылок у него осталось на сегодня
+func (s *Store) Low(userID int64) (remaining int, low bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	record, exists := s.records[userID]
+	if !exists || record.DailyQuota <= 0 || s.warnPercent <= 0 || s.warnPercent >= 100 {
+		return 0, false
+	}
+
+	used := 0
+	if s.usage.Day == s.clock.Now().Format("2006-01-02") {
+		used = s.usage.Counts[userID]
+	}
+	remaining = max(record.DailyQuota-used, 0)
+	return remaining, used*100 >= record.DailyQuota*s.warnPercent
+}
+
+// Refund возвращает в суточную квоту запрос, учтенный Take, если ссылка не была
+// обработана: очередь переполнена или загрузка не удалась. Расход прошлых суток
+// не меняется
+func (s *Store) Refund(userID int64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.usage.Day != s.clock.Now().Format("2006-01-02") || s.usage.Counts[userID] <= 0 {
+		return
+	}
+	s.usage.Counts[userID]--
+	if s.usage.Counts[userID] == 0 {
+		delete(s.usage.Counts, userID)
+	}
+	s.persist()
+}
+
+// persist записывает состояние на диск; вызывается под s.mu
+func (s *Store) persist() {
+	if s.path == "" {
+		return
+	}
+
+	if err := storage.SaveJSON(s.path, state{Users: s.sorted(), Usage: s.usage}); err != nil {
+		s.logger.Warn("Failed to persist imported users",
+			slog.String("file", s.path),
+			slog.Any("error", err),
+		)
+	}
+}
+
+// sorted возвращает записи по возрастанию ID; вызывается под s.mu
+func (s *Store) sorted() []Record {
+	records := make([]Record, 0, len(s.records))
+	for _, record := range s.records {
+		records = append(records, record)
+	}
+	sort.Slice(records, func(i, j int) bool { return records[i].UserID < records[j].UserID })
+	return records
+}
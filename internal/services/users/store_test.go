@@ -0,0 +1,73 @@
+package users
+
+import (
+	"io"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/reelser-bot/internal/clock"
+)
+
+func newTestStore(t *testing.T, clk clock.Clock) *Store {
+	t.Helper()
+	return NewStore(slog.New(slog.NewTextHandler(io.Discard, nil)), "", 80, clk)
+}
+
+func TestRefund(t *testing.T) {
+	clk := clock.NewFake(time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC))
+	s := newTestStore(t, clk)
+	s.Import([]Record{{UserID: 1, Role: RoleUser, DailyQuota: 1}})
+
+	if _, ok := s.Take(1); !ok {
+		t.Fatal("first Take rejected")
+	}
+	if _, ok := s.Take(1); ok {
+		t.Fatal("Take over quota accepted")
+	}
+
+	s.Refund(1)
+	if _, ok := s.Take(1); !ok {
+		t.Fatal("Take after Refund rejected")
+	}
+
+	// Запрос прошлых суток не возвращается в квоту новых
+	clk.Advance(24 * time.Hour)
+	s.Refund(1)
+	if _, ok := s.Take(1); !ok {
+		t.Fatal("Take on a new day rejected")
+	}
+	if _, ok := s.Take(1); ok {
+		t.Fatal("Refund of a previous day increased today's quota")
+	}
+}
+
+func TestImportDemoted(t *testing.T) {
+	s := newTestStore(t, clock.NewFake(time.Now()))
+	s.Import([]Record{{UserID: 1, Role: RoleAdmin}, {UserID: 2, Role: RoleUser}})
+
+	result := s.Import([]Record{{UserID: 1, Role: RoleUser}, {UserID: 2, Role: RoleAdmin}, {UserID: 3, Role: RoleUser}})
+	if result.Added != 1 || result.Updated != 2 {
+		t.Errorf("added, updated = %d, %d, want 1, 2", result.Added, result.Updated)
+	}
+	if len(result.Demoted) != 1 || result.Demoted[0] != 1 {
+		t.Errorf("Demoted = %v, want [1]", result.Demoted)
+	}
+}
+
+func TestLow(t *testing.T) {
+	s := newTestStore(t, clock.NewFake(time.Now()))
+	s.Import([]Record{{UserID: 1, Role: RoleUser, DailyQuota: 5}, {UserID: 2, Role: RoleUser}})
+
+	for i := range 5 {
+		s.Take(1)
+		remaining, low := s.Low(1)
+		if want := i+1 >= 4; low != want || remaining != 4-i {
+			t.Errorf("after %d links Low = %d, %v, want %d, %v", i+1, remaining, low, 4-i, want)
+		}
+	}
+
+	if _, low := s.Low(2); low {
+		t.Error("user without a quota is warned")
+	}
+}
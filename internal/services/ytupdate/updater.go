@@ -0,0 +1,376 @@
+package ytupdate
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/reelser-bot/internal/buildinfo"
+	"github.com/reelser-bot/internal/clock"
+	"github.com/reelser-bot/internal/platform"
+	"github.com/reelser-bot/internal/platform/ytdlp"
+)
+
+// Режимы обновления yt-dlp
+const (
+	ModeOff     = "off"
+	ModeSelf    = "self"
+	ModeRelease = "release"
+)
+
+// maxBinarySize ограничивает размер скачиваемого файла релиза
+const maxBinarySize = 256 << 20
+
+// checksumsFile файл с контрольными суммами SHA-256 в каталоге релиза yt-dlp
+const checksumsFile = "SHA2-256SUMS"
+
+// maxChecksumsSize ограничивает размер файла контрольных сумм
+const maxChecksumsSize = 64 << 10
+
+// latestPath часть адреса GitHub, указывающая на последний релиз
+const latestPath = "/releases/latest/download/"
+
+// Config настройки обновления
+type Config struct {
+	// Mode off, self (yt-dlp -U) или release (скачивание файла релиза в Binary)
+	Mode string
+	// Binary путь к yt-dlp или имя в PATH; в режиме release — путь, куда кладется релиз
+	Binary string
+	// ReleaseURL адрес файла релиза; пусто — сборка GitHub для текущей ОС и архитектуры.
+	// В том же каталоге должен лежать SHA2-256SUMS
+	ReleaseURL string
+	// Proxy прокси для обновления (пусто — прямое подключение или HTTP(S)_PROXY)
+	Proxy    string
+	Interval time.Duration
+	Timeout  time.Duration
+}
+
+// Updater периодически обновляет yt-dlp: экстракторы ломаются при изменениях
+// на сайтах, и устаревший yt-dlp — самая частая причина неудачных загрузок
+type Updater struct {
+	logger *slog.Logger
+	cfg    Config
+	runner ytdlp.CommandRunner
+	client *http.Client
+	clock  clock.Clock
+}
+
+// Option настраивает зависимости updater
+type Option func(*Updater)
+
+// WithRunner задает исполнитель команд (например, ytdlp.FakeRunner)
+func WithRunner(r ytdlp.CommandRunner) Option {
+	return func(u *Updater) { u.runner = r }
+}
+
+// WithClock задает часы для периодических обновлений
+func WithClock(c clock.Clock) Option {
+	return func(u *Updater) { u.clock = c }
+}
+
+// New создает updater
+func New(logger *slog.Logger, cfg Config, opts ...Option) (*Updater, error) {
+	switch cfg.Mode {
+	case ModeOff, ModeSelf:
+	case ModeRelease:
+		if cfg.Binary == "" || !strings.ContainsRune(cfg.Binary, filepath.Separator) {
+			return nil, fmt.Errorf("release update mode requires a file path to the yt-dlp binary")
+		}
+	default:
+		return nil, fmt.Errorf("unknown yt-dlp update mode %q", cfg.Mode)
+	}
+	if cfg.ReleaseURL == "" {
+		cfg.ReleaseURL = defaultReleaseURL()
+	}
+
+	u := &Updater{
+		logger: logger,
+		cfg:    cfg,
+		runner: ytdlp.ExecRunner{},
+		client: platform.NewHTTPClient(cfg.Timeout),
+		clock:  clock.Real{},
+	}
+	for _, opt := range opts {
+		opt(u)
+	}
+	return u, nil
+}
+
+// Enabled возвращает true, если обновление включено
+func (u *Updater) Enabled() bool {
+	return u.cfg.Mode != ModeOff
+}
+
+// OnStartup в режиме release скачивает yt-dlp, если его еще нет по указанному пути,
+// чтобы бот мог запуститься на чистой машине
+func (u *Updater) OnStartup(ctx context.Context) error {
+	if u.cfg.Mode != ModeRelease {
+		return nil
+	}
+	if _, err := os.Stat(u.cfg.Binary); err == nil {
+		return nil
+	}
+
+	u.logger.Info("yt-dlp binary is missing, installing release",
+		slog.String("path", u.cfg.Binary),
+		slog.String("url", u.cfg.ReleaseURL),
+	)
+	return u.Update(ctx)
+}
+
+// Run обновляет yt-dlp сразу после запуска и затем раз в Interval до отмены контекста
+func (u *Updater) Run(ctx context.Context) {
+	if !u.Enabled() || u.cfg.Interval <= 0 {
+		return
+	}
+
+	ticker := u.clock.NewTicker(u.cfg.Interval)
+	defer ticker.Stop()
+
+	for {
+		if err := u.Update(ctx); err != nil && ctx.Err() == nil {
+			u.logger.Warn("Failed to update yt-dlp",
+				slog.String("mode", u.cfg.Mode),
+				slog.Any("error", err),
+			)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C():
+		}
+	}
+}
+
+// Update выполняет одно обновление и логирует смену версии
+func (u *Updater) Update(ctx context.Context) error {
+	if u.cfg.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, u.cfg.Timeout)
+		defer cancel()
+	}
+
+	before, _ := u.version(ctx, u.cfg.Binary)
+
+	var err error
+	switch u.cfg.Mode {
+	case ModeSelf:
+		err = u.selfUpdate(ctx)
+	case ModeRelease:
+		err = u.releaseUpdate(ctx, before)
+	default:
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	after, err := u.version(ctx, u.cfg.Binary)
+	if err != nil {
+		return fmt.Errorf("updated yt-dlp does not run: %w", err)
+	}
+	buildinfo.SetTool("yt-dlp", after)
+	if after != before {
+		u.logger.Info("yt-dlp updated",
+			slog.String("from", before),
+			slog.String("to", after),
+		)
+	} else {
+		u.logger.Debug("yt-dlp is up to date", slog.String("version", after))
+	}
+	return nil
+}
+
+// selfUpdate запускает встроенное обновление yt-dlp -U. Оно работает только для
+// автономных сборок; установки через pip или пакетный менеджер возвращают ошибку
+func (u *Updater) selfUpdate(ctx context.Context) error {
+	args := []string{"-U"}
+	if u.cfg.Proxy != "" {
+		args = append(args, "--proxy", u.cfg.Proxy)
+	}
+
+	stdout, stderr, err := u.runner.Run(ctx, ytdlp.Command{Name: u.cfg.Binary, Args: args})
+	if err != nil {
+		return fmt.Errorf("yt-dlp -U failed: %w: %s", err, strings.TrimSpace(string(stderr)))
+	}
+	// yt-dlp сообщает о невозможности обновления без ненулевого кода выхода
+	output := string(stdout) + string(stderr)
+	if strings.Contains(output, "ERROR:") {
+		return fmt.Errorf("yt-dlp -U failed: %s", strings.TrimSpace(output))
+	}
+	return nil
+}
+
+// releaseUpdate скачивает файл релиза рядом с Binary, сверяет его контрольную сумму
+// с SHA2-256SUMS того же релиза и атомарно заменяет Binary. Файл с неверной суммой
+// не запускается и не устанавливается. Уже запущенные процессы yt-dlp продолжают
+// работать со старым файлом
+func (u *Updater) releaseUpdate(ctx context.Context, current string) error {
+	tag := u.latestTag(ctx)
+	if current != "" && tag != "" && tag == current {
+		return nil
+	}
+	binaryURL, sumsURL := releaseURLs(u.cfg.ReleaseURL, tag)
+
+	expected, err := u.expectedChecksum(ctx, sumsURL, path.Base(binaryURL))
+	if err != nil {
+		return err
+	}
+
+	dir := filepath.Dir(u.cfg.Binary)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("failed to create binary directory: %w", err)
+	}
+
+	tmp, err := os.CreateTemp(dir, ".yt-dlp-*")
+	if err != nil {
+		return fmt.Errorf("failed to create temp file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	hash := sha256.New()
+	if err := u.download(ctx, binaryURL, io.MultiWriter(tmp, hash), maxBinarySize); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write release: %w", err)
+	}
+	if actual := hex.EncodeToString(hash.Sum(nil)); actual != expected {
+		return fmt.Errorf("release checksum mismatch: got %s, want %s", actual, expected)
+	}
+	if err := os.Chmod(tmp.Name(), 0755); err != nil {
+		return fmt.Errorf("failed to make release executable: %w", err)
+	}
+	if _, err := u.version(ctx, tmp.Name()); err != nil {
+		return fmt.Errorf("downloaded release does not run: %w", err)
+	}
+
+	if err := os.Rename(tmp.Name(), u.cfg.Binary); err != nil {
+		return fmt.Errorf("failed to replace yt-dlp binary: %w", err)
+	}
+	return nil
+}
+
+// releaseURLs возвращает адреса файла релиза и SHA2-256SUMS из того же каталога.
+// Если тег последнего релиза известен, адреса latest закрепляются за ним: иначе
+// релиз мог бы смениться между скачиванием файла и контрольных сумм
+func releaseURLs(releaseURL, tag string) (binaryURL, sumsURL string) {
+	binaryURL = releaseURL
+	if tag != "" {
+		binaryURL = strings.Replace(releaseURL, latestPath, "/releases/download/"+tag+"/", 1)
+	}
+	dir, _ := path.Split(binaryURL)
+	return binaryURL, dir + checksumsFile
+}
+
+// expectedChecksum скачивает SHA2-256SUMS и возвращает сумму файла asset
+func (u *Updater) expectedChecksum(ctx context.Context, sumsURL, asset string) (string, error) {
+	var sums bytes.Buffer
+	if err := u.download(ctx, sumsURL, &sums, maxChecksumsSize); err != nil {
+		return "", fmt.Errorf("failed to download checksums: %w", err)
+	}
+
+	for _, line := range strings.Split(sums.String(), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) != 2 || strings.TrimPrefix(fields[1], "*") != asset {
+			continue
+		}
+		sum := strings.ToLower(fields[0])
+		if decoded, err := hex.DecodeString(sum); err != nil || len(decoded) != sha256.Size {
+			return "", fmt.Errorf("invalid checksum for %s in %s", asset, checksumsFile)
+		}
+		return sum, nil
+	}
+	return "", fmt.Errorf("no checksum for %s in %s", asset, checksumsFile)
+}
+
+// download скачивает файл по url в w, не больше limit байт
+func (u *Updater) download(ctx context.Context, url string, w io.Writer, limit int64) error {
+	req, err := http.NewRequestWithContext(platform.WithProxy(ctx, u.cfg.Proxy), http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+
+	resp, err := u.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to download %s: %w", path.Base(url), err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("failed to download %s: unexpected status %s", path.Base(url), resp.Status)
+	}
+
+	n, err := io.Copy(w, io.LimitReader(resp.Body, limit+1))
+	if err != nil {
+		return fmt.Errorf("failed to download %s: %w", path.Base(url), err)
+	}
+	if n > limit {
+		return fmt.Errorf("%s is larger than %d bytes", path.Base(url), limit)
+	}
+	return nil
+}
+
+// latestTag возвращает тег последнего релиза по перенаправлению GitHub
+// .../releases/latest/download/<файл> → .../releases/download/<тег>/<файл>.
+// Пустая строка — тег неизвестен, релиз нужно скачать
+func (u *Updater) latestTag(ctx context.Context) string {
+	req, err := http.NewRequestWithContext(platform.WithProxy(ctx, u.cfg.Proxy), http.MethodHead, u.cfg.ReleaseURL, nil)
+	if err != nil {
+		return ""
+	}
+
+	client := *u.client
+	client.CheckRedirect = func(*http.Request, []*http.Request) error { return http.ErrUseLastResponse }
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return ""
+	}
+	resp.Body.Close()
+
+	_, rest, ok := strings.Cut(resp.Header.Get("Location"), "/releases/download/")
+	if !ok {
+		return ""
+	}
+	tag, _, _ := strings.Cut(rest, "/")
+	return tag
+}
+
+// version возвращает вывод binary --version
+func (u *Updater) version(ctx context.Context, binary string) (string, error) {
+	stdout, stderr, err := u.runner.Run(ctx, ytdlp.Command{Name: binary, Args: []string{"--version"}})
+	if err != nil {
+		return "", fmt.Errorf("%w: %s", err, strings.TrimSpace(string(stderr)))
+	}
+	return strings.TrimSpace(string(stdout)), nil
+}
+
+// defaultReleaseURL возвращает адрес автономной сборки yt-dlp для текущей платформы
+func defaultReleaseURL() string {
+	asset := "yt-dlp"
+	switch {
+	case runtime.GOOS == "linux" && runtime.GOARCH == "amd64":
+		asset = "yt-dlp_linux"
+	case runtime.GOOS == "linux" && runtime.GOARCH == "arm64":
+		asset = "yt-dlp_linux_aarch64"
+	case runtime.GOOS == "darwin":
+		asset = "yt-dlp_macos"
+	case runtime.GOOS == "windows":
+		asset = "yt-dlp.exe"
+	}
+	return "https://github.com/yt-dlp/yt-dlp/releases/latest/download/" + asset
+}
@@ -0,0 +1,123 @@
+package ytupdate
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/reelser-bot/internal/platform/ytdlp"
+)
+
+const (
+	testTag     = "2024.05.01"
+	testRelease = "new yt-dlp release"
+)
+
+// releaseServer имитирует релизы GitHub: latest перенаправляет на тег, в каталоге
+// тега лежат файл релиза и SHA2-256SUMS с содержимым sums
+func releaseServer(t *testing.T, sums string) *httptest.Server {
+	t.Helper()
+	mux := http.NewServeMux()
+	mux.HandleFunc("/releases/latest/download/", func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "/releases/download/"+testTag+"/"+filepath.Base(r.URL.Path), http.StatusFound)
+	})
+	mux.HandleFunc("/releases/download/"+testTag+"/yt-dlp_linux", func(w http.ResponseWriter, _ *http.Request) {
+		io.WriteString(w, testRelease)
+	})
+	mux.HandleFunc("/releases/download/"+testTag+"/SHA2-256SUMS", func(w http.ResponseWriter, _ *http.Request) {
+		io.WriteString(w, sums)
+	})
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+	return server
+}
+
+func testUpdater(t *testing.T, server *httptest.Server, runner *ytdlp.FakeRunner) (*Updater, string) {
+	t.Helper()
+	binary := filepath.Join(t.TempDir(), "yt-dlp")
+	if err := os.WriteFile(binary, []byte("old yt-dlp"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	u, err := New(slog.New(slog.NewTextHandler(io.Discard, nil)), Config{
+		Mode:       ModeRelease,
+		Binary:     binary,
+		ReleaseURL: server.URL + "/releases/latest/download/yt-dlp_linux",
+		Timeout:    time.Minute,
+	}, WithRunner(runner))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return u, binary
+}
+
+func checksum(data string) string {
+	sum := sha256.Sum256([]byte(data))
+	return hex.EncodeToString(sum[:])
+}
+
+func TestReleaseUpdateVerifiesChecksum(t *testing.T) {
+	sums := checksum("other file") + "  yt-dlp.exe\n" + checksum(testRelease) + "  yt-dlp_linux\n"
+	runner := &ytdlp.FakeRunner{}
+	u, binary := testUpdater(t, releaseServer(t, sums), runner)
+
+	if err := u.Update(context.Background()); err != nil {
+		t.Fatalf("Update: %v", err)
+	}
+	if data, _ := os.ReadFile(binary); string(data) != testRelease {
+		t.Errorf("binary = %q, want the new release", data)
+	}
+}
+
+func TestReleaseUpdateRejectsBadChecksum(t *testing.T) {
+	tests := map[string]string{
+		"mismatch":        checksum("tampered release") + "  yt-dlp_linux\n",
+		"missing asset":   checksum(testRelease) + "  yt-dlp_macos\n",
+		"malformed":       "not-a-hash  yt-dlp_linux\n",
+		"empty checksums": "",
+	}
+	for name, sums := range tests {
+		t.Run(name, func(t *testing.T) {
+			runner := &ytdlp.FakeRunner{}
+			u, binary := testUpdater(t, releaseServer(t, sums), runner)
+
+			if err := u.Update(context.Background()); err == nil {
+				t.Fatal("Update accepted a release without a matching checksum")
+			}
+			if data, _ := os.ReadFile(binary); string(data) != "old yt-dlp" {
+				t.Errorf("binary = %q, want the old one kept", data)
+			}
+			for _, call := range runner.Calls() {
+				if call.Name != binary {
+					t.Errorf("unverified file %s was run", call.Name)
+				}
+			}
+			if leftovers, _ := filepath.Glob(filepath.Join(filepath.Dir(binary), ".yt-dlp-*")); len(leftovers) != 0 {
+				t.Errorf("temporary files left: %v", leftovers)
+			}
+		})
+	}
+}
+
+func TestReleaseURLs(t *testing.T) {
+	binaryURL, sumsURL := releaseURLs("https://github.com/yt-dlp/yt-dlp/releases/latest/download/yt-dlp_linux", testTag)
+	if want := "https://github.com/yt-dlp/yt-dlp/releases/download/" + testTag + "/yt-dlp_linux"; binaryURL != want {
+		t.Errorf("binary URL = %s, want %s", binaryURL, want)
+	}
+	if !strings.HasSuffix(sumsURL, "/releases/download/"+testTag+"/SHA2-256SUMS") {
+		t.Errorf("checksums URL = %s", sumsURL)
+	}
+
+	binaryURL, sumsURL = releaseURLs("https://mirror.example.com/yt-dlp/yt-dlp_linux", "")
+	if binaryURL != "https://mirror.example.com/yt-dlp/yt-dlp_linux" || sumsURL != "https://mirror.example.com/yt-dlp/SHA2-256SUMS" {
+		t.Errorf("mirror URLs = %s, %s", binaryURL, sumsURL)
+	}
+}
@@ -0,0 +1,52 @@
+package storage
+
+import (
+	"sync"
+	"time"
+)
+
+// Debouncer откладывает запись файла данных: изменения, сделанные за delay, сохраняются
+// одной записью. Хранилища с частыми изменениями вызывают Schedule после каждого
+// изменения и Flush при остановке бота, чтобы не потерять последние изменения
+type Debouncer struct {
+	delay time.Duration
+	write func()
+
+	mu    sync.Mutex
+	timer *time.Timer
+	// writeMu не дает Flush вернуться, пока идет запись по таймеру
+	writeMu sync.Mutex
+}
+
+// NewDebouncer создает отложенную запись. write сохраняет текущее состояние хранилища
+// и сам берет его блокировку, поэтому Schedule можно вызывать под ней, а Flush — нет
+func NewDebouncer(delay time.Duration, write func()) *Debouncer {
+	return &Debouncer{delay: delay, write: write}
+}
+
+// Schedule планирует запись через delay, если она еще не запланирована
+func (d *Debouncer) Schedule() {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.timer == nil {
+		d.timer = time.AfterFunc(d.delay, d.Flush)
+	}
+}
+
+// Flush сразу записывает запланированные изменения
+func (d *Debouncer) Flush() {
+	d.mu.Lock()
+	timer := d.timer
+	d.timer = nil
+	d.mu.Unlock()
+
+	d.writeMu.Lock()
+	defer d.writeMu.Unlock()
+
+	if timer == nil {
+		return
+	}
+	timer.Stop()
+	d.write()
+}
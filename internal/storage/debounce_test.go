@@ -0,0 +1,37 @@
+package storage
+
+import (
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestDebouncerCoalescesWrites(t *testing.T) {
+	var writes atomic.Int32
+	d := NewDebouncer(time.Hour, func() { writes.Add(1) })
+
+	for range 10 {
+		d.Schedule()
+	}
+	d.Flush()
+	if got := writes.Load(); got != 1 {
+		t.Fatalf("writes after Flush = %d, want 1", got)
+	}
+
+	d.Flush()
+	if got := writes.Load(); got != 1 {
+		t.Errorf("Flush without changes wrote again: %d writes", got)
+	}
+}
+
+func TestDebouncerWritesAfterDelay(t *testing.T) {
+	done := make(chan struct{})
+	d := NewDebouncer(time.Millisecond, func() { close(done) })
+
+	d.Schedule()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("scheduled write did not happen")
+	}
+}
@@ -0,0 +1,222 @@
+package redis
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+	"sync"
+	"time"
+)
+
+// resubscribeDelay пауза перед повторной подпиской после разрыва
+const resubscribeDelay = 5 * time.Second
+
+// invalidateChannel канал уведомлений об изменениях общего состояния
+const invalidateChannel = "invalidate"
+
+// Cache общее состояние нескольких экземпляров бота. Экземпляры держат данные в памяти,
+// а изменения записывают в Redis и рассылают уведомление через pub/sub: получатели
+// перечитывают измененный ключ. Ключи и канал начинаются с общего префикса
+type Cache struct {
+	logger *slog.Logger
+	client *Client
+	prefix string
+	// retryDelay пауза перед повторной подпиской (resubscribeDelay)
+	retryDelay time.Duration
+
+	mu       sync.RWMutex
+	handlers map[string][]func(key string)
+}
+
+// NewCache создает общий кэш поверх клиента
+func NewCache(logger *slog.Logger, client *Client, prefix string) *Cache {
+	return &Cache{
+		logger:     logger,
+		client:     client,
+		prefix:     prefix,
+		retryDelay: resubscribeDelay,
+		handlers:   make(map[string][]func(string)),
+	}
+}
+
+// OnInvalidate подписывает fn на изменения темы topic. fn получает измененный ключ;
+// пустой ключ означает, что нужно перечитать всю тему (после переподключения)
+func (c *Cache) OnInvalidate(topic string, fn func(key string)) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.handlers[topic] = append(c.handlers[topic], fn)
+}
+
+// Invalidate уведомляет все экземпляры, что ключ темы изменился
+func (c *Cache) Invalidate(ctx context.Context, topic, key string) error {
+	_, err := c.client.Do(ctx, "PUBLISH", c.prefix+invalidateChannel, topic+":"+key)
+	return err
+}
+
+// Run слушает уведомления до отмены контекста, переподключаясь после разрывов
+func (c *Cache) Run(ctx context.Context) {
+	for {
+		err := c.client.Subscribe(ctx, c.prefix+invalidateChannel, c.reloadAll, c.dispatch)
+		if ctx.Err() != nil {
+			return
+		}
+		c.logger.Warn("Redis subscription lost, resubscribing",
+			slog.Duration("delay", c.retryDelay),
+			slog.Any("error", err),
+		)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(c.retryDelay):
+		}
+	}
+}
+
+// dispatch передает уведомление "тема:ключ" обработчикам темы
+func (c *Cache) dispatch(message string) {
+	topic, key, _ := strings.Cut(message, ":")
+
+	c.mu.RLock()
+	handlers := c.handlers[topic]
+	c.mu.RUnlock()
+
+	for _, fn := range handlers {
+		fn(key)
+	}
+}
+
+// reloadAll просит все темы перечитать данные: уведомления, отправленные во время
+// разрыва, не доставляются
+func (c *Cache) reloadAll() {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	for _, handlers := range c.handlers {
+		for _, fn := range handlers {
+			fn("")
+		}
+	}
+}
+
+// Get возвращает значение ключа; ErrNil — ключа нет
+func (c *Cache) Get(ctx context.Context, key string) ([]byte, error) {
+	reply, err := c.client.Do(ctx, "GET", c.prefix+key)
+	if err != nil {
+		return nil, err
+	}
+	if reply == nil {
+		return nil, ErrNil
+	}
+	return bytesReply(reply)
+}
+
+// Set записывает значение ключа
+func (c *Cache) Set(ctx context.Context, key string, value []byte) error {
+	_, err := c.client.Do(ctx, "SET", c.prefix+key, string(value))
+	return err
+}
+
+// HGet возвращает поле хеша; ErrNil — поля нет
+func (c *Cache) HGet(ctx context.Context, key, field string) ([]byte, error) {
+	reply, err := c.client.Do(ctx, "HGET", c.prefix+key, field)
+	if err != nil {
+		return nil, err
+	}
+	if reply == nil {
+		return nil, ErrNil
+	}
+	return bytesReply(reply)
+}
+
+// HGetAll возвращает все поля хеша
+func (c *Cache) HGetAll(ctx context.Context, key string) (map[string][]byte, error) {
+	reply, err := c.client.Do(ctx, "HGETALL", c.prefix+key)
+	if err != nil {
+		return nil, err
+	}
+	items, ok := reply.([]any)
+	if !ok {
+		return nil, fmt.Errorf("redis: unexpected HGETALL reply %T", reply)
+	}
+
+	fields := make(map[string][]byte, len(items)/2)
+	for i := 0; i+1 < len(items); i += 2 {
+		field, err := bytesReply(items[i])
+		if err != nil {
+			return nil, err
+		}
+		value, err := bytesReply(items[i+1])
+		if err != nil {
+			return nil, err
+		}
+		fields[string(field)] = value
+	}
+	return fields, nil
+}
+
+// HSet записывает поле хеша
+func (c *Cache) HSet(ctx context.Context, key, field string, value []byte) error {
+	_, err := c.client.Do(ctx, "HSET", c.prefix+key, field, string(value))
+	return err
+}
+
+// HDel удаляет поле хеша
+func (c *Cache) HDel(ctx context.Context, key, field string) error {
+	_, err := c.client.Do(ctx, "HDEL", c.prefix+key, field)
+	return err
+}
+
+// SAdd добавляет элементы в множество
+func (c *Cache) SAdd(ctx context.Context, key string, members ...string) error {
+	if len(members) == 0 {
+		return nil
+	}
+	_, err := c.client.Do(ctx, append([]string{"SADD", c.prefix + key}, members...)...)
+	return err
+}
+
+// SRem удаляет элементы из множества
+func (c *Cache) SRem(ctx context.Context, key string, members ...string) error {
+	if len(members) == 0 {
+		return nil
+	}
+	_, err := c.client.Do(ctx, append([]string{"SREM", c.prefix + key}, members...)...)
+	return err
+}
+
+// SMembers возвращает элементы множества
+func (c *Cache) SMembers(ctx context.Context, key string) ([]string, error) {
+	reply, err := c.client.Do(ctx, "SMEMBERS", c.prefix+key)
+	if err != nil {
+		return nil, err
+	}
+	items, ok := reply.([]any)
+	if !ok {
+		return nil, fmt.Errorf("redis: unexpected SMEMBERS reply %T", reply)
+	}
+
+	members := make([]string, 0, len(items))
+	for _, item := range items {
+		member, err := bytesReply(item)
+		if err != nil {
+			return nil, err
+		}
+		members = append(members, string(member))
+	}
+	return members, nil
+}
+
+// bytesReply приводит ответ к bulk-строке
+func bytesReply(reply any) ([]byte, error) {
+	switch v := reply.(type) {
+	case []byte:
+		return v, nil
+	case string:
+		return []byte(v), nil
+	default:
+		return nil, fmt.Errorf("redis: unexpected reply %T", reply)
+	}
+}
@@ -0,0 +1,271 @@
+package redis
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// defaultTimeout таймаут команды, если в контексте нет дедлайна
+const defaultTimeout = 5 * time.Second
+
+// Ограничения длины bulk-строки и массива в ответе: поврежденный поток не должен
+// заставлять клиент выделять гигабайты памяти
+const (
+	maxBulkLen  = 64 << 20
+	maxArrayLen = 1 << 20
+)
+
+// ErrNil возвращается, если ключа или поля нет
+var ErrNil = errors.New("redis: nil")
+
+// Error ошибка, которую вернул сервер Redis
+type Error string
+
+func (e Error) Error() string {
+	return "redis: " + string(e)
+}
+
+// Client минимальный клиент Redis (протокол RESP2) на одном соединении.
+// Соединение открывается при первой команде и переоткрывается после сетевой ошибки
+type Client struct {
+	addr     string
+	username string
+	password string
+	db       int
+	// dialContext открывает соединение с сервером (подменяется в тестах)
+	dialContext func(ctx context.Context, network, addr string) (net.Conn, error)
+
+	mu   sync.Mutex
+	conn net.Conn
+	rd   *bufio.Reader
+}
+
+// New создает клиент по адресу вида redis://[user:password@]host:port[/db]
+func New(rawURL string) (*Client, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid redis URL: %w", err)
+	}
+	if u.Scheme != "redis" {
+		return nil, fmt.Errorf("invalid redis URL: unsupported scheme %q", u.Scheme)
+	}
+
+	dialer := &net.Dialer{Timeout: defaultTimeout}
+	c := &Client{addr: u.Host, dialContext: dialer.DialContext}
+	if u.Port() == "" {
+		c.addr = net.JoinHostPort(u.Hostname(), "6379")
+	}
+	if u.User != nil {
+		c.password, _ = u.User.Password()
+		c.username = u.User.Username()
+	}
+	if db := strings.Trim(u.Path, "/"); db != "" {
+		if c.db, err = strconv.Atoi(db); err != nil {
+			return nil, fmt.Errorf("invalid redis database %q", db)
+		}
+	}
+	return c, nil
+}
+
+// Do выполняет команду и возвращает ответ: string, int64, []byte, []any или nil
+func (c *Client) Do(ctx context.Context, args ...string) (any, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.conn == nil {
+		conn, rd, err := c.dial(ctx)
+		if err != nil {
+			return nil, err
+		}
+		c.conn, c.rd = conn, rd
+	}
+
+	setDeadline(ctx, c.conn)
+	reply, err := roundTrip(c.conn, c.rd, args)
+	if err != nil {
+		var redisErr Error
+		if !errors.As(err, &redisErr) {
+			// После сетевой ошибки состояние протокола неизвестно
+			c.conn.Close()
+			c.conn, c.rd = nil, nil
+		}
+		return nil, err
+	}
+	return reply, nil
+}
+
+// Close закрывает соединение
+func (c *Client) Close() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.conn == nil {
+		return nil
+	}
+	err := c.conn.Close()
+	c.conn, c.rd = nil, nil
+	return err
+}
+
+// Subscribe подписывается на канал на отдельном соединении и вызывает fn для каждого
+// сообщения. onSubscribe вызывается после каждой (пере)подписки: сообщения, пришедшие
+// во время разрыва, потеряны. Возвращает ошибку при разрыве или отмене контекста
+func (c *Client) Subscribe(ctx context.Context, channel string, onSubscribe func(), fn func(message string)) error {
+	conn, rd, err := c.dial(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	stop := context.AfterFunc(ctx, func() { conn.Close() })
+	defer stop()
+
+	setDeadline(ctx, conn)
+	if _, err := roundTrip(conn, rd, []string{"SUBSCRIBE", channel}); err != nil {
+		return err
+	}
+	conn.SetDeadline(time.Time{})
+	onSubscribe()
+
+	for {
+		reply, err := readReply(rd)
+		if err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			return err
+		}
+		parts, ok := reply.([]any)
+		if !ok || len(parts) != 3 {
+			continue
+		}
+		if kind, _ := parts[0].([]byte); string(kind) != "message" {
+			continue
+		}
+		if payload, ok := parts[2].([]byte); ok {
+			fn(string(payload))
+		}
+	}
+}
+
+// dial открывает соединение, авторизуется и выбирает базу
+func (c *Client) dial(ctx context.Context) (net.Conn, *bufio.Reader, error) {
+	conn, err := c.dialContext(ctx, "tcp", c.addr)
+	if err != nil {
+		return nil, nil, fmt.Errorf("redis: failed to connect to %s: %w", c.addr, err)
+	}
+	rd := bufio.NewReader(conn)
+
+	setDeadline(ctx, conn)
+	if c.password != "" {
+		args := []string{"AUTH", c.password}
+		if c.username != "" {
+			args = []string{"AUTH", c.username, c.password}
+		}
+		if _, err := roundTrip(conn, rd, args); err != nil {
+			conn.Close()
+			return nil, nil, err
+		}
+	}
+	if c.db != 0 {
+		if _, err := roundTrip(conn, rd, []string{"SELECT", strconv.Itoa(c.db)}); err != nil {
+			conn.Close()
+			return nil, nil, err
+		}
+	}
+	return conn, rd, nil
+}
+
+// setDeadline ограничивает команду дедлайном контекста или defaultTimeout
+func setDeadline(ctx context.Context, conn net.Conn) {
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		deadline = time.Now().Add(defaultTimeout)
+	}
+	conn.SetDeadline(deadline)
+}
+
+// roundTrip отправляет команду и читает ответ
+func roundTrip(w io.Writer, rd *bufio.Reader, args []string) (any, error) {
+	if err := writeCommand(w, args); err != nil {
+		return nil, err
+	}
+	return readReply(rd)
+}
+
+// writeCommand кодирует команду массивом bulk-строк
+func writeCommand(w io.Writer, args []string) error {
+	var b strings.Builder
+	fmt.Fprintf(&b, "*%d\r\n", len(args))
+	for _, arg := range args {
+		fmt.Fprintf(&b, "$%d\r\n%s\r\n", len(arg), arg)
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// readReply читает один ответ RESP2
+func readReply(rd *bufio.Reader) (any, error) {
+	line, err := rd.ReadString('\n')
+	if err != nil {
+		return nil, err
+	}
+	line = strings.TrimSuffix(line, "\r\n")
+	if line == "" {
+		return nil, fmt.Errorf("redis: empty reply")
+	}
+
+	switch line[0] {
+	case '+':
+		return line[1:], nil
+	case '-':
+		return nil, Error(line[1:])
+	case ':':
+		return strconv.ParseInt(line[1:], 10, 64)
+	case '$':
+		n, err := strconv.Atoi(line[1:])
+		if err != nil {
+			return nil, fmt.Errorf("redis: invalid bulk length %q", line)
+		}
+		if n < 0 {
+			return nil, nil
+		}
+		if n > maxBulkLen {
+			return nil, fmt.Errorf("redis: bulk length %d exceeds limit", n)
+		}
+		buf := make([]byte, n+2)
+		if _, err := io.ReadFull(rd, buf); err != nil {
+			return nil, err
+		}
+		return buf[:n], nil
+	case '*':
+		n, err := strconv.Atoi(line[1:])
+		if err != nil {
+			return nil, fmt.Errorf("redis: invalid array length %q", line)
+		}
+		if n < 0 {
+			return nil, nil
+		}
+		if n > maxArrayLen {
+			return nil, fmt.Errorf("redis: array length %d exceeds limit", n)
+		}
+		items := make([]any, n)
+		for i := range items {
+			if items[i], err = readReply(rd); err != nil {
+				return nil, err
+			}
+		}
+		return items, nil
+	default:
+		return nil, fmt.Errorf("redis: unexpected reply %q", line)
+	}
+}
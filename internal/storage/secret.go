@@ -0,0 +1,44 @@
+package storage
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/reelser-bot/internal/secrets"
+)
+
+// LoadSecret читает файл с секретом (cookies, сессии, токены) и расшифровывает его
+// связкой keys. Незашифрованный файл возвращается как есть, чтобы шифрование можно
+// было включить на работающем боте; зашифрованный без ключа — ошибка
+func LoadSecret(path string, keys *secrets.Keyring) ([]byte, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+
+	if !secrets.IsSealed(data) {
+		return data, nil
+	}
+	if keys == nil {
+		return nil, fmt.Errorf("%s is encrypted, set STORAGE_ENCRYPTION_KEY", path)
+	}
+
+	plaintext, err := keys.Open(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt %s: %w", path, err)
+	}
+	return plaintext, nil
+}
+
+// SaveSecret атомарно записывает секрет с правами 0600, зашифровав его текущим
+// ключом связки. Без ключей (keys == nil) файл сохраняется открытым текстом
+func SaveSecret(path string, data []byte, keys *secrets.Keyring) error {
+	if keys != nil {
+		sealed, err := keys.Seal(data)
+		if err != nil {
+			return fmt.Errorf("failed to encrypt %s: %w", path, err)
+		}
+		data = sealed
+	}
+	return writeFile(path, data, 0o600)
+}
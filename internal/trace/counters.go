@@ -0,0 +1,55 @@
+package trace
+
+import (
+	"context"
+	"sync"
+)
+
+type countersKey struct{}
+
+// Counters счетчики событий запроса (например, попаданий в кэш) для итоговой записи о задаче
+type Counters struct {
+	mu     sync.Mutex
+	values map[string]int
+}
+
+// NewCounters создает пустые счетчики
+func NewCounters() *Counters {
+	return &Counters{values: make(map[string]int)}
+}
+
+// WithCounters привязывает к контексту счетчики запроса
+func WithCounters(ctx context.Context, c *Counters) context.Context {
+	if c == nil {
+		return ctx
+	}
+	return context.WithValue(ctx, countersKey{}, c)
+}
+
+// Count увеличивает счетчик name у запроса из контекста.
+// Если счетчики не привязаны, ничего не делает
+func Count(ctx context.Context, name string) {
+	if c, ok := ctx.Value(countersKey{}).(*Counters); ok {
+		c.Add(name)
+	}
+}
+
+// Add увеличивает счетчик name
+func (c *Counters) Add(name string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.values[name]++
+}
+
+// Snapshot возвращает копию счетчиков
+func (c *Counters) Snapshot() map[string]int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	out := make(map[string]int, len(c.values))
+	for name, value := range c.values {
+		out[name] = value
+	}
+	return out
+}
@@ -0,0 +1,131 @@
+package trace
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+type contextKey struct{}
+
+type traceContext struct {
+	store     *Store
+	requestID string
+}
+
+// NewContext привязывает к контексту хранилище трассировок и ID запроса
+func NewContext(ctx context.Context, store *Store, requestID string) context.Context {
+	return context.WithValue(ctx, contextKey{}, traceContext{store: store, requestID: requestID})
+}
+
+// RequestID возвращает ID запроса из контекста
+func RequestID(ctx context.Context) string {
+	if tc, ok := ctx.Value(contextKey{}).(traceContext); ok {
+		return tc.requestID
+	}
+	return ""
+}
+
+// Record сохраняет вывод внешней команды для запроса из контекста.
+// Если трассировка не настроена, ничего не делает
+func Record(ctx context.Context, command string, args []string, output []byte, runErr error) {
+	tc, ok := ctx.Value(contextKey{}).(traceContext)
+	if !ok || tc.store == nil || tc.requestID == "" {
+		return
+	}
+	tc.store.write(tc.requestID, command, args, output, runErr)
+}
+
+// Store хранит вывод внешних команд по ID запроса в ограниченной по размеру директории
+type Store struct {
+	dir      string
+	maxFiles int
+
+	mu sync.Mutex
+}
+
+// NewStore создает хранилище трассировок. Пустая директория отключает трассировку
+func NewStore(dir string, maxFiles int) *Store {
+	if dir == "" {
+		return nil
+	}
+	return &Store{dir: dir, maxFiles: maxFiles}
+}
+
+// Path возвращает путь к файлу трассировки запроса
+func (s *Store) Path(requestID string) (string, error) {
+	if s == nil {
+		return "", fmt.Errorf("tracing is disabled")
+	}
+	if requestID == "" || strings.ContainsAny(requestID, `/\.`) {
+		return "", fmt.Errorf("invalid request id")
+	}
+
+	path := filepath.Join(s.dir, requestID+".log")
+	if _, err := os.Stat(path); err != nil {
+		return "", fmt.Errorf("trace not found: %w", err)
+	}
+	return path, nil
+}
+
+func (s *Store) write(requestID, command string, args []string, output []byte, runErr error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := os.MkdirAll(s.dir, 0o755); err != nil {
+		return
+	}
+
+	file, err := os.OpenFile(filepath.Join(s.dir, requestID+".log"), os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o600)
+	if err != nil {
+		return
+	}
+	defer file.Close()
+
+	status := "ok"
+	if runErr != nil {
+		status = runErr.Error()
+	}
+
+	fmt.Fprintf(file, "=== %s %s %s\n", time.Now().Format(time.RFC3339), command, strings.Join(args, " "))
+	fmt.Fprintf(file, "--- status: %s\n", status)
+	file.Write(output)
+	fmt.Fprintln(file)
+
+	s.prune()
+}
+
+// prune удаляет самые старые трассировки сверх лимита; вызывается под s.mu
+func (s *Store) prune() {
+	if s.maxFiles <= 0 {
+		return
+	}
+
+	entries, err := os.ReadDir(s.dir)
+	if err != nil || len(entries) <= s.maxFiles {
+		return
+	}
+
+	type fileInfo struct {
+		path    string
+		modTime time.Time
+	}
+	files := make([]fileInfo, 0, len(entries))
+	for _, entry := range entries {
+		info, err := entry.Info()
+		if err != nil || entry.IsDir() {
+			continue
+		}
+		files = append(files, fileInfo{path: filepath.Join(s.dir, entry.Name()), modTime: info.ModTime()})
+	}
+
+	sort.Slice(files, func(i, j int) bool { return files[i].modTime.Before(files[j].modTime) })
+	for i := 0; i < len(files)-s.maxFiles; i++ {
+		os.Remove(files[i].path)
+	}
+}
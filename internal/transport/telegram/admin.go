@@ -0,0 +1,43 @@
+package telegram
+
+import (
+	"log/slog"
+	"strings"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// isAdmin проверяет, отправлено ли сообщение администратором бота
+func (h *Handler) isAdmin(message *tgbotapi.Message) bool {
+	if message == nil || message.From == nil {
+		return false
+	}
+	return h.auth.IsAdmin(int64(message.From.ID))
+}
+
+// handleTraceCommand отправляет администратору вывод yt-dlp по ID запроса: /trace <id>
+func (h *Handler) handleTraceCommand(message *tgbotapi.Message) {
+	chatID := message.Chat.ID
+
+	requestID := strings.TrimSpace(message.CommandArguments())
+	if requestID == "" {
+		h.sendMessage(chatID, "Использование: /trace &lt;код запроса&gt;")
+		return
+	}
+
+	path, err := h.traces.Path(requestID)
+	if err != nil {
+		h.sendMessage(chatID, "❌ Трассировка для этого запроса не найдена.")
+		return
+	}
+
+	doc := tgbotapi.NewDocument(chatID, tgbotapi.FilePath(path))
+	doc.Caption = "Вывод yt-dlp для запроса " + requestID
+	if _, err := h.bot.Send(doc); err != nil {
+		h.logger.Error("Failed to send trace",
+			slog.String("request_id", requestID),
+			slog.Any("error", err),
+		)
+		h.sendMessage(chatID, "❌ Не удалось отправить трассировку.")
+	}
+}
@@ -0,0 +1,112 @@
+package telegram
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"html"
+	"io"
+	"log/slog"
+	"os"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+
+	"github.com/reelser-bot/internal/platform"
+	"github.com/reelser-bot/internal/services/downloader"
+)
+
+// sendAnimation отправляет MP4 без звука как GIF-анимацию, в том числе в Business чаты
+func (h *Handler) sendAnimation(
+	chatID int64, businessConnectionID, filePath string, maxAllowed int64, caption string,
+) (*tgbotapi.Message, error) {
+	file, err := os.Open(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open file: %w", err)
+	}
+	defer file.Close()
+
+	fileInfo, err := file.Stat()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get file info: %w", err)
+	}
+	if fileInfo.Size() > maxAllowed {
+		return nil, fmt.Errorf("file size %d exceeds maximum allowed size %d", fileInfo.Size(), maxAllowed)
+	}
+
+	params := tgbotapi.Params{}
+	params.AddNonEmpty("business_connection_id", businessConnectionID)
+	params.AddNonZero64("chat_id", chatID)
+	params.AddNonEmpty("caption", caption)
+	thumbnail := h.addVideoProps(params, filePath)
+	defer h.removeThumbnail(thumbnail)
+
+	h.logger.Info("Sending animation",
+		slog.Int64("chat_id", chatID),
+		slog.String("file", filePath),
+		slog.Int64("size", fileInfo.Size()),
+	)
+
+	var sent tgbotapi.Message
+	err = h.retryUpload(file, func(r io.Reader) error {
+		files := videoFiles(fileInfo.Name(), r, thumbnail)
+		files[0].Name = "animation"
+
+		resp, err := h.bot.UploadFiles("sendAnimation", params, files)
+		if err != nil {
+			return err
+		}
+		return json.Unmarshal(resp.Result, &sent)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to send animation: %w", err)
+	}
+
+	h.logger.Info("Animation sent successfully", slog.Int64("chat_id", chatID))
+	return &sent, nil
+}
+
+// convertToAnimation конвертирует скачанное видео для /gif. Исходный файл удаляется;
+// при ошибке пользователь уже уведомлен
+func (h *Handler) convertToAnimation(req *downloadRequest, filePath string) (string, bool) {
+	if platform.IsSlideshow(filePath) {
+		h.notify(req, "❌ Из фото-слайдшоу нельзя сделать GIF-анимацию.")
+		return "", false
+	}
+
+	animation, err := h.downloader.ToAnimation(req.phaseContext(), filePath)
+	if err != nil {
+		h.logger.Error("Failed to convert video to animation",
+			slog.String("file", filePath),
+			slog.Any("error", err),
+		)
+		if errors.Is(err, downloader.ErrAnimationTooLong) {
+			h.notifyAnimationTooLong(req)
+			return "", false
+		}
+
+		h.recordFailure(req, err)
+		if h.notifyPhaseTimeout(req, err, "") {
+			return "", false
+		}
+		h.notify(req, "❌ Не удалось сделать GIF-анимацию: "+html.EscapeString(err.Error()))
+		return "", false
+	}
+	return animation, true
+}
+
+// animationDurationLimit возвращает лимит длительности для /gif (0 — запрос не на GIF)
+func animationDurationLimit(req *downloadRequest) time.Duration {
+	if !req.animation {
+		return 0
+	}
+	return downloader.MaxAnimationDuration
+}
+
+// notifyAnimationTooLong сообщает, что ролик слишком длинный для GIF-анимации
+func (h *Handler) notifyAnimationTooLong(req *downloadRequest) {
+	h.notify(req, fmt.Sprintf(
+		"❌ Ролик слишком длинный для GIF-анимации (максимум %s). Укажи фрагмент после ссылки: <code>/gif ссылка 00:10-00:20</code>",
+		h.locale(req.language).duration(downloader.MaxAnimationDuration),
+	))
+}
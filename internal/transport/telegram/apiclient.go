@@ -0,0 +1,273 @@
+package telegram
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net"
+	"net/http"
+	"path"
+	"strings"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+
+	"github.com/reelser-bot/internal/config"
+)
+
+// uploadMethods методы Bot API, которые могут загружать файлы. Политика загрузок
+// применяется, только если тело запроса потоковое (файл, а не file_id)
+var uploadMethods = map[string]bool{
+	"sendVideo":      true,
+	"sendAudio":      true,
+	"sendDocument":   true,
+	"sendPhoto":      true,
+	"sendAnimation":  true,
+	"sendVoice":      true,
+	"sendVideoNote":  true,
+	"sendMediaGroup": true,
+	"sendPaidMedia":  true,
+}
+
+// retryableStatusError сообщает о временной ошибке Bot API (429, 5xx) для запроса,
+// который нельзя повторить на уровне HTTP (потоковая загрузка файла)
+type retryableStatusError struct {
+	Status     int
+	RetryAfter time.Duration
+}
+
+func (e *retryableStatusError) Error() string {
+	return fmt.Sprintf("telegram api returned status %d", e.Status)
+}
+
+// apiClient HTTP-клиент Bot API с таймаутами и повторами по политикам из конфигурации.
+// Запросы с воспроизводимым телом (обычные вызовы) повторяются здесь же;
+// потоковые загрузки файлов получают только таймаут, а повторяются через retryUpload
+type apiClient struct {
+	logger   *slog.Logger
+	base     *http.Client
+	messages config.RetryPolicy
+	uploads  config.RetryPolicy
+}
+
+func newAPIClient(logger *slog.Logger, cfg config.TelegramConfig) *apiClient {
+	return &apiClient{
+		logger:   logger,
+		base:     &http.Client{},
+		messages: cfg.Messages,
+		uploads:  cfg.Uploads,
+	}
+}
+
+// Do выполняет запрос согласно политике метода
+func (c *apiClient) Do(req *http.Request) (*http.Response, error) {
+	method := path.Base(req.URL.Path)
+
+	// Long polling сам ограничивает время ожидания и повторяется в poll
+	if method == "getUpdates" {
+		return c.base.Do(req)
+	}
+
+	policy := c.messages
+	if uploadMethods[method] && req.GetBody == nil {
+		policy = c.uploads
+	}
+	attempts := max(policy.Attempts, 1)
+
+	for attempt := 1; ; attempt++ {
+		if attempt > 1 {
+			body, err := req.GetBody()
+			if err != nil {
+				return nil, fmt.Errorf("failed to rewind request body: %w", err)
+			}
+			req.Body = body
+		}
+
+		resp, cancel, err := c.do(req, policy.Timeout)
+		retryAfter, retryable := retryableResponse(resp, err)
+		if !retryable {
+			if err != nil {
+				return nil, err
+			}
+			resp.Body = &cancelOnClose{ReadCloser: resp.Body, cancel: cancel}
+			return resp, nil
+		}
+
+		replayable := req.GetBody != nil
+		if attempt >= attempts || !replayable {
+			if err != nil {
+				cancel()
+				return nil, err
+			}
+			if !replayable {
+				resp.Body.Close()
+				cancel()
+				return nil, &retryableStatusError{Status: resp.StatusCode, RetryAfter: retryAfter}
+			}
+			resp.Body = &cancelOnClose{ReadCloser: resp.Body, cancel: cancel}
+			return resp, nil
+		}
+
+		if resp != nil {
+			resp.Body.Close()
+		}
+		cancel()
+
+		delay := max(backoffDelay(policy, attempt), retryAfter)
+		c.logger.Warn("Telegram API call failed, retrying",
+			slog.String("method", method),
+			slog.Int("attempt", attempt),
+			slog.Duration("delay", delay),
+			slog.Any("error", describeFailure(resp, err)),
+		)
+
+		if err := sleepCtx(req.Context(), delay); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// do выполняет одну попытку с таймаутом. cancel нужно вызвать после чтения ответа
+func (c *apiClient) do(req *http.Request, timeout time.Duration) (*http.Response, context.CancelFunc, error) {
+	ctx, cancel := req.Context(), context.CancelFunc(func() {})
+	if timeout > 0 {
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+	}
+
+	resp, err := c.base.Do(req.WithContext(ctx))
+	if err != nil {
+		cancel()
+		return nil, func() {}, err
+	}
+	return resp, cancel, nil
+}
+
+// retryableResponse определяет, стоит ли повторить вызов, и сколько ждать по retry_after.
+// Тело ответа 429 читается и подменяется копией
+func retryableResponse(resp *http.Response, err error) (time.Duration, bool) {
+	if err != nil {
+		var netErr net.Error
+		return 0, errors.As(err, &netErr) || errors.Is(err, io.ErrUnexpectedEOF) || errors.Is(err, context.DeadlineExceeded)
+	}
+
+	switch {
+	case resp.StatusCode == http.StatusTooManyRequests:
+		data, _ := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		resp.Body = io.NopCloser(bytes.NewReader(data))
+
+		var apiResp tgbotapi.APIResponse
+		if json.Unmarshal(data, &apiResp) == nil && apiResp.Parameters != nil {
+			return time.Duration(apiResp.Parameters.RetryAfter) * time.Second, true
+		}
+		return 0, true
+	case resp.StatusCode >= http.StatusInternalServerError:
+		return 0, true
+	default:
+		return 0, false
+	}
+}
+
+// retryUpload повторяет потоковую загрузку файла по политике загрузок.
+// send получает ридер файла, перемотанного в начало; tgbotapi закрывает переданные
+// ридеры после отправки, поэтому сам файл скрыт за оберткой без Close
+func (h *Handler) retryUpload(file io.ReadSeeker, send func(r io.Reader) error) error {
+	first := true
+	return h.retryUploads(func() error {
+		if !first {
+			if _, err := file.Seek(0, io.SeekStart); err != nil {
+				return fmt.Errorf("failed to rewind file: %w", err)
+			}
+		}
+		first = false
+		return send(struct{ io.Reader }{file})
+	})
+}
+
+// retryUploads повторяет загрузку по политике загрузок. send должен сам заново
+// открывать файлы на каждой попытке (например, через tgbotapi.FilePath)
+func (h *Handler) retryUploads(send func() error) error {
+	attempts := max(h.uploadPolicy.Attempts, 1)
+
+	for attempt := 1; ; attempt++ {
+		err := send()
+		if err == nil {
+			return nil
+		}
+
+		retryAfter, retryable := retryableSendError(err)
+		if !retryable || attempt >= attempts {
+			return err
+		}
+
+		delay := max(backoffDelay(h.uploadPolicy, attempt), retryAfter)
+		h.logger.Warn("File upload failed, retrying",
+			slog.Int("attempt", attempt),
+			slog.Duration("delay", delay),
+			slog.Any("error", err),
+		)
+		time.Sleep(delay)
+	}
+}
+
+// retryableSendError определяет, стоит ли повторить загрузку после ошибки Bot API
+func retryableSendError(err error) (time.Duration, bool) {
+	var statusErr *retryableStatusError
+	if errors.As(err, &statusErr) {
+		return statusErr.RetryAfter, true
+	}
+
+	var apiErr *tgbotapi.Error
+	if errors.As(err, &apiErr) {
+		return time.Duration(apiErr.RetryAfter) * time.Second, apiErr.RetryAfter > 0
+	}
+
+	var netErr net.Error
+	return 0, errors.As(err, &netErr) || errors.Is(err, io.ErrUnexpectedEOF) || errors.Is(err, context.DeadlineExceeded)
+}
+
+// backoffDelay возвращает экспоненциальную задержку перед повтором после attempt-й попытки
+func backoffDelay(policy config.RetryPolicy, attempt int) time.Duration {
+	delay := policy.Backoff << (attempt - 1)
+	if policy.MaxBackoff > 0 && (delay > policy.MaxBackoff || delay <= 0) {
+		delay = policy.MaxBackoff
+	}
+	return delay
+}
+
+// describeFailure возвращает ошибку попытки для логов
+func describeFailure(resp *http.Response, err error) any {
+	if err != nil {
+		return err
+	}
+	return strings.TrimSpace(resp.Status)
+}
+
+// sleepCtx ждет d или отмены контекста
+func sleepCtx(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// cancelOnClose освобождает контекст попытки после чтения тела ответа
+type cancelOnClose struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+func (b *cancelOnClose) Close() error {
+	err := b.ReadCloser.Close()
+	b.cancel()
+	return err
+}
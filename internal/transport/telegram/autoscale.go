@@ -0,0 +1,134 @@
+package telegram
+
+import (
+	"log/slog"
+	"sync"
+	"time"
+)
+
+// workerScaler подбирает, сколько воркеров загрузки работает одновременно: от min до max.
+// Когда все работающие воркеры заняты и в очереди ждут задачи, пул сразу растет на
+// длину очереди (но не выше max). Когда часть пула простаивает дольше idle, пул
+// уменьшается на один воркер. Под давлением на систему пул ограничен pressureCap и не растет.
+//
+// Горутины воркеров запускаются по max, а размер пула задается лимитом очереди
+// (jobQueue.setLimit): лишние воркеры просто ждут
+type workerScaler struct {
+	logger *slog.Logger
+	queue  *jobQueue
+
+	min, max int
+	interval time.Duration
+	idle     time.Duration
+
+	mu   sync.Mutex
+	size int
+	// pressureCap предел пула под давлением; 0 — давления нет
+	pressureCap int
+	// idleSince с какого момента часть пула простаивает; нулевое — пул занят целиком
+	idleSince time.Time
+}
+
+func newWorkerScaler(logger *slog.Logger, queue *jobQueue, minSize, maxSize int, interval, idle time.Duration) *workerScaler {
+	minSize = min(max(minSize, 1), maxSize)
+	s := &workerScaler{
+		logger:   logger,
+		queue:    queue,
+		min:      minSize,
+		max:      maxSize,
+		interval: interval,
+		idle:     idle,
+		size:     minSize,
+	}
+	queue.setLimit(minSize)
+	return s
+}
+
+// enabled сообщает, что размер пула меняется (WORKER_POOL_MIN меньше WORKER_POOL_MAX)
+func (s *workerScaler) enabled() bool {
+	return s.min < s.max
+}
+
+// current возвращает текущий размер пула с учетом давления
+func (s *workerScaler) current() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.limit()
+}
+
+// run пересчитывает размер пула каждые interval
+func (s *workerScaler) run() {
+	if !s.enabled() {
+		return
+	}
+
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	for now := range ticker.C {
+		s.adjust(now)
+	}
+}
+
+// adjust пересчитывает размер пула по длине очереди и простою воркеров
+func (s *workerScaler) adjust(now time.Time) {
+	pending, active, _ := s.queue.occupancy()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	size := s.size
+	switch {
+	case pending > 0 && active >= s.limit():
+		size = min(size+pending, s.max)
+		s.idleSince = time.Time{}
+	case active < size:
+		if s.idleSince.IsZero() {
+			s.idleSince = now
+		} else if now.Sub(s.idleSince) >= s.idle {
+			size--
+			s.idleSince = now
+		}
+	default:
+		s.idleSince = time.Time{}
+	}
+	// Под давлением пул не растет
+	if s.pressureCap > 0 {
+		size = min(size, max(s.size, s.pressureCap))
+	}
+	s.resize(max(size, s.min), pending, active)
+}
+
+// setPressure ограничивает пул под давлением на систему (0 — давление спало)
+func (s *workerScaler) setPressure(workers int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.pressureCap = workers
+	s.queue.setLimit(s.limit())
+}
+
+// resize меняет размер пула; вызывается под s.mu
+func (s *workerScaler) resize(size, pending, active int) {
+	if size == s.size {
+		return
+	}
+
+	s.logger.Info("Download worker pool resized",
+		slog.Int("from", s.size),
+		slog.Int("to", size),
+		slog.Int("pending", pending),
+		slog.Int("active", active),
+	)
+	s.size = size
+	s.queue.setLimit(s.limit())
+}
+
+// limit возвращает, сколько воркеров может работать сейчас; вызывается под s.mu
+func (s *workerScaler) limit() int {
+	if s.pressureCap > 0 {
+		return min(s.size, s.pressureCap)
+	}
+	return s.size
+}
@@ -0,0 +1,132 @@
+package telegram
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"path/filepath"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+
+	"github.com/reelser-bot/internal/clock"
+	"github.com/reelser-bot/internal/config"
+	"github.com/reelser-bot/internal/platform"
+	"github.com/reelser-bot/internal/services/bandwidth"
+)
+
+// newBandwidthBudget создает суточный бюджет трафика (DAILY_BANDWIDTH_GB)
+func newBandwidthBudget(logger *slog.Logger, cfg *config.Config) *bandwidth.Budget {
+	return bandwidth.NewBudget(
+		logger,
+		int64(cfg.Download.DailyBandwidthGB)*1024*1024*1024,
+		cfg.Download.BandwidthWarnPercent,
+		filepath.Join(cfg.Storage.DataDir, "bandwidth.json"),
+		clock.Real{},
+	)
+}
+
+// budgetExhausted проверяет суточный бюджет трафика перед загрузкой и отклоняет запрос,
+// если бюджет исчерпан. Видео с сохраненным file_id к этому моменту уже отправлены
+// (serveCachedVideo). Возвращает true, если запрос обработан и загружать не нужно
+func (h *Handler) budgetExhausted(req *downloadRequest) bool {
+	if h.bandwidth.Allow() {
+		return false
+	}
+
+	h.clearStatusMessage(req)
+
+	h.logger.Warn("Bandwidth budget exhausted, download rejected",
+		slog.Int64("chat_id", req.chatID),
+		slog.String("url", req.url),
+	)
+	h.notify(req, fmt.Sprintf(
+		"⛔ Суточный лимит трафика бота исчерпан. Новые видео снова будут доступны примерно через %s.",
+		h.locale(req.language).age(h.bandwidth.RetryAfter()),
+	))
+	return true
+}
+
+// bandwidthWarning возвращает предупреждение для подписи видео, если суточный бюджет
+// трафика почти исчерпан (BANDWIDTH_WARN_PERCENT), иначе пустую строку
+func (h *Handler) bandwidthWarning(req *downloadRequest) string {
+	if !h.bandwidth.Low() {
+		return ""
+	}
+	return fmt.Sprintf(
+		"⚠️ Суточный лимит трафика бота почти исчерпан: осталось %s. Скоро новые видео станут недоступны до его восполнения.",
+		h.locale(req.language).size(h.bandwidth.Remaining()),
+	)
+}
+
+// cachedFileID возвращает file_id уже загруженного в Telegram видео, если его можно
+// переслать вместо загрузки (то же качество, целое видео, без платного медиа)
+func (h *Handler) cachedFileID(req *downloadRequest) (string, bool) {
+	if req.audioOnly || req.animation || req.videoNote || req.document || req.chapters ||
+		req.section != nil || platform.MaxHeight(req.quality) > 0 || req.paidStars > 0 {
+		return "", false
+	}
+	return h.fileIDs.get(req.url)
+}
+
+// serveCachedVideo отправляет видео, уже загруженное в Telegram, по сохраненному file_id:
+// мгновенно и без трафика. Устаревший file_id удаляется из кэша, и видео загружается
+// заново. Возвращает true, если видео отправлено
+func (h *Handler) serveCachedVideo(req *downloadRequest) bool {
+	fileID, ok := h.cachedFileID(req)
+	if !ok {
+		return false
+	}
+
+	if !h.waitSlowMode(req) {
+		return false
+	}
+
+	h.enterPhase(req, phaseUpload)
+	sent, err := h.sendCachedVideo(req, fileID)
+	if err != nil {
+		if !isFileIDError(err) || !h.forgetFileID(req.url, err) {
+			h.logger.Warn("Failed to send cached video",
+				slog.String("url", req.url),
+				slog.Any("error", err),
+			)
+		}
+		return false
+	}
+
+	h.clearStatusMessage(req)
+	req.summary.count("file_id_cache_hits")
+	req.summary.sent(0)
+	h.logger.Info("Served cached video by file_id",
+		slog.Int64("chat_id", req.chatID),
+		slog.String("url", req.url),
+	)
+
+	h.applyDeliveryEffects(req, sent)
+	h.deleteOriginalMessage(req)
+	return true
+}
+
+// sendCachedVideo отправляет видео по file_id, в том числе в Business чаты
+func (h *Handler) sendCachedVideo(req *downloadRequest, fileID string) (*tgbotapi.Message, error) {
+	params := tgbotapi.Params{}
+	params.AddNonEmpty("business_connection_id", req.businessConnectionID)
+	params.AddNonZero64("chat_id", req.chatID)
+	params.AddNonEmpty("video", fileID)
+	params.AddBool("supports_streaming", true)
+	if markup := h.shareMarkup(req.url); markup != nil && req.businessConnectionID == "" {
+		if err := params.AddInterface("reply_markup", markup); err != nil {
+			return nil, fmt.Errorf("failed to encode reply markup: %w", err)
+		}
+	}
+
+	resp, err := h.bot.MakeRequest("sendVideo", params)
+	if err != nil {
+		return nil, err
+	}
+
+	var sent tgbotapi.Message
+	if err := json.Unmarshal(resp.Result, &sent); err != nil {
+		return nil, fmt.Errorf("failed to decode sent message: %w", err)
+	}
+	return &sent, nil
+}
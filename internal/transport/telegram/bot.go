@@ -4,10 +4,19 @@ import (
 	"context"
 	"fmt"
 	"log/slog"
+	"path/filepath"
 	"runtime"
+	"sync"
+	"sync/atomic"
 
+	"github.com/reelser-bot/internal/clock"
+	"github.com/reelser-bot/internal/config"
 	"github.com/reelser-bot/internal/services/auth"
+	"github.com/reelser-bot/internal/services/delivery"
 	"github.com/reelser-bot/internal/services/downloader"
+	"github.com/reelser-bot/internal/services/pressure"
+	"github.com/reelser-bot/internal/services/shard"
+	"github.com/reelser-bot/internal/storage/redis"
 
 	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
 )
@@ -20,25 +29,48 @@ type Bot struct {
 	ctx           context.Context
 	cancel        context.CancelFunc
 	updateWorkers int
-	updateQueue   chan tgbotapi.Update
+	updateQueue   chan incomingUpdate
+	// offsetFile хранит ID последнего обработанного апдейта между перезапусками
+	offsetFile string
+	offsetMu   sync.Mutex
+	// lastUpdateID последний полученный апдейт; pendingUpdates полученные, но еще
+	// не обработанные апдейты; savedUpdateID последний сохраненный в offsetFile
+	lastUpdateID   int
+	pendingUpdates map[int]struct{}
+	savedUpdateID  int
+
+	// webhook настройки вебхука; lastWebhookUpdate время последнего апдейта через него (UnixNano)
+	webhook           config.WebhookConfig
+	lastWebhookUpdate atomic.Int64
+	// shards распределение чатов между экземплярами; nil — шардирование выключено
+	shards *shard.Coordinator
+
+	ready     chan struct{}
+	readyOnce sync.Once
+	// healthListen адрес сервера проверки состояния; пустой — сервер не запускается
+	healthListen string
 }
 
 // NewBot создает новый экземпляр бота
 func NewBot(
-	token string,
+	cfg *config.Config,
 	logger *slog.Logger,
 	downloader *downloader.Service,
 	authService *auth.Service,
-	maxVideoSizeMB int,
-	workerCount int,
+	deliveries *delivery.Store,
 ) (*Bot, error) {
-	api, err := tgbotapi.NewBotAPI(token)
+	apiEndpoint := tgbotapi.APIEndpoint
+	if cfg.Telegram.APIEndpoint != "" {
+		apiEndpoint = cfg.Telegram.APIEndpoint
+	}
+
+	api, err := tgbotapi.NewBotAPIWithClient(cfg.Telegram.BotToken, apiEndpoint, newAPIClient(logger, cfg.Telegram))
 	if err != nil {
 		return nil, fmt.Errorf("failed to create bot API: %w", err)
 	}
 
 	botUsername := api.Self.UserName
-	handler := NewHandler(api, botUsername, logger, downloader, authService, maxVideoSizeMB, workerCount)
+	handler := NewHandler(api, botUsername, logger, downloader, authService, deliveries, cfg)
 
 	ctx, cancel := context.WithCancel(context.Background())
 
@@ -51,8 +83,11 @@ func NewBot(
 		updateWorkers = 10 // максимум 10 воркеров
 	}
 
-	// Размер очереди апдейтов = количество воркеров * 2
-	updateQueueSize := updateWorkers * 2
+	// Размер очереди апдейтов по умолчанию = количество воркеров * 2
+	updateQueueSize := cfg.Telegram.UpdateQueueSize
+	if updateQueueSize <= 0 {
+		updateQueueSize = updateWorkers * 2
+	}
 
 	bot := &Bot{
 		api:           api,
@@ -61,7 +96,30 @@ func NewBot(
 		ctx:           ctx,
 		cancel:        cancel,
 		updateWorkers: updateWorkers,
-		updateQueue:   make(chan tgbotapi.Update, updateQueueSize),
+		updateQueue:   make(chan incomingUpdate, updateQueueSize),
+		offsetFile:    filepath.Join(cfg.Storage.DataDir, "update_offset.json"),
+		webhook:       cfg.Telegram.Webhook,
+		ready:         make(chan struct{}),
+		healthListen:  cfg.Telegram.HealthListen,
+
+		pendingUpdates: make(map[int]struct{}),
+	}
+	handler.updateQueue = bot.updateQueue
+
+	if sharding := cfg.Telegram.Webhook.Sharding; sharding.Enabled {
+		bot.shards = shard.NewCoordinator(
+			logger,
+			sharding.Dir,
+			shard.Member{ID: sharding.InstanceID, URL: sharding.PeerURL},
+			sharding.Heartbeat,
+			sharding.MemberTTL,
+			clock.Real{},
+		)
+		logger.Info("Chat sharding enabled",
+			slog.String("instance", sharding.InstanceID),
+			slog.String("peer_url", sharding.PeerURL),
+			slog.String("dir", sharding.Dir),
+		)
 	}
 
 	logger.Info("Bot initialized",
@@ -78,6 +136,11 @@ func NewBot(
 func (b *Bot) Start() error {
 	b.logger.Info("Starting bot...")
 
+	// Проверка состояния отвечает 503 и до прохождения стартовых проверок
+	if b.healthListen != "" {
+		go b.serveHealth(b.ctx)
+	}
+
 	// Запускаем пул воркеров для обработки апдейтов
 	for i := 0; i < b.updateWorkers; i++ {
 		workerID := i + 1
@@ -98,16 +161,28 @@ func (b *Bot) Start() error {
 					b.logger.Info("Update worker stopped", slog.Int("worker_id", id))
 					return
 				case update := <-b.updateQueue:
-					b.handler.HandleUpdate(b.ctx, update)
+					b.processUpdate(update)
 				}
 			}
 		}(workerID)
 	}
 
-	u := tgbotapi.NewUpdate(0)
-	u.Timeout = 60
+	// Не забираем апдейты, пока стартовые проверки не завершены
+	select {
+	case <-b.ctx.Done():
+		return nil
+	case <-b.ready:
+	}
+
+	// Возвращаем в очередь запросы, принятые до перезапуска, и досылаем файлы,
+	// отправка которых прервалась
+	b.handler.ResumeJobs(b.ctx)
+	go b.handler.ResumeDeliveries()
+	go b.handler.RunGroupStatus(b.ctx, b.ownsChat)
+	go b.handler.watchQueuePositions(b.ctx)
 
-	updates := b.api.GetUpdatesChan(u)
+	b.loadOffset()
+	updates := b.receiveUpdates(b.ctx)
 
 	for {
 		select {
@@ -115,7 +190,11 @@ func (b *Bot) Start() error {
 			b.logger.Info("Bot context cancelled, stopping...")
 			return nil
 
-		case update := <-updates:
+		case update, ok := <-updates:
+			if !ok {
+				return nil
+			}
+
 			// Пытаемся добавить апдейт в очередь
 			select {
 			case b.updateQueue <- update:
@@ -125,14 +204,63 @@ func (b *Bot) Start() error {
 				b.logger.Warn("Update queue is full, dropping update",
 					slog.Int("queue_size", cap(b.updateQueue)),
 				)
+				b.finishUpdate(update.UpdateID)
 			}
 		}
 	}
 }
 
+// ShareSettings хранит настройки чатов в общем для экземпляров Redis
+func (b *Bot) ShareSettings(ctx context.Context, cache *redis.Cache) error {
+	return b.handler.chatSettings.Share(ctx, cache)
+}
+
+// WatchPressure включает сброс нагрузки по сигналам монитора: под давлением работает
+// workers воркеров загрузки (0 — половина пула)
+func (b *Bot) WatchPressure(monitor *pressure.Monitor, workers int) {
+	b.handler.watchPressure(monitor, workers)
+}
+
+// MarkReady помечает бота готовым к приему апдейтов
+func (b *Bot) MarkReady() {
+	b.readyOnce.Do(func() {
+		close(b.ready)
+		b.logger.Info("Bot marked as ready")
+	})
+}
+
+// IsReady возвращает, готов ли бот принимать апдейты
+func (b *Bot) IsReady() bool {
+	select {
+	case <-b.ready:
+		return true
+	default:
+		return false
+	}
+}
+
+// Ping проверяет доступность Telegram Bot API (getMe)
+func (b *Bot) Ping(ctx context.Context) error {
+	if _, err := b.api.GetMe(); err != nil {
+		return fmt.Errorf("getMe failed: %w", err)
+	}
+	return ctx.Err()
+}
+
+// processUpdate передает апдейт обработчику и после обработки отмечает его завершенным
+func (b *Bot) processUpdate(update incomingUpdate) {
+	defer b.finishUpdate(update.UpdateID)
+
+	if update.BusinessMessage != nil {
+		b.handler.HandleBusinessMessage(b.ctx, update.BusinessMessage)
+		return
+	}
+	b.handler.HandleUpdate(b.ctx, update.Update)
+}
+
 // Stop останавливает бота
 func (b *Bot) Stop() {
 	b.logger.Info("Stopping bot...")
 	b.cancel()
-	b.api.StopReceivingUpdates()
+	b.handler.Close()
 }
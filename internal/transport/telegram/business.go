@@ -0,0 +1,194 @@
+package telegram
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+
+	"github.com/reelser-bot/internal/platform"
+)
+
+// HandleBusinessMessage обрабатывает сообщения клиентов бизнес-аккаунта,
+// подключившего бота через Telegram Business
+func (h *Handler) HandleBusinessMessage(ctx context.Context, message *businessMessage) {
+	defer func() {
+		if r := recover(); r != nil {
+			h.logger.Error("Panic recovered in HandleBusinessMessage",
+				slog.Any("panic", r),
+			)
+		}
+	}()
+
+	if !h.businessEnabled || message == nil || message.Chat == nil || !h.chatTypeAllowed("private") {
+		return
+	}
+
+	url := h.messageURL(&message.Message, message.Text)
+	userID, ok := senderID(&message.Message)
+	if url == "" || !ok {
+		return
+	}
+
+	h.logger.Info("Received business message",
+		slog.Int64("chat_id", message.Chat.ID),
+		slog.Int64("user_id", userID),
+		slog.String("connection_id", message.BusinessConnectionID),
+		slog.String("url", url),
+	)
+
+	// Отказ в авторизации не объясняется: бот отвечает от имени владельца аккаунта,
+	// и инструкции по токену доступа бота его клиентам ни к чему
+	if h.auth != nil && h.auth.IsEnabled() && !h.auth.IsAuthorized(userID) {
+		h.logger.Info("Ignoring business message from unauthorized user", slog.Int64("user_id", userID))
+		return
+	}
+
+	spec := requestSpec{
+		chatID:               message.Chat.ID,
+		chatType:             "private",
+		userID:               userID,
+		userName:             displayName(message.From),
+		url:                  url,
+		language:             userLanguage(message.From),
+		source:               "business",
+		businessConnectionID: message.BusinessConnectionID,
+	}
+	if !h.checkPolicy(ctx, spec) || !h.acquireCooldown(spec) {
+		return
+	}
+	if !h.checkQuota(&spec) {
+		h.cooldowns.release(spec.chatID)
+		return
+	}
+
+	requestCtx, cancel := context.WithCancel(ctx)
+	req := &downloadRequest{
+		requestSpec: spec,
+
+		id:      newRequestID(),
+		baseCtx: ctx,
+		ctx:     requestCtx,
+		cancel:  cancel,
+	}
+
+	if !h.enqueueDownload(req) {
+		cancel()
+		h.cooldowns.release(spec.chatID)
+		h.refundQuota(spec)
+		h.notify(req, "⚠️ Слишком много одновременных запросов. Попробуй повторить через пару минут.")
+	}
+}
+
+// notify отправляет служебное сообщение о запросе (статус, ошибку) с учетом
+// бизнес-подключения
+func (h *Handler) notify(req *downloadRequest, text string) {
+	h.reply(req.requestSpec, text)
+}
+
+// reply отправляет служебное сообщение в чат запроса; в чаты бизнес-аккаунта — через
+// его подключение, иначе бот не может в них писать. Автоудаление (/cleanup) действует
+// только в обычных чатах
+func (h *Handler) reply(spec requestSpec, text string) {
+	if spec.businessConnectionID == "" {
+		h.sendServiceMessage(spec.chatID, text)
+		return
+	}
+	h.sendBusinessMessage(spec, text)
+}
+
+// report отправляет итоговый отчет о запросе (плейлист, главы): в отличие от
+// служебных сообщений, отчет не удаляется автоматически
+func (h *Handler) report(req *downloadRequest, text string) {
+	if req.businessConnectionID == "" {
+		h.sendMessage(req.chatID, text)
+		return
+	}
+	h.sendBusinessMessage(req.requestSpec, text)
+}
+
+// sendBusinessMessage отправляет текстовое сообщение через бизнес-подключение запроса
+func (h *Handler) sendBusinessMessage(spec requestSpec, text string) {
+	params := tgbotapi.Params{}
+	params.AddNonEmpty("business_connection_id", spec.businessConnectionID)
+	params.AddNonZero64("chat_id", spec.chatID)
+	params.AddNonEmpty("text", text)
+	params.AddNonEmpty("parse_mode", "HTML")
+
+	if _, err := h.bot.MakeRequest("sendMessage", params); err != nil {
+		h.logger.Error("Failed to send business message",
+			slog.Int64("chat_id", spec.chatID),
+			slog.Any("error", err),
+		)
+	}
+}
+
+// deliverVideoOnce отправляет видео в чат запроса с учетом бизнес-подключения и платного медиа.
+// caption — подпись к обычному видео. Возвращает отправленное сообщение, если оно известно
+func (h *Handler) deliverVideoOnce(req *downloadRequest, filePath string, maxAllowed int64, caption string) (*tgbotapi.Message, error) {
+	if req.paidStars > 0 {
+		if platform.IsSlideshow(filePath) {
+			return nil, fmt.Errorf("slideshows cannot be sent as paid media")
+		}
+		return h.sendPaidVideo(req.chatID, filePath, req.paidStars, maxAllowed)
+	}
+
+	if req.audioOnly {
+		// Название и длительность показываются в плеере Telegram
+		return h.sendAudio(req.chatID, filePath, maxAllowed, req.media)
+	}
+
+	if platform.IsSlideshow(filePath) {
+		return h.sendSlideshow(req.chatID, req.businessConnectionID, filePath, maxAllowed)
+	}
+
+	if req.animation {
+		return h.sendAnimation(req.chatID, req.businessConnectionID, filePath, maxAllowed, caption)
+	}
+
+	if req.videoNote {
+		return h.sendVideoNote(req.chatID, filePath, maxAllowed)
+	}
+
+	if req.document {
+		return h.sendDocument(req.chatID, req.businessConnectionID, filePath, maxAllowed, caption)
+	}
+
+	if req.businessConnectionID == "" {
+		return h.sendVideo(req.chatID, req.url, filePath, maxAllowed, caption)
+	}
+
+	file, err := os.Open(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open file: %w", err)
+	}
+	defer file.Close()
+
+	fileInfo, err := file.Stat()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get file info: %w", err)
+	}
+	if fileInfo.Size() > maxAllowed {
+		return nil, fmt.Errorf("file size %d exceeds maximum allowed size %d", fileInfo.Size(), maxAllowed)
+	}
+
+	params := tgbotapi.Params{}
+	params.AddNonEmpty("business_connection_id", req.businessConnectionID)
+	params.AddNonZero64("chat_id", req.chatID)
+	params.AddBool("supports_streaming", true)
+	params.AddNonEmpty("caption", caption)
+	thumbnail := h.addVideoProps(params, filePath)
+	defer h.removeThumbnail(thumbnail)
+
+	err = h.retryUpload(file, func(r io.Reader) error {
+		_, err := h.bot.UploadFiles("sendVideo", params, videoFiles(fileInfo.Name(), r, thumbnail))
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to send business video: %w", err)
+	}
+	return nil, nil
+}
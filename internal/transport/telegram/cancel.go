@@ -0,0 +1,45 @@
+package telegram
+
+import (
+	"fmt"
+	"log/slog"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// handleCancelAllCommand отменяет все запросы автора команды: ожидающие, отложенные
+// и уже загружающиеся. Чужие запросы не затрагиваются
+func (h *Handler) handleCancelAllCommand(message *tgbotapi.Message) {
+	chatID := message.Chat.ID
+
+	userID, ok := senderID(message)
+	if !ok {
+		return
+	}
+
+	cancelled := 0
+	for _, id := range h.queue.owned(userID) {
+		req, state, ok := h.queue.cancel(id, cancelByOwner)
+		if !ok {
+			continue
+		}
+		h.jobs.Remove(id)
+		cancelled++
+
+		// Активная задача уберет статус сама, когда загрузка прервется
+		if state != jobActive {
+			h.clearStatusMessage(req)
+		}
+	}
+
+	if cancelled == 0 {
+		h.sendMessage(chatID, "ℹ️ У тебя нет запросов в очереди.")
+		return
+	}
+
+	h.logger.Info("Download requests cancelled by owner",
+		slog.Int64("user_id", userID),
+		slog.Int("count", cancelled),
+	)
+	h.sendMessage(chatID, fmt.Sprintf("🚫 Отменено запросов: %d.", cancelled))
+}
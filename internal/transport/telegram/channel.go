@@ -0,0 +1,153 @@
+package telegram
+
+import (
+	"fmt"
+	"html"
+	"log/slog"
+	"strconv"
+	"strings"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+
+	"github.com/reelser-bot/internal/services/channels"
+)
+
+// handleLinkChannelCommand привязывает личный канал пользователя: /link_channel @channel
+func (h *Handler) handleLinkChannelCommand(message *tgbotapi.Message) {
+	chatID := message.Chat.ID
+
+	if message.Chat.Type != "private" || message.From == nil {
+		h.sendMessage(chatID, "❌ Привязать канал можно только в личном чате с ботом.")
+		return
+	}
+	userID := int64(message.From.ID)
+
+	arg := strings.TrimSpace(message.CommandArguments())
+	if arg == "" {
+		if link, ok := h.channels.Get(userID); ok {
+			h.sendMessage(chatID, fmt.Sprintf(
+				"📦 Видео из личного чата публикуются в канал <b>%s</b>.\n"+
+					"Чтобы отвязать его, используй /unlink_channel.",
+				html.EscapeString(link.Title),
+			))
+			return
+		}
+		h.sendMessage(chatID, "Использование: /link_channel @канал или /link_channel -100…\n\n"+
+			"Добавь бота в канал администратором с правом публикации сообщений. "+
+			"Ты тоже должен быть администратором канала.")
+		return
+	}
+
+	channel, err := h.bot.GetChat(channelChatConfig(arg))
+	if err != nil {
+		h.logger.Warn("Failed to resolve channel",
+			slog.String("channel", arg),
+			slog.Any("error", err),
+		)
+		h.sendMessage(chatID, "❌ Канал не найден. Проверь ссылку и что бот добавлен в канал.")
+		return
+	}
+	if channel.Type != "channel" {
+		h.sendMessage(chatID, "❌ Это не канал. Укажи @username или ID канала.")
+		return
+	}
+
+	if err := h.verifyChannelPermissions(channel.ID, userID); err != nil {
+		h.sendMessage(chatID, "❌ "+err.Error())
+		return
+	}
+
+	h.channels.Set(userID, channels.Link{
+		ChannelID: channel.ID,
+		Title:     channel.Title,
+		LinkedAt:  time.Now(),
+	})
+
+	h.logger.Info("Personal channel linked",
+		slog.Int64("user_id", userID),
+		slog.Int64("channel_id", channel.ID),
+	)
+	h.sendMessage(chatID, fmt.Sprintf(
+		"✅ Канал <b>%s</b> привязан. Все видео, запрошенные в личном чате, будут публиковаться туда.",
+		html.EscapeString(channel.Title),
+	))
+}
+
+// handleUnlinkChannelCommand отвязывает личный канал пользователя
+func (h *Handler) handleUnlinkChannelCommand(message *tgbotapi.Message) {
+	chatID := message.Chat.ID
+	if message.From == nil {
+		return
+	}
+
+	if !h.channels.Remove(int64(message.From.ID)) {
+		h.sendMessage(chatID, "ℹ️ Личный канал не привязан.")
+		return
+	}
+	h.sendMessage(chatID, "✅ Канал отвязан.")
+}
+
+// verifyChannelPermissions проверяет, что пользователь администрирует канал,
+// а бот может публиковать в нем сообщения
+func (h *Handler) verifyChannelPermissions(channelID, userID int64) error {
+	member, err := h.bot.GetChatMember(tgbotapi.GetChatMemberConfig{
+		ChatConfigWithUser: tgbotapi.ChatConfigWithUser{ChatID: channelID, UserID: userID},
+	})
+	if err != nil || !(member.IsCreator() || member.IsAdministrator()) {
+		return fmt.Errorf("ты должен быть администратором этого канала")
+	}
+
+	botMember, err := h.bot.GetChatMember(tgbotapi.GetChatMemberConfig{
+		ChatConfigWithUser: tgbotapi.ChatConfigWithUser{ChatID: channelID, UserID: h.bot.Self.ID},
+	})
+	if err != nil || !botMember.IsAdministrator() || !botMember.CanPostMessages {
+		return fmt.Errorf("добавь бота в канал администратором с правом публикации сообщений")
+	}
+
+	return nil
+}
+
+// archiveToChannel копирует доставленное видео в личный канал пользователя
+func (h *Handler) archiveToChannel(req *downloadRequest, sent *tgbotapi.Message) {
+	if sent == nil || req.chatType != "private" || req.userID == 0 {
+		return
+	}
+
+	link, ok := h.channels.Get(req.userID)
+	if !ok {
+		return
+	}
+
+	copyMsg := tgbotapi.NewCopyMessage(link.ChannelID, sent.Chat.ID, sent.MessageID)
+	copyMsg.Caption = req.url
+	copied, err := h.bot.CopyMessage(copyMsg)
+	if err != nil {
+		h.logger.Warn("Failed to archive video to personal channel",
+			slog.Int64("user_id", req.userID),
+			slog.Int64("channel_id", link.ChannelID),
+			slog.Any("error", err),
+		)
+		h.notify(req, "⚠️ Не удалось опубликовать видео в привязанный канал. "+
+			"Проверь права бота или привяжи канал заново через /link_channel.")
+		return
+	}
+
+	h.logger.Info("Video archived to personal channel",
+		slog.Int64("user_id", req.userID),
+		slog.Int64("channel_id", link.ChannelID),
+	)
+	h.recordReceipt(req, link.ChannelID, copied.MessageID)
+}
+
+// channelChatConfig формирует запрос GetChat по @username или числовому ID канала
+func channelChatConfig(arg string) tgbotapi.ChatInfoConfig {
+	arg = strings.TrimPrefix(arg, "https://t.me/")
+	if id, err := strconv.ParseInt(arg, 10, 64); err == nil {
+		return tgbotapi.ChatInfoConfig{ChatConfig: tgbotapi.ChatConfig{ChatID: id}}
+	}
+	if !strings.HasPrefix(arg, "@") {
+		arg = "@" + arg
+	}
+	return tgbotapi.ChatInfoConfig{ChatConfig: tgbotapi.ChatConfig{SuperGroupUsername: arg}}
+}
@@ -0,0 +1,193 @@
+package telegram
+
+import (
+	"errors"
+	"fmt"
+	"html"
+	"log/slog"
+	"strings"
+
+	"github.com/reelser-bot/internal/platform"
+	"github.com/reelser-bot/internal/services/downloader"
+	"github.com/reelser-bot/internal/trace"
+)
+
+// processChapters скачивает видео для /chapters, делит его на главы и отправляет
+// каждую отдельным видео. Главы больше лимита чата пропускаются и перечисляются
+// в итоговом отчете. Возвращает false, если запрос не на главы
+func (h *Handler) processChapters(req *downloadRequest) bool {
+	if !req.chapters {
+		return false
+	}
+
+	quality := req.quality
+	if quality == platform.QualityDefault {
+		quality = h.setup.defaultQuality()
+	}
+
+	downloadCtx := trace.NewContext(h.enterPhase(req, phaseDownload), h.traces, req.id)
+	chapters, info, err := h.downloader.Chapters(downloadCtx, req.url, downloader.Options{
+		Quality:  quality,
+		Language: req.language,
+	})
+	defer func() {
+		for _, chapter := range chapters {
+			if err := h.downloader.Cleanup(chapter.File); err != nil {
+				h.logger.Warn("Failed to cleanup file", slog.String("file", chapter.File), slog.Any("error", err))
+			}
+		}
+	}()
+	if err != nil {
+		h.clearStatusMessage(req)
+		h.notifyChaptersError(req, info, err)
+		return true
+	}
+
+	title := info.Title
+	if title == "" {
+		title = "без названия"
+	}
+	maxAllowed := h.sizeLimits.forChat(req.chatID, req.chatType)
+
+	var delivered int
+	var skipped []skippedItem
+	for i, chapter := range chapters {
+		if req.cancelledBy.Load() != cancelNone {
+			break
+		}
+
+		h.editStatus(req, fmt.Sprintf("📑 «%s»: глава %d из %d\n⏳ Отправляю «%s»...",
+			html.EscapeString(title), i+1, len(chapters), html.EscapeString(chapterTitle(chapter, i)),
+		))
+
+		size, ok := h.sendChapter(req, chapter, i, len(chapters), maxAllowed)
+		switch {
+		case size > maxAllowed:
+			skipped = append(skipped, skippedItem{title: chapterTitle(chapter, i), size: size})
+		case ok:
+			delivered++
+		}
+	}
+
+	h.clearStatusMessage(req)
+
+	if by := req.cancelledBy.Load(); by != cancelNone {
+		if by == cancelByAdmin {
+			h.notify(req, "🚫 Загрузка глав отменена администратором.")
+		}
+		return true
+	}
+
+	h.logger.Info("Chapters processed",
+		slog.String("url", req.url),
+		slog.Int("chapters", len(chapters)),
+		slog.Int("delivered", delivered),
+		slog.Int("skipped", len(skipped)),
+	)
+
+	h.report(req, chaptersReport(title, len(chapters), delivered, skipped, maxAllowed, h.locale(req.language)))
+	if delivered > 0 {
+		h.recordDownload(req)
+		h.deleteOriginalMessage(req)
+	}
+	return true
+}
+
+// sendChapter отправляет главу index из total. Возвращает размер файла главы и true,
+// если она доставлена; глава больше maxAllowed не отправляется
+func (h *Handler) sendChapter(req *downloadRequest, chapter downloader.Chapter, index, total int, maxAllowed int64) (int64, bool) {
+	size, err := h.downloader.GetFileSize(chapter.File)
+	if err != nil {
+		h.logger.Error("Failed to get file size", slog.String("file", chapter.File), slog.Any("error", err))
+		return 0, false
+	}
+	h.bandwidth.Consume(size)
+	req.summary.downloaded(size)
+	if size > maxAllowed {
+		return size, false
+	}
+
+	caption := fmt.Sprintf("📑 %d/%d. %s", index+1, total, chapterTitle(chapter, index))
+	if chapter.Duration > 0 {
+		caption += fmt.Sprintf(" (%s)", h.locale(req.language).duration(chapter.Duration))
+	}
+	h.enterPhase(req, phaseUpload)
+	sent, err := h.deliverVideo(req, chapter.File, maxAllowed, caption)
+	if err != nil {
+		h.logger.Error("Failed to send chapter",
+			slog.String("file", chapter.File),
+			slog.Int("chapter", index+1),
+			slog.Any("error", err),
+		)
+		return size, false
+	}
+	h.bandwidth.Consume(size)
+	req.summary.sent(size)
+
+	if req.chatType == "channel" && sent != nil {
+		h.recordReceipt(req, req.chatID, sent.MessageID)
+	}
+	return size, true
+}
+
+// notifyChaptersError сообщает пользователю, почему видео не удалось разделить на главы
+func (h *Handler) notifyChaptersError(req *downloadRequest, info downloader.MediaInfo, err error) {
+	loc := h.locale(req.language)
+	switch req.cancelledBy.Load() {
+	case cancelByAdmin:
+		h.notify(req, "🚫 Загрузка отменена администратором.")
+		return
+	case cancelByOwner:
+		return
+	}
+
+	var durationErr *downloader.DurationError
+	switch {
+	case errors.Is(err, downloader.ErrChaptersUnsupported):
+		h.notify(req, "❌ На главы можно разделить только видео YouTube.")
+	case errors.Is(err, downloader.ErrNoChapters):
+		h.notify(req, "❌ У этого видео нет глав. Отправь ссылку без /chapters, чтобы скачать его целиком."+mediaSummary(info, loc))
+	case errors.Is(err, downloader.ErrSignInRequired):
+		h.notify(req, "❌ YouTube требует подтвердить вход для этого видео.\n"+
+			"Администратору бота нужно настроить YOUTUBE_PO_TOKEN.")
+	case errors.As(err, &durationErr):
+		h.notify(req, fmt.Sprintf(
+			"❌ Видео слишком длинное (%s). Ограничение — %s.%s",
+			loc.duration(durationErr.Duration),
+			loc.duration(durationErr.Limit),
+			mediaSummary(info, loc),
+		))
+	default:
+		h.logger.Error("Failed to download chapters", slog.String("url", req.url), slog.Any("error", err))
+		h.recordFailure(req, err)
+		if h.notifyPhaseTimeout(req, err, mediaSummary(info, loc)) {
+			return
+		}
+		h.notify(req, fmt.Sprintf(
+			"❌ Не удалось разделить видео на главы: %s%s\nКод запроса: <code>%s</code>",
+			html.EscapeString(err.Error()), mediaSummary(info, loc), req.id,
+		))
+	}
+}
+
+// chaptersReport формирует итоговое сообщение об отправке глав
+func chaptersReport(title string, total, delivered int, skipped []skippedItem, limit int64, loc locale) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "📑 «%s»: отправлено глав %d из %d.", html.EscapeString(title), delivered, total)
+
+	if len(skipped) > 0 {
+		fmt.Fprintf(&b, "\n\n⚠️ Пропущены из-за лимита %s:", loc.limit(limit))
+		for _, item := range skipped {
+			fmt.Fprintf(&b, "\n• %s (%s)", html.EscapeString(item.title), loc.size(item.size))
+		}
+	}
+	return b.String()
+}
+
+// chapterTitle возвращает название главы или ее номер
+func chapterTitle(chapter downloader.Chapter, index int) string {
+	if chapter.Title != "" {
+		return chapter.Title
+	}
+	return fmt.Sprintf("Глава %d", index+1)
+}
@@ -0,0 +1,58 @@
+package telegram
+
+import (
+	"log/slog"
+	"strings"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// chatTypeNames названия типов чатов для сообщения об ограничении
+var chatTypeNames = map[string]string{
+	"private": "личных сообщениях",
+	"group":   "группах",
+	"channel": "каналах",
+}
+
+// chatTypeClass сводит тип чата Telegram к значению ALLOWED_CHAT_TYPES:
+// супергруппы настраиваются вместе с группами
+func chatTypeClass(chatType string) string {
+	if chatType == "supergroup" {
+		return "group"
+	}
+	return chatType
+}
+
+// allowedChatTypes строит множество разрешенных типов чатов из конфигурации
+func allowedChatTypes(types []string) map[string]bool {
+	allowed := make(map[string]bool, len(types))
+	for _, chatType := range types {
+		allowed[chatType] = true
+	}
+	return allowed
+}
+
+// chatTypeAllowed проверяет, что бот работает в чатах этого типа (ALLOWED_CHAT_TYPES)
+func (h *Handler) chatTypeAllowed(chatType string) bool {
+	return len(h.allowedChatTypes) == 0 || h.allowedChatTypes[chatTypeClass(chatType)]
+}
+
+// rejectChatType сообщает, что в чатах этого типа бот не работает, и перечисляет,
+// где он доступен
+func (h *Handler) rejectChatType(message *tgbotapi.Message) {
+	h.logger.Info("Message from disallowed chat type ignored",
+		slog.Int64("chat_id", message.Chat.ID),
+		slog.String("chat_type", message.Chat.Type),
+	)
+
+	var allowed []string
+	for _, chatType := range []string{"private", "group", "channel"} {
+		if h.allowedChatTypes[chatType] {
+			allowed = append(allowed, chatTypeNames[chatType])
+		}
+	}
+	if len(allowed) == 0 {
+		return
+	}
+	h.sendMessage(message.Chat.ID, "🔒 Этот бот работает только в "+strings.Join(allowed, " и ")+".")
+}
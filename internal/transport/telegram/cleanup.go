@@ -0,0 +1,91 @@
+package telegram
+
+import (
+	"fmt"
+	"html"
+	"log/slog"
+	"strings"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+
+	"github.com/reelser-bot/internal/services/chatsettings"
+)
+
+// Границы срока хранения служебных сообщений. Telegram не дает боту удалять
+// сообщения старше 48 часов
+const (
+	minCleanupAfter = time.Minute
+	maxCleanupAfter = 47 * time.Hour
+)
+
+// scheduleCleanup удаляет служебное сообщение бота по истечении срока, заданного в чате.
+// Таймеры хранятся в памяти: после перезапуска сообщения остаются в чате
+func (h *Handler) scheduleCleanup(chatID int64, messageID int) {
+	after := h.chatSettings.Get(chatID).CleanupAfter
+	if after <= 0 || messageID == 0 {
+		return
+	}
+
+	time.AfterFunc(after, func() {
+		// Статусные сообщения обычно уже удалены после загрузки, поэтому ошибка не важна
+		if _, err := h.bot.Request(tgbotapi.NewDeleteMessage(chatID, messageID)); err != nil {
+			h.logger.Debug("Failed to clean up service message",
+				slog.Int64("chat_id", chatID),
+				slog.Int("message_id", messageID),
+				slog.Any("error", err),
+			)
+		}
+	})
+}
+
+// handleCleanupCommand настраивает автоудаление служебных сообщений в группе:
+// /cleanup 10m, /cleanup off или /cleanup без аргументов для просмотра настройки
+func (h *Handler) handleCleanupCommand(message *tgbotapi.Message) {
+	chatID := message.Chat.ID
+
+	if message.Chat.Type != "group" && message.Chat.Type != "supergroup" {
+		h.sendMessage(chatID, "ℹ️ Автоудаление сообщений настраивается в группах.")
+		return
+	}
+
+	arg := strings.TrimSpace(message.CommandArguments())
+	if arg == "" {
+		if after := h.chatSettings.Get(chatID).CleanupAfter; after > 0 {
+			h.sendMessage(chatID, fmt.Sprintf("🧹 Служебные сообщения удаляются через %s.\nОтключить: /cleanup off", after))
+		} else {
+			h.sendMessage(chatID, "Автоудаление служебных сообщений выключено.\nИспользование: /cleanup 10m")
+		}
+		return
+	}
+
+	if !h.isChatAdmin(message) {
+		h.sendMessage(chatID, "🔒 Настраивать автоудаление могут только администраторы чата.")
+		return
+	}
+
+	if strings.EqualFold(arg, "off") {
+		h.chatSettings.Update(chatID, func(s *chatsettings.Settings) { s.CleanupAfter = 0 })
+		h.sendMessage(chatID, "✅ Автоудаление служебных сообщений выключено.")
+		return
+	}
+
+	after, err := time.ParseDuration(arg)
+	if err != nil || after < minCleanupAfter || after > maxCleanupAfter {
+		h.sendMessage(chatID, fmt.Sprintf(
+			"❌ Укажи срок от %s до %s, например: /cleanup 10m",
+			minCleanupAfter, maxCleanupAfter,
+		))
+		return
+	}
+
+	h.chatSettings.Update(chatID, func(s *chatsettings.Settings) { s.CleanupAfter = after })
+
+	userID, _ := senderID(message)
+	h.logger.Info("Service message cleanup updated",
+		slog.Int64("chat_id", chatID),
+		slog.Int64("user_id", userID),
+		slog.Duration("after", after),
+	)
+	h.sendMessage(chatID, "✅ Служебные сообщения (ошибки, статусы) будут удаляться через "+html.EscapeString(after.String())+".")
+}
@@ -0,0 +1,68 @@
+package telegram
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+
+	"github.com/reelser-bot/internal/platform"
+	"github.com/reelser-bot/internal/services/compress"
+)
+
+// maxShrinkFactor во сколько раз сжатие или конвертация могут уменьшить видео.
+// Исходник больше лимита чата в это число раз не скачивается: после сжатия до лимита
+// от его качества ничего бы не осталось
+const maxShrinkFactor = 4
+
+// preflightSizeLimit возвращает лимит для проверки размера до загрузки (0 — не проверять).
+// Видео, которое можно сжать, а также анимации и видеосообщения, которые после
+// конвертации становятся меньше исходника, ограничены лимитом чата с запасом maxShrinkFactor
+func (h *Handler) preflightSizeLimit(req *downloadRequest, maxAllowed int64) int64 {
+	if req.animation || req.videoNote || (h.compressor.Enabled() && !req.audioOnly) {
+		return maxAllowed * maxShrinkFactor
+	}
+	return maxAllowed
+}
+
+// compressVideo пережимает видео больше лимита чата. При успехе исходный файл
+// удаляется, а возвращается путь к сжатому и его размер
+func (h *Handler) compressVideo(req *downloadRequest, filePath string, maxAllowed int64) (string, int64, bool) {
+	if !h.compressor.Enabled() || req.audioOnly || platform.IsSlideshow(filePath) {
+		return "", 0, false
+	}
+
+	// Для видео плейлиста прогресс показывает статус самого плейлиста
+	if !req.playlistItem {
+		statusMsg := h.sendServiceMessage(req.chatID, fmt.Sprintf(
+			"🗜 Видео больше лимита чата (%s), сжимаю...",
+			h.locale(req.language).limit(maxAllowed),
+		))
+		req.statusMessageID = h.safeMessageID(statusMsg)
+		defer h.clearStatusMessage(req)
+	}
+
+	compressed, err := h.compressor.Compress(req.phaseContext(), filePath, maxAllowed)
+	if err != nil {
+		level := slog.LevelError
+		if errors.Is(err, compress.ErrTooLong) {
+			level = slog.LevelInfo
+		}
+		h.logger.Log(req.phaseContext(), level, "Failed to compress video",
+			slog.String("file", filePath),
+			slog.Any("error", err),
+		)
+		return "", 0, false
+	}
+
+	size, err := h.downloader.GetFileSize(compressed)
+	if err != nil {
+		h.logger.Error("Failed to get file size", slog.String("file", compressed), slog.Any("error", err))
+		h.downloader.Cleanup(compressed)
+		return "", 0, false
+	}
+
+	if err := h.downloader.Cleanup(filePath); err != nil {
+		h.logger.Warn("Failed to cleanup file", slog.String("file", filePath), slog.Any("error", err))
+	}
+	return compressed, size, true
+}
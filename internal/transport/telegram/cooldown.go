@@ -0,0 +1,51 @@
+package telegram
+
+import (
+	"sync"
+	"time"
+)
+
+// cooldowns ограничивает частоту загрузок в пределах одного чата
+type cooldowns struct {
+	mu     sync.Mutex
+	byType map[string]time.Duration
+	last   map[int64]time.Time
+}
+
+func newCooldowns(byType map[string]time.Duration) *cooldowns {
+	return &cooldowns{
+		byType: byType,
+		last:   make(map[int64]time.Time),
+	}
+}
+
+// acquire фиксирует загрузку в чате, если кулдаун истек.
+// Иначе возвращает оставшееся время ожидания
+func (c *cooldowns) acquire(chatID int64, chatType string) (time.Duration, bool) {
+	period := c.byType[chatType]
+	if period <= 0 {
+		return 0, true
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := time.Now()
+	if last, ok := c.last[chatID]; ok {
+		if wait := period - now.Sub(last); wait > 0 {
+			return wait, false
+		}
+	}
+
+	c.last[chatID] = now
+	return 0, true
+}
+
+// release снимает кулдаун, зафиксированный acquire, если запрос так и не был принят
+// (исчерпан лимит или переполнена очередь)
+func (c *cooldowns) release(chatID int64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	delete(c.last, chatID)
+}
@@ -0,0 +1,70 @@
+package telegram
+
+import (
+	"log/slog"
+	"os"
+)
+
+// ResumeDeliveries отправляет файлы, которые были скачаны, но не доставлены до перезапуска,
+// и применяет эффекты уже доставленных (статистика, история, кэш file_id)
+func (h *Handler) ResumeDeliveries() {
+	tasks := h.deliveries.Pending()
+	if len(tasks) == 0 {
+		return
+	}
+
+	h.logger.Info("Resuming pending deliveries", slog.Int("count", len(tasks)))
+
+	for _, task := range tasks {
+		req := deliveryRequest(task)
+		if task.Sent != nil {
+			h.applyDeliveryEffects(req, sentMessage(task))
+			h.logger.Info("Pending delivery effects applied",
+				slog.String("id", task.ID),
+				slog.Int64("chat_id", task.ChatID),
+			)
+			if _, err := os.Stat(task.FilePath); err == nil {
+				if err := h.downloader.Cleanup(task.FilePath); err != nil {
+					h.logger.Warn("Failed to cleanup file", slog.String("file", task.FilePath), slog.Any("error", err))
+				}
+			}
+			continue
+		}
+
+		if _, err := os.Stat(task.FilePath); err != nil {
+			h.logger.Warn("Pending delivery file is missing, dropping task",
+				slog.String("id", task.ID),
+				slog.String("file", task.FilePath),
+				slog.Any("error", err),
+			)
+			h.deliveries.Remove(task.ID)
+			continue
+		}
+
+		// Отправка идет тем же путем, что и обычная доставка: платные посты,
+		// бизнес-подключения и медленный режим групп учитываются одинаково
+		sent, err := h.deliverVideo(req, task.FilePath, h.sizeLimits.forChat(task.ChatID, task.ChatType), "")
+		if err != nil {
+			h.logger.Error("Failed to resume delivery",
+				slog.String("id", task.ID),
+				slog.Int64("chat_id", task.ChatID),
+				slog.Any("error", err),
+			)
+		} else {
+			if size, err := h.downloader.GetFileSize(task.FilePath); err == nil {
+				h.bandwidth.Consume(size)
+			}
+			h.logger.Info("Pending delivery resumed",
+				slog.String("id", task.ID),
+				slog.Int64("chat_id", task.ChatID),
+				slog.String("url", task.URL),
+			)
+			h.completeDelivery(req, sent)
+		}
+
+		if err := h.downloader.Cleanup(task.FilePath); err != nil {
+			h.logger.Warn("Failed to cleanup file", slog.String("file", task.FilePath), slog.Any("error", err))
+		}
+		h.deliveries.Remove(task.ID)
+	}
+}
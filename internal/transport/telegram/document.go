@@ -0,0 +1,108 @@
+package telegram
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"strings"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+
+	"github.com/reelser-bot/internal/services/chatsettings"
+)
+
+// sendDocument отправляет видео файлом, чтобы Telegram не пережимал его.
+// disable_content_type_detection не дает Telegram превратить файл обратно в видео
+func (h *Handler) sendDocument(
+	chatID int64, businessConnectionID, filePath string, maxAllowed int64, caption string,
+) (*tgbotapi.Message, error) {
+	file, err := os.Open(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open file: %w", err)
+	}
+	defer file.Close()
+
+	fileInfo, err := file.Stat()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get file info: %w", err)
+	}
+	if fileInfo.Size() > maxAllowed {
+		return nil, fmt.Errorf("file size %d exceeds maximum allowed size %d", fileInfo.Size(), maxAllowed)
+	}
+
+	params := tgbotapi.Params{}
+	params.AddNonEmpty("business_connection_id", businessConnectionID)
+	params.AddNonZero64("chat_id", chatID)
+	params.AddNonEmpty("caption", caption)
+	params.AddBool("disable_content_type_detection", true)
+
+	h.logger.Info("Sending document",
+		slog.Int64("chat_id", chatID),
+		slog.String("file", filePath),
+		slog.Int64("size", fileInfo.Size()),
+	)
+
+	var sent tgbotapi.Message
+	err = h.retryUpload(file, func(r io.Reader) error {
+		files := []tgbotapi.RequestFile{{
+			Name: "document",
+			Data: tgbotapi.FileReader{Name: fileInfo.Name(), Reader: r},
+		}}
+		resp, err := h.bot.UploadFiles("sendDocument", params, files)
+		if err != nil {
+			return err
+		}
+		return json.Unmarshal(resp.Result, &sent)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to send document: %w", err)
+	}
+
+	h.logger.Info("Document sent successfully", slog.Int64("chat_id", chatID))
+	return &sent, nil
+}
+
+// handleDocumentCommand включает отправку видео файлом для всех ссылок чата:
+// /document on, /document off или /document для просмотра настройки.
+// В личном чате это настройка пользователя, в группе ее меняют администраторы
+func (h *Handler) handleDocumentCommand(message *tgbotapi.Message) {
+	chatID := message.Chat.ID
+
+	arg := strings.ToLower(strings.TrimSpace(message.CommandArguments()))
+	if arg == "" {
+		if h.chatSettings.Get(chatID).AsDocument {
+			h.sendMessage(chatID, "📎 Видео в этом чате присылаются файлом, без пережатия Telegram.\nОтключить: /document off")
+		} else {
+			h.sendMessage(chatID, "Видео присылаются обычным видеосообщением.\n"+
+				"Присылать файлом без пережатия: /document on, для одной ссылки: /file &lt;ссылка&gt;")
+		}
+		return
+	}
+
+	if arg != "on" && arg != "off" {
+		h.sendMessage(chatID, "Использование: /document on или /document off")
+		return
+	}
+
+	if message.Chat.Type != "private" && !h.isChatAdmin(message) {
+		h.sendMessage(chatID, "🔒 Менять способ отправки могут только администраторы чата.")
+		return
+	}
+
+	enabled := arg == "on"
+	h.chatSettings.Update(chatID, func(s *chatsettings.Settings) { s.AsDocument = enabled })
+
+	userID, _ := senderID(message)
+	h.logger.Info("Document delivery updated",
+		slog.Int64("chat_id", chatID),
+		slog.Int64("user_id", userID),
+		slog.Bool("enabled", enabled),
+	)
+	if enabled {
+		h.sendMessage(chatID, "✅ Видео будут присылаться файлом, без пережатия Telegram.")
+	} else {
+		h.sendMessage(chatID, "✅ Видео снова будут присылаться обычным видеосообщением.")
+	}
+}
@@ -0,0 +1,66 @@
+package telegram
+
+import (
+	"strings"
+	"unicode/utf16"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// entityText возвращает текст сущности. Telegram задает offset и length
+// в UTF-16 code units, поэтому резать строку по байтам нельзя
+func entityText(text string, entity tgbotapi.MessageEntity) (string, bool) {
+	units := utf16.Encode([]rune(text))
+
+	start := entity.Offset
+	end := entity.Offset + entity.Length
+	if start < 0 || entity.Length <= 0 || end > len(units) {
+		return "", false
+	}
+
+	return string(utf16.Decode(units[start:end])), true
+}
+
+// messageEntities возвращает текст и сущности сообщения (или подписи к медиа)
+func messageEntities(message *tgbotapi.Message) (string, []tgbotapi.MessageEntity) {
+	if message.Text != "" {
+		return message.Text, message.Entities
+	}
+	return message.Caption, message.CaptionEntities
+}
+
+// entityMentions возвращает упоминания (@username) из сообщения без символа @
+func entityMentions(message *tgbotapi.Message) []string {
+	text, entities := messageEntities(message)
+
+	var mentions []string
+	for _, entity := range entities {
+		if entity.Type != "mention" {
+			continue
+		}
+		if mention, ok := entityText(text, entity); ok {
+			mentions = append(mentions, strings.TrimPrefix(mention, "@"))
+		}
+	}
+	return mentions
+}
+
+// entityURLs возвращает ссылки из сущностей url и text_link в порядке появления
+func entityURLs(message *tgbotapi.Message) []string {
+	text, entities := messageEntities(message)
+
+	var urls []string
+	for _, entity := range entities {
+		switch entity.Type {
+		case "url":
+			if url, ok := entityText(text, entity); ok {
+				urls = append(urls, url)
+			}
+		case "text_link":
+			if entity.URL != "" {
+				urls = append(urls, entity.URL)
+			}
+		}
+	}
+	return urls
+}
@@ -0,0 +1,103 @@
+package telegram
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// locale правила форматирования размеров, длительностей и времени в сообщениях
+type locale struct {
+	// decimal разделитель дробной части
+	decimal string
+	// Единицы размера и длительности
+	mb, gb               string
+	hour, minute, second string
+	// clock формат времени суток (24- или 12-часовой)
+	clock string
+}
+
+var (
+	// localeRU правила языка интерфейса бота: ими форматируются сообщения администраторам
+	// и статусы групп
+	localeRU = locale{decimal: ",", mb: "МБ", gb: "ГБ", hour: "ч", minute: "мин", second: "с", clock: "15:04"}
+	localeEN = locale{decimal: ".", mb: "MB", gb: "GB", hour: "h", minute: "min", second: "s", clock: "3:04 PM"}
+)
+
+// locales правила по основному коду языка из настроек Telegram пользователя;
+// остальные языки форматируются по DEFAULT_LANGUAGE
+var locales = map[string]locale{
+	"en": localeEN,
+	"ru": localeRU,
+}
+
+// localeFor возвращает правила форматирования для языка пользователя ("en-US", "ru")
+// или fallback, если язык неизвестен или не поддерживается
+func localeFor(language string, fallback locale) locale {
+	base, _, _ := strings.Cut(strings.ToLower(language), "-")
+	if l, ok := locales[base]; ok {
+		return l
+	}
+	return fallback
+}
+
+// locale возвращает правила форматирования для языка пользователя или DEFAULT_LANGUAGE
+func (h *Handler) locale(language string) locale {
+	return localeFor(language, h.defaultLocale)
+}
+
+// number форматирует число с precision знаками после запятой
+func (l locale) number(v float64, precision int) string {
+	return strings.Replace(strconv.FormatFloat(v, 'f', precision, 64), ".", l.decimal, 1)
+}
+
+// size форматирует размер файла: мегабайты с одним знаком, от гигабайта — гигабайты
+func (l locale) size(bytes int64) string {
+	if bytes >= 1<<30 {
+		return l.number(float64(bytes)/(1<<30), 2) + " " + l.gb
+	}
+	return l.number(float64(bytes)/(1<<20), 1) + " " + l.mb
+}
+
+// limit форматирует лимит размера в целых мегабайтах
+func (l locale) limit(bytes int64) string {
+	return strconv.FormatInt(bytes/(1<<20), 10) + " " + l.mb
+}
+
+// duration форматирует длительность с точностью до секунды: "1 ч 2 мин 5 с"
+func (l locale) duration(d time.Duration) string {
+	seconds := int(d.Round(time.Second) / time.Second)
+	if seconds <= 0 {
+		return "0 " + l.second
+	}
+
+	var parts []string
+	if h := seconds / 3600; h > 0 {
+		parts = append(parts, fmt.Sprintf("%d %s", h, l.hour))
+	}
+	if m := seconds / 60 % 60; m > 0 {
+		parts = append(parts, fmt.Sprintf("%d %s", m, l.minute))
+	}
+	if s := seconds % 60; s > 0 {
+		parts = append(parts, fmt.Sprintf("%d %s", s, l.second))
+	}
+	return strings.Join(parts, " ")
+}
+
+// age форматирует примерный интервал: секунды, минуты или часы с минутами
+func (l locale) age(d time.Duration) string {
+	switch {
+	case d < time.Minute:
+		return fmt.Sprintf("%d %s", int(d.Seconds()), l.second)
+	case d < time.Hour:
+		return fmt.Sprintf("%d %s", int(d.Minutes()), l.minute)
+	default:
+		return fmt.Sprintf("%d %s %d %s", int(d.Hours()), l.hour, int(d.Minutes())%60, l.minute)
+	}
+}
+
+// clockTime форматирует время суток
+func (l locale) clockTime(t time.Time) string {
+	return t.Format(l.clock)
+}
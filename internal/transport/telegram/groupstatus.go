@@ -0,0 +1,245 @@
+package telegram
+
+import (
+	"context"
+	"fmt"
+	"html"
+	"log/slog"
+	"strings"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+
+	"github.com/reelser-bot/internal/services/chatsettings"
+)
+
+// handleStatusCommand ведет в группе закрепленное сообщение со статусом бота: /status on,
+// /status off или /status без аргументов для просмотра настройки
+func (h *Handler) handleStatusCommand(message *tgbotapi.Message) {
+	chatID := message.Chat.ID
+
+	if h.groupStatusInterval <= 0 {
+		h.sendMessage(chatID, "ℹ️ Закрепленный статус выключен администратором бота.")
+		return
+	}
+	if message.Chat.Type != "group" && message.Chat.Type != "supergroup" {
+		h.sendMessage(chatID, "ℹ️ Закрепленный статус ведется в группах.")
+		return
+	}
+
+	messageID := h.chatSettings.Get(chatID).StatusMessageID
+	arg := strings.ToLower(strings.TrimSpace(message.CommandArguments()))
+	if arg == "" {
+		if messageID != 0 {
+			h.sendMessage(chatID, fmt.Sprintf(
+				"📌 Статус бота закреплен в чате и обновляется каждые %s.\nОтключить: /status off",
+				formatAge(h.groupStatusInterval),
+			))
+		} else {
+			h.sendMessage(chatID, "Закрепленный статус бота выключен.\nВключить: /status on")
+		}
+		return
+	}
+
+	if !h.isChatAdmin(message) {
+		h.sendMessage(chatID, "🔒 Настраивать закрепленный статус могут только администраторы чата.")
+		return
+	}
+
+	userID, _ := senderID(message)
+	switch arg {
+	case "on":
+		if messageID != 0 {
+			h.sendMessage(chatID, "📌 Статус бота уже закреплен в чате.\nОтключить: /status off")
+			return
+		}
+
+		// Статус отправляется в обход sendMessage, чтобы автоудаление (/cleanup) его не тронуло
+		msg := tgbotapi.NewMessage(chatID, h.groupStatusText(chatID))
+		msg.ParseMode = "HTML"
+		sent, err := h.bot.Send(msg)
+		if err != nil {
+			h.logger.Error("Failed to send group status", slog.Int64("chat_id", chatID), slog.Any("error", err))
+			h.sendMessage(chatID, "❌ Не удалось отправить статус бота.")
+			return
+		}
+		h.chatSettings.Update(chatID, func(s *chatsettings.Settings) { s.StatusMessageID = sent.MessageID })
+
+		h.logger.Info("Group status enabled",
+			slog.Int64("chat_id", chatID),
+			slog.Int64("user_id", userID),
+			slog.Int("message_id", sent.MessageID),
+		)
+
+		pin := tgbotapi.PinChatMessageConfig{ChatID: chatID, MessageID: sent.MessageID, DisableNotification: true}
+		if _, err := h.bot.Request(pin); err != nil {
+			h.logger.Warn("Failed to pin group status", slog.Int64("chat_id", chatID), slog.Any("error", err))
+			h.sendMessage(chatID, "⚠️ Не удалось закрепить статус: дай боту право закреплять сообщения. "+
+				"Статус все равно будет обновляться.")
+		}
+
+	case "off":
+		if messageID == 0 {
+			h.sendMessage(chatID, "ℹ️ Закрепленный статус бота и так выключен.")
+			return
+		}
+
+		h.chatSettings.Update(chatID, func(s *chatsettings.Settings) { s.StatusMessageID = 0 })
+		unpin := tgbotapi.UnpinChatMessageConfig{ChatID: chatID, MessageID: messageID}
+		if _, err := h.bot.Request(unpin); err != nil {
+			h.logger.Debug("Failed to unpin group status", slog.Int64("chat_id", chatID), slog.Any("error", err))
+		}
+		h.deleteMessage(chatID, messageID)
+
+		h.logger.Info("Group status disabled", slog.Int64("chat_id", chatID), slog.Int64("user_id", userID))
+		h.sendMessage(chatID, "✅ Закрепленный статус бота выключен.")
+
+	default:
+		h.sendMessage(chatID, "Использование: /status on — закрепить статус бота, /status off — убрать его.")
+	}
+}
+
+// RunGroupStatus обновляет закрепленные статусы групп каждые GROUP_STATUS_INTERVAL, пока
+// ctx не отменен. owns сообщает, что чат обслуживает этот экземпляр: при шардировании
+// статус чата обновляет только его владелец
+func (h *Handler) RunGroupStatus(ctx context.Context, owns func(chatID int64) bool) {
+	if h.groupStatusInterval <= 0 {
+		return
+	}
+
+	ticker := time.NewTicker(h.groupStatusInterval)
+	defer ticker.Stop()
+
+	for {
+		for chatID, settings := range h.chatSettings.All() {
+			if settings.StatusMessageID != 0 && owns(chatID) {
+				h.refreshGroupStatus(chatID, settings.StatusMessageID)
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// refreshGroupStatus обновляет закрепленный статус. Если сообщение удалили или бота
+// исключили из группы, статус перестает вестись
+func (h *Handler) refreshGroupStatus(chatID int64, messageID int) {
+	edit := tgbotapi.NewEditMessageText(chatID, messageID, h.groupStatusText(chatID))
+	edit.ParseMode = "HTML"
+	_, err := h.bot.Request(edit)
+	if err == nil || strings.Contains(err.Error(), "message is not modified") {
+		return
+	}
+
+	if !isGoneError(err) {
+		h.logger.Warn("Failed to refresh group status",
+			slog.Int64("chat_id", chatID),
+			slog.Any("error", err),
+		)
+		return
+	}
+
+	h.chatSettings.Update(chatID, func(s *chatsettings.Settings) {
+		if s.StatusMessageID == messageID {
+			s.StatusMessageID = 0
+		}
+	})
+	h.logger.Info("Group status message is gone, disabling",
+		slog.Int64("chat_id", chatID),
+		slog.Any("error", err),
+	)
+}
+
+// isGoneError сообщает, что статус больше некуда писать: сообщение удалено или бот
+// потерял доступ к чату
+func isGoneError(err error) bool {
+	text := err.Error()
+	markers := []string{
+		"message to edit not found",
+		"message can't be edited",
+		"chat not found",
+		"bot was kicked",
+		"not a member",
+	}
+	for _, marker := range markers {
+		if strings.Contains(text, marker) {
+			return true
+		}
+	}
+	return false
+}
+
+// groupStatusText формирует закрепленный статус: очередь, нагрузку, тихие часы, загрузки
+// чата за сегодня, суточный бюджет трафика и состояние платформ за последний час
+func (h *Handler) groupStatusText(chatID int64) string {
+	loc := h.chatLocation(chatID)
+	now := time.Now()
+
+	var b strings.Builder
+	b.WriteString("📌 <b>Статус бота</b>\n")
+	fmt.Fprintf(&b, "🕒 Обновлено в %s, обновляется каждые %s\n",
+		localeRU.clockTime(now.In(loc)), formatAge(h.groupStatusInterval))
+
+	var pending, active, deferred, chatPending int
+	for _, job := range h.queue.snapshot() {
+		switch job.State {
+		case jobQueued:
+			pending++
+			if job.ChatID == chatID {
+				chatPending++
+			}
+		case jobActive:
+			active++
+		case jobDeferred:
+			deferred++
+		}
+	}
+	fmt.Fprintf(&b, "\n📥 Очередь: ждут %d, в работе %d из %d", pending, active, h.scaler.current())
+	if chatPending > 0 {
+		fmt.Fprintf(&b, "\n👥 Из этого чата ждут: %d", chatPending)
+	}
+	if deferred > 0 {
+		fmt.Fprintf(&b, "\n⏱ Отложено: %d", deferred)
+	}
+	b.WriteString(h.pressureStatus())
+	if until, quiet := h.quietUntil(chatID, "supergroup"); quiet {
+		fmt.Fprintf(&b, "\n🌙 Тихие часы до %s", localeRU.clockTime(until))
+	}
+
+	fmt.Fprintf(&b, "\n\n📊 Загрузок в чате сегодня: %d", h.stats.Top(chatID, 1, 0, loc).Total)
+	if h.bandwidth.Enabled() {
+		remaining := max(h.bandwidth.Remaining(), 0)
+		fmt.Fprintf(&b, "\n📶 Трафик за сутки: %.2f из %.0f GB",
+			float64(h.bandwidth.PerDay()-remaining)/(1024*1024*1024),
+			float64(h.bandwidth.PerDay())/(1024*1024*1024),
+		)
+		if remaining == 0 {
+			fmt.Fprintf(&b, "\n⛔ Бюджет исчерпан, загрузки возобновятся через %s",
+				formatAge(h.bandwidth.RetryAfter().Round(time.Minute)))
+		}
+	}
+
+	states := h.health.snapshot(now)
+	if len(states) == 0 {
+		b.WriteString("\n\n🌐 За последний час загрузок не было")
+		return b.String()
+	}
+
+	titles := make(map[string]string)
+	for _, platform := range h.downloader.Platforms() {
+		titles[platform.Name] = platform.Title
+	}
+	b.WriteString("\n\n🌐 Платформы за последний час:")
+	for _, state := range states {
+		title := titles[state.Platform]
+		if title == "" {
+			title = state.Platform
+		}
+		fmt.Fprintf(&b, "\n%s %s — успешно %d из %d", state.mark(), html.EscapeString(title), state.Succeeded, state.Total)
+	}
+	return b.String()
+}
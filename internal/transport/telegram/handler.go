@@ -2,14 +2,41 @@ package telegram
 
 import (
 	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"html"
+	"io"
 	"log/slog"
 	"os"
+	"path/filepath"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 
+	"github.com/reelser-bot/internal/clock"
+	"github.com/reelser-bot/internal/config"
+	"github.com/reelser-bot/internal/platform"
+	"github.com/reelser-bot/internal/platform/ytdlp"
 	"github.com/reelser-bot/internal/services/auth"
+	"github.com/reelser-bot/internal/services/bandwidth"
+	"github.com/reelser-bot/internal/services/channels"
+	"github.com/reelser-bot/internal/services/chatsettings"
+	"github.com/reelser-bot/internal/services/compress"
+	"github.com/reelser-bot/internal/services/delivery"
 	"github.com/reelser-bot/internal/services/downloader"
+	"github.com/reelser-bot/internal/services/fileids"
+	"github.com/reelser-bot/internal/services/history"
+	"github.com/reelser-bot/internal/services/hooks"
+	"github.com/reelser-bot/internal/services/jobs"
+	"github.com/reelser-bot/internal/services/pressure"
+	"github.com/reelser-bot/internal/services/setup"
+	"github.com/reelser-bot/internal/services/stats"
+	"github.com/reelser-bot/internal/services/users"
+	"github.com/reelser-bot/internal/trace"
 
 	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
 )
@@ -21,20 +48,170 @@ type Handler struct {
 	logger         *slog.Logger
 	downloader     *downloader.Service
 	auth           *auth.Service
-	maxVideoSize   int64 // в байтах
-	downloadQueue  chan *downloadRequest
+	sizeLimits     *sizeLimits
+	queue          *jobQueue
 	workerCount    int
 	queueSizeLimit int
+	// scaler размер пула воркеров между WORKER_POOL_MIN и WORKER_POOL_MAX (workerCount)
+	scaler *workerScaler
+	// updateQueue очередь апдейтов бота; используется только для статистики
+	updateQueue chan incomingUpdate
+
+	loginRetryDelay    time.Duration
+	loginRetryAttempts int
+
+	// uploadPolicy повторы потоковой загрузки файлов в Telegram
+	uploadPolicy config.RetryPolicy
+
+	deliveries  *delivery.Store
+	postProcess *hooks.PostProcessor
+	policy      *hooks.PolicyHook
+
+	businessEnabled bool
+	paidMediaPrices map[int64]int
+	// allowedChatTypes типы чатов, в которых работает бот (private, group, channel); пусто — все
+	allowedChatTypes map[string]bool
+
+	cooldowns *cooldowns
+	// infoSlots занятые места запросов /info, которые выполняются в фоне
+	infoSlots chan struct{}
+	traces    *trace.Store
+	channels  *channels.Store
+	failures  *history.Store
+	// receipts публикации в каналах для /post
+	receipts *history.ReceiptStore
+	// chatSettings настройки чатов (тихие часы и т.п.)
+	chatSettings *chatsettings.Store
+	// defaultLocation часовой пояс чатов без своей настройки (DEFAULT_TIMEZONE)
+	defaultLocation *time.Location
+	// defaultLocale правила форматирования для пользователей с неизвестным языком (DEFAULT_LANGUAGE)
+	defaultLocale locale
+	// setup первичная настройка бота, запущенного без ADMIN_IDS
+	setup *setupWizard
+	// slowModes задержки медленного режима групп для отправки видео
+	slowModes *slowModes
+	// phaseTimeouts бюджеты времени этапов обработки запроса
+	phaseTimeouts phaseTimeouts
+	// jobLog журнал сводных записей о задачах (JOB_LOG_FILE)
+	jobLog *jobLog
+	// health итоги недавних загрузок по платформам для закрепленного статуса групп
+	health *platformHealth
+	// groupStatusInterval период обновления закрепленного статуса групп; 0 — /status выключен
+	groupStatusInterval time.Duration
+	// pressure монитор давления на систему для сброса нагрузки; nil — выключен
+	pressure *pressure.Monitor
+
+	// bandwidth суточный бюджет трафика (DAILY_BANDWIDTH_GB)
+	bandwidth *bandwidth.Budget
+	// compressor сжимает видео больше лимита чата (COMPRESS_OVERSIZED)
+	compressor *compress.Compressor
+	// stats счетчики загрузок по чатам для /top
+	stats *stats.Store
+	// users пользователи из импорта CSV: роли и суточные квоты (/import_users)
+	users *users.Store
+	// jobs принятые запросы, которые продолжаются после перезапуска
+	jobs *jobs.Store
+
+	// shareButton добавляет под видео кнопку пересылки через inline-режим
+	shareButton bool
+	fileIDs     *fileIDCache
+
+	// Время кэширования inline-ответов в Telegram и локальный кэш готовых результатов
+	inlineCacheTime time.Duration
+	inlineResults   *inlineResultCache
+
+	// texts тексты /start, /help и подсказки, построенные по списку включенных платформ
+	texts botTexts
+}
+
+// requestSpec что и куда скачать: ссылка, вид отправки и чат. Параметры выделены из
+// downloadRequest, чтобы повтор запроса копировал их целиком и ни один не терялся
+type requestSpec struct {
+	chatID   int64
+	chatType string
+	userID   int64
+	// userName имя отправителя для статистики /top
+	userName string
+	url      string
+	password string
+	// quality вариант качества (из inline-режима или модификатора "ссылка 720p")
+	quality   string
+	audioOnly bool
+	// animation конвертирует видео в анимацию без звука (/gif)
+	animation bool
+	// videoNote отправляет видео квадратным видеосообщением (/circle)
+	videoNote bool
+	// document отправляет видео файлом без пережатия Telegram (/file, /document)
+	document bool
+	// chapters делит видео на главы и отправляет их по отдельности (/chapters)
+	chapters bool
+	language string
+	// section фрагмент видео из текста запроса ("<ссылка> 00:30-01:45"); nil — целиком
+	section *platform.Section
+	// noCaption отправить видео без подписи (модификатор "ссылка nocaption")
+	noCaption       bool
+	source          string
+	originalMessage int
+
+	// businessConnectionID задан для запросов из Telegram Business чатов
+	businessConnectionID string
+	// paidStars цена платного медиа в Telegram Stars (для каналов)
+	paidStars int
+	// quotaTaken ссылка учтена в суточной квоте пользователя
+	quotaTaken bool
 }
 
 type downloadRequest struct {
+	requestSpec
+
+	id              string
+	baseCtx         context.Context
 	ctx             context.Context
 	cancel          context.CancelFunc
-	chatID          int64
-	url             string
+	attempt         int
 	statusMessageID int
-	source          string
-	originalMessage int
+
+	// Время постановки в очередь и начала обработки
+	enqueuedAt time.Time
+	startedAt  time.Time
+	// bumped задача поднята администратором и запускается раньше остальных (jobQueue.bump)
+	bumped bool
+	// priority класс приоритета в очереди, задается при постановке (requestPriority)
+	priority jobPriority
+	// cancelledBy выставляется при отмене запроса администратором или владельцем
+	cancelledBy atomic.Int32
+
+	// statusMu не дает обновлению места в очереди перезаписать статус запущенной задачи;
+	// защищает queueShown, queueEditedAt и queueLeft
+	statusMu sync.Mutex
+	// queueShown место в очереди, показанное в статусе (нулевое — еще не показывалось)
+	queueShown jobPosition
+	// queueEditedAt время последнего обновления места в статусе
+	queueEditedAt time.Time
+	// queueLeft задача запущена или снята, место в очереди больше не показывается
+	queueLeft bool
+
+	// phase текущий этап обработки (requestPhase); phaseCtx ограничен бюджетом этапа
+	phase        atomic.Int32
+	phaseCtx     context.Context
+	phaseCancel  context.CancelFunc
+	phaseStarted time.Time
+	// summary итог задачи для сводной записи (общий у видео плейлиста)
+	summary *jobSummary
+
+	// slowModeSlot слот отправки в группу с медленным режимом занят до загрузки
+	// (deferForSlowMode), и первой отправке ждать его не нужно
+	slowModeSlot bool
+
+	// playlistItem запрос на одно видео из плейлиста (см. processPlaylist)
+	playlistItem bool
+	// deliveredItems ссылки видео плейлиста, отправленных до перезапуска бота
+	deliveredItems map[string]struct{}
+	// compressed файл пережат под лимит чата и не годится для кэша file_id
+	compressed bool
+	// media сведения о медиа из метаданных, полученных перед загрузкой: по ним
+	// подписывается аудио и составляются сообщения об ошибках после загрузки
+	media downloader.MediaInfo
 }
 
 // NewHandler создает новый обработчик Telegram
@@ -44,53 +221,156 @@ func NewHandler(
 	logger *slog.Logger,
 	downloader *downloader.Service,
 	authService *auth.Service,
-	maxVideoSizeMB int,
-	workerCount int,
+	deliveries *delivery.Store,
+	cfg *config.Config,
 ) *Handler {
+	workerCount := cfg.Download.WorkerPoolSize
 	if workerCount <= 0 {
 		workerCount = 1
 	}
 
-	queueSize := workerCount * 2
+	queueSize := queueCapacity(cfg.Download.QueueSize, workerCount)
+	queue := newJobQueue(queueSize, cfg.Download.MaxConcurrentPerUser, cfg.Download.QueueChatWeights)
+
 	handler := &Handler{
 		bot:            bot,
 		botUsername:    botUsername,
 		logger:         logger,
 		downloader:     downloader,
 		auth:           authService,
-		maxVideoSize:   int64(maxVideoSizeMB) * 1024 * 1024, // конвертируем в байты
+		sizeLimits:     newSizeLimits(cfg),
 		workerCount:    workerCount,
 		queueSizeLimit: queueSize,
-		downloadQueue:  make(chan *downloadRequest, queueSize),
+		queue:          queue,
+		scaler: newWorkerScaler(logger, queue, cfg.Download.WorkerPoolMin, workerCount,
+			cfg.Download.AutoscaleInterval, cfg.Download.AutoscaleIdle),
+
+		loginRetryDelay:    cfg.Download.LoginRetryDelay,
+		loginRetryAttempts: cfg.Download.LoginRetryAttempts,
+
+		uploadPolicy: cfg.Telegram.Uploads,
+
+		deliveries:  deliveries,
+		postProcess: hooks.NewPostProcessor(logger, cfg.Hooks),
+		policy:      hooks.NewPolicyHook(logger, cfg.Hooks),
+
+		businessEnabled: cfg.Telegram.BusinessEnabled,
+		paidMediaPrices: cfg.Telegram.PaidMediaPrices,
+
+		allowedChatTypes: allowedChatTypes(cfg.Telegram.AllowedChatTypes),
+
+		cooldowns: newCooldowns(map[string]time.Duration{
+			"private":    cfg.Telegram.CooldownPrivate,
+			"group":      cfg.Telegram.CooldownGroup,
+			"supergroup": cfg.Telegram.CooldownGroup,
+		}),
+		traces:   trace.NewStore(cfg.Storage.TraceDir, cfg.Storage.TraceMaxFiles),
+		channels: channels.NewStore(logger, filepath.Join(cfg.Storage.DataDir, "channels.json")),
+		failures: history.NewStore(logger, filepath.Join(cfg.Storage.DataDir, "failures.json"), cfg.Storage.FailureHistorySize),
+		receipts: history.NewReceiptStore(logger, filepath.Join(cfg.Storage.DataDir, "receipts.json"), cfg.Storage.ReceiptHistorySize),
+
+		chatSettings:    chatsettings.NewStore(logger, filepath.Join(cfg.Storage.DataDir, "chat_settings.json")),
+		defaultLocation: defaultLocation(cfg.Telegram.DefaultTimezone),
+		defaultLocale:   localeFor(cfg.Telegram.DefaultLanguage, localeEN),
+		setup:           &setupWizard{store: setup.NewStore(logger, filepath.Join(cfg.Storage.DataDir, "setup.json"))},
+		bandwidth:       newBandwidthBudget(logger, cfg),
+		compressor:      compress.NewCompressor(logger, ytdlp.ExecRunner{}, cfg.Download.Compression),
+		stats:           stats.NewStore(logger, filepath.Join(cfg.Storage.DataDir, "stats.json"), clock.Real{}),
+		users:           newUsersStore(logger, cfg),
+		jobs:            jobs.NewStore(logger, filepath.Join(cfg.Storage.DataDir, "jobs.json")),
+
+		shareButton: cfg.Telegram.ShareButton,
+		fileIDs: newFileIDCache(
+			fileids.NewStore(logger, fileIDStorePath(cfg.Storage), cfg.Storage.FileIDCacheSize),
+			downloader.CanonicalURL,
+		),
+		slowModes: newSlowModes(),
+		infoSlots: make(chan struct{}, maxInfoRequests),
+
+		phaseTimeouts: phaseTimeouts{
+			metadata:  cfg.Download.MetadataTimeout,
+			transcode: cfg.Download.TranscodeTimeout,
+			upload:    cfg.Download.DeliveryTimeout,
+		},
+
+		jobLog: newJobLog(logger, cfg.Log.JobFile),
+		health: newPlatformHealth(),
+
+		groupStatusInterval: cfg.Telegram.GroupStatusInterval,
+
+		inlineCacheTime: cfg.Telegram.InlineCacheTime,
+		inlineResults:   newInlineResultCache(cfg.Telegram.InlineCacheTime, clock.Real{}, downloader.CanonicalURL),
+
+		texts: newBotTexts(logger, cfg.Texts, downloader.Platforms()),
 	}
 
+	handler.restoreSetup()
+	handler.grantImportedRoles(handler.users.All())
+	if cfg.Auth.ImportFile != "" {
+		handler.importUsersFromFile(cfg.Auth.ImportFile)
+	}
 	handler.startWorkers()
 
 	return handler
 }
 
+// Close записывает на диск изменения хранилищ, ожидающие отложенной записи.
+// Вызывается при остановке бота
+func (h *Handler) Close() {
+	h.jobs.Close()
+	h.fileIDs.store.Close()
+	h.jobLog.close()
+}
+
+// queueCapacity размер очереди загрузок. По умолчанию с запасом: задачи сохраняются
+// на диск, а пул воркеров растет под нагрузкой
+func queueCapacity(configured, workerCount int) int {
+	if configured > 0 {
+		return configured
+	}
+	return max(workerCount*10, 100)
+}
+
 func (h *Handler) startWorkers() {
+	go h.scaler.run()
+
 	for i := 0; i < h.workerCount; i++ {
 		workerID := i + 1
 		go func(id int) {
-			// Обработка паник в воркерах
-			defer func() {
-				if r := recover(); r != nil {
-					h.logger.Error("Panic recovered in download worker",
-						slog.Int("worker_id", id),
-						slog.Any("panic", r),
-					)
-				}
-			}()
-
 			h.logger.Info("Download worker started", slog.Int("worker_id", id))
-			for req := range h.downloadQueue {
-				h.processDownload(req)
+			for {
+				h.runJob(id, h.queue.pop())
 			}
 		}(workerID)
 	}
 }
 
+// runJob обрабатывает задачу, взятую воркером из очереди. Паника при обработке
+// завершает только эту задачу: она снимается с учета, и воркер берет следующую
+func (h *Handler) runJob(workerID int, req *downloadRequest) {
+	if h.deferForSlowMode(req) {
+		return
+	}
+
+	defer h.finishJob(req)
+	defer h.queue.done(req)
+	// Обработка паник в воркерах
+	defer func() {
+		if r := recover(); r != nil {
+			h.logger.Error("Panic recovered in download worker",
+				slog.Int("worker_id", workerID),
+				slog.String("request_id", req.id),
+				slog.Any("panic", r),
+			)
+			h.notify(req, "❌ Внутренняя ошибка при обработке запроса.")
+		}
+	}()
+
+	h.leaveQueue(req)
+	h.jobs.MarkStarted(req.id)
+	h.processDownload(req)
+}
+
 // HandleUpdate обрабатывает обновление от Telegram
 func (h *Handler) HandleUpdate(ctx context.Context, update tgbotapi.Update) {
 	// Обработка паник для предотвращения падения приложения
@@ -105,10 +385,14 @@ func (h *Handler) HandleUpdate(ctx context.Context, update tgbotapi.Update) {
 	switch {
 	case update.Message != nil:
 		h.handleMessage(ctx, update.Message)
+	case update.ChannelPost != nil:
+		h.handleChannelPost(ctx, update.ChannelPost)
 	case update.InlineQuery != nil:
 		h.handleInlineQuery(ctx, update.InlineQuery)
 	case update.ChosenInlineResult != nil:
 		h.handleChosenInlineResult(ctx, update.ChosenInlineResult)
+	case update.CallbackQuery != nil:
+		h.handleCallbackQuery(ctx, update.CallbackQuery)
 	default:
 		// Игнорируем остальные типы обновлений
 	}
@@ -121,21 +405,21 @@ func (h *Handler) handleMessage(ctx context.Context, message *tgbotapi.Message)
 		return
 	}
 
-	if message.From == nil {
-		h.logger.Warn("Received message without From field", slog.Int64("chat_id", message.Chat.ID))
+	if message.Chat == nil {
+		h.logger.Warn("Received message without Chat field")
 		return
 	}
 
-	if message.Chat == nil {
-		h.logger.Warn("Received message without Chat field")
+	userID, ok := senderID(message)
+	if !ok {
+		h.logger.Warn("Received message without From field", slog.Int64("chat_id", message.Chat.ID))
 		return
 	}
 
 	chatID := message.Chat.ID
-	userID := int64(message.From.ID)
 
 	username := ""
-	if message.From.UserName != "" {
+	if message.From != nil && message.From.UserName != "" {
 		username = message.From.UserName
 	}
 
@@ -165,6 +449,19 @@ func (h *Handler) handleMessage(ctx context.Context, message *tgbotapi.Message)
 		}
 	}
 
+	// Бот без администратора предлагает первому /start в личке пройти настройку
+	if chatType == "private" && message.Command() == "start" && h.setupRequired() {
+		if h.handleSetupStart(message, userID) {
+			return
+		}
+	}
+
+	// Администраторы бота управляют им из любого чата, в том числе там, где загрузки запрещены
+	if !h.chatTypeAllowed(chatType) && !h.isAdmin(message) {
+		h.rejectChatType(message)
+		return
+	}
+
 	// Проверка авторизации
 	if h.auth != nil && h.auth.IsEnabled() && !h.auth.IsAuthorized(userID) {
 		h.handleAuthFlow(ctx, message)
@@ -191,29 +488,103 @@ func (h *Handler) handleCommand(ctx context.Context, message *tgbotapi.Message)
 	chatID := message.Chat.ID
 	command := message.Command()
 
+	if link, ok := linkCommands[command]; ok {
+		if strings.TrimSpace(message.CommandArguments()) == "" {
+			h.sendMessage(chatID, link.usage)
+			return
+		}
+		h.acceptLink(ctx, message, message.CommandArguments(), link.mode)
+		return
+	}
+
 	switch command {
 	case "start":
-		h.sendMessage(chatID, "👋 Привет! Я бот для скачивания видео.\n\n"+
-			"Отправь мне ссылку на видео с:\n"+
-			"• YouTube\n"+
-			"• TikTok\n"+
-			"• Instagram (Reels и обычные видео)\n\n"+
-			"И я скачаю и отправлю тебе видео!")
+		h.sendMessage(chatID, h.texts.start)
 
 	case "help":
-		h.sendMessage(chatID, "📖 Помощь\n\n"+
-			"Доступные команды:\n"+
-			"/start - Начать работу с ботом\n"+
-			"/help - Показать эту справку\n\n"+
-			"Как использовать:\n"+
-			"Просто отправь ссылку на видео, и я скачаю его для тебя!\n\n"+
-			"Поддерживаемые платформы:\n"+
-			"• YouTube (youtube.com, youtu.be)\n"+
-			"• TikTok (tiktok.com)\n"+
-			"• Instagram (instagram.com)")
+		h.sendMessage(chatID, h.texts.help)
+
+	case "link_channel":
+		h.handleLinkChannelCommand(message)
+
+	case "unlink_channel":
+		h.handleUnlinkChannelCommand(message)
+
+	case "quiet":
+		h.handleQuietCommand(message)
+
+	case "cleanup":
+		h.handleCleanupCommand(message)
+
+	case "status":
+		h.handleStatusCommand(message)
+
+	case "timezone":
+		h.handleTimezoneCommand(message)
+
+	case "cancel_all":
+		h.handleCancelAllCommand(message)
+
+	case "document":
+		h.handleDocumentCommand(message)
+
+	case "info":
+		h.handleInfoCommand(ctx, message)
+
+	case "version":
+		h.handleVersionCommand(message)
+
+	case "top":
+		h.handleTopCommand(message)
 
 	default:
-		h.sendMessage(chatID, "❓ Неизвестная команда. Используй /help для справки.")
+		h.handleAdminCommand(ctx, message, command)
+	}
+}
+
+// linkCommands команды, принимающие ссылку: вид отправки и подсказка без аргументов
+var linkCommands = map[string]struct {
+	mode  linkMode
+	usage string
+}{
+	"audio":    {linkAudio, "Использование: /audio &lt;ссылка&gt; — пришлю только звуковую дорожку в MP3."},
+	"mp3":      {linkAudio, "Использование: /audio &lt;ссылка&gt; — пришлю только звуковую дорожку в MP3."},
+	"gif":      {linkAnimation, "Использование: /gif &lt;ссылка&gt; — пришлю короткий ролик как GIF-анимацию без звука."},
+	"file":     {linkDocument, "Использование: /file &lt;ссылка&gt; — пришлю видео файлом, без пережатия Telegram."},
+	"chapters": {linkChapters, "Использование: /chapters &lt;ссылка&gt; — разделю видео YouTube по главам и пришлю каждую отдельно."},
+	"circle":   {linkVideoNote, "Использование: /circle &lt;ссылка&gt; — пришлю видео «кружком» (квадрат до минуты)."},
+	"note":     {linkVideoNote, "Использование: /circle &lt;ссылка&gt; — пришлю видео «кружком» (квадрат до минуты)."},
+}
+
+// handleAdminCommand обрабатывает команды администраторов; для остальных
+// пользователей они выглядят как неизвестные
+func (h *Handler) handleAdminCommand(ctx context.Context, message *tgbotapi.Message, command string) {
+	if !h.isAdmin(message) {
+		h.sendMessage(message.Chat.ID, "❓ Неизвестная команда. Используй /help для справки.")
+		return
+	}
+
+	switch command {
+	case "trace":
+		h.handleTraceCommand(message)
+
+	case "stats":
+		h.handleStatsCommand(message)
+
+	case "queue":
+		h.handleQueueCommand(message)
+
+	case "replay":
+		h.handleReplayCommand(ctx, message)
+
+	case "post":
+		h.handlePostCommand(message)
+
+	case "import_users":
+		h.handleImportUsersCommand(ctx, message)
+
+	default:
+		h.sendMessage(message.Chat.ID, "❓ Неизвестная команда. Используй /help для справки.")
 	}
 }
 
@@ -228,7 +599,6 @@ func (h *Handler) handleTextMessage(ctx context.Context, message *tgbotapi.Messa
 		return
 	}
 
-	chatID := message.Chat.ID
 	text := strings.TrimSpace(message.Text)
 
 	if message.Chat.Type == "group" || message.Chat.Type == "supergroup" {
@@ -242,123 +612,673 @@ func (h *Handler) handleTextMessage(ctx context.Context, message *tgbotapi.Messa
 		}
 	}
 
-	if !h.containsURL(text) {
-		h.sendMessage(chatID, "❌ Пожалуйста, отправь валидную ссылку на видео.")
+	h.acceptLink(ctx, message, text, linkVideo)
+}
+
+// linkMode определяет, в каком виде отправить скачанное по ссылке
+type linkMode int
+
+const (
+	linkVideo linkMode = iota
+	// linkAudio только звуковая дорожка (/audio)
+	linkAudio
+	// linkAnimation анимация без звука (/gif)
+	linkAnimation
+	// linkVideoNote видеосообщение-«кружок» (/circle)
+	linkVideoNote
+	// linkDocument видео файлом без пережатия (/file)
+	linkDocument
+	// linkChapters видео по главам (/chapters)
+	linkChapters
+)
+
+// acceptLink извлекает ссылку из текста сообщения и ставит загрузку в очередь
+func (h *Handler) acceptLink(ctx context.Context, message *tgbotapi.Message, text string, mode linkMode) {
+	spec, mode, ok := h.parseLink(message, text, mode)
+	if !ok {
+		return
+	}
+
+	chatID := spec.chatID
+	if !h.checkPolicy(ctx, spec) {
+		return
+	}
+	if h.shedLoad(message, spec.chapters) {
 		return
 	}
 
-	url := h.extractURL(text)
-	if url == "" {
-		h.sendMessage(chatID, "❌ Не удалось извлечь ссылку из сообщения.")
+	if !h.acquireCooldown(spec) {
+		return
+	}
+	if !h.checkQuota(&spec) {
+		h.cooldowns.release(chatID)
 		return
 	}
 
-	statusMsg := h.sendMessage(chatID, "⏳ Запрос принят, начинаю загрузку видео...")
-	downloadCtx, cancel := context.WithTimeout(ctx, 5*time.Minute)
+	if h.deferUntilQuiet(ctx, spec) {
+		return
+	}
+
+	statusText, ok := acceptedTexts[mode]
+	if !ok {
+		statusText = "⏳ Запрос принят, начинаю загрузку видео..."
+	}
+	statusMsg := h.sendServiceMessage(chatID, statusText)
+	requestCtx, cancel := context.WithCancel(ctx)
 
 	req := &downloadRequest{
-		ctx:             downloadCtx,
+		requestSpec:     spec,
+		id:              newRequestID(),
+		baseCtx:         ctx,
+		ctx:             requestCtx,
 		cancel:          cancel,
+		statusMessageID: h.safeMessageID(statusMsg),
+	}
+
+	if !h.enqueueDownload(req) {
+		cancel()
+		h.cooldowns.release(chatID)
+		h.refundQuota(spec)
+		h.handleQueueOverflow(req)
+	}
+}
+
+// acceptedTexts статус принятого запроса по виду отправки (для видео — текст по умолчанию)
+var acceptedTexts = map[linkMode]string{
+	linkAudio:     "⏳ Запрос принят, извлекаю звуковую дорожку...",
+	linkAnimation: "⏳ Запрос принят, делаю GIF-анимацию...",
+	linkVideoNote: "⏳ Запрос принят, делаю видеосообщение...",
+	linkDocument:  "⏳ Запрос принят, скачиваю видео, пришлю файлом...",
+	linkChapters:  "⏳ Запрос принят, скачиваю видео и делю его на главы...",
+}
+
+// parseLink разбирает текст запроса: ссылку, фрагмент и модификаторы после ссылки.
+// Возвращает параметры запроса и итоговый вид отправки; ok=false — пользователю
+// уже объяснено, что не так
+func (h *Handler) parseLink(message *tgbotapi.Message, text string, mode linkMode) (spec requestSpec, _ linkMode, ok bool) {
+	chatID := message.Chat.ID
+
+	url := h.messageURL(message, text)
+	if url == "" {
+		if !h.containsURL(text) {
+			h.sendServiceMessage(chatID, "❌ Пожалуйста, отправь валидную ссылку на видео.")
+			return spec, mode, false
+		}
+		h.sendServiceMessage(chatID, "❌ Не удалось извлечь ссылку из сообщения.")
+		return spec, mode, false
+	}
+
+	section, err := extractSection(text)
+	if err != nil {
+		h.sendServiceMessage(chatID, "❌ Не удалось разобрать фрагмент: "+html.EscapeString(err.Error())+
+			"\nУкажи его после ссылки, например: <code>ссылка 00:30-01:45</code>")
+		return spec, mode, false
+	}
+
+	mods, err := extractModifiers(text, url)
+	if err != nil {
+		h.sendServiceMessage(chatID, "❌ Не удалось разобрать параметры запроса: "+html.EscapeString(err.Error())+
+			"\nПример: <code>ссылка 720p nocaption</code>")
+		return spec, mode, false
+	}
+	if mods.mode != linkVideo {
+		if mode != linkVideo && mode != mods.mode {
+			h.sendServiceMessage(chatID, "❌ Команда и параметр после ссылки задают разный вид отправки. Оставь что-то одно.")
+			return spec, mode, false
+		}
+		mode = mods.mode
+	}
+
+	userID, _ := senderID(message)
+	spec = requestSpec{
 		chatID:          chatID,
+		chatType:        message.Chat.Type,
+		userID:          userID,
+		userName:        displayName(message.From),
 		url:             url,
-		statusMessageID: h.safeMessageID(statusMsg),
+		password:        extractPassword(text),
+		quality:         mods.quality,
+		audioOnly:       mode == linkAudio,
+		animation:       mode == linkAnimation,
+		videoNote:       mode == linkVideoNote,
+		document:        mode == linkDocument || (mode == linkVideo && h.chatSettings.Get(chatID).AsDocument),
+		chapters:        mode == linkChapters,
+		language:        userLanguage(message.From),
+		section:         section,
+		noCaption:       mods.noCaption,
 		source:          "direct_message",
 		originalMessage: message.MessageID,
 	}
+	return spec, mode, true
+}
 
-	if !h.enqueueDownload(req) {
-		cancel()
-		h.handleQueueOverflow(chatID, req.statusMessageID)
+// deferUntilQuiet в тихие часы принимает ссылку, а загрузку откладывает до конца окна.
+// Возвращает false, если тихих часов сейчас нет
+func (h *Handler) deferUntilQuiet(ctx context.Context, spec requestSpec) bool {
+	until, quiet := h.quietUntil(spec.chatID, spec.chatType)
+	if !quiet {
+		return false
+	}
+
+	statusMsg := h.sendServiceMessage(spec.chatID, fmt.Sprintf(
+		"🌙 В чате тихие часы. Ссылка принята, видео пришлю после %s.",
+		h.locale(spec.language).clockTime(until),
+	))
+	req := &downloadRequest{
+		requestSpec:     spec,
+		id:              newRequestID(),
+		baseCtx:         ctx,
+		statusMessageID: h.safeMessageID(statusMsg),
 	}
+
+	h.logger.Info("Download deferred until the end of quiet hours",
+		slog.Int64("chat_id", spec.chatID),
+		slog.String("url", spec.url),
+		slog.Time("until", until),
+	)
+	h.scheduleDownload(req, time.Until(until))
+	return true
 }
 
-func (h *Handler) enqueueDownload(req *downloadRequest) bool {
-	select {
-	case h.downloadQueue <- req:
-		h.logger.Info("Download request enqueued",
-			slog.Int64("chat_id", req.chatID),
-			slog.String("url", req.url),
-			slog.String("source", req.source),
-		)
+// acquireCooldown фиксирует загрузку в кулдауне чата и сообщает, сколько ждать,
+// если кулдаун еще не истек
+func (h *Handler) acquireCooldown(spec requestSpec) bool {
+	wait, ok := h.cooldowns.acquire(spec.chatID, spec.chatType)
+	if !ok {
+		h.reply(spec, fmt.Sprintf(
+			"⏳ Подожди ещё %s перед следующей загрузкой в этом чате.",
+			h.locale(spec.language).duration(wait),
+		))
+	}
+	return ok
+}
+
+// checkPolicy проверяет запрос хуком политики и сообщает пользователю об отказе.
+// Причина отказа приходит от внешнего сервиса и экранируется. В канал отказ не
+// публикуется: его увидели бы подписчики, а хук и так записывает его в лог
+func (h *Handler) checkPolicy(ctx context.Context, spec requestSpec) bool {
+	decision := h.policy.Evaluate(ctx, hooks.PolicyInput{
+		UserID:   spec.userID,
+		ChatID:   spec.chatID,
+		ChatType: spec.chatType,
+		URL:      spec.url,
+		Platform: h.downloader.Platform(spec.url),
+	})
+	if decision.Allow {
 		return true
-	default:
+	}
+
+	if spec.chatType == "channel" {
+		return false
+	}
+	text := "🚫 Запрос отклонен правилами бота."
+	if decision.Reason != "" {
+		text += "\n" + html.EscapeString(decision.Reason)
+	}
+	h.reply(spec, text)
+	return false
+}
+
+func (h *Handler) enqueueDownload(req *downloadRequest) bool {
+	req.priority = h.requestPriority(req)
+	h.saveJob(req, time.Time{})
+	if !h.queue.push(req) {
+		h.jobs.Remove(req.id)
 		h.logger.Warn("Download queue is full",
 			slog.Int("queue_capacity", h.queueSizeLimit),
 			slog.String("url", req.url),
 		)
 		return false
 	}
+
+	h.logger.Info("Download request enqueued",
+		slog.String("request_id", req.id),
+		slog.Int64("chat_id", req.chatID),
+		slog.String("url", req.url),
+		slog.String("source", req.source),
+		slog.Bool("high_priority", req.priority == priorityHigh),
+	)
+	return true
 }
 
-func (h *Handler) handleQueueOverflow(chatID int64, statusMessageID int) {
-	if statusMessageID != 0 {
-		h.deleteMessage(chatID, statusMessageID)
+// requestPriority возвращает класс приоритета запроса: личные чаты и администраторы
+// обслуживаются раньше групп и каналов
+func (h *Handler) requestPriority(req *downloadRequest) jobPriority {
+	if req.chatType == "private" || h.auth.IsAdmin(req.userID) {
+		return priorityHigh
 	}
-	h.sendMessage(chatID, "⚠️ Слишком много одновременных запросов. Попробуй повторить через пару минут.")
+	return priorityNormal
+}
+
+// handleQueueOverflow сообщает о переполнении очереди. Метаданные здесь не запрашиваются,
+// чтобы не нагружать перегруженный бот, поэтому указывается только платформа
+func (h *Handler) handleQueueOverflow(req *downloadRequest) {
+	if req.statusMessageID != 0 {
+		h.deleteMessage(req.chatID, req.statusMessageID)
+	}
+	h.sendMessage(req.chatID, "⚠️ Слишком много одновременных запросов. Попробуй повторить через пару минут."+
+		mediaSummary(downloader.MediaInfo{Platform: h.downloader.PlatformTitle(req.url)}, h.locale(req.language)))
+}
+
+// mediaSummary описывает медиа для сообщений об ошибках: название, платформу и размер,
+// если они известны. Пустая строка — сведений нет
+func mediaSummary(info downloader.MediaInfo, loc locale) string {
+	var details []string
+	if info.Platform != "" {
+		details = append(details, html.EscapeString(info.Platform))
+	}
+	if info.Size > 0 {
+		details = append(details, "≈ "+loc.size(info.Size))
+	}
+
+	var summary string
+	if info.Title != "" {
+		summary += "\n🎬 <b>" + html.EscapeString(info.Title) + "</b>"
+	}
+	if len(details) > 0 {
+		summary += "\n📺 " + strings.Join(details, " · ")
+	}
+	if summary == "" {
+		return ""
+	}
+	return "\n" + summary
 }
 
 func (h *Handler) processDownload(req *downloadRequest) {
 	defer req.cancel()
 
+	req.summary = newJobSummary()
+	defer h.logJobSummary(req)
+	defer h.refundUndelivered(req)
+
 	h.logger.Info("Processing download request",
 		slog.Int64("chat_id", req.chatID),
 		slog.String("url", req.url),
 		slog.String("source", req.source),
 	)
 
-	filePath, err := h.downloader.Download(req.ctx, req.url)
-	if err != nil {
-		h.clearStatusMessage(req)
-		h.logger.Error("Failed to download video",
-			slog.String("url", req.url),
-			slog.Any("error", err),
-		)
-		h.sendMessage(req.chatID, fmt.Sprintf("❌ Ошибка при загрузке видео: %s", err.Error()))
+	if h.serveCachedVideo(req) {
+		return
+	}
+	if h.budgetExhausted(req) {
+		return
+	}
+	if h.processChapters(req) {
 		return
 	}
+	if h.processPlaylist(req) {
+		return
+	}
+	h.downloadAndSend(req)
+}
+
+// itemResult итог загрузки одного видео
+type itemResult int
+
+const (
+	itemFailed itemResult = iota
+	itemDelivered
+	// itemTooBig видео скачано, но превышает лимит чата (для элементов плейлиста
+	// об этом сообщается в итоговом отчете)
+	itemTooBig
+)
+
+// downloadAndSend скачивает видео запроса и отправляет его в чат. Ошибки сообщаются
+// пользователю здесь же; size — размер файла для itemTooBig
+func (h *Handler) downloadAndSend(req *downloadRequest) (result itemResult, size int64) {
+	// Привязываем ID запроса, чтобы вывод yt-dlp сохранился для /trace
+	downloadCtx := trace.NewContext(h.enterPhase(req, phaseDownload), h.traces, req.id)
+
+	quality := req.quality
+	if quality == platform.QualityDefault {
+		quality = h.setup.defaultQuality()
+	}
+
+	maxAllowed := h.sizeLimits.forChat(req.chatID, req.chatType)
+
+	downloaded, media, err := h.downloader.Download(downloadCtx, req.url, downloader.Options{
+		Password:  req.password,
+		Quality:   quality,
+		AudioOnly: req.audioOnly,
+		Language:  req.language,
+		Section:   req.section,
+		MaxSize:   h.preflightSizeLimit(req, maxAllowed),
+		// Для GIF длительность проверяется по метаданным, чтобы не скачивать длинный ролик
+		MaxDuration: animationDurationLimit(req),
+	})
+	req.media = media
+	if err != nil {
+		return h.handleDownloadError(req, err)
+	}
+	// Удаляются и скачанный файл, и результат конвертации или сжатия: при ошибке
+	// на любом шаге временный файл не должен остаться на диске
+	filePath := downloaded
 	defer func() {
-		if err := h.downloader.Cleanup(filePath); err != nil {
-			h.logger.Warn("Failed to cleanup file", slog.String("file", filePath), slog.Any("error", err))
+		paths := []string{downloaded}
+		if filePath != downloaded {
+			paths = append(paths, filePath)
+		}
+		for _, path := range paths {
+			if err := h.downloader.Cleanup(path); err != nil {
+				h.logger.Warn("Failed to cleanup file", slog.String("file", path), slog.Any("error", err))
+			}
 		}
 	}()
 
 	h.clearStatusMessage(req)
 
+	prepared, ok := h.prepareFile(req, downloaded)
+	if !ok {
+		return
+	}
+	filePath = prepared
+
 	fileSize, err := h.downloader.GetFileSize(filePath)
 	if err != nil {
 		h.logger.Error("Failed to get file size", slog.String("file", filePath), slog.Any("error", err))
-		h.sendMessage(req.chatID, "❌ Ошибка при проверке размера файла.")
+		h.notify(req, "❌ Ошибка при проверке размера файла.")
 		return
 	}
+	h.bandwidth.Consume(fileSize)
+	req.summary.downloaded(fileSize)
 
-	maxAllowed := h.maxAllowedFileSize()
+	var captions []string
+	if req.section != nil {
+		captions = append(captions, "✂️ Фрагмент "+req.section.String())
+	}
 	if fileSize > maxAllowed {
-		h.sendMessage(req.chatID, fmt.Sprintf(
-			"❌ Видео слишком большое (%.2f MB). Ограничение Telegram %.0f MB.",
-			float64(fileSize)/(1024*1024),
-			float64(maxAllowed)/(1024*1024),
-		))
+		if compressed, compressedSize, ok := h.compressVideo(req, filePath, maxAllowed); ok {
+			captions = append(captions, fmt.Sprintf("🗜 Видео сжато с %s до %s, чтобы уложиться в лимит чата.",
+				h.locale(req.language).size(fileSize), h.locale(req.language).size(compressedSize),
+			))
+			filePath, fileSize = compressed, compressedSize
+			req.compressed = true
+		} else if req.cancelledBy.Load() != cancelNone {
+			if req.cancelledBy.Load() == cancelByAdmin {
+				h.notify(req, "🚫 Загрузка отменена администратором.")
+			}
+			return
+		} else if h.notifyPhaseTimeout(req, nil, "") {
+			return
+		}
+	}
+
+	if fileSize > maxAllowed && req.playlistItem {
+		return itemTooBig, fileSize
+	}
+	if fileSize > maxAllowed {
+		h.notifyTooBig(req, fileSize, maxAllowed)
 		return
 	}
 
-	if err := h.sendVideo(req.chatID, filePath); err != nil {
+	if warning := h.bandwidthWarning(req); warning != "" {
+		captions = append(captions, warning)
+	}
+	if warning := h.quotaWarning(req); warning != "" {
+		captions = append(captions, warning)
+	}
+	if req.noCaption {
+		captions = nil
+	}
+	return h.sendDownloaded(req, filePath, fileSize, maxAllowed, strings.Join(captions, "\n"))
+}
+
+// handleDownloadError сообщает пользователю об ошибке загрузки
+func (h *Handler) handleDownloadError(req *downloadRequest, err error) (itemResult, int64) {
+	loc := h.locale(req.language)
+	h.clearStatusMessage(req)
+
+	switch req.cancelledBy.Load() {
+	case cancelByAdmin:
+		h.logger.Info("Download cancelled by admin", slog.String("request_id", req.id))
+		h.notify(req, "🚫 Загрузка отменена администратором.")
+		return itemFailed, 0
+	case cancelByOwner:
+		h.logger.Info("Download cancelled by owner", slog.String("request_id", req.id))
+		return itemFailed, 0
+	}
+
+	h.logger.Error("Failed to download video",
+		slog.String("url", req.url),
+		slog.Int("attempt", req.attempt),
+		slog.Any("error", err),
+	)
+
+	if errors.Is(err, downloader.ErrLoginRequired) {
+		h.handleLoginRequired(req, err)
+		return itemFailed, 0
+	}
+
+	var sizeErr *downloader.SizeError
+	if errors.As(err, &sizeErr) {
+		if req.playlistItem {
+			return itemTooBig, sizeErr.Size
+		}
+		// Размер уже указан в тексте, из метаданных нужны название и платформа.
+		// Лимит берется у чата: для сжатия порог проверки выше лимита
+		info := downloader.MediaInfoFromError(err)
+		info.Size = 0
+		h.notify(req, fmt.Sprintf(
+			"❌ Видео слишком большое (≈%s по данным платформы). Ограничение для этого чата %s.%s",
+			loc.size(sizeErr.Size),
+			loc.limit(h.sizeLimits.forChat(req.chatID, req.chatType)),
+			mediaSummary(info, loc),
+		))
+		return itemFailed, 0
+	}
+
+	h.recordFailure(req, err)
+	summary := mediaSummary(downloader.MediaInfoFromError(err), loc)
+
+	if h.notifyPhaseTimeout(req, err, summary) {
+		return itemFailed, 0
+	}
+
+	if errors.Is(err, downloader.ErrSignInRequired) {
+		h.logger.Error("YouTube requires sign in, configure YOUTUBE_PLAYER_CLIENT and YOUTUBE_PO_TOKEN",
+			slog.String("url", req.url),
+		)
+		h.notify(req, "❌ YouTube требует подтвердить вход для этого видео.\n"+
+			"Администратору бота нужно настроить YOUTUBE_PO_TOKEN.")
+		return itemFailed, 0
+	}
+
+	if errors.Is(err, downloader.ErrPasswordRequired) {
+		h.notify(req, "🔒 Видео защищено паролем. Отправь ссылку вместе с паролем:\n"+
+			"<code>ссылка pass:пароль</code>")
+		return itemFailed, 0
+	}
+
+	if errors.Is(err, downloader.ErrPrivateAddress) {
+		h.notify(req, "❌ Ссылка ведет на внутренний адрес, такие ссылки бот не загружает.")
+		return itemFailed, 0
+	}
+
+	if errors.Is(err, downloader.ErrUnknownDuration) {
+		h.notify(req, "❌ Это прямая трансляция или видео без известной длительности, такие бот не загружает."+summary)
+		return itemFailed, 0
+	}
+
+	if errors.Is(err, downloader.ErrContentNotAllowed) {
+		h.notify(req, "❌ По этой ссылке не видео или аудио поддерживаемого формата, такие файлы бот не пересылает.")
+		return itemFailed, 0
+	}
+
+	var durationErr *downloader.DurationError
+	if errors.As(err, &durationErr) {
+		if req.animation && durationErr.Limit == downloader.MaxAnimationDuration {
+			h.notifyAnimationTooLong(req)
+			return itemFailed, 0
+		}
+		h.notify(req, fmt.Sprintf(
+			"❌ Видео слишком длинное (%s). Ограничение — %s.%s",
+			loc.duration(durationErr.Duration),
+			loc.duration(durationErr.Limit),
+			summary,
+		))
+		return itemFailed, 0
+	}
+
+	h.notify(req, fmt.Sprintf(
+		"❌ Ошибка при загрузке видео: %s%s\nКод запроса: <code>%s</code>",
+		html.EscapeString(err.Error()), summary, req.id,
+	))
+	return itemFailed, 0
+}
+
+// prepareFile применяет хуки постобработки и переделывает файл в GIF или видеосообщение,
+// если это запрошено. ok=false — пользователь уже уведомлен об ошибке
+func (h *Handler) prepareFile(req *downloadRequest, filePath string) (path string, ok bool) {
+	// Пользовательские хуки постобработки (водяной знак и т.п.) рассчитаны на один файл,
+	// поэтому к слайдшоу не применяются
+	h.enterPhase(req, phaseTranscode)
+	hookInput := hooks.PostProcessInput{FilePath: filePath, URL: req.url, ChatID: req.chatID}
+	if !platform.IsSlideshow(filePath) {
+		if err := h.postProcess.Run(req.phaseContext(), hookInput); err != nil {
+			if !h.notifyPhaseTimeout(req, err, "") {
+				h.notify(req, "❌ Ошибка при обработке видео перед отправкой.")
+			}
+			return "", false
+		}
+	}
+
+	switch {
+	case req.animation:
+		animation, ok := h.convertToAnimation(req, filePath)
+		if !ok {
+			return "", false
+		}
+		filePath = animation
+	case req.videoNote:
+		note, ok := h.convertToVideoNote(req, filePath)
+		if !ok {
+			return "", false
+		}
+		filePath = note
+	}
+	return filePath, true
+}
+
+// notifyTooBig сообщает, что файл не укладывается в лимит чата
+func (h *Handler) notifyTooBig(req *downloadRequest, fileSize, maxAllowed int64) {
+	loc := h.locale(req.language)
+	// Размер уже указан в тексте, из метаданных нужны название и платформа
+	info := req.media
+	info.Size = 0
+	h.notify(req, fmt.Sprintf(
+		"❌ Видео слишком большое (%s). Ограничение для этого чата %s.%s",
+		loc.size(fileSize),
+		loc.limit(maxAllowed),
+		mediaSummary(info, loc),
+	))
+}
+
+// sendDownloaded отправляет готовый файл в чат через outbox и применяет эффекты доставки
+func (h *Handler) sendDownloaded(req *downloadRequest, filePath string, fileSize, maxAllowed int64, caption string) (itemResult, int64) {
+	// Сохраняем намерение отправки, чтобы возобновить его после перезапуска
+	h.deliveries.Add(delivery.Task{
+		ID:        req.id,
+		ChatID:    req.chatID,
+		ChatType:  req.chatType,
+		UserID:    req.userID,
+		UserName:  req.userName,
+		URL:       req.url,
+		FilePath:  filePath,
+		Source:    req.source,
+		Quality:   req.quality,
+		Audio:     req.audioOnly,
+		Animation: req.animation,
+		VideoNote: req.videoNote,
+		Document:  req.document,
+		CreatedAt: time.Now(),
+
+		BusinessConnectionID: req.businessConnectionID,
+		PaidStars:            req.paidStars,
+		Compressed:           req.compressed,
+	})
+	defer h.deliveries.Remove(req.id)
+
+	h.enterPhase(req, phaseUpload)
+	sent, err := h.deliverVideo(req, filePath, maxAllowed, caption)
+	if err != nil {
 		h.logger.Error("Failed to send video",
 			slog.String("file", filePath),
 			slog.Any("error", err),
 		)
-		h.sendMessage(req.chatID, fmt.Sprintf("❌ Ошибка при отправке видео: %s", err.Error()))
-		return
+		h.recordFailure(req, err)
+		info := req.media
+		info.Size = fileSize
+		loc := h.locale(req.language)
+		if h.notifyPhaseTimeout(req, err, mediaSummary(info, loc)) {
+			return itemFailed, 0
+		}
+		h.notify(req, fmt.Sprintf("❌ Ошибка при отправке видео: %s%s", html.EscapeString(err.Error()), mediaSummary(info, loc)))
+		return itemFailed, 0
 	}
 
+	h.bandwidth.Consume(fileSize)
+	req.summary.sent(fileSize)
+
 	h.logger.Info("Video delivered successfully",
 		slog.Int64("chat_id", req.chatID),
 		slog.String("url", req.url),
 	)
 
+	h.completeDelivery(req, sent)
+
+	if req.attempt > 0 {
+		h.notify(req, fmt.Sprintf("✅ Видео получено с попытки №%d.", req.attempt+1))
+	}
+
 	h.deleteOriginalMessage(req)
+	return itemDelivered, fileSize
+}
+
+// handleLoginRequired откладывает повторную попытку, если платформа временно требует авторизацию
+func (h *Handler) handleLoginRequired(req *downloadRequest, err error) {
+	loc := h.locale(req.language)
+	if req.attempt >= h.loginRetryAttempts || h.loginRetryDelay <= 0 {
+		h.recordFailure(req, err)
+		h.notify(req, fmt.Sprintf(
+			"❌ Платформа так и не отдала видео без авторизации (попыток: %d). Попробуй позже.%s",
+			req.attempt+1,
+			mediaSummary(downloader.MediaInfoFromError(err), loc),
+		))
+		return
+	}
+
+	// Экспоненциальная задержка: delay, 2*delay, 4*delay...
+	delay := h.loginRetryDelay * time.Duration(1<<req.attempt)
+
+	h.logger.Info("Scheduling download retry after login wall",
+		slog.Int64("chat_id", req.chatID),
+		slog.String("url", req.url),
+		slog.Int("attempt", req.attempt+1),
+		slog.Duration("delay", delay),
+	)
+
+	req.summary.retry()
+	h.notify(req, fmt.Sprintf(
+		"⏳ Платформа временно требует авторизацию. Повторю попытку через %s (попытка %d из %d).",
+		loc.duration(delay),
+		req.attempt+2,
+		h.loginRetryAttempts+1,
+	))
+
+	// Параметры запроса копируются целиком, состояние обработки у повтора свое
+	h.scheduleDownload(&downloadRequest{
+		requestSpec: req.requestSpec,
+		id:          newRequestID(),
+		baseCtx:     req.baseCtx,
+		attempt:     req.attempt + 1,
+	}, delay)
 }
 
 func (h *Handler) clearStatusMessage(req *downloadRequest) {
+	req.statusMu.Lock()
+	defer req.statusMu.Unlock()
+
+	req.queueLeft = true
 	if req.statusMessageID != 0 {
 		h.deleteMessage(req.chatID, req.statusMessageID)
 		req.statusMessageID = 0
@@ -374,13 +1294,18 @@ func (h *Handler) deleteOriginalMessage(req *downloadRequest) {
 
 // handleAuthFlow обрабатывает сообщения от неавторизованных пользователей
 func (h *Handler) handleAuthFlow(ctx context.Context, message *tgbotapi.Message) {
-	if message == nil || message.From == nil || message.Chat == nil {
+	if message == nil || message.Chat == nil {
+		h.logger.Warn("Invalid message in handleAuthFlow")
+		return
+	}
+
+	userID, ok := senderID(message)
+	if !ok {
 		h.logger.Warn("Invalid message in handleAuthFlow")
 		return
 	}
 
 	chatID := message.Chat.ID
-	userID := int64(message.From.ID)
 
 	text := ""
 	if message.Text != "" {
@@ -432,7 +1357,7 @@ func (h *Handler) handleInlineQuery(ctx context.Context, inlineQuery *tgbotapi.I
 	if h.auth != nil && h.auth.IsEnabled() && !h.auth.IsAuthorized(userID) {
 		results := []interface{}{
 			tgbotapi.NewInlineQueryResultArticle(
-				inlineQuery.ID+"-auth",
+				"auth",
 				"Требуется авторизация",
 				"Этот бот защищён.\nОткрой личный чат с ботом и отправь токен доступа, который выдал администратор.",
 			),
@@ -454,16 +1379,21 @@ func (h *Handler) handleInlineQuery(ctx context.Context, inlineQuery *tgbotapi.I
 		return
 	}
 
-	results := h.buildInlineResults(inlineQuery.ID, queryText)
-
 	inlineConfig := tgbotapi.InlineConfig{
 		InlineQueryID: inlineQuery.ID,
-		Results:       results,
-		CacheTime:     0,
+		Results:       h.buildInlineResults(queryText),
+		CacheTime:     int(h.inlineCacheTime.Seconds()),
 		IsPersonal:    true,
 	}
 
-	if _, err := h.bot.Request(inlineConfig); err != nil {
+	_, err := h.bot.Request(inlineConfig)
+	// Telegram отклоняет весь ответ, если file_id готового видео устарел:
+	// убираем его и предлагаем обычные варианты со свежей загрузкой
+	if isFileIDError(err) && h.forgetFileID(h.extractURL(queryText), err) {
+		inlineConfig.Results = h.buildInlineResults(queryText)
+		_, err = h.bot.Request(inlineConfig)
+	}
+	if err != nil {
 		h.logger.Error("Failed to answer inline query",
 			slog.String("query_id", inlineQuery.ID),
 			slog.Any("error", err),
@@ -471,24 +1401,38 @@ func (h *Handler) handleInlineQuery(ctx context.Context, inlineQuery *tgbotapi.I
 	}
 }
 
-func (h *Handler) buildInlineResults(queryID, rawQuery string) []interface{} {
-	var results []interface{}
-
-	if url := h.extractURL(rawQuery); url != "" && h.containsURL(url) {
-		messageText := fmt.Sprintf("⏳ Запрос на скачивание:\n%s\n\nБот отправит видео в личные сообщения.", url)
-		result := tgbotapi.NewInlineQueryResultArticle(queryID+"-download", "Скачать видео", messageText)
-		result.Description = "Поддерживаются YouTube, TikTok и Instagram"
-		results = append(results, result)
-	} else {
+func (h *Handler) buildInlineResults(rawQuery string) []interface{} {
+	url := h.extractURL(rawQuery)
+	if url == "" || !h.containsURL(url) {
 		helpResult := tgbotapi.NewInlineQueryResultArticle(
-			queryID+"-help",
+			"help",
 			"Укажи ссылку на видео",
 			"Пример: https://www.youtube.com/watch?v=dQw4w9WgXcQ",
 		)
-		helpResult.Description = "Поддерживаются YouTube, TikTok и Instagram"
-		results = append(results, helpResult)
+		helpResult.Description = h.texts.inlineDescription
+		return []interface{}{helpResult}
+	}
+
+	if results, ok := h.inlineResults.get(url); ok {
+		return results
+	}
+
+	var results []interface{}
+
+	// Видео уже есть в Telegram — предлагаем отправить его без повторной загрузки
+	if cached, ok := h.cachedInlineResult(url); ok {
+		results = append(results, cached)
 	}
 
+	// Варианты качества: пользователь выбирает нужный прямо в inline-панели
+	for _, variant := range inlineVariants {
+		messageText := fmt.Sprintf("⏳ Запрос на скачивание (%s):\n%s\n\nБот отправит файл в личные сообщения.", variant.label, url)
+		result := tgbotapi.NewInlineQueryResultArticle(inlineResultID(variant.kind, url), variant.title, messageText)
+		result.Description = variant.description
+		results = append(results, result)
+	}
+
+	h.inlineResults.put(url, results)
 	return results
 }
 
@@ -503,6 +1447,12 @@ func (h *Handler) handleChosenInlineResult(ctx context.Context, result *tgbotapi
 		return
 	}
 
+	// Закэшированное видео Telegram отправил сам, загружать ничего не нужно
+	if strings.HasSuffix(result.ResultID, cachedResultSuffix) {
+		h.logger.Info("Cached inline video sent", slog.String("query", result.Query))
+		return
+	}
+
 	url := h.extractURL(result.Query)
 	if url == "" {
 		h.logger.Warn("Chosen inline result without URL", slog.String("query", result.Query))
@@ -519,22 +1469,68 @@ func (h *Handler) handleChosenInlineResult(ctx context.Context, result *tgbotapi
 		h.sendMessage(chatID, "🔒 Этот бот защищён. Отправь токен доступа в личные сообщения бота, чтобы продолжить использование.")
 		return
 	}
-	statusMsg := h.sendMessage(chatID, "⏳ Обработка inline-запроса, загружаю видео...")
-	downloadCtx, cancel := context.WithTimeout(ctx, 5*time.Minute)
+	if !h.checkPolicy(ctx, requestSpec{chatID: chatID, chatType: "private", userID: userID, url: url}) {
+		return
+	}
+
+	variant, _ := variantByResultID(result.ResultID)
+
+	statusMsg := h.sendServiceMessage(chatID, "⏳ Обработка inline-запроса, загружаю видео...")
+	requestCtx, cancel := context.WithCancel(ctx)
 
 	req := &downloadRequest{
-		ctx:             downloadCtx,
+		requestSpec: requestSpec{
+			chatID:    chatID,
+			chatType:  "private",
+			userID:    userID,
+			userName:  displayName(result.From),
+			url:       url,
+			quality:   variant.quality,
+			audioOnly: variant.audioOnly,
+			language:  userLanguage(result.From),
+			source:    "inline_mode",
+		},
+
+		id:              newRequestID(),
+		baseCtx:         ctx,
+		ctx:             requestCtx,
 		cancel:          cancel,
-		chatID:          chatID,
-		url:             url,
 		statusMessageID: h.safeMessageID(statusMsg),
-		source:          "inline_mode",
 	}
 
 	if !h.enqueueDownload(req) {
 		cancel()
-		h.handleQueueOverflow(chatID, req.statusMessageID)
+		h.handleQueueOverflow(req)
+	}
+}
+
+// userLanguage возвращает код языка пользователя из настроек Telegram, пусто — неизвестен
+func userLanguage(user *tgbotapi.User) string {
+	if user == nil {
+		return ""
+	}
+	return user.LanguageCode
+}
+
+// senderID возвращает идентификатор отправителя сообщения. Для анонимных администраторов
+// группы (сообщение от имени самой группы) используется ID чата из sender_chat
+func senderID(message *tgbotapi.Message) (int64, bool) {
+	if message.SenderChat != nil && message.Chat != nil && message.SenderChat.ID == message.Chat.ID {
+		return message.SenderChat.ID, true
+	}
+	if message.From != nil {
+		return int64(message.From.ID), true
+	}
+	return 0, false
+}
+
+// newRequestID генерирует короткий идентификатор запроса
+func newRequestID() string {
+	b := make([]byte, 6)
+	if _, err := rand.Read(b); err != nil {
+		return fmt.Sprintf("%x", time.Now().UnixNano())
 	}
+	return hex.EncodeToString(b)
 }
 
 func (h *Handler) safeMessageID(msg *tgbotapi.Message) int {
@@ -544,14 +1540,6 @@ func (h *Handler) safeMessageID(msg *tgbotapi.Message) int {
 	return msg.MessageID
 }
 
-func (h *Handler) maxAllowedFileSize() int64 {
-	const telegramLimit = int64(50 * 1024 * 1024)
-	if h.maxVideoSize <= 0 || h.maxVideoSize > telegramLimit {
-		return telegramLimit
-	}
-	return h.maxVideoSize
-}
-
 // isBotMentioned проверяет, упомянут ли бот в сообщении
 func (h *Handler) isBotMentioned(message *tgbotapi.Message) bool {
 	if h.botUsername == "" || message == nil {
@@ -564,18 +1552,9 @@ func (h *Handler) isBotMentioned(message *tgbotapi.Message) bool {
 	}
 
 	// Проверяем entities (упоминания через @username)
-	if len(message.Entities) > 0 {
-		for _, entity := range message.Entities {
-			if entity.Type == "mention" {
-				// Проверяем границы перед обращением к строке
-				if entity.Offset >= 0 && entity.Offset+entity.Length <= len(message.Text) {
-					mention := message.Text[entity.Offset : entity.Offset+entity.Length]
-					// Убираем @ и сравниваем
-					if strings.TrimPrefix(mention, "@") == h.botUsername {
-						return true
-					}
-				}
-			}
+	for _, mention := range entityMentions(message) {
+		if strings.EqualFold(mention, h.botUsername) {
+			return true
 		}
 	}
 
@@ -603,14 +1582,26 @@ func (h *Handler) removeBotMentionFromText(text string) string {
 	return strings.Join(cleaned, " ")
 }
 
-// containsURL проверяет, содержит ли текст URL
+// containsURL проверяет, содержит ли текст URL или домен одной из включенных платформ
 func (h *Handler) containsURL(text string) bool {
-	return strings.Contains(text, "http://") ||
-		strings.Contains(text, "https://") ||
-		strings.Contains(text, "youtube.com") ||
-		strings.Contains(text, "youtu.be") ||
-		strings.Contains(text, "tiktok.com") ||
-		strings.Contains(text, "instagram.com")
+	if strings.Contains(text, "http://") || strings.Contains(text, "https://") {
+		return true
+	}
+	for _, hint := range h.texts.urlHints {
+		if strings.Contains(text, hint) {
+			return true
+		}
+	}
+	return false
+}
+
+// messageURL возвращает первую ссылку из сущностей сообщения (url, text_link),
+// а если их нет — ищет ссылку в тексте
+func (h *Handler) messageURL(message *tgbotapi.Message, text string) string {
+	if urls := entityURLs(message); len(urls) > 0 {
+		return urls[0]
+	}
+	return h.extractURL(text)
 }
 
 // extractURL извлекает первый URL из текста
@@ -626,6 +1617,32 @@ func (h *Handler) extractURL(text string) string {
 	return ""
 }
 
+// extractSection извлекает фрагмент видео из текста вида "<url> 00:30-01:45".
+// Слово считается диапазоном, если начинается с цифры и содержит ":" и "-"
+func extractSection(text string) (*platform.Section, error) {
+	for _, word := range strings.Fields(text) {
+		if word[0] < '0' || word[0] > '9' || !strings.Contains(word, ":") || !strings.Contains(word, "-") {
+			continue
+		}
+		section, err := platform.ParseSection(word)
+		if err != nil {
+			return nil, err
+		}
+		return &section, nil
+	}
+	return nil, nil
+}
+
+// extractPassword извлекает пароль из текста вида "<url> pass:1234"
+func extractPassword(text string) string {
+	for _, word := range strings.Fields(text) {
+		if password, ok := strings.CutPrefix(word, "pass:"); ok {
+			return password
+		}
+	}
+	return ""
+}
+
 // sendMessage отправляет текстовое сообщение
 func (h *Handler) sendMessage(chatID int64, text string) *tgbotapi.Message {
 	msg := tgbotapi.NewMessage(chatID, text)
@@ -642,6 +1659,24 @@ func (h *Handler) sendMessage(chatID int64, text string) *tgbotapi.Message {
 	return &sentMsg
 }
 
+// sendServiceMessage отправляет служебное сообщение (статус загрузки, ошибку запроса),
+// которое удаляется по настройке /cleanup. Ответы на команды, видео и отчеты
+// отправляются через sendMessage и остаются в чате
+func (h *Handler) sendServiceMessage(chatID int64, text string) *tgbotapi.Message {
+	sent := h.sendMessage(chatID, text)
+	if sent != nil {
+		h.scheduleCleanup(chatID, sent.MessageID)
+	}
+	return sent
+}
+
+// notifyAdmins отправляет сообщение всем администраторам бота
+func (h *Handler) notifyAdmins(text string) {
+	for _, adminID := range h.auth.Admins() {
+		h.sendMessage(adminID, text)
+	}
+}
+
 // deleteMessage удаляет сообщение
 func (h *Handler) deleteMessage(chatID int64, messageID int) {
 	deleteMsg := tgbotapi.NewDeleteMessage(chatID, messageID)
@@ -655,34 +1690,35 @@ func (h *Handler) deleteMessage(chatID int64, messageID int) {
 }
 
 // sendVideo отправляет видео файл
-func (h *Handler) sendVideo(chatID int64, filePath string) error {
+func (h *Handler) sendVideo(chatID int64, url, filePath string, maxAllowed int64, caption string) (*tgbotapi.Message, error) {
 	file, err := os.Open(filePath)
 	if err != nil {
-		return fmt.Errorf("failed to open file: %w", err)
+		return nil, fmt.Errorf("failed to open file: %w", err)
 	}
 	defer file.Close()
 
 	// Получаем информацию о файле
 	fileInfo, err := file.Stat()
 	if err != nil {
-		return fmt.Errorf("failed to get file info: %w", err)
+		return nil, fmt.Errorf("failed to get file info: %w", err)
 	}
 
 	// Проверяем размер файла перед отправкой
-	maxAllowed := h.maxAllowedFileSize()
 	if fileInfo.Size() > maxAllowed {
-		return fmt.Errorf("file size %d exceeds maximum allowed size %d", fileInfo.Size(), maxAllowed)
+		return nil, fmt.Errorf("file size %d exceeds maximum allowed size %d", fileInfo.Size(), maxAllowed)
 	}
 
-	// Используем FileReader для потоковой отправки вместо загрузки всего файла в память
-	fileReader := tgbotapi.FileReader{
-		Name:   fileInfo.Name(),
-		Reader: file,
+	params := tgbotapi.Params{}
+	params.AddNonZero64("chat_id", chatID)
+	params.AddNonEmpty("caption", caption)
+	params.AddBool("supports_streaming", true)
+	if markup := h.shareMarkup(url); markup != nil {
+		if err := params.AddInterface("reply_markup", markup); err != nil {
+			return nil, fmt.Errorf("failed to encode reply markup: %w", err)
+		}
 	}
-
-	// Отправляем видео
-	video := tgbotapi.NewVideo(chatID, fileReader)
-	video.SupportsStreaming = true
+	thumbnail := h.addVideoProps(params, filePath)
+	defer h.removeThumbnail(thumbnail)
 
 	h.logger.Info("Sending video",
 		slog.Int64("chat_id", chatID),
@@ -690,10 +1726,63 @@ func (h *Handler) sendVideo(chatID int64, filePath string) error {
 		slog.Int64("size", fileInfo.Size()),
 	)
 
-	if _, err := h.bot.Send(video); err != nil {
-		return fmt.Errorf("failed to send video: %w", err)
+	// Отправляем видео; файл подставляется при каждой попытке в retryUpload
+	var sent tgbotapi.Message
+	err = h.retryUpload(file, func(r io.Reader) error {
+		resp, err := h.bot.UploadFiles("sendVideo", params, videoFiles(fileInfo.Name(), r, thumbnail))
+		if err != nil {
+			return err
+		}
+		return json.Unmarshal(resp.Result, &sent)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to send video: %w", err)
 	}
 
 	h.logger.Info("Video sent successfully", slog.Int64("chat_id", chatID))
-	return nil
+	return &sent, nil
+}
+
+// sendAudio отправляет аудиофайл
+func (h *Handler) sendAudio(chatID int64, filePath string, maxAllowed int64, info downloader.MediaInfo) (*tgbotapi.Message, error) {
+	file, err := os.Open(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open file: %w", err)
+	}
+	defer file.Close()
+
+	fileInfo, err := file.Stat()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get file info: %w", err)
+	}
+
+	if fileInfo.Size() > maxAllowed {
+		return nil, fmt.Errorf("file size %d exceeds maximum allowed size %d", fileInfo.Size(), maxAllowed)
+	}
+
+	audio := tgbotapi.NewAudio(chatID, nil)
+	audio.Title = info.Title
+	audio.Performer = info.Uploader
+	audio.Duration = int(info.Duration.Round(time.Second).Seconds())
+
+	h.logger.Info("Sending audio",
+		slog.Int64("chat_id", chatID),
+		slog.String("file", filePath),
+		slog.Int64("size", fileInfo.Size()),
+	)
+
+	var sent tgbotapi.Message
+	err = h.retryUpload(file, func(r io.Reader) error {
+		audio.File = tgbotapi.FileReader{Name: fileInfo.Name(), Reader: r}
+
+		var err error
+		sent, err = h.bot.Send(audio)
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to send audio: %w", err)
+	}
+
+	h.logger.Info("Audio sent successfully", slog.Int64("chat_id", chatID))
+	return &sent, nil
 }
@@ -0,0 +1,110 @@
+package telegram
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// Оценка состояния платформ по недавним загрузкам
+const (
+	// healthWindow за какой период учитываются загрузки
+	healthWindow = time.Hour
+	// healthMaxEvents сколько последних загрузок платформы хранится
+	healthMaxEvents = 200
+)
+
+// platformHealth итоги недавних загрузок по платформам. Учитываются только исходы,
+// зависящие от платформы: доставка и ошибка или таймаут загрузки
+type platformHealth struct {
+	mu     sync.Mutex
+	events map[string][]healthEvent
+}
+
+// healthEvent итог одной загрузки
+type healthEvent struct {
+	at time.Time
+	ok bool
+}
+
+// platformState сводка по платформе за healthWindow
+type platformState struct {
+	Platform  string
+	Succeeded int
+	Total     int
+}
+
+func newPlatformHealth() *platformHealth {
+	return &platformHealth{events: make(map[string][]healthEvent)}
+}
+
+// record учитывает итог задачи (jobResult*); остальные итоги пропускаются
+func (p *platformHealth) record(platform, result string, at time.Time) {
+	var ok bool
+	switch result {
+	case jobResultDelivered, jobResultPartial:
+		ok = true
+	case jobResultFailed, jobResultTimeout:
+	default:
+		return
+	}
+	if platform == "" {
+		return
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	events := append(p.events[platform], healthEvent{at: at, ok: ok})
+	if len(events) > healthMaxEvents {
+		events = events[len(events)-healthMaxEvents:]
+	}
+	p.events[platform] = events
+}
+
+// snapshot возвращает платформы с загрузками за healthWindow, по убыванию числа загрузок
+func (p *platformHealth) snapshot(now time.Time) []platformState {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	since := now.Add(-healthWindow)
+	var states []platformState
+	for platform, events := range p.events {
+		// События идут по времени: отбрасываем устаревшие с начала
+		i := sort.Search(len(events), func(i int) bool { return events[i].at.After(since) })
+		events = events[i:]
+		if len(events) == 0 {
+			delete(p.events, platform)
+			continue
+		}
+		p.events[platform] = events
+
+		state := platformState{Platform: platform, Total: len(events)}
+		for _, event := range events {
+			if event.ok {
+				state.Succeeded++
+			}
+		}
+		states = append(states, state)
+	}
+
+	sort.Slice(states, func(i, j int) bool {
+		if states[i].Total != states[j].Total {
+			return states[i].Total > states[j].Total
+		}
+		return states[i].Platform < states[j].Platform
+	})
+	return states
+}
+
+// mark значок состояния: от 80% успешных загрузок — норма, меньше половины — сбой
+func (s platformState) mark() string {
+	switch rate := float64(s.Succeeded) / float64(s.Total); {
+	case rate >= 0.8:
+		return "🟢"
+	case rate >= 0.5:
+		return "🟡"
+	default:
+		return "🔴"
+	}
+}
@@ -0,0 +1,106 @@
+package telegram
+
+import (
+	"context"
+	"fmt"
+	"html"
+	"log/slog"
+	"strings"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+
+	"github.com/reelser-bot/internal/services/downloader"
+)
+
+// infoTimeout ограничивает получение метаданных для /info
+const infoTimeout = time.Minute
+
+// maxInfoRequests сколько запросов /info выполняется одновременно: каждый запускает yt-dlp
+const maxInfoRequests = 4
+
+// handleInfoCommand присылает сведения о видео без загрузки: /info <ссылка>.
+// Метаданные запрашиваются в фоне, чтобы не занимать обработчик апдейтов; запрос
+// подчиняется кулдауну чата, а число одновременных запросов ограничено maxInfoRequests
+func (h *Handler) handleInfoCommand(ctx context.Context, message *tgbotapi.Message) {
+	chatID := message.Chat.ID
+
+	text := message.CommandArguments()
+	url := h.messageURL(message, text)
+	if url == "" {
+		h.sendMessage(chatID, "Использование: /info &lt;ссылка&gt; — покажу название, автора, длительность "+
+			"и примерный размер видео без загрузки.")
+		return
+	}
+
+	language := userLanguage(message.From)
+	if wait, ok := h.cooldowns.acquire(chatID, message.Chat.Type); !ok {
+		h.sendMessage(chatID, fmt.Sprintf(
+			"⏳ Подожди ещё %s перед следующим запросом в этом чате.",
+			h.locale(language).duration(wait),
+		))
+		return
+	}
+	select {
+	case h.infoSlots <- struct{}{}:
+	default:
+		h.cooldowns.release(chatID)
+		h.sendMessage(chatID, "⏳ Сейчас обрабатывается слишком много запросов /info, попробуй через минуту.")
+		return
+	}
+
+	statusMsg := h.sendServiceMessage(chatID, "🔎 Получаю сведения о видео...")
+	req := &downloadRequest{requestSpec: requestSpec{chatID: chatID}, statusMessageID: h.safeMessageID(statusMsg)}
+
+	go func() {
+		defer func() { <-h.infoSlots }()
+
+		infoCtx, cancel := context.WithTimeout(ctx, infoTimeout)
+		defer cancel()
+
+		info, err := h.downloader.Info(infoCtx, url, downloader.Options{Language: language})
+		if err != nil {
+			h.logger.Warn("Failed to fetch media info",
+				slog.String("url", url),
+				slog.Any("error", err),
+			)
+			h.editStatus(req, "❌ Не удалось получить сведения о видео: "+html.EscapeString(err.Error()))
+			return
+		}
+
+		h.editStatus(req, mediaInfoReport(info, h.sizeLimits.forChat(chatID, message.Chat.Type), h.locale(language)))
+	}()
+}
+
+// mediaInfoReport формирует ответ /info и предупреждает, если видео не пройдет по лимиту чата
+func mediaInfoReport(info downloader.MediaInfo, limit int64, loc locale) string {
+	var b strings.Builder
+	b.WriteString("ℹ️ <b>Сведения о видео</b>\n")
+
+	if info.Title != "" {
+		fmt.Fprintf(&b, "\n🎬 %s", html.EscapeString(info.Title))
+	}
+	if info.Uploader != "" {
+		fmt.Fprintf(&b, "\n👤 %s", html.EscapeString(info.Uploader))
+	}
+	if info.Platform != "" {
+		fmt.Fprintf(&b, "\n📺 %s", html.EscapeString(info.Platform))
+	}
+	if info.Duration > 0 {
+		fmt.Fprintf(&b, "\n⏱ %s", loc.duration(info.Duration))
+	}
+	if info.Width > 0 && info.Height > 0 {
+		fmt.Fprintf(&b, "\n🖥 %d×%d", info.Width, info.Height)
+	}
+
+	if info.Size <= 0 {
+		b.WriteString("\n📦 Размер неизвестен")
+		return b.String()
+	}
+
+	fmt.Fprintf(&b, "\n📦 ≈ %s", loc.size(info.Size))
+	if info.Size > limit {
+		fmt.Fprintf(&b, "\n\n⚠️ Больше лимита этого чата (%s) — видео не получится отправить.", loc.limit(limit))
+	}
+	return b.String()
+}
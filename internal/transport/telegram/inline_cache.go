@@ -0,0 +1,94 @@
+package telegram
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"sync"
+	"time"
+
+	"github.com/reelser-bot/internal/clock"
+	"github.com/reelser-bot/internal/services/resolver"
+)
+
+// inlineResultCache кратковременно хранит готовые inline-результаты по каноническому URL,
+// чтобы не собирать их заново для популярных ссылок
+type inlineResultCache struct {
+	ttl   time.Duration
+	clock clock.Clock
+	// key приводит URL к ключу кэша (канонический URL)
+	key func(url string) string
+
+	mu      sync.Mutex
+	entries map[string]inlineCacheEntry
+}
+
+type inlineCacheEntry struct {
+	results   []interface{}
+	expiresAt time.Time
+}
+
+func newInlineResultCache(ttl time.Duration, clk clock.Clock, key func(url string) string) *inlineResultCache {
+	return &inlineResultCache{
+		ttl:     ttl,
+		clock:   clk,
+		key:     key,
+		entries: make(map[string]inlineCacheEntry),
+	}
+}
+
+// get возвращает результаты для URL, если они еще не устарели
+func (c *inlineResultCache) get(url string) ([]interface{}, bool) {
+	if c.ttl <= 0 {
+		return nil, false
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	key := c.key(url)
+	entry, ok := c.entries[key]
+	if !ok {
+		return nil, false
+	}
+	if c.clock.Now().After(entry.expiresAt) {
+		delete(c.entries, key)
+		return nil, false
+	}
+	return entry.results, true
+}
+
+// put сохраняет результаты для URL и попутно удаляет устаревшие записи
+func (c *inlineResultCache) put(url string, results []interface{}) {
+	if c.ttl <= 0 {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := c.clock.Now()
+	for key, entry := range c.entries {
+		if now.After(entry.expiresAt) {
+			delete(c.entries, key)
+		}
+	}
+	c.entries[c.key(url)] = inlineCacheEntry{
+		results:   results,
+		expiresAt: now.Add(c.ttl),
+	}
+}
+
+// invalidate удаляет результаты для URL (например, когда появилось готовое видео)
+func (c *inlineResultCache) invalidate(url string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	delete(c.entries, c.key(url))
+}
+
+// inlineResultID возвращает стабильный ID inline-результата для URL, не зависящий от запроса,
+// чтобы Telegram мог переиспользовать закэшированные ответы
+func inlineResultID(kind, url string) string {
+	sum := sha1.Sum([]byte(resolver.Canonicalize(url)))
+	return kind + "-" + hex.EncodeToString(sum[:8])
+}
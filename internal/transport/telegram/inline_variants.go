@@ -0,0 +1,53 @@
+package telegram
+
+import (
+	"strings"
+
+	"github.com/reelser-bot/internal/services/downloader"
+)
+
+// inlineVariant описывает вариант загрузки, предлагаемый в inline-режиме
+type inlineVariant struct {
+	// kind префикс ID inline-результата, по которому вариант определяется в chosen_inline_result
+	kind        string
+	title       string
+	description string
+	label       string
+	quality     string
+	audioOnly   bool
+}
+
+// inlineVariants варианты загрузки в порядке показа в inline-панели
+var inlineVariants = []inlineVariant{
+	{
+		kind:        "hd",
+		title:       "🎬 Видео HD",
+		description: "Лучшее доступное качество",
+		label:       "видео HD",
+		quality:     downloader.QualityHD,
+	},
+	{
+		kind:        "sd",
+		title:       "📱 Видео SD",
+		description: "До 480p — быстрее и меньше по размеру",
+		label:       "видео SD",
+		quality:     downloader.QualitySD,
+	},
+	{
+		kind:        "audio",
+		title:       "🎵 Только аудио",
+		description: "Звуковая дорожка в MP3",
+		label:       "аудио",
+		audioOnly:   true,
+	},
+}
+
+// variantByResultID возвращает вариант по ID выбранного inline-результата
+func variantByResultID(resultID string) (inlineVariant, bool) {
+	for _, variant := range inlineVariants {
+		if strings.HasPrefix(resultID, variant.kind+"-") {
+			return variant, true
+		}
+	}
+	return inlineVariant{}, false
+}
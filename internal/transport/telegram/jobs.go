@@ -0,0 +1,170 @@
+package telegram
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/reelser-bot/internal/services/jobs"
+)
+
+// jobMaxStarts сколько раз задача может начаться заново после перезапуска. Задачу,
+// на которой бот падает (например, из-за нехватки памяти), дальше не продолжаем
+const jobMaxStarts = 3
+
+// saveJob сохраняет принятый запрос, чтобы продолжить его после перезапуска. runAt —
+// время запуска отложенного запроса (нулевое — запрос уже в очереди)
+func (h *Handler) saveJob(req *downloadRequest, runAt time.Time) {
+	h.jobs.Save(jobs.Job{
+		ID:       req.id,
+		ChatID:   req.chatID,
+		ChatType: req.chatType,
+		UserID:   req.userID,
+		UserName: req.userName,
+		URL:      req.url,
+		Source:   req.source,
+		Language: req.language,
+		Attempt:  req.attempt,
+
+		Quality:   req.quality,
+		Audio:     req.audioOnly,
+		Animation: req.animation,
+		VideoNote: req.videoNote,
+		Document:  req.document,
+		Chapters:  req.chapters,
+		NoCaption: req.noCaption,
+		Section:   req.section,
+
+		BusinessConnectionID: req.businessConnectionID,
+		PaidStars:            req.paidStars,
+		QuotaTaken:           req.quotaTaken,
+
+		StatusMessageID: req.statusMessageID,
+		OriginalMessage: req.originalMessage,
+		RunAt:           runAt,
+	})
+}
+
+// finishJob снимает запрос с учета после обработки. Запрос, прерванный остановкой
+// бота, остается сохраненным и будет продолжен после запуска
+func (h *Handler) finishJob(req *downloadRequest) {
+	if req.baseCtx.Err() != nil {
+		return
+	}
+	h.jobs.Remove(req.id)
+}
+
+// jobRequest восстанавливает запрос из сохраненной задачи
+func jobRequest(ctx context.Context, job jobs.Job) *downloadRequest {
+	return &downloadRequest{
+		requestSpec: requestSpec{
+			chatID:               job.ChatID,
+			chatType:             job.ChatType,
+			userID:               job.UserID,
+			userName:             job.UserName,
+			url:                  job.URL,
+			source:               job.Source,
+			language:             job.Language,
+			quality:              job.Quality,
+			audioOnly:            job.Audio,
+			animation:            job.Animation,
+			videoNote:            job.VideoNote,
+			document:             job.Document,
+			chapters:             job.Chapters,
+			noCaption:            job.NoCaption,
+			section:              job.Section,
+			businessConnectionID: job.BusinessConnectionID,
+			paidStars:            job.PaidStars,
+			quotaTaken:           job.QuotaTaken,
+			originalMessage:      job.OriginalMessage,
+		},
+
+		id:              job.ID,
+		baseCtx:         ctx,
+		attempt:         job.Attempt,
+		statusMessageID: job.StatusMessageID,
+		deliveredItems:  deliveredItems(job.Delivered),
+	}
+}
+
+// deliveredItems возвращает множество отправленных видео плейлиста (nil — таких нет)
+func deliveredItems(urls []string) map[string]struct{} {
+	if len(urls) == 0 {
+		return nil
+	}
+	items := make(map[string]struct{}, len(urls))
+	for _, u := range urls {
+		items[u] = struct{}{}
+	}
+	return items
+}
+
+// ResumeJobs возвращает в очередь запросы, принятые до перезапуска: ожидавшие в очереди,
+// отложенные и прерванные на середине загрузки. Запросы, файл которых уже скачан,
+// досылает ResumeDeliveries, поэтому здесь они пропускаются
+func (h *Handler) ResumeJobs(ctx context.Context) {
+	pending := h.jobs.Pending()
+	if len(pending) == 0 {
+		return
+	}
+
+	h.logger.Info("Resuming pending jobs", slog.Int("count", len(pending)))
+
+	for _, job := range pending {
+		if _, ok := h.deliveries.Get(job.ID); ok {
+			h.jobs.Remove(job.ID)
+			continue
+		}
+
+		req := jobRequest(ctx, job)
+		if job.Starts >= jobMaxStarts {
+			h.dropJob(req, job)
+			continue
+		}
+
+		if delay := time.Until(job.RunAt); delay > 0 {
+			h.scheduleDownload(req, delay)
+			h.logger.Info("Deferred job resumed",
+				slog.String("request_id", job.ID),
+				slog.Int64("chat_id", job.ChatID),
+				slog.Duration("delay", delay),
+			)
+			continue
+		}
+
+		req.ctx, req.cancel = context.WithCancel(ctx)
+		if !h.enqueueDownload(req) {
+			req.cancel()
+			h.handleQueueOverflow(req)
+			continue
+		}
+		h.logger.Info("Pending job resumed",
+			slog.String("request_id", job.ID),
+			slog.Int64("chat_id", job.ChatID),
+			slog.String("url", job.URL),
+			slog.Int("starts", job.Starts),
+		)
+	}
+}
+
+// dropJob снимает задачу, которую перезапуск бота прерывал jobMaxStarts раз,
+// и сообщает об этом пользователю
+func (h *Handler) dropJob(req *downloadRequest, job jobs.Job) {
+	h.jobs.Remove(job.ID)
+	h.logger.Warn("Dropping job interrupted too many times",
+		slog.String("request_id", job.ID),
+		slog.Int64("chat_id", job.ChatID),
+		slog.String("url", job.URL),
+		slog.Int("starts", job.Starts),
+	)
+
+	err := fmt.Errorf("processing was interrupted by a restart %d times", job.Starts)
+	h.recordFailure(req, err)
+	h.clearStatusMessage(req)
+	h.notify(req, fmt.Sprintf(
+		"❌ Не удалось обработать ссылку: загрузка несколько раз прерывалась перезапуском бота. "+
+			"Повтори запрос позже.\nКод запроса: <code>%s</code>",
+		job.ID,
+	))
+}
@@ -0,0 +1,319 @@
+package telegram
+
+import (
+	"context"
+	"log/slog"
+	"net/url"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/reelser-bot/internal/trace"
+)
+
+// Итог задачи в сводной записи
+const (
+	jobResultDelivered = "delivered"
+	jobResultPartial   = "partial"
+	jobResultFailed    = "failed"
+	jobResultTimeout   = "timeout"
+	jobResultCancelled = "cancelled"
+	jobResultRetry     = "retry"
+	// jobResultRejected видео не отправлено без ошибки загрузки: лимиты размера
+	// и длительности, исчерпанный бюджет трафика
+	jobResultRejected = "rejected"
+)
+
+// jobSummary накапливает итог одной задачи очереди для сводной записи (одна запись
+// на задачу). Видео плейлиста пишут в сводку родительской задачи
+type jobSummary struct {
+	counters *trace.Counters
+
+	mu        sync.Mutex
+	phases    map[requestPhase]time.Duration
+	delivered int
+	// downloadedBytes размер скачанных файлов, sentBytes — отправленных в Telegram
+	downloadedBytes int64
+	sentBytes       int64
+	failure         error
+	timedOut        bool
+	retried         bool
+}
+
+func newJobSummary() *jobSummary {
+	return &jobSummary{
+		counters: trace.NewCounters(),
+		phases:   make(map[requestPhase]time.Duration),
+	}
+}
+
+// Методы jobSummary допускают nil: у задач, восстановленных после перезапуска, сводки нет
+
+func (s *jobSummary) addPhase(phase requestPhase, d time.Duration) {
+	if s == nil || phase == phaseNone {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.phases[phase] += d
+}
+
+func (s *jobSummary) downloaded(size int64) {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.downloadedBytes += size
+}
+
+func (s *jobSummary) sent(size int64) {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.delivered++
+	s.sentBytes += size
+}
+
+func (s *jobSummary) fail(err error) {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.failure = err
+}
+
+func (s *jobSummary) timeout() {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.timedOut = true
+}
+
+func (s *jobSummary) retry() {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.retried = true
+}
+
+// settled сообщает, что задача отправила видео или запланировала повтор
+func (s *jobSummary) settled() bool {
+	if s == nil {
+		return false
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.delivered > 0 || s.retried
+}
+
+func (s *jobSummary) count(name string) {
+	if s == nil {
+		return
+	}
+	s.counters.Add(name)
+}
+
+// contextWith привязывает счетчики сводки к контексту этапа
+func (s *jobSummary) contextWith(ctx context.Context) context.Context {
+	if s == nil {
+		return ctx
+	}
+	return trace.WithCounters(ctx, s.counters)
+}
+
+// result определяет итог задачи
+func (s *jobSummary) result(cancelled bool) string {
+	switch {
+	case cancelled:
+		return jobResultCancelled
+	case s.delivered > 0 && (s.failure != nil || s.timedOut):
+		return jobResultPartial
+	case s.delivered > 0:
+		return jobResultDelivered
+	case s.timedOut:
+		return jobResultTimeout
+	case s.retried:
+		return jobResultRetry
+	case s.failure != nil:
+		return jobResultFailed
+	default:
+		return jobResultRejected
+	}
+}
+
+// jobLog журнал сводок: JSON-строки в отдельном файле (JOB_LOG_FILE) или основной лог
+type jobLog struct {
+	logger *slog.Logger
+	// file открытый JOB_LOG_FILE; nil — сводки пишутся в основной лог
+	file *os.File
+}
+
+// newJobLog создает журнал сводок. Если файл не задан или не открылся, сводки пишутся
+// в основной лог. В сводках ID пользователей и ссылки, поэтому файл доступен только
+// владельцу, в том числе созданный прежними версиями
+func newJobLog(logger *slog.Logger, path string) *jobLog {
+	if path == "" {
+		return &jobLog{logger: logger}
+	}
+
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o600)
+	if err == nil {
+		if err = file.Chmod(0o600); err != nil {
+			file.Close()
+		}
+	}
+	if err != nil {
+		logger.Warn("Failed to open job log file, writing job summaries to the main log",
+			slog.String("path", path),
+			slog.Any("error", err),
+		)
+		return &jobLog{logger: logger}
+	}
+	return &jobLog{logger: slog.New(slog.NewJSONHandler(file, nil)), file: file}
+}
+
+// close закрывает файл журнала при остановке бота
+func (l *jobLog) close() {
+	if l.file == nil {
+		return
+	}
+	if err := l.file.Close(); err != nil {
+		l.logger.Warn("Failed to close job log file", slog.Any("error", err))
+	}
+}
+
+// secretParams параметры запроса, которые несут авторизацию (подписанные ссылки CDN,
+// токены доступа) и не должны попадать в журнал сводок
+var secretParams = map[string]bool{
+	"token":        true,
+	"access_token": true,
+	"auth":         true,
+	"key":          true,
+	"api_key":      true,
+	"apikey":       true,
+	"sig":          true,
+	"signature":    true,
+	"password":     true,
+	"secret":       true,
+	"session":      true,
+}
+
+// secretParamPrefixes префиксы параметров подписей облачных хранилищ
+var secretParamPrefixes = []string{"x-amz-", "x-goog-"}
+
+// summaryURL возвращает ссылку для журнала сводок: канонический URL без трекинговых
+// параметров, учетных данных и параметров авторизации
+func (h *Handler) summaryURL(rawURL string) string {
+	u, err := url.Parse(h.downloader.CanonicalURL(rawURL))
+	if err != nil {
+		return ""
+	}
+	u.User = nil
+
+	query := u.Query()
+	for name := range query {
+		if isSecretParam(name) {
+			query.Del(name)
+		}
+	}
+	u.RawQuery = query.Encode()
+	u.Fragment = ""
+	return u.String()
+}
+
+// isSecretParam сообщает, что параметр запроса несет авторизацию
+func isSecretParam(name string) bool {
+	name = strings.ToLower(name)
+	if secretParams[name] {
+		return true
+	}
+	for _, prefix := range secretParamPrefixes {
+		if strings.HasPrefix(name, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// logJobSummary пишет сводную запись о завершенной задаче: длительности этапов, итог,
+// размеры и попадания в кэши. Подробный ход обработки остается в основном логе
+func (h *Handler) logJobSummary(req *downloadRequest) {
+	s := req.summary
+	if s == nil {
+		return
+	}
+	h.leavePhase(req)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	finished := time.Now()
+	result := s.result(req.cancelledBy.Load() != cancelNone)
+	platform := h.downloader.Platform(req.url)
+	h.health.record(platform, result, finished)
+
+	attrs := []slog.Attr{
+		slog.String("request_id", req.id),
+		slog.String("result", result),
+		slog.String("platform", platform),
+		slog.String("url", h.summaryURL(req.url)),
+		slog.String("source", req.source),
+		slog.String("mode", req.mode()),
+		slog.Int64("chat_id", req.chatID),
+		slog.String("chat_type", req.chatType),
+		slog.Int64("user_id", req.userID),
+		slog.Int("attempt", req.attempt),
+		slog.Int64("queue_ms", req.startedAt.Sub(req.enqueuedAt).Milliseconds()),
+		slog.Int64("total_ms", finished.Sub(req.startedAt).Milliseconds()),
+	}
+	for _, phase := range []requestPhase{phaseMetadata, phaseDownload, phaseTranscode, phaseUpload} {
+		attrs = append(attrs, slog.Int64(phase.String()+"_ms", s.phases[phase].Milliseconds()))
+	}
+	attrs = append(attrs,
+		slog.Int("delivered", s.delivered),
+		slog.Int64("downloaded_bytes", s.downloadedBytes),
+		slog.Int64("sent_bytes", s.sentBytes),
+	)
+	counters := s.counters.Snapshot()
+	names := make([]string, 0, len(counters))
+	for name := range counters {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		attrs = append(attrs, slog.Int(name, counters[name]))
+	}
+	if s.failure != nil {
+		attrs = append(attrs, slog.String("error", s.failure.Error()))
+	}
+
+	h.jobLog.logger.LogAttrs(context.Background(), slog.LevelInfo, "Job summary", attrs...)
+}
+
+// mode описывает, в каком виде запрошено медиа
+func (req *downloadRequest) mode() string {
+	switch {
+	case req.chapters:
+		return "chapters"
+	case req.audioOnly:
+		return "audio"
+	case req.animation:
+		return "animation"
+	case req.videoNote:
+		return "video_note"
+	case req.document:
+		return "document"
+	default:
+		return "video"
+	}
+}
@@ -0,0 +1,76 @@
+package telegram
+
+import (
+	"sync/atomic"
+
+	"github.com/reelser-bot/internal/config"
+)
+
+const (
+	// cloudAPIUploadLimit лимит загрузки файлов ботом через облачный Bot API
+	cloudAPIUploadLimit = int64(50 * 1024 * 1024)
+	// localAPIUploadLimit лимит загрузки через локальный Bot API сервер
+	localAPIUploadLimit = int64(2000 * 1024 * 1024)
+)
+
+// sizeLimits вычисляет допустимый размер видео для конкретного чата
+type sizeLimits struct {
+	apiLimit int64
+	// defaultMax общий лимит; меняется при первичной настройке бота
+	defaultMax atomic.Int64
+	byChatType map[string]int64
+	byChat     map[int64]int64
+}
+
+func newSizeLimits(cfg *config.Config) *sizeLimits {
+	apiLimit := cloudAPIUploadLimit
+	if cfg.Telegram.APIEndpoint != "" {
+		apiLimit = localAPIUploadLimit
+	}
+
+	limits := &sizeLimits{
+		apiLimit:   apiLimit,
+		byChatType: make(map[string]int64),
+		byChat:     make(map[int64]int64),
+	}
+	limits.defaultMax.Store(megabytes(cfg.Download.MaxVideoSizeMB))
+	// Нулевые значения означают "использовать общую настройку"
+	for chatType, mb := range cfg.Download.MaxVideoSizeByChatType {
+		if mb > 0 {
+			limits.byChatType[chatType] = megabytes(mb)
+		}
+	}
+	for chatID, mb := range cfg.Download.MaxVideoSizeByChat {
+		if mb > 0 {
+			limits.byChat[chatID] = megabytes(mb)
+		}
+	}
+
+	return limits
+}
+
+// forChat возвращает лимит в байтах: настройка чата, затем типа чата, затем общая,
+// но не больше лимита Bot API
+func (l *sizeLimits) forChat(chatID int64, chatType string) int64 {
+	limit := l.defaultMax.Load()
+	if v, ok := l.byChatType[chatType]; ok {
+		limit = v
+	}
+	if v, ok := l.byChat[chatID]; ok {
+		limit = v
+	}
+
+	if limit <= 0 || limit > l.apiLimit {
+		return l.apiLimit
+	}
+	return limit
+}
+
+// setDefault заменяет общий лимит; 0 — использовать лимит Bot API
+func (l *sizeLimits) setDefault(mb int) {
+	l.defaultMax.Store(megabytes(mb))
+}
+
+func megabytes(mb int) int64 {
+	return int64(mb) * 1024 * 1024
+}
@@ -0,0 +1,107 @@
+package telegram
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/reelser-bot/internal/platform"
+)
+
+// Пределы высоты кадра в модификаторе качества ("720p")
+const (
+	minModifierHeight = 144
+	maxModifierHeight = 4320
+)
+
+// modifierModes модификаторы, выбирающие вид отправки (как команды /audio, /gif, /circle, /file)
+var modifierModes = map[string]linkMode{
+	"audio":  linkAudio,
+	"mp3":    linkAudio,
+	"gif":    linkAnimation,
+	"circle": linkVideoNote,
+	"file":   linkDocument,
+}
+
+// requestModifiers параметры запроса, заданные словами после ссылки:
+// "ссылка 720p", "ссылка audio", "ссылка nocaption"
+type requestModifiers struct {
+	// quality вариант качества; пусто — по настройке
+	quality string
+	// mode вид отправки; linkVideo — как без модификатора
+	mode linkMode
+	// noCaption отправить видео без подписи
+	noCaption bool
+}
+
+// extractModifiers разбирает модификаторы запроса — слова после ссылки url. Текст до
+// ссылки не учитывается, а незнакомые слова пропускаются: в сообщении со ссылкой может
+// быть и обычный текст
+func extractModifiers(text, url string) (requestModifiers, error) {
+	var mods requestModifiers
+	var qualityWord, modeWord string
+
+	for _, word := range wordsAfterLink(text, url) {
+		word = strings.ToLower(strings.TrimRight(word, ".,;!?"))
+
+		quality, isQuality, err := parseQualityModifier(word)
+		if err != nil {
+			return mods, err
+		}
+		mode, isMode := modifierModes[word]
+
+		switch {
+		case isQuality:
+			if qualityWord != "" && quality != mods.quality {
+				return mods, fmt.Errorf("указано два качества: %s и %s", qualityWord, word)
+			}
+			mods.quality, qualityWord = quality, word
+		case isMode:
+			if modeWord != "" && mode != mods.mode {
+				return mods, fmt.Errorf("%s и %s нельзя указать вместе", modeWord, word)
+			}
+			mods.mode, modeWord = mode, word
+		case word == "nocaption":
+			mods.noCaption = true
+		}
+	}
+
+	if mods.mode == linkAudio && qualityWord != "" {
+		return mods, fmt.Errorf("%s не сочетается с %s", qualityWord, modeWord)
+	}
+	return mods, nil
+}
+
+// wordsAfterLink возвращает слова текста после слова со ссылкой. Если ссылки в тексте
+// нет (она скрыта в text_link), слов после нее тоже нет
+func wordsAfterLink(text, url string) []string {
+	bare := strings.TrimPrefix(strings.TrimPrefix(url, "https://"), "http://")
+	words := strings.Fields(text)
+	for i, word := range words {
+		if strings.Contains(word, "://") || (bare != "" && strings.Contains(word, bare)) {
+			return words[i+1:]
+		}
+	}
+	return nil
+}
+
+// parseQualityModifier распознает модификатор качества: hd, sd или высоту кадра "720p"
+func parseQualityModifier(word string) (quality string, ok bool, err error) {
+	switch word {
+	case platform.QualityHD, platform.QualitySD:
+		return word, true, nil
+	}
+
+	digits, found := strings.CutSuffix(word, "p")
+	if !found || digits == "" || digits[0] < '0' || digits[0] > '9' {
+		return "", false, nil
+	}
+	height, convErr := strconv.Atoi(digits)
+	if convErr != nil {
+		return "", false, nil
+	}
+	if height < minModifierHeight || height > maxModifierHeight {
+		return "", false, fmt.Errorf("качество %s вне диапазона %dp–%dp", word, minModifierHeight, maxModifierHeight)
+	}
+	return platform.HeightQuality(height), true, nil
+}
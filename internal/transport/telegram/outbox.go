@@ -0,0 +1,102 @@
+package telegram
+
+import (
+	"context"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+
+	"github.com/reelser-bot/internal/services/delivery"
+)
+
+// Эффекты доставки: применяются по записи outbox (delivery.Task) один раз, даже
+// если бот перезапустился между отправкой файла и их применением
+const (
+	effectFileID  = "file_id"
+	effectReceipt = "receipt"
+	effectArchive = "archive"
+	effectStats   = "stats"
+)
+
+// completeDelivery сохраняет результат отправки в outbox и применяет эффекты доставки.
+// Пока результат не сохранен, задача после перезапуска отправляется заново; после —
+// только применяются непримененные эффекты
+func (h *Handler) completeDelivery(req *downloadRequest, sent *tgbotapi.Message) {
+	record := delivery.Sent{At: time.Now()}
+	if sent != nil {
+		record.MessageID = sent.MessageID
+		if sent.Video != nil {
+			record.FileID = sent.Video.FileID
+		}
+	}
+	h.deliveries.Complete(req.id, record)
+	h.applyDeliveryEffects(req, sent)
+}
+
+// applyDeliveryEffects применяет непримененные эффекты доставки и удаляет задачу из outbox
+func (h *Handler) applyDeliveryEffects(req *downloadRequest, sent *tgbotapi.Message) {
+	task, _ := h.deliveries.Get(req.id)
+
+	effects := []struct {
+		name  string
+		apply func()
+	}{
+		{effectFileID, func() { h.rememberSentVideo(req, sent) }},
+		{effectReceipt, func() {
+			if req.chatType == "channel" && sent != nil {
+				h.recordReceipt(req, req.chatID, sent.MessageID)
+			}
+		}},
+		{effectArchive, func() { h.archiveToChannel(req, sent) }},
+		{effectStats, func() { h.recordDownload(req) }},
+	}
+	for _, effect := range effects {
+		if task.IsApplied(effect.name) {
+			continue
+		}
+		effect.apply()
+		h.deliveries.MarkApplied(req.id, effect.name)
+	}
+
+	h.deliveries.Remove(req.id)
+}
+
+// deliveryRequest восстанавливает запрос из задачи outbox для возобновления отправки
+func deliveryRequest(task delivery.Task) *downloadRequest {
+	ctx := context.Background()
+	return &downloadRequest{
+		requestSpec: requestSpec{
+			chatID:               task.ChatID,
+			chatType:             task.ChatType,
+			userID:               task.UserID,
+			userName:             task.UserName,
+			url:                  task.URL,
+			quality:              task.Quality,
+			audioOnly:            task.Audio,
+			animation:            task.Animation,
+			videoNote:            task.VideoNote,
+			document:             task.Document,
+			source:               task.Source,
+			businessConnectionID: task.BusinessConnectionID,
+			paidStars:            task.PaidStars,
+		},
+
+		id:         task.ID,
+		baseCtx:    ctx,
+		ctx:        ctx,
+		compressed: task.Compressed,
+	}
+}
+
+// sentMessage восстанавливает отправленное сообщение по результату из outbox
+func sentMessage(task delivery.Task) *tgbotapi.Message {
+	if task.Sent == nil || task.Sent.MessageID == 0 {
+		return nil
+	}
+
+	sent := &tgbotapi.Message{MessageID: task.Sent.MessageID, Chat: &tgbotapi.Chat{ID: task.ChatID}}
+	if task.Sent.FileID != "" {
+		sent.Video = &tgbotapi.Video{FileID: task.Sent.FileID}
+	}
+	return sent
+}
@@ -0,0 +1,120 @@
+package telegram
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// handleChannelPost обрабатывает ссылки, опубликованные в каналах с настроенной
+// ценой платного медиа: видео публикуется в канал как платный пост (Telegram Stars)
+func (h *Handler) handleChannelPost(ctx context.Context, post *tgbotapi.Message) {
+	if post == nil || post.Chat == nil || !h.chatTypeAllowed(post.Chat.Type) {
+		return
+	}
+
+	stars, ok := h.paidMediaPrices[post.Chat.ID]
+	if !ok {
+		return
+	}
+
+	url := h.messageURL(post, post.Text)
+	if url == "" {
+		return
+	}
+
+	h.logger.Info("Received channel post for paid media",
+		slog.Int64("chat_id", post.Chat.ID),
+		slog.String("url", url),
+		slog.Int("stars", stars),
+	)
+
+	spec := requestSpec{
+		chatID:          post.Chat.ID,
+		chatType:        "channel",
+		url:             url,
+		source:          "channel_paid",
+		originalMessage: post.MessageID,
+		paidStars:       stars,
+	}
+	if !h.checkPolicy(ctx, spec) {
+		return
+	}
+
+	requestCtx, cancel := context.WithCancel(ctx)
+	req := &downloadRequest{
+		requestSpec: spec,
+
+		id:      newRequestID(),
+		baseCtx: ctx,
+		ctx:     requestCtx,
+		cancel:  cancel,
+	}
+
+	if !h.enqueueDownload(req) {
+		cancel()
+		h.logger.Warn("Dropping paid media request because of queue overflow",
+			slog.Int64("chat_id", post.Chat.ID),
+			slog.String("url", url),
+		)
+	}
+}
+
+// sendPaidVideo публикует видео как платное медиа (sendPaidMedia) и возвращает пост
+func (h *Handler) sendPaidVideo(chatID int64, filePath string, stars int, maxAllowed int64) (*tgbotapi.Message, error) {
+	file, err := os.Open(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open file: %w", err)
+	}
+	defer file.Close()
+
+	fileInfo, err := file.Stat()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get file info: %w", err)
+	}
+	if fileInfo.Size() > maxAllowed {
+		return nil, fmt.Errorf("file size %d exceeds maximum allowed size %d", fileInfo.Size(), maxAllowed)
+	}
+
+	media, err := json.Marshal([]map[string]any{{
+		"type":               "video",
+		"media":              "attach://video",
+		"supports_streaming": true,
+	}})
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode paid media: %w", err)
+	}
+
+	params := tgbotapi.Params{}
+	params.AddNonZero64("chat_id", chatID)
+	params.AddNonZero("star_count", stars)
+	params.AddNonEmpty("media", string(media))
+
+	h.logger.Info("Sending paid video",
+		slog.Int64("chat_id", chatID),
+		slog.String("file", filePath),
+		slog.Int("stars", stars),
+	)
+
+	var sent tgbotapi.Message
+	err = h.retryUpload(file, func(r io.Reader) error {
+		files := []tgbotapi.RequestFile{{
+			Name: "video",
+			Data: tgbotapi.FileReader{Name: fileInfo.Name(), Reader: r},
+		}}
+		resp, err := h.bot.UploadFiles("sendPaidMedia", params, files)
+		if err != nil {
+			return err
+		}
+		return json.Unmarshal(resp.Result, &sent)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to send paid media: %w", err)
+	}
+	return &sent, nil
+}
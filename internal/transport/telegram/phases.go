@@ -0,0 +1,145 @@
+package telegram
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+)
+
+// requestPhase этап обработки запроса. У каждого этапа свой бюджет времени, поэтому
+// быстрая загрузка не прерывается из-за долгой обработки и наоборот
+type requestPhase int32
+
+const (
+	phaseNone requestPhase = iota
+	// phaseMetadata получение сведений о видео или списка видео плейлиста
+	phaseMetadata
+	// phaseDownload загрузка видео (бюджет адаптивный, см. downloader.Timeout)
+	phaseDownload
+	// phaseTranscode постобработка: хуки, GIF, видеосообщения, сжатие
+	phaseTranscode
+	// phaseUpload отправка в Telegram, включая ожидание медленного режима и повторы
+	phaseUpload
+)
+
+func (p requestPhase) String() string {
+	switch p {
+	case phaseMetadata:
+		return "metadata"
+	case phaseDownload:
+		return "download"
+	case phaseTranscode:
+		return "transcode"
+	case phaseUpload:
+		return "upload"
+	default:
+		return "none"
+	}
+}
+
+// phaseTimeouts бюджеты времени этапов (кроме загрузки); 0 — без ограничения
+type phaseTimeouts struct {
+	metadata  time.Duration
+	transcode time.Duration
+	upload    time.Duration
+}
+
+// phaseBudget возвращает бюджет этапа для запроса
+func (h *Handler) phaseBudget(req *downloadRequest, phase requestPhase) time.Duration {
+	switch phase {
+	case phaseMetadata:
+		return h.phaseTimeouts.metadata
+	case phaseDownload:
+		return h.downloader.Timeout(req.url)
+	case phaseTranscode:
+		return h.phaseTimeouts.transcode
+	case phaseUpload:
+		return h.phaseTimeouts.upload
+	default:
+		return 0
+	}
+}
+
+// enterPhase начинает этап запроса: контекст предыдущего этапа отменяется, новый
+// получает бюджет этапа и отменяется вместе с запросом
+func (h *Handler) enterPhase(req *downloadRequest, phase requestPhase) context.Context {
+	h.leavePhase(req)
+
+	var ctx context.Context
+	var cancel context.CancelFunc
+	if budget := h.phaseBudget(req, phase); budget > 0 {
+		ctx, cancel = context.WithTimeout(req.ctx, budget)
+	} else {
+		ctx, cancel = context.WithCancel(req.ctx)
+	}
+	req.phase.Store(int32(phase))
+	req.phaseCtx, req.phaseCancel = req.summary.contextWith(ctx), cancel
+	req.phaseStarted = time.Now()
+	return req.phaseCtx
+}
+
+// leavePhase завершает текущий этап: отменяет его контекст и учитывает длительность
+// в сводке задачи
+func (h *Handler) leavePhase(req *downloadRequest) {
+	if req.phaseCancel == nil {
+		return
+	}
+	req.phaseCancel()
+	req.phaseCancel = nil
+	req.summary.addPhase(requestPhase(req.phase.Load()), time.Since(req.phaseStarted))
+}
+
+// phaseContext возвращает контекст текущего этапа (или запроса, если этап не начат)
+func (req *downloadRequest) phaseContext() context.Context {
+	if req.phaseCtx != nil {
+		return req.phaseCtx
+	}
+	return req.ctx
+}
+
+// notifyPhaseTimeout сообщает пользователю, на каком этапе истекло время, если ошибка
+// вызвана бюджетом текущего этапа (а не отменой запроса). Возвращает true, если сообщено
+func (h *Handler) notifyPhaseTimeout(req *downloadRequest, err error, summary string) bool {
+	phase := requestPhase(req.phase.Load())
+	if phase == phaseNone || req.ctx.Err() != nil {
+		return false
+	}
+
+	expired := errors.Is(req.phaseContext().Err(), context.DeadlineExceeded)
+	// Отдельные попытки отправки ограничены таймаутом TELEGRAM_UPLOAD_TIMEOUT
+	if phase == phaseUpload && errors.Is(err, context.DeadlineExceeded) {
+		expired = true
+	}
+	if !expired {
+		return false
+	}
+
+	req.summary.timeout()
+	budget := h.phaseBudget(req, phase)
+	h.logger.Warn("Request phase timed out",
+		slog.String("request_id", req.id),
+		slog.String("phase", phase.String()),
+		slog.Duration("budget", budget),
+		slog.String("url", req.url),
+	)
+
+	var text string
+	switch phase {
+	case phaseMetadata:
+		text = "⌛ Платформа слишком долго не отдавала сведения о видео%s. Попробуй позже."
+	case phaseDownload:
+		text = "⌛ Видео скачивалось слишком долго%s, загрузка прервана. Попробуй позже или выбери качество пониже."
+	case phaseTranscode:
+		text = "⌛ Обработка видео заняла слишком много времени%s и была прервана. Попробуй фрагмент покороче."
+	default:
+		text = "⌛ Отправка видео в Telegram заняла слишком много времени%s и была прервана. Попробуй позже."
+	}
+	var limit string
+	if budget > 0 {
+		limit = " (лимит " + h.locale(req.language).duration(budget) + ")"
+	}
+	h.notify(req, fmt.Sprintf(text, limit)+summary+fmt.Sprintf("\nКод запроса: <code>%s</code>", req.id))
+	return true
+}
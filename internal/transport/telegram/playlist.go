@@ -0,0 +1,198 @@
+package telegram
+
+import (
+	"context"
+	"fmt"
+	"html"
+	"log/slog"
+	"strings"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+
+	"github.com/reelser-bot/internal/platform"
+	"github.com/reelser-bot/internal/services/downloader"
+)
+
+// skippedItem видео плейлиста, не отправленное из-за лимита размера
+type skippedItem struct {
+	title string
+	size  int64
+}
+
+// processPlaylist скачивает и отправляет по очереди первые видео плейлиста, обновляя
+// статусное сообщение. Возвращает false, если ссылка не на плейлист
+func (h *Handler) processPlaylist(req *downloadRequest) bool {
+	if req.playlistItem {
+		return false
+	}
+
+	playlist, ok, err := h.downloader.Playlist(h.enterPhase(req, phaseMetadata), req.url, downloader.Options{Language: req.language})
+	if !ok {
+		return false
+	}
+	if err != nil && req.cancelledBy.Load() != cancelNone {
+		h.clearStatusMessage(req)
+		return true
+	}
+	if err != nil {
+		h.logger.Error("Failed to expand playlist", slog.String("url", req.url), slog.Any("error", err))
+		h.clearStatusMessage(req)
+		h.recordFailure(req, err)
+		if h.notifyPhaseTimeout(req, err, "") {
+			return true
+		}
+		h.notify(req, fmt.Sprintf(
+			"❌ Не удалось получить список видео плейлиста: %s\nКод запроса: <code>%s</code>",
+			html.EscapeString(err.Error()), req.id,
+		))
+		return true
+	}
+	// Видео плейлиста учитывают свои этапы сами
+	h.leavePhase(req)
+
+	title := playlist.Title
+	if title == "" {
+		title = "без названия"
+	}
+
+	var delivered int
+	var skipped []skippedItem
+	var overBudget bool
+	for i, item := range playlist.Items {
+		if req.cancelledBy.Load() != cancelNone {
+			break
+		}
+		// Видео, отправленные до перезапуска бота, не загружаются повторно
+		if _, ok := req.deliveredItems[item.URL]; ok {
+			delivered++
+			continue
+		}
+		if !h.bandwidth.Allow() {
+			overBudget = true
+			break
+		}
+
+		h.editStatus(req, fmt.Sprintf("📃 Плейлист «%s»: %d из %d\n⏳ Загружаю «%s»...",
+			html.EscapeString(title), i+1, len(playlist.Items), html.EscapeString(itemTitle(item)),
+		))
+
+		result, size := h.processPlaylistItem(req, item)
+		switch result {
+		case itemDelivered:
+			delivered++
+			h.jobs.MarkDelivered(req.id, item.URL)
+		case itemTooBig:
+			skipped = append(skipped, skippedItem{title: itemTitle(item), size: size})
+		}
+	}
+
+	h.clearStatusMessage(req)
+
+	if by := req.cancelledBy.Load(); by != cancelNone {
+		if by == cancelByAdmin {
+			h.notify(req, "🚫 Загрузка плейлиста отменена администратором.")
+		}
+		return true
+	}
+
+	h.logger.Info("Playlist processed",
+		slog.String("url", req.url),
+		slog.Int("items", len(playlist.Items)),
+		slog.Int("delivered", delivered),
+		slog.Int("skipped", len(skipped)),
+	)
+
+	report := playlistReport(title, len(playlist.Items), delivered, skipped,
+		h.sizeLimits.forChat(req.chatID, req.chatType), h.locale(req.language))
+	if overBudget {
+		report += "\n\n⛔ Остальные видео не загружены: исчерпан суточный лимит трафика бота."
+	}
+	h.report(req, report)
+	if delivered > 0 {
+		h.deleteOriginalMessage(req)
+	}
+	return true
+}
+
+// processPlaylistItem скачивает и отправляет одно видео плейлиста. Отмена запроса
+// плейлиста администратором или владельцем прерывает и текущее видео
+func (h *Handler) processPlaylistItem(parent *downloadRequest, item platform.PlaylistItem) (itemResult, int64) {
+	ctx, cancel := context.WithCancel(parent.baseCtx)
+	defer cancel()
+
+	req := &downloadRequest{
+		requestSpec: requestSpec{
+			chatID:               parent.chatID,
+			chatType:             parent.chatType,
+			userID:               parent.userID,
+			userName:             parent.userName,
+			url:                  item.URL,
+			quality:              parent.quality,
+			audioOnly:            parent.audioOnly,
+			animation:            parent.animation,
+			videoNote:            parent.videoNote,
+			document:             parent.document,
+			language:             parent.language,
+			source:               parent.source,
+			businessConnectionID: parent.businessConnectionID,
+			paidStars:            parent.paidStars,
+		},
+
+		id:           newRequestID(),
+		baseCtx:      parent.baseCtx,
+		ctx:          ctx,
+		cancel:       cancel,
+		playlistItem: true,
+		summary:      parent.summary,
+	}
+
+	// Об отмене сообщает processPlaylist, поэтому видео прерывается без уведомления
+	stop := context.AfterFunc(parent.ctx, func() {
+		if parent.cancelledBy.Load() != cancelNone {
+			req.cancelledBy.Store(cancelByOwner)
+			cancel()
+		}
+	})
+	defer stop()
+	defer h.leavePhase(req)
+
+	return h.downloadAndSend(req)
+}
+
+// editStatus заменяет текст статусного сообщения запроса
+func (h *Handler) editStatus(req *downloadRequest, text string) {
+	if req.statusMessageID == 0 {
+		return
+	}
+
+	edit := tgbotapi.NewEditMessageText(req.chatID, req.statusMessageID, text)
+	edit.ParseMode = "HTML"
+	if _, err := h.bot.Request(edit); err != nil && !strings.Contains(err.Error(), "message is not modified") {
+		h.logger.Warn("Failed to update status message",
+			slog.Int64("chat_id", req.chatID),
+			slog.Any("error", err),
+		)
+	}
+}
+
+// playlistReport формирует итоговое сообщение о загрузке плейлиста
+func playlistReport(title string, total, delivered int, skipped []skippedItem, limit int64, loc locale) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "📃 Плейлист «%s»: отправлено %d из %d.", html.EscapeString(title), delivered, total)
+
+	if len(skipped) > 0 {
+		fmt.Fprintf(&b, "\n\n⚠️ Пропущены из-за лимита %s:", loc.limit(limit))
+		for _, item := range skipped {
+			fmt.Fprintf(&b, "\n• %s (%s)", html.EscapeString(item.title), loc.size(item.size))
+		}
+	}
+	return b.String()
+}
+
+// itemTitle возвращает название видео плейлиста или его ссылку
+func itemTitle(item platform.PlaylistItem) string {
+	if item.Title != "" {
+		return item.Title
+	}
+	return item.URL
+}
@@ -0,0 +1,92 @@
+package telegram
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// Обновление места в очереди в статусах ожидающих запросов
+const (
+	// queuePositionInterval как часто пересчитываются места
+	queuePositionInterval = 5 * time.Second
+	// queuePositionMinEdit как часто можно менять статус одного запроса. Вместе с одной
+	// правкой на чат за проход это держит бота в пределах лимитов Telegram для групп
+	queuePositionMinEdit = 15 * time.Second
+)
+
+// watchQueuePositions показывает в статусах ожидающих запросов их место в очереди
+// и обновляет его по мере продвижения очереди, пока ctx не отменен
+func (h *Handler) watchQueuePositions(ctx context.Context) {
+	ticker := time.NewTicker(queuePositionInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			h.refreshQueuePositions()
+		}
+	}
+}
+
+// refreshQueuePositions обновляет статусы, место в которых изменилось. За проход
+// в каждом чате правится не больше одного статуса — самого раннего запроса
+func (h *Handler) refreshQueuePositions() {
+	edited := make(map[int64]bool)
+	for _, pos := range h.queue.positions() {
+		req := pos.Request
+		// Запрос, который ждет меньше интервала, скорее всего сейчас заберет воркер
+		if edited[req.chatID] || time.Since(req.enqueuedAt) < queuePositionInterval {
+			continue
+		}
+		if h.showQueuePosition(req, pos) {
+			edited[req.chatID] = true
+		}
+	}
+}
+
+// showQueuePosition выводит место запроса в его статусе. Возвращает false, если статус
+// не изменился
+func (h *Handler) showQueuePosition(req *downloadRequest, pos jobPosition) bool {
+	req.statusMu.Lock()
+	defer req.statusMu.Unlock()
+
+	if req.queueLeft || req.statusMessageID == 0 || req.businessConnectionID != "" {
+		return false
+	}
+	if req.queueShown.Place == pos.Place && req.queueShown.Held == pos.Held {
+		return false
+	}
+	if time.Since(req.queueEditedAt) < queuePositionMinEdit {
+		return false
+	}
+
+	h.editStatus(req, h.queuePositionText(pos))
+	req.queueShown = pos
+	req.queueEditedAt = time.Now()
+	return true
+}
+
+// leaveQueue отмечает, что запрос забрал воркер. Если в статусе показывалось место
+// в очереди, оно заменяется сообщением о начале загрузки
+func (h *Handler) leaveQueue(req *downloadRequest) {
+	req.statusMu.Lock()
+	defer req.statusMu.Unlock()
+
+	req.queueLeft = true
+	if req.queueShown.Place > 0 {
+		h.editStatus(req, "⏳ Очередь подошла, начинаю загрузку...")
+	}
+}
+
+// queuePositionText формирует статус ожидающего запроса
+func (h *Handler) queuePositionText(pos jobPosition) string {
+	text := fmt.Sprintf("⏳ Ты #%d в очереди. Начну загрузку, как только освободится место.", pos.Place)
+	if pos.Held {
+		text += fmt.Sprintf("\n⏸ Одновременно обрабатывается не больше %d твоих загрузок: "+
+			"эта начнется, когда завершится одна из них.", h.queue.perUser)
+	}
+	return text
+}
@@ -0,0 +1,142 @@
+package telegram
+
+import (
+	"fmt"
+	"html"
+	"log/slog"
+	"strings"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+
+	"github.com/reelser-bot/internal/services/history"
+)
+
+// recentPostsLimit количество публикаций в /post без аргументов
+const recentPostsLimit = 10
+
+// recordReceipt сохраняет публикацию видео в канале, чтобы ее можно было удалить
+// или отредактировать через /post
+func (h *Handler) recordReceipt(req *downloadRequest, chatID int64, messageID int) {
+	h.receipts.Record(history.Receipt{
+		ID:          req.id,
+		ChatID:      chatID,
+		MessageID:   messageID,
+		URL:         req.url,
+		Source:      req.source,
+		DeliveredAt: time.Now(),
+	})
+
+	h.logger.Info("Channel post recorded",
+		slog.String("request_id", req.id),
+		slog.Int64("chat_id", chatID),
+		slog.Int("message_id", messageID),
+	)
+}
+
+// handlePostCommand управляет опубликованными в каналах видео:
+// /post — последние публикации, /post <код> — публикации запроса,
+// /post <код> delete — удалить, /post <код> edit <подпись> — изменить подпись
+func (h *Handler) handlePostCommand(message *tgbotapi.Message) {
+	chatID := message.Chat.ID
+
+	args := strings.Fields(message.CommandArguments())
+	if len(args) == 0 {
+		h.sendMessage(chatID, recentPostsReport(h.receipts.Recent(recentPostsLimit)))
+		return
+	}
+
+	requestID := args[0]
+	receipts := h.receipts.Get(requestID)
+	if len(receipts) == 0 {
+		h.sendMessage(chatID, "❌ Публикации по этому коду запроса не найдены.")
+		return
+	}
+
+	if len(args) == 1 {
+		var b strings.Builder
+		fmt.Fprintf(&b, "📬 Публикации запроса <code>%s</code>:\n", html.EscapeString(requestID))
+		for _, receipt := range receipts {
+			b.WriteString("\n" + receiptLine(receipt))
+		}
+		h.sendMessage(chatID, b.String())
+		return
+	}
+
+	switch args[1] {
+	case "delete":
+		h.deletePosts(chatID, receipts)
+	case "edit":
+		_, caption, _ := strings.Cut(message.CommandArguments(), "edit")
+		h.editPosts(chatID, receipts, strings.TrimSpace(caption))
+	default:
+		h.sendMessage(chatID, "Использование:\n"+
+			"/post — последние публикации в каналах\n"+
+			"/post &lt;код&gt; — публикации запроса\n"+
+			"/post &lt;код&gt; delete — удалить пост\n"+
+			"/post &lt;код&gt; edit &lt;подпись&gt; — изменить подпись (пустая — убрать)")
+	}
+}
+
+// deletePosts удаляет опубликованные посты и записи о них
+func (h *Handler) deletePosts(chatID int64, receipts []history.Receipt) {
+	deleted := 0
+	for _, receipt := range receipts {
+		if _, err := h.bot.Request(tgbotapi.NewDeleteMessage(receipt.ChatID, receipt.MessageID)); err != nil {
+			h.logger.Warn("Failed to delete channel post",
+				slog.String("request_id", receipt.ID),
+				slog.Int64("chat_id", receipt.ChatID),
+				slog.Int("message_id", receipt.MessageID),
+				slog.Any("error", err),
+			)
+			continue
+		}
+		h.receipts.Remove(receipt.ChatID, receipt.MessageID)
+		deleted++
+	}
+
+	h.sendMessage(chatID, fmt.Sprintf("🗑 Удалено постов: %d из %d.", deleted, len(receipts)))
+}
+
+// editPosts меняет подпись у опубликованных постов
+func (h *Handler) editPosts(chatID int64, receipts []history.Receipt, caption string) {
+	edited := 0
+	for _, receipt := range receipts {
+		if _, err := h.bot.Request(tgbotapi.NewEditMessageCaption(receipt.ChatID, receipt.MessageID, caption)); err != nil {
+			h.logger.Warn("Failed to edit channel post",
+				slog.String("request_id", receipt.ID),
+				slog.Int64("chat_id", receipt.ChatID),
+				slog.Int("message_id", receipt.MessageID),
+				slog.Any("error", err),
+			)
+			continue
+		}
+		edited++
+	}
+
+	h.sendMessage(chatID, fmt.Sprintf("✏️ Подпись изменена у постов: %d из %d.", edited, len(receipts)))
+}
+
+// recentPostsReport формирует список последних публикаций для /post
+func recentPostsReport(receipts []history.Receipt) string {
+	if len(receipts) == 0 {
+		return "📭 Бот ещё ничего не публиковал в каналах."
+	}
+
+	var b strings.Builder
+	b.WriteString("📬 <b>Последние публикации в каналах</b>\n")
+	for _, receipt := range receipts {
+		fmt.Fprintf(&b, "\n<code>%s</code> %s", receipt.ID, receiptLine(receipt))
+	}
+	return b.String()
+}
+
+// receiptLine описывает одну публикацию: канал, сообщение, возраст и ссылку
+func receiptLine(receipt history.Receipt) string {
+	return fmt.Sprintf("канал %d, сообщение %d, %s назад\n%s",
+		receipt.ChatID,
+		receipt.MessageID,
+		formatAge(time.Since(receipt.DeliveredAt)),
+		html.EscapeString(receipt.URL),
+	)
+}
@@ -0,0 +1,88 @@
+package telegram
+
+import (
+	"log/slog"
+	"strings"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+
+	"github.com/reelser-bot/internal/services/pressure"
+)
+
+// pressureReasonTexts описания превышенных порогов для пользователей
+var pressureReasonTexts = map[string]string{
+	pressure.ReasonDisk:   "мало места на диске",
+	pressure.ReasonLoad:   "высокая нагрузка на процессор",
+	pressure.ReasonMemory: "мало свободной памяти",
+}
+
+// watchPressure подключает сброс нагрузки: под давлением воркеров становится меньше,
+// а запросы с низким приоритетом отклоняются; после спада все восстанавливается
+func (h *Handler) watchPressure(monitor *pressure.Monitor, workers int) {
+	if workers <= 0 {
+		workers = max(h.workerCount/2, 1)
+	}
+	workers = min(workers, h.workerCount)
+
+	h.pressure = monitor
+	monitor.OnChange(func(active bool) {
+		if active {
+			h.scaler.setPressure(workers)
+			h.logger.Warn("Download concurrency reduced",
+				slog.Int("workers", workers),
+				slog.Int("pool_size", h.workerCount),
+			)
+			return
+		}
+		h.scaler.setPressure(0)
+		h.logger.Info("Download concurrency restored", slog.Int("workers", h.scaler.current()))
+	})
+}
+
+// shedLoad отклоняет запрос с низким приоритетом, пока система под давлением, и честно
+// сообщает причину. Низкий приоритет — запросы не администраторов в группах и каналах
+// и деление на главы. Возвращает true, если запрос отклонен
+func (h *Handler) shedLoad(message *tgbotapi.Message, chapters bool) bool {
+	if !h.pressure.Active() || h.isAdmin(message) {
+		return false
+	}
+	if message.Chat.Type == "private" && !chapters {
+		return false
+	}
+
+	var reasons []string
+	for _, reason := range h.pressure.Reasons() {
+		reasons = append(reasons, pressureReasonTexts[reason])
+	}
+
+	h.logger.Warn("Request shed under system pressure",
+		slog.Int64("chat_id", message.Chat.ID),
+		slog.String("chat_type", message.Chat.Type),
+		slog.Bool("chapters", chapters),
+	)
+
+	text := "🔥 Сервер бота сейчас перегружен"
+	if len(reasons) > 0 {
+		text += " (" + strings.Join(reasons, ", ") + ")"
+	}
+	text += ", поэтому бот временно обрабатывает только обычные запросы в личных сообщениях. " +
+		"Попробуй позже — ограничение снимется автоматически, когда нагрузка спадет."
+	h.sendServiceMessage(message.Chat.ID, text)
+	return true
+}
+
+// pressureStatus строка для /stats; пустая, если сброс нагрузки выключен
+func (h *Handler) pressureStatus() string {
+	if h.pressure == nil {
+		return ""
+	}
+	if !h.pressure.Active() {
+		return "\n🌡 Нагрузка: в норме"
+	}
+
+	var reasons []string
+	for _, reason := range h.pressure.Reasons() {
+		reasons = append(reasons, pressureReasonTexts[reason])
+	}
+	return "\n🔥 Нагрузка: сброс (" + strings.Join(reasons, ", ") + "), запросы с низким приоритетом отклоняются"
+}
@@ -0,0 +1,503 @@
+package telegram
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// jobState состояние задачи загрузки
+type jobState string
+
+const (
+	jobQueued jobState = "queued"
+	jobActive jobState = "active"
+	// jobDeferred задача отложена (тихие часы, повтор после ошибки) и еще не в очереди
+	jobDeferred jobState = "deferred"
+)
+
+// Кто отменил задачу (downloadRequest.cancelledBy)
+const (
+	cancelNone int32 = iota
+	cancelByAdmin
+	cancelByOwner
+)
+
+// jobPriority класс приоритета задачи: задачи старшего класса запускаются раньше
+type jobPriority int
+
+const (
+	// priorityNormal запросы из групп и каналов
+	priorityNormal jobPriority = iota
+	// priorityHigh запросы из личных чатов и от администраторов
+	priorityHigh
+)
+
+// priorityAging сколько задача обычного приоритета ждет, прежде чем сравняться со
+// старшим классом: поток личных запросов не может задержать группы бесконечно
+const priorityAging = 5 * time.Minute
+
+// jobInfo снимок задачи для отображения администратору
+type jobInfo struct {
+	ID       string
+	UserID   int64
+	ChatID   int64
+	URL      string
+	Source   string
+	State    jobState
+	Priority jobPriority
+	// Since время постановки в очередь (для ожидающих) или начала обработки (для активных)
+	Since time.Time
+}
+
+// jobQueue очередь задач загрузки с ограниченной емкостью. В отличие от канала
+// позволяет просматривать задачи, отменять их и поднимать в начало очереди.
+//
+// Сначала запускаются задачи старшего приоритета (личные чаты и администраторы, см.
+// jobPriority), задачи групп догоняют их за priorityAging ожидания. Внутри класса воркеры
+// распределяются между чатами справедливо: выбирается чат, который израсходовал меньше
+// всего времени воркеров (с учетом веса чата и задач, которые еще выполняются). Поэтому
+// группа, в которую прислали десятки ссылок, не задерживает загрузки остальных чатов.
+// Внутри чата пользователи чередуются: запускается самая ранняя задача того участника,
+// у которого меньше всего выполняющихся задач и который дольше всех ждал своей очереди.
+// Задачи пользователя, у которого уже выполняется perUser задач, ждут, пока одна из них
+// не завершится, и не занимают воркеры, нужные остальным
+type jobQueue struct {
+	mu       sync.Mutex
+	cond     *sync.Cond
+	capacity int
+	// pending ожидающие задачи в порядке поступления
+	pending []*downloadRequest
+	active  map[string]*downloadRequest
+	// deferred задачи, ожидающие запуска по таймеру
+	deferred map[string]*downloadRequest
+
+	// limit сколько задач может выполняться одновременно (0 — сколько есть воркеров);
+	// уменьшается при сбросе нагрузки
+	limit int
+	// perUser сколько задач одного пользователя может выполняться одновременно (0 — без
+	// ограничения)
+	perUser int
+
+	// weights веса чатов (по умолчанию 1)
+	weights map[int64]int
+	// usage время воркеров, израсходованное чатом, деленное на вес. Учитываются только
+	// чаты, которые недавно пользовались воркерами
+	usage map[int64]time.Duration
+	// vtime израсходованное время чата, запущенного последним: чат, вернувшийся после
+	// простоя, начинает с него и не получает воркеры вне очереди за прошлый простой
+	vtime time.Duration
+	// served когда пользователю последний раз достался воркер (чередование внутри чата)
+	served map[int64]time.Time
+}
+
+func newJobQueue(capacity, perUser int, weights map[int64]int) *jobQueue {
+	q := &jobQueue{
+		capacity: capacity,
+		perUser:  perUser,
+		active:   make(map[string]*downloadRequest),
+		deferred: make(map[string]*downloadRequest),
+		weights:  weights,
+		usage:    make(map[int64]time.Duration),
+		served:   make(map[int64]time.Time),
+	}
+	q.cond = sync.NewCond(&q.mu)
+	return q
+}
+
+// push добавляет задачу в конец очереди. Возвращает false, если очередь заполнена
+func (q *jobQueue) push(req *downloadRequest) bool {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if len(q.pending) >= q.capacity {
+		return false
+	}
+
+	if q.usage[req.chatID] < q.vtime {
+		q.usage[req.chatID] = q.vtime
+	}
+	req.enqueuedAt = time.Now()
+	q.pending = append(q.pending, req)
+	q.cond.Signal()
+	return true
+}
+
+// pop ждет следующую задачу и помечает ее активной
+func (q *jobQueue) pop() *downloadRequest {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	var i int
+	now := time.Now()
+	for {
+		if q.limit <= 0 || len(q.active) < q.limit {
+			if i = q.next(now); i >= 0 {
+				break
+			}
+		}
+		q.cond.Wait()
+		now = time.Now()
+	}
+
+	req := q.pending[i]
+	q.pending = append(q.pending[:i], q.pending[i+1:]...)
+	req.bumped = false
+
+	if usage := q.usage[req.chatID]; usage > q.vtime {
+		q.vtime = usage
+	}
+	req.startedAt = now
+	q.active[req.id] = req
+	q.served[req.owner()] = now
+	return req
+}
+
+// next выбирает индекс следующей задачи: поднятую администратором, иначе из старшего
+// ожидающего класса приоритета — чат с наименьшим израсходованным временем, а в нем
+// самую раннюю задачу пользователя, чья очередь подошла (nextInChat). Задачи
+// пользователей, исчерпавших perUser, пропускаются; -1 — запускать нечего
+func (q *jobQueue) next(now time.Time) int {
+	if len(q.pending) == 0 {
+		return -1
+	}
+
+	// Выполняющиеся задачи тоже расходуют время чата, иначе чат занял бы все воркеры
+	// еще до того, как первая его задача завершится
+	running := make(map[int64]time.Duration)
+	runningJobs := make(map[int64]int)
+	for _, req := range q.active {
+		running[req.chatID] += q.cost(req.chatID, now.Sub(req.startedAt))
+		runningJobs[req.owner()]++
+	}
+
+	if q.pending[0].bumped && !q.held(q.pending[0], runningJobs) {
+		return 0
+	}
+
+	top := priorityNormal
+	found := false
+	for _, req := range q.pending {
+		if !q.held(req, runningJobs) {
+			top, found = max(top, q.priority(req, now)), true
+		}
+	}
+	if !found {
+		return -1
+	}
+
+	bestChat := int64(0)
+	var bestUsage time.Duration
+	found = false
+	seen := make(map[int64]bool)
+	for _, req := range q.pending {
+		if seen[req.chatID] || q.held(req, runningJobs) || q.priority(req, now) < top {
+			continue
+		}
+		seen[req.chatID] = true
+
+		usage := q.usage[req.chatID] + running[req.chatID]
+		if !found || usage < bestUsage {
+			bestChat, bestUsage, found = req.chatID, usage, true
+		}
+	}
+	return q.nextInChat(bestChat, top, runningJobs, now)
+}
+
+// nextInChat выбирает в чате самую раннюю задачу класса top того пользователя, у которого
+// меньше всего выполняющихся задач, а при равенстве — дольше всех не получавшего воркер
+func (q *jobQueue) nextInChat(chatID int64, top jobPriority, runningJobs map[int64]int, now time.Time) int {
+	best := -1
+	seen := make(map[int64]bool)
+	for i, req := range q.pending {
+		if req.chatID != chatID || seen[req.owner()] || q.held(req, runningJobs) || q.priority(req, now) < top {
+			continue
+		}
+		seen[req.owner()] = true
+
+		if best < 0 {
+			best = i
+			continue
+		}
+		other := q.pending[best]
+		jobs, otherJobs := runningJobs[req.owner()], runningJobs[other.owner()]
+		if jobs < otherJobs || (jobs == otherJobs && q.served[req.owner()].Before(q.served[other.owner()])) {
+			best = i
+		}
+	}
+	return best
+}
+
+// owner возвращает, чьи задачи ограничиваются perUser и чередуются внутри чата: автор
+// запроса, а для постов каналов, у которых автора нет, — сам канал
+func (req *downloadRequest) owner() int64 {
+	if req.userID == 0 {
+		return req.chatID
+	}
+	return req.userID
+}
+
+// held сообщает, что у владельца задачи уже выполняется perUser задач
+func (q *jobQueue) held(req *downloadRequest, runningJobs map[int64]int) bool {
+	return q.perUser > 0 && runningJobs[req.owner()] >= q.perUser
+}
+
+// priority возвращает класс задачи с учетом ожидания: задача обычного приоритета,
+// прождавшая priorityAging, считается срочной
+func (q *jobQueue) priority(req *downloadRequest, now time.Time) jobPriority {
+	if req.priority < priorityHigh && now.Sub(req.enqueuedAt) >= priorityAging {
+		return priorityHigh
+	}
+	return req.priority
+}
+
+// cost переводит время воркера в израсходованное время чата с учетом веса
+func (q *jobQueue) cost(chatID int64, elapsed time.Duration) time.Duration {
+	if weight := q.weights[chatID]; weight > 1 {
+		return elapsed / time.Duration(weight)
+	}
+	return elapsed
+}
+
+// schedule учитывает отложенную задачу, чтобы ее можно было увидеть и отменить до запуска
+func (q *jobQueue) schedule(req *downloadRequest) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	req.enqueuedAt = time.Now()
+	q.deferred[req.id] = req
+}
+
+// release снимает отложенную задачу с учета перед запуском.
+// Возвращает false, если задача была отменена
+func (q *jobQueue) release(req *downloadRequest) bool {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if _, ok := q.deferred[req.id]; !ok {
+		return false
+	}
+	delete(q.deferred, req.id)
+	return true
+}
+
+// done снимает задачу с учета после завершения обработки и списывает ее время с чата
+func (q *jobQueue) done(req *downloadRequest) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	delete(q.active, req.id)
+	q.usage[req.chatID] += q.cost(req.chatID, time.Since(req.startedAt))
+	q.forgetIdle()
+	if q.limit > 0 || q.perUser > 0 {
+		q.cond.Broadcast()
+	}
+}
+
+// setLimit ограничивает число одновременно выполняемых задач (0 — без ограничения).
+// Уже запущенные задачи не прерываются
+func (q *jobQueue) setLimit(limit int) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	q.limit = limit
+	q.cond.Broadcast()
+}
+
+// forgetIdle забывает чаты без задач, которые израсходовали не больше vtime: при
+// возвращении они все равно начнут с vtime
+func (q *jobQueue) forgetIdle() {
+	busy := make(map[int64]bool, len(q.pending)+len(q.active))
+	for _, req := range q.pending {
+		busy[req.chatID] = true
+	}
+	for _, req := range q.active {
+		busy[req.chatID] = true
+	}
+	for chatID, usage := range q.usage {
+		if !busy[chatID] && usage <= q.vtime {
+			delete(q.usage, chatID)
+		}
+	}
+
+	users := make(map[int64]bool, len(q.pending)+len(q.active))
+	for _, req := range q.pending {
+		users[req.owner()] = true
+	}
+	for _, req := range q.active {
+		users[req.owner()] = true
+	}
+	for userID := range q.served {
+		if !users[userID] {
+			delete(q.served, userID)
+		}
+	}
+}
+
+// jobPosition место ожидающей задачи в очереди
+type jobPosition struct {
+	Request *downloadRequest
+	// Place номер задачи среди ожидающих, начиная с 1
+	Place int
+	// Held у владельца уже выполняется предельное число задач (perUser)
+	Held bool
+}
+
+// positions оценивает места ожидающих задач: впереди задачи стоят поднятая администратором,
+// задачи старшего класса приоритета и более ранние задачи того же класса. Справедливое
+// распределение между чатами не моделируется, поэтому место приблизительное.
+// Задачи возвращаются в порядке поступления
+func (q *jobQueue) positions() []jobPosition {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	now := time.Now()
+	runningJobs := make(map[int64]int)
+	for _, req := range q.active {
+		runningJobs[req.owner()]++
+	}
+
+	// Порядок, в котором задачи будут запущены без учета справедливости между чатами
+	order := make([]int, len(q.pending))
+	priorities := make([]jobPriority, len(q.pending))
+	for i, req := range q.pending {
+		order[i] = i
+		priorities[i] = q.priority(req, now)
+	}
+	bumped := len(q.pending) > 0 && q.pending[0].bumped
+	sort.SliceStable(order, func(a, b int) bool {
+		i, j := order[a], order[b]
+		if bumped && (i == 0 || j == 0) {
+			return i == 0
+		}
+		return priorities[i] > priorities[j]
+	})
+
+	places := make([]int, len(q.pending))
+	for place, i := range order {
+		places[i] = place + 1
+	}
+
+	positions := make([]jobPosition, 0, len(q.pending))
+	for i, req := range q.pending {
+		positions = append(positions, jobPosition{Request: req, Place: places[i], Held: q.held(req, runningJobs)})
+	}
+	return positions
+}
+
+// occupancy возвращает количество ожидающих, активных и отложенных задач
+func (q *jobQueue) occupancy() (pending, active, deferred int) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	return len(q.pending), len(q.active), len(q.deferred)
+}
+
+// snapshot возвращает активные задачи и затем ожидающие в порядке поступления
+func (q *jobQueue) snapshot() []jobInfo {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	jobs := make([]jobInfo, 0, len(q.active)+len(q.pending))
+	for _, req := range q.active {
+		jobs = append(jobs, newJobInfo(req, jobActive, req.startedAt))
+	}
+	// Активные задачи — от самой давней
+	sort.Slice(jobs, func(i, j int) bool { return jobs[i].Since.Before(jobs[j].Since) })
+	for _, req := range q.pending {
+		jobs = append(jobs, newJobInfo(req, jobQueued, req.enqueuedAt))
+	}
+
+	deferred := make([]jobInfo, 0, len(q.deferred))
+	for _, req := range q.deferred {
+		deferred = append(deferred, newJobInfo(req, jobDeferred, req.enqueuedAt))
+	}
+	sort.Slice(deferred, func(i, j int) bool { return deferred[i].Since.Before(deferred[j].Since) })
+	return append(jobs, deferred...)
+}
+
+// cancel отменяет задачу: ожидающая и отложенная снимаются с учета, у активной отменяется
+// контекст. by — кто отменяет (cancelByAdmin, cancelByOwner).
+// Возвращает задачу и ее состояние на момент отмены
+func (q *jobQueue) cancel(id string, by int32) (*downloadRequest, jobState, bool) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if req, ok := q.active[id]; ok {
+		req.cancelledBy.Store(by)
+		req.cancel()
+		return req, jobActive, true
+	}
+
+	for i, req := range q.pending {
+		if req.id == id {
+			q.pending = append(q.pending[:i], q.pending[i+1:]...)
+			req.cancelledBy.Store(by)
+			req.cancel()
+			return req, jobQueued, true
+		}
+	}
+
+	// У отложенной задачи контекст загрузки еще не создан
+	if req, ok := q.deferred[id]; ok {
+		delete(q.deferred, id)
+		req.cancelledBy.Store(by)
+		return req, jobDeferred, true
+	}
+
+	return nil, "", false
+}
+
+// owned возвращает ID задач пользователя во всех состояниях
+func (q *jobQueue) owned(userID int64) []string {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	var ids []string
+	for id, req := range q.active {
+		if req.userID == userID {
+			ids = append(ids, id)
+		}
+	}
+	for _, req := range q.pending {
+		if req.userID == userID {
+			ids = append(ids, req.id)
+		}
+	}
+	for id, req := range q.deferred {
+		if req.userID == userID {
+			ids = append(ids, id)
+		}
+	}
+	return ids
+}
+
+// bump переносит ожидающую задачу в начало очереди: она запускается следующей,
+// независимо от того, сколько времени воркеров израсходовал ее чат
+func (q *jobQueue) bump(id string) bool {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	for i, req := range q.pending {
+		if req.id == id {
+			copy(q.pending[1:i+1], q.pending[:i])
+			q.pending[0] = req
+			req.bumped = true
+			return true
+		}
+	}
+	return false
+}
+
+func newJobInfo(req *downloadRequest, state jobState, since time.Time) jobInfo {
+	return jobInfo{
+		ID:       req.id,
+		UserID:   req.userID,
+		ChatID:   req.chatID,
+		URL:      req.url,
+		Source:   req.source,
+		State:    state,
+		Priority: req.priority,
+		Since:    since,
+	}
+}
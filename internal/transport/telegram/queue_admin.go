@@ -0,0 +1,196 @@
+package telegram
+
+import (
+	"context"
+	"fmt"
+	"html"
+	"log/slog"
+	"strings"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+const (
+	// queueCallbackPrefix префикс callback-данных кнопок /queue
+	queueCallbackPrefix = "q:"
+	// queueListLimit максимальное количество задач в сообщении /queue
+	queueListLimit = 15
+	// queueURLMaxLen длина, до которой обрезаются ссылки в списке
+	queueURLMaxLen = 60
+)
+
+// handleQueueCommand показывает администратору активные и ожидающие задачи: /queue
+func (h *Handler) handleQueueCommand(message *tgbotapi.Message) {
+	text, markup := h.renderQueue()
+
+	msg := tgbotapi.NewMessage(message.Chat.ID, text)
+	msg.ParseMode = "HTML"
+	msg.DisableWebPagePreview = true
+	msg.ReplyMarkup = markup
+
+	if _, err := h.bot.Send(msg); err != nil {
+		h.logger.Error("Failed to send queue status",
+			slog.Int64("chat_id", message.Chat.ID),
+			slog.Any("error", err),
+		)
+	}
+}
+
+// handleCallbackQuery обрабатывает нажатия inline-кнопок
+func (h *Handler) handleCallbackQuery(_ context.Context, query *tgbotapi.CallbackQuery) {
+	if query.From != nil && strings.HasPrefix(query.Data, setupCallbackPrefix) {
+		h.handleSetupCallback(query)
+		return
+	}
+
+	if query.From == nil || !strings.HasPrefix(query.Data, queueCallbackPrefix) {
+		h.answerCallback(query.ID, "")
+		return
+	}
+
+	if !h.auth.IsAdmin(int64(query.From.ID)) {
+		h.answerCallback(query.ID, "Недостаточно прав")
+		return
+	}
+
+	action, id, _ := strings.Cut(strings.TrimPrefix(query.Data, queueCallbackPrefix), ":")
+	h.answerCallback(query.ID, h.applyQueueAction(action, id, int64(query.From.ID)))
+
+	if query.Message != nil {
+		h.refreshQueueMessage(query.Message)
+	}
+}
+
+// applyQueueAction выполняет действие над задачей и возвращает текст ответа на нажатие
+func (h *Handler) applyQueueAction(action, id string, adminID int64) string {
+	switch action {
+	case "cancel":
+		req, state, ok := h.queue.cancel(id, cancelByAdmin)
+		if !ok {
+			return "Задача уже завершена"
+		}
+		h.jobs.Remove(id)
+
+		h.logger.Info("Download request cancelled by admin",
+			slog.String("request_id", id),
+			slog.Int64("admin_id", adminID),
+			slog.String("state", string(state)),
+		)
+
+		// Активная задача сообщит об отмене сама, когда загрузка прервется
+		if state != jobActive {
+			h.clearStatusMessage(req)
+			h.notify(req, "🚫 Загрузка отменена администратором.")
+		}
+		return "Задача отменена"
+
+	case "bump":
+		if !h.queue.bump(id) {
+			return "Задача уже не в очереди"
+		}
+
+		h.logger.Info("Download request moved to the front of the queue",
+			slog.String("request_id", id),
+			slog.Int64("admin_id", adminID),
+		)
+		return "Задача поднята в начало очереди"
+
+	case "refresh":
+		return ""
+
+	default:
+		return "Неизвестное действие"
+	}
+}
+
+// refreshQueueMessage обновляет сообщение /queue после действия администратора
+func (h *Handler) refreshQueueMessage(message *tgbotapi.Message) {
+	text, markup := h.renderQueue()
+
+	edit := tgbotapi.NewEditMessageTextAndMarkup(message.Chat.ID, message.MessageID, text, markup)
+	edit.ParseMode = "HTML"
+	edit.DisableWebPagePreview = true
+
+	if _, err := h.bot.Request(edit); err != nil && !strings.Contains(err.Error(), "message is not modified") {
+		h.logger.Warn("Failed to refresh queue status",
+			slog.Int64("chat_id", message.Chat.ID),
+			slog.Any("error", err),
+		)
+	}
+}
+
+// renderQueue формирует текст и кнопки управления очередью
+func (h *Handler) renderQueue() (string, tgbotapi.InlineKeyboardMarkup) {
+	jobs := h.queue.snapshot()
+	now := time.Now()
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "📋 <b>Очередь загрузок</b> (воркеров: %d, лимит очереди: %d)\n\n", h.scaler.current(), h.queueSizeLimit)
+
+	refresh := tgbotapi.NewInlineKeyboardRow(
+		tgbotapi.NewInlineKeyboardButtonData("🔄 Обновить", queueCallbackPrefix+"refresh:"),
+	)
+
+	if len(jobs) == 0 {
+		b.WriteString("Очередь пуста.")
+		return b.String(), tgbotapi.NewInlineKeyboardMarkup(refresh)
+	}
+
+	var rows [][]tgbotapi.InlineKeyboardButton
+	for i, job := range jobs {
+		if i == queueListLimit {
+			fmt.Fprintf(&b, "…и ещё %d\n", len(jobs)-queueListLimit)
+			break
+		}
+
+		state := "⏳ в очереди"
+		switch job.State {
+		case jobActive:
+			state = "⬇️ загружается"
+		case jobDeferred:
+			state = "🌙 отложена"
+		}
+		if job.Priority == priorityHigh && job.State == jobQueued {
+			state += " ⚡"
+		}
+
+		fmt.Fprintf(&b, "%d. <code>%s</code> %s, %s\n   👤 <code>%d</code> · 💬 <code>%d</code> · %s\n   %s\n",
+			i+1, job.ID, state, formatAge(now.Sub(job.Since)),
+			job.UserID, job.ChatID, html.EscapeString(job.Source),
+			html.EscapeString(truncate(job.URL, queueURLMaxLen)),
+		)
+
+		row := []tgbotapi.InlineKeyboardButton{
+			tgbotapi.NewInlineKeyboardButtonData("✖ "+job.ID, queueCallbackPrefix+"cancel:"+job.ID),
+		}
+		if job.State == jobQueued {
+			row = append(row, tgbotapi.NewInlineKeyboardButtonData("⬆ "+job.ID, queueCallbackPrefix+"bump:"+job.ID))
+		}
+		rows = append(rows, row)
+	}
+
+	rows = append(rows, refresh)
+	return b.String(), tgbotapi.NewInlineKeyboardMarkup(rows...)
+}
+
+// answerCallback отвечает на нажатие inline-кнопки (убирает индикатор загрузки)
+func (h *Handler) answerCallback(queryID, text string) {
+	if _, err := h.bot.Request(tgbotapi.NewCallback(queryID, text)); err != nil {
+		h.logger.Warn("Failed to answer callback query", slog.Any("error", err))
+	}
+}
+
+// formatAge форматирует возраст задачи
+func formatAge(d time.Duration) string {
+	return localeRU.age(d)
+}
+
+// truncate обрезает строку до max символов
+func truncate(s string, max int) string {
+	runes := []rune(s)
+	if len(runes) <= max {
+		return s
+	}
+	return string(runes[:max-1]) + "…"
+}
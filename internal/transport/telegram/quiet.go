@@ -0,0 +1,121 @@
+package telegram
+
+import (
+	"context"
+	"fmt"
+	"html"
+	"log/slog"
+	"strings"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+
+	"github.com/reelser-bot/internal/services/chatsettings"
+)
+
+// quietUntil возвращает время окончания тихих часов, если они сейчас действуют в групповом чате
+func (h *Handler) quietUntil(chatID int64, chatType string) (time.Time, bool) {
+	if chatType != "group" && chatType != "supergroup" {
+		return time.Time{}, false
+	}
+
+	quiet := h.chatSettings.Get(chatID).QuietHours
+	if quiet == nil {
+		return time.Time{}, false
+	}
+	return quiet.Until(time.Now().In(h.chatLocation(chatID)))
+}
+
+// scheduleDownload ставит запрос в очередь через delay. Контекст загрузки создается
+// в момент запуска, чтобы таймаут отсчитывался от начала обработки
+func (h *Handler) scheduleDownload(req *downloadRequest, delay time.Duration) {
+	h.saveJob(req, time.Now().Add(delay))
+	h.queue.schedule(req)
+	time.AfterFunc(delay, func() {
+		if !h.queue.release(req) || req.baseCtx.Err() != nil {
+			return
+		}
+
+		req.ctx, req.cancel = context.WithCancel(req.baseCtx)
+		if !h.enqueueDownload(req) {
+			req.cancel()
+			h.clearStatusMessage(req)
+			h.refundQuota(req.requestSpec)
+			h.notify(req, "⚠️ Слишком много одновременных запросов. Попробуй повторить через пару минут.")
+		}
+	})
+}
+
+// handleQuietCommand настраивает тихие часы группы: /quiet 23:00-08:00, /quiet off
+// или /quiet без аргументов для просмотра текущей настройки
+func (h *Handler) handleQuietCommand(message *tgbotapi.Message) {
+	chatID := message.Chat.ID
+
+	if message.Chat.Type != "group" && message.Chat.Type != "supergroup" {
+		h.sendMessage(chatID, "ℹ️ Тихие часы настраиваются в группах.")
+		return
+	}
+
+	arg := strings.TrimSpace(message.CommandArguments())
+	if arg == "" {
+		if quiet := h.chatSettings.Get(chatID).QuietHours; quiet != nil {
+			h.sendMessage(chatID, fmt.Sprintf("🌙 Тихие часы: %s.\nОтключить: /quiet off", quiet))
+		} else {
+			h.sendMessage(chatID, "Тихие часы не заданы.\nИспользование: /quiet 23:00-08:00")
+		}
+		return
+	}
+
+	if !h.isChatAdmin(message) {
+		h.sendMessage(chatID, "🔒 Настраивать тихие часы могут только администраторы чата.")
+		return
+	}
+
+	if strings.EqualFold(arg, "off") {
+		h.chatSettings.Update(chatID, func(s *chatsettings.Settings) { s.QuietHours = nil })
+		h.sendMessage(chatID, "✅ Тихие часы отключены.")
+		return
+	}
+
+	quiet, err := chatsettings.ParseQuietHours(arg)
+	if err != nil {
+		h.sendMessage(chatID, "❌ "+html.EscapeString(err.Error())+"\nИспользование: /quiet 23:00-08:00")
+		return
+	}
+
+	h.chatSettings.Update(chatID, func(s *chatsettings.Settings) { s.QuietHours = &quiet })
+
+	userID, _ := senderID(message)
+	h.logger.Info("Quiet hours updated",
+		slog.Int64("chat_id", chatID),
+		slog.Int64("user_id", userID),
+		slog.String("quiet_hours", quiet.String()),
+	)
+	h.sendMessage(chatID, fmt.Sprintf(
+		"✅ Тихие часы: %s. В это время я принимаю ссылки, а видео присылаю после окончания окна.",
+		quiet,
+	))
+}
+
+// isChatAdmin проверяет, что автор сообщения администрирует чат. Анонимный
+// администратор пишет от имени самой группы
+func (h *Handler) isChatAdmin(message *tgbotapi.Message) bool {
+	if message.SenderChat != nil && message.SenderChat.ID == message.Chat.ID {
+		return true
+	}
+	if message.From == nil {
+		return false
+	}
+
+	member, err := h.bot.GetChatMember(tgbotapi.GetChatMemberConfig{
+		ChatConfigWithUser: tgbotapi.ChatConfigWithUser{ChatID: message.Chat.ID, UserID: message.From.ID},
+	})
+	if err != nil {
+		h.logger.Warn("Failed to check chat admin",
+			slog.Int64("chat_id", message.Chat.ID),
+			slog.Any("error", err),
+		)
+		return false
+	}
+	return member.IsCreator() || member.IsAdministrator()
+}
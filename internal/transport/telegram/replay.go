@@ -0,0 +1,184 @@
+package telegram
+
+import (
+	"context"
+	"fmt"
+	"html"
+	"log/slog"
+	"strings"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+
+	"github.com/reelser-bot/internal/services/history"
+)
+
+// replayTimeLayouts форматы границ интервала в /replay
+var replayTimeLayouts = []string{
+	time.RFC3339,
+	"2006-01-02T15:04",
+	"2006-01-02",
+}
+
+// recordFailure сохраняет неудавшуюся загрузку для повторного запуска через /replay
+func (h *Handler) recordFailure(req *downloadRequest, err error) {
+	req.summary.fail(err)
+	h.failures.Record(history.Failure{
+		ID:        req.id,
+		ChatID:    req.chatID,
+		ChatType:  req.chatType,
+		UserID:    req.userID,
+		URL:       req.url,
+		Source:    req.source,
+		Quality:   req.quality,
+		AudioOnly: req.audioOnly,
+		Animation: req.animation,
+		VideoNote: req.videoNote,
+		Document:  req.document,
+		Chapters:  req.chapters,
+		Language:  req.language,
+
+		BusinessConnectionID: req.businessConnectionID,
+		PaidStars:            req.paidStars,
+
+		Error:    err.Error(),
+		FailedAt: time.Now(),
+	})
+}
+
+// handleReplayCommand повторно ставит в очередь неудавшиеся загрузки:
+// /replay <код запроса> или /replay <интервал>
+func (h *Handler) handleReplayCommand(ctx context.Context, message *tgbotapi.Message) {
+	chatID := message.Chat.ID
+
+	arg := strings.TrimSpace(message.CommandArguments())
+	if arg == "" {
+		h.sendMessage(chatID, "Использование:\n"+
+			"/replay &lt;код запроса&gt;\n"+
+			"/replay 2h — ошибки за последние 2 часа\n"+
+			"/replay 2024-05-01T10:00..2024-05-01T12:00 — ошибки за интервал")
+		return
+	}
+
+	failures, err := h.findFailures(arg, h.chatLocation(chatID))
+	if err != nil {
+		h.sendMessage(chatID, "❌ "+html.EscapeString(err.Error()))
+		return
+	}
+	if len(failures) == 0 {
+		h.sendMessage(chatID, "Неудавшихся загрузок по запросу не найдено.")
+		return
+	}
+
+	replayed := 0
+	for _, failure := range failures {
+		if !h.replayFailure(ctx, failure) {
+			break
+		}
+		replayed++
+	}
+
+	h.logger.Info("Failed downloads replayed",
+		slog.Int64("admin_id", int64(message.From.ID)),
+		slog.String("selector", arg),
+		slog.Int("found", len(failures)),
+		slog.Int("replayed", replayed),
+	)
+
+	text := fmt.Sprintf("🔁 Повторно поставлено в очередь: %d из %d.", replayed, len(failures))
+	if replayed < len(failures) {
+		text += "\nОчередь заполнена, остальные запросы сохранены — повтори команду позже."
+	}
+	h.sendMessage(chatID, text)
+}
+
+// findFailures находит записи истории по коду запроса или интервалу времени;
+// границы интервала указываются в часовом поясе loc
+func (h *Handler) findFailures(arg string, loc *time.Location) ([]history.Failure, error) {
+	if failure, ok := h.failures.Get(arg); ok {
+		return []history.Failure{failure}, nil
+	}
+
+	from, to, err := parseReplayRange(arg, time.Now().In(loc))
+	if err != nil {
+		return nil, err
+	}
+	return h.failures.Between(from, to), nil
+}
+
+// replayFailure ставит неудавшуюся загрузку в очередь заново. Возвращает false при переполнении очереди
+func (h *Handler) replayFailure(ctx context.Context, failure history.Failure) bool {
+	requestCtx, cancel := context.WithCancel(ctx)
+	req := &downloadRequest{
+		requestSpec: requestSpec{
+			chatID:               failure.ChatID,
+			chatType:             failure.ChatType,
+			userID:               failure.UserID,
+			url:                  failure.URL,
+			quality:              failure.Quality,
+			audioOnly:            failure.AudioOnly,
+			animation:            failure.Animation,
+			videoNote:            failure.VideoNote,
+			document:             failure.Document,
+			chapters:             failure.Chapters,
+			language:             failure.Language,
+			source:               failure.Source,
+			businessConnectionID: failure.BusinessConnectionID,
+			paidStars:            failure.PaidStars,
+		},
+
+		id:      newRequestID(),
+		baseCtx: ctx,
+		ctx:     requestCtx,
+		cancel:  cancel,
+	}
+
+	if !h.enqueueDownload(req) {
+		cancel()
+		return false
+	}
+
+	h.failures.Remove(failure.ID)
+	h.notify(req, "🔁 Повторяю загрузку, которая раньше завершилась ошибкой...")
+	return true
+}
+
+// parseReplayRange разбирает интервал: длительность назад от now (2h)
+// или границы через «..» (2024-05-01T10:00..2024-05-01T12:00, правая граница необязательна)
+func parseReplayRange(arg string, now time.Time) (time.Time, time.Time, error) {
+	if d, err := time.ParseDuration(arg); err == nil && d > 0 {
+		return now.Add(-d), now, nil
+	}
+
+	fromStr, toStr, ok := strings.Cut(arg, "..")
+	if !ok {
+		return time.Time{}, time.Time{}, fmt.Errorf("запрос с кодом %s не найден в истории ошибок", arg)
+	}
+
+	from, err := parseReplayTime(fromStr, now.Location())
+	if err != nil {
+		return time.Time{}, time.Time{}, err
+	}
+
+	to := now
+	if toStr != "" {
+		if to, err = parseReplayTime(toStr, now.Location()); err != nil {
+			return time.Time{}, time.Time{}, err
+		}
+	}
+
+	if to.Before(from) {
+		return time.Time{}, time.Time{}, fmt.Errorf("начало интервала позже конца")
+	}
+	return from, to, nil
+}
+
+// parseReplayTime разбирает границу интервала в часовом поясе loc
+func parseReplayTime(value string, loc *time.Location) (time.Time, error) {
+	for _, layout := range replayTimeLayouts {
+		if t, err := time.ParseInLocation(layout, value, loc); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("не удалось разобрать время %q", value)
+}
@@ -0,0 +1,253 @@
+package telegram
+
+import (
+	"fmt"
+	"log/slog"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+
+	"github.com/reelser-bot/internal/platform"
+	"github.com/reelser-bot/internal/services/setup"
+)
+
+// setupCallbackPrefix префикс callback-данных кнопок первичной настройки
+const setupCallbackPrefix = "setup:"
+
+// setupWizard состояние первичной настройки бота, запущенного без ADMIN_IDS
+type setupWizard struct {
+	store *setup.Store
+
+	mu sync.Mutex
+	// pendingAdmin первый отправивший /start пользователь; 0 — настройку еще никто не начал
+	pendingAdmin int64
+	draft        setup.Result
+	// quality качество по умолчанию, выбранное при настройке
+	quality string
+}
+
+// defaultQuality возвращает качество, выбранное при настройке (пусто — VIDEO_QUALITY)
+func (w *setupWizard) defaultQuality() string {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	return w.quality
+}
+
+// setupRequired проверяет, что бот запущен без администратора и ждет первичной настройки
+func (h *Handler) setupRequired() bool {
+	return h.auth != nil && !h.auth.HasAdmins()
+}
+
+// restoreSetup применяет сохраненный результат настройки, если администраторы не заданы в окружении
+func (h *Handler) restoreSetup() {
+	if !h.setupRequired() {
+		return
+	}
+
+	result, ok := h.setup.store.Result()
+	if !ok {
+		h.logger.Info("No admin configured, first /start sender will be offered the setup")
+		return
+	}
+
+	h.applySetup(result)
+	h.logger.Info("Setup result restored", slog.Int64("admin_id", result.AdminID))
+}
+
+// applySetup включает выбранные при настройке параметры без перезапуска
+func (h *Handler) applySetup(result setup.Result) {
+	h.auth.AddAdmin(result.AdminID)
+	h.auth.SetEnabled(result.AuthEnabled)
+	if result.AuthEnabled {
+		h.auth.Allow(result.AdminID)
+	}
+	h.sizeLimits.setDefault(result.MaxVideoSizeMB)
+
+	h.setup.mu.Lock()
+	h.setup.quality = result.Quality
+	h.setup.mu.Unlock()
+}
+
+// handleSetupStart начинает настройку для первого пользователя, отправившего /start
+// в личных сообщениях. Возвращает false, если настройку уже проходит другой пользователь
+func (h *Handler) handleSetupStart(message *tgbotapi.Message, userID int64) bool {
+	h.setup.mu.Lock()
+	if h.setup.pendingAdmin != 0 && h.setup.pendingAdmin != userID {
+		h.setup.mu.Unlock()
+		return false
+	}
+	h.setup.pendingAdmin = userID
+	h.setup.draft = setup.Result{AdminID: userID}
+	h.setup.mu.Unlock()
+
+	h.logger.Info("Setup started", slog.Int64("user_id", userID))
+
+	msg := tgbotapi.NewMessage(message.Chat.ID,
+		"👋 Бот запущен без администратора — ты станешь им после короткой настройки.\n\n"+
+			"<b>Шаг 1 из 3.</b> Пускать в бота только по токену доступа?")
+	msg.ParseMode = "HTML"
+	msg.ReplyMarkup = tgbotapi.NewInlineKeyboardMarkup(
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("🔒 Да, по токену", setupCallbackPrefix+"auth:on"),
+			tgbotapi.NewInlineKeyboardButtonData("🔓 Нет, всем", setupCallbackPrefix+"auth:off"),
+		),
+	)
+	if _, err := h.bot.Send(msg); err != nil {
+		h.logger.Error("Failed to send setup message",
+			slog.Int64("chat_id", message.Chat.ID),
+			slog.Any("error", err),
+		)
+	}
+	return true
+}
+
+// handleSetupCallback обрабатывает нажатия кнопок первичной настройки
+func (h *Handler) handleSetupCallback(query *tgbotapi.CallbackQuery) {
+	if !h.setupRequired() {
+		h.answerCallback(query.ID, "Настройка уже завершена")
+		return
+	}
+
+	userID := int64(query.From.ID)
+	step, value, _ := strings.Cut(strings.TrimPrefix(query.Data, setupCallbackPrefix), ":")
+
+	h.setup.mu.Lock()
+	if h.setup.pendingAdmin != userID {
+		h.setup.mu.Unlock()
+		h.answerCallback(query.ID, "Настройку проходит другой пользователь")
+		return
+	}
+
+	var (
+		text   string
+		markup *tgbotapi.InlineKeyboardMarkup
+		done   bool
+	)
+	switch step {
+	case "auth":
+		h.setup.draft.AuthEnabled = value == "on"
+		text, markup = h.setupSizeStep()
+	case "size":
+		mb, err := strconv.Atoi(value)
+		if err != nil || mb < 0 {
+			h.setup.mu.Unlock()
+			h.answerCallback(query.ID, "")
+			return
+		}
+		h.setup.draft.MaxVideoSizeMB = mb
+		text, markup = setupQualityStep()
+	case "quality":
+		switch value {
+		case platform.QualityHD, platform.QualitySD:
+			h.setup.draft.Quality = value
+		default:
+			h.setup.draft.Quality = platform.QualityDefault
+		}
+		h.setup.draft.CompletedAt = time.Now()
+		done = true
+	default:
+		h.setup.mu.Unlock()
+		h.answerCallback(query.ID, "")
+		return
+	}
+	result := h.setup.draft
+	h.setup.mu.Unlock()
+
+	h.answerCallback(query.ID, "")
+
+	if done {
+		h.setup.store.Save(result)
+		h.applySetup(result)
+
+		h.logger.Info("Setup completed",
+			slog.Int64("admin_id", result.AdminID),
+			slog.Bool("auth_enabled", result.AuthEnabled),
+			slog.Int("max_video_size_mb", result.MaxVideoSizeMB),
+			slog.String("quality", result.Quality),
+		)
+		text = h.setupSummary(result)
+	}
+
+	if query.Message == nil {
+		return
+	}
+
+	var edit tgbotapi.EditMessageTextConfig
+	if markup != nil {
+		edit = tgbotapi.NewEditMessageTextAndMarkup(query.Message.Chat.ID, query.Message.MessageID, text, *markup)
+	} else {
+		edit = tgbotapi.NewEditMessageText(query.Message.Chat.ID, query.Message.MessageID, text)
+	}
+	edit.ParseMode = "HTML"
+	if _, err := h.bot.Request(edit); err != nil {
+		h.logger.Warn("Failed to update setup message",
+			slog.Int64("chat_id", query.Message.Chat.ID),
+			slog.Any("error", err),
+		)
+	}
+}
+
+// setupSizeStep второй шаг: лимит размера видео
+func (h *Handler) setupSizeStep() (string, *tgbotapi.InlineKeyboardMarkup) {
+	apiMB := h.sizeLimits.apiLimit / (1024 * 1024)
+
+	row := tgbotapi.NewInlineKeyboardRow(
+		tgbotapi.NewInlineKeyboardButtonData("20 MB", setupCallbackPrefix+"size:20"),
+	)
+	// С локальным Bot API сервером лимит выше облачных 50 MB
+	if apiMB > 50 {
+		row = append(row, tgbotapi.NewInlineKeyboardButtonData("50 MB", setupCallbackPrefix+"size:50"))
+	}
+	row = append(row, tgbotapi.NewInlineKeyboardButtonData(fmt.Sprintf("Максимум (%d MB)", apiMB), setupCallbackPrefix+"size:0"))
+
+	markup := tgbotapi.NewInlineKeyboardMarkup(row)
+	return "<b>Шаг 2 из 3.</b> Какого размера видео присылать? Более тяжелые файлы бот не отправит.", &markup
+}
+
+// setupQualityStep третий шаг: качество по умолчанию
+func setupQualityStep() (string, *tgbotapi.InlineKeyboardMarkup) {
+	markup := tgbotapi.NewInlineKeyboardMarkup(
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("HD", setupCallbackPrefix+"quality:"+platform.QualityHD),
+			tgbotapi.NewInlineKeyboardButtonData("SD (до 480p)", setupCallbackPrefix+"quality:"+platform.QualitySD),
+		),
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("Как в VIDEO_QUALITY", setupCallbackPrefix+"quality:default"),
+		),
+	)
+	return "<b>Шаг 3 из 3.</b> В каком качестве скачивать видео по умолчанию?", &markup
+}
+
+// setupSummary итоговое сообщение настройки
+func (h *Handler) setupSummary(result setup.Result) string {
+	var b strings.Builder
+	b.WriteString("✅ Настройка завершена, теперь ты администратор бота.\n\n")
+
+	if result.AuthEnabled {
+		b.WriteString("🔒 Доступ: по токену. Выдай пользователям токен из AUTH_TOKENS.\n")
+	} else {
+		b.WriteString("🔓 Доступ: открыт всем.\n")
+	}
+
+	limitMB := h.sizeLimits.apiLimit / (1024 * 1024)
+	if result.MaxVideoSizeMB > 0 && int64(result.MaxVideoSizeMB) < limitMB {
+		limitMB = int64(result.MaxVideoSizeMB)
+	}
+	fmt.Fprintf(&b, "📦 Лимит размера: %d MB\n", limitMB)
+
+	switch result.Quality {
+	case platform.QualityHD:
+		b.WriteString("🎞 Качество: HD\n")
+	case platform.QualitySD:
+		b.WriteString("🎞 Качество: SD (до 480p)\n")
+	default:
+		b.WriteString("🎞 Качество: по настройке VIDEO_QUALITY\n")
+	}
+
+	b.WriteString("\nНастройки сохранены и применяются, пока в окружении не задан ADMIN_IDS.")
+	return b.String()
+}
@@ -0,0 +1,119 @@
+package telegram
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/reelser-bot/internal/services/shard"
+)
+
+const (
+	// shardForwardHeader помечает апдейт, пересланный другим экземпляром: его
+	// обрабатывают на месте, не проверяя кольцо повторно
+	shardForwardHeader = "X-Reelser-Forwarded-By"
+	// shardSignatureHeader подпись тела пересланного апдейта общим секретом SHARD_SECRET
+	shardSignatureHeader = "X-Reelser-Signature"
+	// shardForwardTimeout таймаут пересылки апдейта владельцу чата
+	shardForwardTimeout = 10 * time.Second
+)
+
+// ownsChat сообщает, что чат обслуживает этот экземпляр (без шардирования — любой чат)
+func (b *Bot) ownsChat(chatID int64) bool {
+	return b.shards == nil || b.shards.Owner(chatID).ID == b.shards.Self().ID
+}
+
+// forwardUpdate пересылает апдейт экземпляру, которому принадлежит чат. false —
+// апдейт нужно обработать здесь: чат принадлежит этому экземпляру, у апдейта нет
+// чата или владелец недоступен (тогда апдейт не теряется, а обрабатывается на месте)
+func (b *Bot) forwardUpdate(ctx context.Context, update incomingUpdate, body []byte) bool {
+	if b.shards == nil {
+		return false
+	}
+
+	chatID, ok := shardKey(update)
+	if !ok {
+		return false
+	}
+	owner := b.shards.Owner(chatID)
+	if owner.ID == b.shards.Self().ID {
+		return false
+	}
+
+	forwardCtx, cancel := context.WithTimeout(ctx, shardForwardTimeout)
+	defer cancel()
+
+	err := b.postToPeer(forwardCtx, owner.URL, body)
+	if err != nil {
+		b.logger.Warn("Failed to forward update to shard owner, handling locally",
+			slog.Int("update_id", update.UpdateID),
+			slog.Int64("chat_id", chatID),
+			slog.String("owner", owner.ID),
+			slog.Any("error", err),
+		)
+		return false
+	}
+
+	b.logger.Debug("Update forwarded to shard owner",
+		slog.Int("update_id", update.UpdateID),
+		slog.Int64("chat_id", chatID),
+		slog.String("owner", owner.ID),
+	)
+	return true
+}
+
+// postToPeer отправляет тело апдейта на вебхук другого экземпляра с тем же секретом
+// и подписью SHARD_SECRET
+func (b *Bot) postToPeer(ctx context.Context, url string, body []byte) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(shardForwardHeader, b.shards.Self().ID)
+	req.Header.Set(shardSignatureHeader, shard.Sign(b.webhook.Sharding.Secret, body))
+	req.Header.Set("X-Telegram-Bot-Api-Secret-Token", b.webhook.Secret)
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("peer responded with status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// shardKey возвращает ID чата, по которому апдейт распределяется между экземплярами.
+// Для апдейтов без чата (inline-запросы) используется ID пользователя — он совпадает
+// с ID его личного чата с ботом
+func shardKey(update incomingUpdate) (int64, bool) {
+	switch {
+	case update.BusinessMessage != nil && update.BusinessMessage.Chat != nil:
+		return update.BusinessMessage.Chat.ID, true
+	case update.CallbackQuery != nil && update.CallbackQuery.Message != nil && update.CallbackQuery.Message.Chat != nil:
+		return update.CallbackQuery.Message.Chat.ID, true
+	case update.CallbackQuery != nil:
+		if update.CallbackQuery.From != nil {
+			return update.CallbackQuery.From.ID, true
+		}
+		return 0, false
+	case update.MyChatMember != nil:
+		return update.MyChatMember.Chat.ID, true
+	case update.ChatMember != nil:
+		return update.ChatMember.Chat.ID, true
+	}
+
+	if chat := update.FromChat(); chat != nil {
+		return chat.ID, true
+	}
+	if user := update.SentFrom(); user != nil {
+		return user.ID, true
+	}
+	return 0, false
+}
@@ -0,0 +1,136 @@
+package telegram
+
+import (
+	"errors"
+	"log/slog"
+	"path/filepath"
+	"strings"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+
+	"github.com/reelser-bot/internal/config"
+	"github.com/reelser-bot/internal/platform"
+	"github.com/reelser-bot/internal/services/fileids"
+)
+
+// cachedResultSuffix отмечает inline-результаты с уже загруженным в Telegram видео
+const cachedResultSuffix = "-cached"
+
+// fileIDCache хранит file_id отправленных видео по каноническому URL, чтобы повторные
+// запросы и inline-режим пересылали их без повторной загрузки
+type fileIDCache struct {
+	store *fileids.Store
+	// key приводит URL к ключу кэша (канонический URL)
+	key func(url string) string
+}
+
+func newFileIDCache(store *fileids.Store, key func(url string) string) *fileIDCache {
+	return &fileIDCache{store: store, key: key}
+}
+
+// get возвращает file_id видео для URL
+func (c *fileIDCache) get(url string) (string, bool) {
+	return c.store.Get(c.key(url))
+}
+
+// put запоминает file_id видео для URL
+func (c *fileIDCache) put(url, fileID string) {
+	if url == "" {
+		return
+	}
+	c.store.Put(c.key(url), fileID)
+}
+
+// forget удаляет file_id видео для URL и возвращает true, если он был сохранен
+func (c *fileIDCache) forget(url string) bool {
+	return c.store.Forget(c.key(url))
+}
+
+// fileIDStorePath возвращает файл кэша file_id или пустую строку, если кэш хранится
+// только в памяти (FILE_ID_CACHE_PERSIST=false)
+func fileIDStorePath(cfg config.StorageConfig) string {
+	if !cfg.FileIDCachePersist {
+		return ""
+	}
+	return filepath.Join(cfg.DataDir, "file_ids.json")
+}
+
+// fileIDErrors фрагменты ответов Bot API об устаревшем или недействительном file_id
+var fileIDErrors = []string{
+	"wrong file identifier",
+	"wrong remote file identifier",
+	"wrong file_id",
+	"file reference",
+	"file_reference_expired",
+	"file_reference_invalid",
+	"media_empty",
+}
+
+// isFileIDError проверяет, что Telegram отклонил отправку по file_id, потому что
+// идентификатор истек или стал недействительным
+func isFileIDError(err error) bool {
+	var apiErr *tgbotapi.Error
+	if !errors.As(err, &apiErr) {
+		return false
+	}
+
+	message := strings.ToLower(apiErr.Message)
+	for _, fragment := range fileIDErrors {
+		if strings.Contains(message, fragment) {
+			return true
+		}
+	}
+	return false
+}
+
+// forgetFileID удаляет устаревший file_id из кэша вместе с inline-результатами,
+// в которых он использовался. Следующая загрузка по ссылке сохранит новый file_id
+func (h *Handler) forgetFileID(url string, err error) bool {
+	if !h.fileIDs.forget(url) {
+		return false
+	}
+	h.inlineResults.invalidate(url)
+
+	h.logger.Warn("Cached file_id expired, removed from cache",
+		slog.String("url", url),
+		slog.Any("error", err),
+	)
+	return true
+}
+
+// rememberSentVideo сохраняет file_id доставленного видео для повторных запросов и inline-пересылки.
+// Запоминаются только целые видео обычного качества без сжатия, чтобы по ссылке не пересылалась
+// SD-версия, фрагмент или пережатая под лимит чата копия
+func (h *Handler) rememberSentVideo(req *downloadRequest, sent *tgbotapi.Message) {
+	if sent == nil || sent.Video == nil || req.audioOnly || req.section != nil || req.compressed || platform.MaxHeight(req.quality) > 0 {
+		return
+	}
+	h.fileIDs.put(req.url, sent.Video.FileID)
+	h.inlineResults.invalidate(req.url)
+}
+
+// shareMarkup возвращает кнопку "Поделиться", открывающую inline-режим с этой ссылкой
+func (h *Handler) shareMarkup(url string) *tgbotapi.InlineKeyboardMarkup {
+	if !h.shareButton || url == "" {
+		return nil
+	}
+
+	markup := tgbotapi.NewInlineKeyboardMarkup(
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonSwitch("↗ Поделиться", url),
+		),
+	)
+	return &markup
+}
+
+// cachedInlineResult возвращает inline-результат с уже загруженным видео, если он есть в кэше
+func (h *Handler) cachedInlineResult(url string) (interface{}, bool) {
+	fileID, ok := h.fileIDs.get(url)
+	if !ok {
+		return nil, false
+	}
+
+	result := tgbotapi.NewInlineQueryResultCachedVideo(inlineResultID("video", url)+cachedResultSuffix, fileID, "Отправить видео")
+	result.Description = "Видео уже скачано, отправится сразу"
+	return result, true
+}
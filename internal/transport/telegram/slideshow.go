@@ -0,0 +1,116 @@
+package telegram
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"os"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+
+	"github.com/reelser-bot/internal/platform"
+)
+
+// albumSize максимальное число медиа в одном альбоме Telegram
+const albumSize = 10
+
+// sendSlideshow отправляет слайдшоу альбомами по albumSize фото, а затем звуковую дорожку.
+// Возвращает первое сообщение альбома
+func (h *Handler) sendSlideshow(chatID int64, businessConnectionID, dir string, maxAllowed int64) (*tgbotapi.Message, error) {
+	show, err := platform.ReadSlideshow(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, path := range append(show.Images, show.Audio) {
+		if path == "" {
+			continue
+		}
+		info, err := os.Stat(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get file info: %w", err)
+		}
+		if info.Size() > maxAllowed {
+			return nil, fmt.Errorf("file size %d exceeds maximum allowed size %d", info.Size(), maxAllowed)
+		}
+	}
+
+	h.logger.Info("Sending slideshow",
+		slog.Int64("chat_id", chatID),
+		slog.String("dir", dir),
+		slog.Int("images", len(show.Images)),
+		slog.Bool("audio", show.Audio != ""),
+	)
+
+	var first *tgbotapi.Message
+	for start := 0; start < len(show.Images); start += albumSize {
+		end := min(start+albumSize, len(show.Images))
+		messages, err := h.sendPhotoAlbum(chatID, businessConnectionID, show.Images[start:end])
+		if err != nil {
+			return nil, fmt.Errorf("failed to send slideshow album: %w", err)
+		}
+		if first == nil && len(messages) > 0 {
+			first = &messages[0]
+		}
+	}
+
+	if show.Audio != "" {
+		params := tgbotapi.Params{}
+		params.AddNonEmpty("business_connection_id", businessConnectionID)
+		params.AddNonZero64("chat_id", chatID)
+		files := []tgbotapi.RequestFile{{Name: "audio", Data: tgbotapi.FilePath(show.Audio)}}
+
+		err := h.retryUploads(func() error {
+			_, err := h.bot.UploadFiles("sendAudio", params, files)
+			return err
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to send slideshow audio: %w", err)
+		}
+	}
+
+	h.logger.Info("Slideshow sent successfully", slog.Int64("chat_id", chatID))
+	return first, nil
+}
+
+// sendPhotoAlbum отправляет до albumSize фото одним альбомом (sendMediaGroup).
+// Альбом из одного фото Telegram не принимает, поэтому оно отправляется через sendPhoto
+func (h *Handler) sendPhotoAlbum(chatID int64, businessConnectionID string, images []string) ([]tgbotapi.Message, error) {
+	params := tgbotapi.Params{}
+	params.AddNonEmpty("business_connection_id", businessConnectionID)
+	params.AddNonZero64("chat_id", chatID)
+
+	if len(images) == 1 {
+		files := []tgbotapi.RequestFile{{Name: "photo", Data: tgbotapi.FilePath(images[0])}}
+		var sent tgbotapi.Message
+		err := h.retryUploads(func() error {
+			resp, err := h.bot.UploadFiles("sendPhoto", params, files)
+			if err != nil {
+				return err
+			}
+			return json.Unmarshal(resp.Result, &sent)
+		})
+		return []tgbotapi.Message{sent}, err
+	}
+
+	media := make([]map[string]string, 0, len(images))
+	files := make([]tgbotapi.RequestFile, 0, len(images))
+	for i, path := range images {
+		name := fmt.Sprintf("photo-%d", i)
+		media = append(media, map[string]string{"type": "photo", "media": "attach://" + name})
+		files = append(files, tgbotapi.RequestFile{Name: name, Data: tgbotapi.FilePath(path)})
+	}
+	if err := params.AddInterface("media", media); err != nil {
+		return nil, err
+	}
+
+	var sent []tgbotapi.Message
+	err := h.retryUploads(func() error {
+		resp, err := h.bot.UploadFiles("sendMediaGroup", params, files)
+		if err != nil {
+			return err
+		}
+		return json.Unmarshal(resp.Result, &sent)
+	})
+	return sent, err
+}
@@ -0,0 +1,222 @@
+package telegram
+
+import (
+	"errors"
+	"log/slog"
+	"strings"
+	"sync"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// slowModeTTL как долго доверять сохраненной задержке медленного режима чата
+const slowModeTTL = 10 * time.Minute
+
+// slowModeState медленный режим одного чата
+type slowModeState struct {
+	// delay минимальный интервал между сообщениями бота; 0 — ограничения нет
+	// (медленный режим выключен или бот администратор)
+	delay     time.Duration
+	checkedAt time.Time
+	// next момент, раньше которого следующую отправку начинать нельзя
+	next time.Time
+}
+
+// slowModes задержки медленного режима групп. Отправки в такой чат выстраиваются
+// с интервалом delay, чтобы несколько видео подряд не отклонялись Telegram
+type slowModes struct {
+	mu    sync.Mutex
+	chats map[int64]*slowModeState
+}
+
+func newSlowModes() *slowModes {
+	return &slowModes{chats: make(map[int64]*slowModeState)}
+}
+
+// reserve занимает следующий слот отправки в чат и возвращает, сколько до него ждать.
+// known == false — задержка чата неизвестна или устарела и ее нужно запросить
+func (s *slowModes) reserve(chatID int64, now time.Time) (wait time.Duration, known bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	state, ok := s.chats[chatID]
+	if !ok || now.Sub(state.checkedAt) > slowModeTTL {
+		return 0, false
+	}
+	if state.delay <= 0 {
+		return 0, true
+	}
+
+	slot := now
+	if state.next.After(slot) {
+		slot = state.next
+	}
+	state.next = slot.Add(state.delay)
+	return slot.Sub(now), true
+}
+
+// set сохраняет задержку чата. Задержка из ошибки Telegram (retryAfter > 0) сдвигает
+// следующий слот, а не только интервал
+func (s *slowModes) set(chatID int64, delay, retryAfter time.Duration, now time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	state, ok := s.chats[chatID]
+	if !ok {
+		state = &slowModeState{}
+		s.chats[chatID] = state
+	}
+	state.delay = delay
+	state.checkedAt = now
+	if next := now.Add(retryAfter); next.After(state.next) {
+		state.next = next
+	}
+}
+
+// deliverVideo отправляет видео с учетом медленного режима группы: ждет своего слота,
+// а если Telegram все равно отклонил отправку из-за медленного режима, ждет указанное
+// время и повторяет ее один раз
+func (h *Handler) deliverVideo(req *downloadRequest, filePath string, maxAllowed int64, caption string) (*tgbotapi.Message, error) {
+	if !h.waitSlowMode(req) {
+		return nil, req.phaseContext().Err()
+	}
+
+	sent, err := h.deliverVideoOnce(req, filePath, maxAllowed, caption)
+	retryAfter, slow := slowModeError(err)
+	if !slow || !isGroupChatType(req.chatType) {
+		return sent, err
+	}
+
+	delay := max(h.slowModeDelay(req.chatID), retryAfter)
+	h.slowModes.set(req.chatID, delay, retryAfter, time.Now())
+	h.logger.Warn("Delivery hit slow mode, retrying",
+		slog.Int64("chat_id", req.chatID),
+		slog.Duration("retry_after", retryAfter),
+		slog.Any("error", err),
+	)
+
+	if !h.waitSlowMode(req) {
+		return nil, req.phaseContext().Err()
+	}
+	return h.deliverVideoOnce(req, filePath, maxAllowed, caption)
+}
+
+// deferForSlowMode занимает слот отправки в группу с медленным режимом до начала
+// загрузки. Если слот наступит не сразу, задача возвращается в очередь к его началу,
+// а не держит воркер в ожидании. true — задача отложена
+func (h *Handler) deferForSlowMode(req *downloadRequest) bool {
+	if req.slowModeSlot || !isGroupChatType(req.chatType) || req.businessConnectionID != "" {
+		return false
+	}
+
+	wait := h.reserveSlowMode(req.chatID)
+	req.slowModeSlot = true
+	if wait <= 0 {
+		return false
+	}
+
+	h.logger.Info("Deferring download until the slow mode slot",
+		slog.Int64("chat_id", req.chatID),
+		slog.String("request_id", req.id),
+		slog.Duration("wait", wait),
+	)
+	h.queue.done(req)
+	req.cancel()
+	h.scheduleDownload(req, wait)
+	return true
+}
+
+// waitSlowMode ждет слота отправки в группу с медленным режимом. Слот, занятый до
+// загрузки, используется без ожидания. Возвращает false, если запрос отменен во время
+// ожидания
+func (h *Handler) waitSlowMode(req *downloadRequest) bool {
+	if !isGroupChatType(req.chatType) || req.businessConnectionID != "" {
+		return true
+	}
+	if req.slowModeSlot {
+		req.slowModeSlot = false
+		return true
+	}
+
+	wait := h.reserveSlowMode(req.chatID)
+	if wait <= 0 {
+		return true
+	}
+
+	h.logger.Info("Waiting for slow mode before delivery",
+		slog.Int64("chat_id", req.chatID),
+		slog.Duration("wait", wait),
+	)
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return true
+	case <-req.phaseContext().Done():
+		return false
+	}
+}
+
+// reserveSlowMode занимает следующий слот отправки в чат и возвращает, сколько до него
+// ждать. Неизвестная или устаревшая задержка чата запрашивается у Telegram
+func (h *Handler) reserveSlowMode(chatID int64) time.Duration {
+	wait, known := h.slowModes.reserve(chatID, time.Now())
+	if !known {
+		h.slowModes.set(chatID, h.slowModeDelay(chatID), 0, time.Now())
+		wait, _ = h.slowModes.reserve(chatID, time.Now())
+	}
+	return wait
+}
+
+// slowModeDelay запрашивает задержку медленного режима чата. Администраторов медленный
+// режим не ограничивает, поэтому для них задержка нулевая
+func (h *Handler) slowModeDelay(chatID int64) time.Duration {
+	chat, err := h.bot.GetChat(tgbotapi.ChatInfoConfig{ChatConfig: tgbotapi.ChatConfig{ChatID: chatID}})
+	if err != nil {
+		h.logger.Warn("Failed to get chat slow mode",
+			slog.Int64("chat_id", chatID),
+			slog.Any("error", err),
+		)
+		return 0
+	}
+	if chat.SlowModeDelay <= 0 {
+		return 0
+	}
+
+	member, err := h.bot.GetChatMember(tgbotapi.GetChatMemberConfig{
+		ChatConfigWithUser: tgbotapi.ChatConfigWithUser{ChatID: chatID, UserID: h.bot.Self.ID},
+	})
+	if err == nil && (member.IsAdministrator() || member.IsCreator()) {
+		return 0
+	}
+	return time.Duration(chat.SlowModeDelay) * time.Second
+}
+
+// slowModeError определяет, что отправка отклонена медленным режимом или лимитом частоты,
+// и сколько ждать по retry_after
+func slowModeError(err error) (time.Duration, bool) {
+	if err == nil {
+		return 0, false
+	}
+
+	var statusErr *retryableStatusError
+	if errors.As(err, &statusErr) && statusErr.RetryAfter > 0 {
+		return statusErr.RetryAfter, true
+	}
+
+	var apiErr *tgbotapi.Error
+	if errors.As(err, &apiErr) {
+		message := strings.ToLower(apiErr.Message)
+		if apiErr.RetryAfter > 0 || strings.Contains(message, "slow mode") || strings.Contains(message, "slowmode") {
+			return time.Duration(apiErr.RetryAfter) * time.Second, true
+		}
+	}
+	return 0, false
+}
+
+// isGroupChatType проверяет, что тип чата — группа (медленный режим есть только в них)
+func isGroupChatType(chatType string) bool {
+	return chatType == "group" || chatType == "supergroup"
+}
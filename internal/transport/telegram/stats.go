@@ -0,0 +1,44 @@
+package telegram
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// handleStatsCommand показывает администратору состояние бота: /stats
+func (h *Handler) handleStatsCommand(message *tgbotapi.Message) {
+	var b strings.Builder
+	b.WriteString("📊 <b>Статистика</b>\n")
+
+	pending, active, deferred := h.queue.occupancy()
+	fmt.Fprintf(&b, "\n📥 Очередь загрузок: %d из %d, в работе %d из %d", pending, h.queueSizeLimit, active, h.scaler.current())
+	if h.scaler.enabled() {
+		fmt.Fprintf(&b, " (пул %d–%d)", h.scaler.min, h.scaler.max)
+	}
+	if deferred > 0 {
+		fmt.Fprintf(&b, ", отложено %d", deferred)
+	}
+	if h.updateQueue != nil {
+		fmt.Fprintf(&b, "\n📨 Очередь апдейтов: %d из %d", len(h.updateQueue), cap(h.updateQueue))
+	}
+	b.WriteString(h.pressureStatus())
+
+	if h.bandwidth.Enabled() {
+		remaining := h.bandwidth.Remaining()
+		fmt.Fprintf(&b, "\n📶 Бюджет трафика: %.2f из %.0f GB в сутки",
+			float64(max(remaining, 0))/(1024*1024*1024),
+			float64(h.bandwidth.PerDay())/(1024*1024*1024),
+		)
+		if remaining <= 0 {
+			fmt.Fprintf(&b, "\n⛔ Исчерпан, восполнится через %s", formatAge(h.bandwidth.RetryAfter().Round(time.Second)))
+		}
+	} else {
+		b.WriteString("\n📶 Бюджет трафика: без ограничения")
+	}
+	fmt.Fprintf(&b, "\n📎 Кэш file_id: %d видео", h.fileIDs.store.Len())
+
+	h.sendMessage(message.Chat.ID, b.String())
+}
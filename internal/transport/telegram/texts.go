@@ -0,0 +1,187 @@
+package telegram
+
+import (
+	"bytes"
+	"log/slog"
+	"os"
+	"strings"
+	"text/template"
+
+	"github.com/reelser-bot/internal/config"
+	"github.com/reelser-bot/internal/services/downloader"
+)
+
+// defaultStartTemplate текст /start по умолчанию
+const defaultStartTemplate = `👋 Привет! Я бот для скачивания видео.
+
+Отправь мне ссылку на видео с:
+{{range .Platforms}}• {{.Title}}{{if .Note}} ({{.Note}}){{end}}
+{{end}}
+И я скачаю и отправлю тебе видео!
+{{- if .Rules}}
+
+📜 Правила:
+{{.Rules}}{{end}}
+{{- if .Contact}}
+
+✉️ Связь: {{.Contact}}{{end}}`
+
+// defaultHelpTemplate текст /help по умолчанию
+const defaultHelpTemplate = `📖 Помощь
+
+Доступные команды:
+/start - Начать работу с ботом
+/help - Показать эту справку
+/link_channel - Публиковать видео из личного чата в свой канал
+/unlink_channel - Отвязать личный канал
+/audio - Скачать только звук в MP3: /audio ссылка
+/gif - Короткий ролик как GIF-анимация без звука: /gif ссылка
+/circle - Видео «кружком» (видеосообщение до минуты): /circle ссылка
+/file - Видео файлом, без пережатия Telegram: /file ссылка
+/document - Присылать все видео файлом: /document on или off
+/chapters - Видео YouTube по главам: /chapters ссылка
+/info - Сведения о видео без загрузки: /info ссылка
+/top - Самые активные участники чата и популярные платформы: /top или /top month
+/cancel_all - Отменить все свои запросы в очереди
+/timezone - Часовой пояс чата: /timezone Europe/Moscow
+/status - Закрепленный статус бота в группе: /status on или off
+/version - Версия бота и yt-dlp (пригодится для сообщения об ошибке)
+
+Как использовать:
+Просто отправь ссылку на видео, и я скачаю его для тебя!
+После ссылки можно добавить параметры <code>720p</code>, <code>audio</code>, <code>file</code>, <code>nocaption</code>,
+например: <code>ссылка 720p nocaption</code>
+{{- if .Enabled "vimeo"}}
+Для видео Vimeo с паролем добавь пароль после ссылки: <code>ссылка pass:1234</code>{{end}}
+
+Поддерживаемые платформы:
+{{- range .Platforms}}
+• {{.Title}}{{if .Hosts}} ({{join .Hosts ", "}}){{else if .Note}} ({{.Note}}){{end}}{{end}}
+{{- if .Rules}}
+
+📜 Правила:
+{{.Rules}}{{end}}
+{{- if .Contact}}
+
+✉️ Связь: {{.Contact}}{{end}}`
+
+// textData данные, доступные в шаблонах /start и /help
+type textData struct {
+	Platforms []downloader.PlatformInfo
+	Rules     string
+	Contact   string
+}
+
+// Enabled проверяет, включена ли платформа с указанным идентификатором
+func (d textData) Enabled(name string) bool {
+	for _, p := range d.Platforms {
+		if p.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// botTexts содержит отрендеренные тексты команд /start и /help
+// и производные от списка платформ подсказки
+type botTexts struct {
+	start string
+	help  string
+
+	// inlineDescription описание подсказки inline-режима без ссылки
+	inlineDescription string
+	// urlHints домены платформ для распознавания ссылок без схемы
+	urlHints []string
+}
+
+// newBotTexts рендерит тексты /start и /help из шаблонов конфигурации или встроенных.
+// При ошибке в пользовательском шаблоне используется встроенный
+func newBotTexts(logger *slog.Logger, cfg config.TextsConfig, platforms []downloader.PlatformInfo) botTexts {
+	data := textData{
+		Platforms: platforms,
+		Rules:     cfg.Rules,
+		Contact:   cfg.Contact,
+	}
+
+	return botTexts{
+		start: renderText(logger, "start", cfg.StartTemplateFile, defaultStartTemplate, data),
+		help:  renderText(logger, "help", cfg.HelpTemplateFile, defaultHelpTemplate, data),
+
+		inlineDescription: inlineDescription(platforms),
+		urlHints:          urlHints(platforms),
+	}
+}
+
+// inlineDescription формирует перечисление платформ для inline-подсказки
+func inlineDescription(platforms []downloader.PlatformInfo) string {
+	titles := make([]string, 0, len(platforms))
+	for _, p := range platforms {
+		titles = append(titles, p.Title)
+	}
+
+	switch len(titles) {
+	case 0:
+		return "Нет доступных платформ"
+	case 1:
+		return "Поддерживается " + titles[0]
+	default:
+		return "Поддерживаются " + strings.Join(titles[:len(titles)-1], ", ") + " и " + titles[len(titles)-1]
+	}
+}
+
+// urlHints собирает домены платформ; шаблоны путей вида twitch.tv/.../clip/... обрезаются до домена
+func urlHints(platforms []downloader.PlatformInfo) []string {
+	var hints []string
+	for _, p := range platforms {
+		for _, host := range p.Hosts {
+			if i := strings.Index(host, "/..."); i >= 0 {
+				host = host[:i]
+			}
+			hints = append(hints, host)
+		}
+	}
+	return hints
+}
+
+// renderText рендерит шаблон из файла или встроенный шаблон
+func renderText(logger *slog.Logger, name, file, fallback string, data textData) string {
+	if file != "" {
+		content, err := os.ReadFile(file)
+		if err == nil {
+			var text string
+			text, err = executeText(name, string(content), data)
+			if err == nil {
+				return text
+			}
+		}
+		logger.Error("Failed to render text template, using default",
+			slog.String("template", name),
+			slog.String("file", file),
+			slog.Any("error", err),
+		)
+	}
+
+	text, err := executeText(name, fallback, data)
+	if err != nil {
+		// Встроенные шаблоны проверены, сюда попадать не должны
+		logger.Error("Failed to render default text template",
+			slog.String("template", name),
+			slog.Any("error", err),
+		)
+	}
+	return text
+}
+
+// executeText разбирает и выполняет шаблон
+func executeText(name, text string, data textData) (string, error) {
+	tmpl, err := template.New(name).Funcs(template.FuncMap{"join": strings.Join}).Parse(text)
+	if err != nil {
+		return "", err
+	}
+
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(buf.String()), nil
+}
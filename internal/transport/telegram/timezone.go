@@ -0,0 +1,95 @@
+package telegram
+
+import (
+	"fmt"
+	"html"
+	"log/slog"
+	"strings"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+
+	"github.com/reelser-bot/internal/services/chatsettings"
+)
+
+// defaultLocation возвращает часовой пояс DEFAULT_TIMEZONE или время сервера.
+// Значение уже проверено при загрузке конфигурации
+func defaultLocation(name string) *time.Location {
+	if name == "" {
+		return time.Local
+	}
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		return time.Local
+	}
+	return loc
+}
+
+// chatLocation возвращает часовой пояс чата: заданный через /timezone или DEFAULT_TIMEZONE.
+// По нему считаются тихие часы, границы дней в /top и интервалы /replay
+func (h *Handler) chatLocation(chatID int64) *time.Location {
+	name := h.chatSettings.Get(chatID).Timezone
+	if name == "" {
+		return h.defaultLocation
+	}
+
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		h.logger.Warn("Invalid chat timezone, using default",
+			slog.Int64("chat_id", chatID),
+			slog.String("timezone", name),
+			slog.Any("error", err),
+		)
+		return h.defaultLocation
+	}
+	return loc
+}
+
+// handleTimezoneCommand настраивает часовой пояс чата: /timezone Europe/Moscow,
+// /timezone off для возврата к поясу по умолчанию или /timezone для просмотра
+func (h *Handler) handleTimezoneCommand(message *tgbotapi.Message) {
+	chatID := message.Chat.ID
+
+	arg := strings.TrimSpace(message.CommandArguments())
+	if arg == "" {
+		loc := h.chatLocation(chatID)
+		h.sendMessage(chatID, fmt.Sprintf(
+			"🕒 Часовой пояс чата: %s (сейчас %s).\nИзменить: /timezone Europe/Moscow, сбросить: /timezone off",
+			html.EscapeString(loc.String()),
+			h.locale(userLanguage(message.From)).clockTime(time.Now().In(loc)),
+		))
+		return
+	}
+
+	if message.Chat.Type != "private" && !h.isChatAdmin(message) {
+		h.sendMessage(chatID, "🔒 Менять часовой пояс могут только администраторы чата.")
+		return
+	}
+
+	if strings.EqualFold(arg, "off") {
+		h.chatSettings.Update(chatID, func(s *chatsettings.Settings) { s.Timezone = "" })
+		h.sendMessage(chatID, fmt.Sprintf("✅ Используется часовой пояс по умолчанию: %s.", html.EscapeString(h.defaultLocation.String())))
+		return
+	}
+
+	loc, err := time.LoadLocation(arg)
+	if err != nil || strings.EqualFold(arg, "local") {
+		h.sendMessage(chatID, "❌ Неизвестный часовой пояс. Укажи его в формате IANA, "+
+			"например <code>Europe/Moscow</code> или <code>Asia/Almaty</code>.")
+		return
+	}
+
+	h.chatSettings.Update(chatID, func(s *chatsettings.Settings) { s.Timezone = loc.String() })
+
+	userID, _ := senderID(message)
+	h.logger.Info("Chat timezone updated",
+		slog.Int64("chat_id", chatID),
+		slog.Int64("user_id", userID),
+		slog.String("timezone", loc.String()),
+	)
+	h.sendMessage(chatID, fmt.Sprintf(
+		"✅ Часовой пояс чата: %s (сейчас %s). По нему считаются тихие часы и границы дней в /top.",
+		html.EscapeString(loc.String()),
+		h.locale(userLanguage(message.From)).clockTime(time.Now().In(loc)),
+	))
+}
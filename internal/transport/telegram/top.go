@@ -0,0 +1,92 @@
+package telegram
+
+import (
+	"fmt"
+	"html"
+	"strings"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+
+	"github.com/reelser-bot/internal/services/stats"
+)
+
+// topLimit количество мест в рейтинге /top
+const topLimit = 5
+
+// topMedals значки первых мест рейтинга
+var topMedals = []string{"🥇", "🥈", "🥉"}
+
+// recordDownload учитывает успешную загрузку в статистике чата для /top
+func (h *Handler) recordDownload(req *downloadRequest) {
+	h.stats.Record(stats.Download{
+		ChatID:   req.chatID,
+		UserID:   req.userID,
+		UserName: req.userName,
+		Platform: h.downloader.PlatformTitle(req.url),
+		Location: h.chatLocation(req.chatID),
+	})
+}
+
+// handleTopCommand показывает самых активных участников чата и популярные платформы:
+// /top за неделю, /top month за месяц
+func (h *Handler) handleTopCommand(message *tgbotapi.Message) {
+	chatID := message.Chat.ID
+
+	days, period := 7, "неделю"
+	switch strings.ToLower(strings.TrimSpace(message.CommandArguments())) {
+	case "", "week", "неделя":
+	case "month", "месяц":
+		days, period = 30, "месяц"
+	default:
+		h.sendMessage(chatID, "Использование: /top — рейтинг за неделю, /top month — за месяц.")
+		return
+	}
+
+	top := h.stats.Top(chatID, days, topLimit, h.chatLocation(chatID))
+	if top.Total == 0 {
+		h.sendMessage(chatID, fmt.Sprintf("📊 За %s в этом чате ещё ничего не скачивали.", period))
+		return
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "🏆 <b>Топ чата за %s</b>\n\nВсего загрузок: %d\n", period, top.Total)
+
+	if len(top.Users) > 0 {
+		b.WriteString("\n👥 <b>Самые активные</b>\n")
+		for i, user := range top.Users {
+			name := user.Name
+			if name == "" {
+				name = fmt.Sprintf("id%d", user.UserID)
+			}
+			fmt.Fprintf(&b, "%s %s — %d\n", topPlace(i), html.EscapeString(name), user.Count)
+		}
+	}
+
+	b.WriteString("\n📺 <b>Платформы</b>\n")
+	for i, platform := range top.Platforms {
+		fmt.Fprintf(&b, "%s %s — %d\n", topPlace(i), html.EscapeString(platform.Platform), platform.Count)
+	}
+
+	h.sendMessage(chatID, strings.TrimSuffix(b.String(), "\n"))
+}
+
+// topPlace возвращает обозначение места в рейтинге
+func topPlace(i int) string {
+	if i < len(topMedals) {
+		return topMedals[i]
+	}
+	return fmt.Sprintf("%d.", i+1)
+}
+
+// displayName возвращает имя пользователя для рейтингов
+func displayName(user *tgbotapi.User) string {
+	if user == nil {
+		return ""
+	}
+
+	name := strings.TrimSpace(user.FirstName + " " + user.LastName)
+	if name == "" && user.UserName != "" {
+		name = "@" + user.UserName
+	}
+	return name
+}
@@ -0,0 +1,227 @@
+package telegram
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+
+	"github.com/reelser-bot/internal/storage"
+)
+
+const (
+	// pollTimeout таймаут long polling в секундах
+	pollTimeout = 60
+	// offsetMaxAge срок, после которого сохраненный update_id не используется: если
+	// у бота неделю не было апдейтов, Telegram выбирает следующий update_id случайно
+	offsetMaxAge = 7 * 24 * time.Hour
+	// pollRetryDelay пауза перед повтором getUpdates после ошибки
+	pollRetryDelay = 3 * time.Second
+	// pollPendingDelay пауза перед повтором getUpdates, если Telegram вернул только
+	// апдейты, которые еще обрабатываются
+	pollPendingDelay = time.Second
+)
+
+// updateOffset последний обработанный апдейт, сохраняемый между перезапусками
+type updateOffset struct {
+	LastUpdateID int       `json:"last_update_id"`
+	UpdatedAt    time.Time `json:"updated_at"`
+}
+
+// loadOffset восстанавливает ID последнего обработанного апдейта. Без него Telegram
+// после перезапуска повторно присылает апдейты последней неподтвержденной пачки,
+// и пользователи получают видео дважды
+func (b *Bot) loadOffset() {
+	if b.offsetFile == "" {
+		return
+	}
+
+	var saved updateOffset
+	if err := storage.LoadJSON(b.offsetFile, &saved); err != nil {
+		b.logger.Warn("Failed to load update offset", slog.String("file", b.offsetFile), slog.Any("error", err))
+		return
+	}
+	if saved.LastUpdateID == 0 || time.Since(saved.UpdatedAt) > offsetMaxAge {
+		return
+	}
+
+	b.offsetMu.Lock()
+	b.lastUpdateID = saved.LastUpdateID
+	b.savedUpdateID = saved.LastUpdateID
+	b.offsetMu.Unlock()
+
+	b.logger.Info("Resuming updates after the last processed one", slog.Int("update_id", saved.LastUpdateID))
+}
+
+// markUpdate отмечает апдейт полученным. false — апдейт уже был получен: после
+// переподключения или смены вебхука на polling Telegram может прислать его повторно.
+// Полученный апдейт считается необработанным, пока не вызван finishUpdate
+func (b *Bot) markUpdate(updateID int) bool {
+	b.offsetMu.Lock()
+	defer b.offsetMu.Unlock()
+
+	if updateID <= b.lastUpdateID {
+		b.logger.Debug("Skipping duplicate update", slog.Int("update_id", updateID))
+		return false
+	}
+	b.lastUpdateID = updateID
+	b.pendingUpdates[updateID] = struct{}{}
+	return true
+}
+
+// finishUpdate отмечает апдейт обработанным (или отброшенным) и сохраняет offset,
+// если все апдейты до него тоже обработаны. Апдейты других экземпляров при
+// шардировании не отмечались полученными и пропускаются
+func (b *Bot) finishUpdate(updateID int) {
+	b.offsetMu.Lock()
+	defer b.offsetMu.Unlock()
+
+	if _, ok := b.pendingUpdates[updateID]; !ok {
+		return
+	}
+	delete(b.pendingUpdates, updateID)
+	b.saveOffsetLocked()
+}
+
+// processedUpdateID возвращает ID, до которого включительно все полученные апдейты
+// обработаны. Вызывается под offsetMu
+func (b *Bot) processedUpdateID() int {
+	processed := b.lastUpdateID
+	for id := range b.pendingUpdates {
+		if id <= processed {
+			processed = id - 1
+		}
+	}
+	return processed
+}
+
+// nextOffset возвращает offset для getUpdates (0 — апдейты еще не получались).
+// Offset подтверждает Telegram только обработанные апдейты: если бот остановится
+// посреди обработки, необработанные апдейты придут снова после перезапуска
+func (b *Bot) nextOffset() int {
+	b.offsetMu.Lock()
+	defer b.offsetMu.Unlock()
+
+	if b.lastUpdateID == 0 {
+		return 0
+	}
+	return b.processedUpdateID() + 1
+}
+
+// saveOffsetLocked сохраняет ID последнего обработанного апдейта, если он изменился.
+// Апдейт, переданный обработчику, но не обработанный до остановки, не сохраняется:
+// после перезапуска Telegram пришлет его снова. Вызывается под offsetMu
+func (b *Bot) saveOffsetLocked() {
+	processed := b.processedUpdateID()
+	if b.offsetFile == "" || processed <= b.savedUpdateID {
+		return
+	}
+
+	if err := storage.SaveJSON(b.offsetFile, updateOffset{LastUpdateID: processed, UpdatedAt: time.Now()}); err != nil {
+		b.logger.Warn("Failed to persist update offset", slog.String("file", b.offsetFile), slog.Any("error", err))
+		return
+	}
+	b.savedUpdateID = processed
+}
+
+// incomingUpdate расширяет tgbotapi.Update полями, которые библиотека не поддерживает
+type incomingUpdate struct {
+	tgbotapi.Update
+	BusinessMessage *businessMessage `json:"business_message,omitempty"`
+}
+
+// businessMessage сообщение, полученное через подключенный Telegram Business аккаунт
+type businessMessage struct {
+	tgbotapi.Message
+	BusinessConnectionID string `json:"business_connection_id"`
+}
+
+// pollUpdates получает апдейты через getUpdates с поддержкой полей, неизвестных tgbotapi
+func (b *Bot) pollUpdates(ctx context.Context) <-chan incomingUpdate {
+	ch := make(chan incomingUpdate, b.updateWorkers*2)
+
+	go func() {
+		defer close(ch)
+		b.poll(ctx, ch)
+	}()
+
+	return ch
+}
+
+// poll получает апдейты через long polling в ch, пока не отменен ctx
+func (b *Bot) poll(ctx context.Context, ch chan<- incomingUpdate) {
+	for ctx.Err() == nil {
+		params := tgbotapi.Params{}
+		params.AddNonZero("offset", b.nextOffset())
+		params.AddNonZero("timeout", pollTimeout)
+
+		resp, err := b.api.MakeRequest("getUpdates", params)
+		if err != nil {
+			b.logger.Warn("Failed to get updates, retrying in 3 seconds...", slog.Any("error", err))
+			if sleepCtx(ctx, pollRetryDelay) != nil {
+				return
+			}
+			continue
+		}
+
+		var batch []json.RawMessage
+		if err := json.Unmarshal(resp.Result, &batch); err != nil {
+			b.logger.Error("Failed to decode updates, retrying in 3 seconds...", slog.Any("error", err))
+			if sleepCtx(ctx, pollRetryDelay) != nil {
+				return
+			}
+			continue
+		}
+
+		received := 0
+		for _, data := range batch {
+			update, ok := b.decodeUpdate(data)
+			// Неразобранный апдейт тоже отмечается полученным и сразу обработанным,
+			// чтобы Telegram не присылал его снова и не задерживал следующие
+			if update.UpdateID == 0 || !b.markUpdate(update.UpdateID) {
+				continue
+			}
+			received++
+			if !ok {
+				b.finishUpdate(update.UpdateID)
+				continue
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case ch <- update:
+			}
+		}
+
+		// Неподтвержденные апдейты Telegram возвращает сразу, без ожидания long polling
+		if len(batch) > 0 && received == 0 && sleepCtx(ctx, pollPendingDelay) != nil {
+			return
+		}
+	}
+}
+
+// decodeUpdate разбирает апдейт из пачки getUpdates. Если апдейт разобрать не удалось,
+// возвращается false и по возможности его update_id
+func (b *Bot) decodeUpdate(data json.RawMessage) (incomingUpdate, bool) {
+	var update incomingUpdate
+	err := json.Unmarshal(data, &update)
+	if err == nil {
+		return update, true
+	}
+
+	var id struct {
+		UpdateID int `json:"update_id"`
+	}
+	if idErr := json.Unmarshal(data, &id); idErr != nil {
+		b.logger.Error("Failed to decode update without ID, skipping", slog.Any("error", err))
+		return incomingUpdate{}, false
+	}
+	b.logger.Error("Failed to decode update, skipping",
+		slog.Int("update_id", id.UpdateID),
+		slog.Any("error", err),
+	)
+	return incomingUpdate{Update: tgbotapi.Update{UpdateID: id.UpdateID}}, false
+}
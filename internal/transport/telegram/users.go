@@ -0,0 +1,274 @@
+package telegram
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"html"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+
+	"github.com/reelser-bot/internal/clock"
+	"github.com/reelser-bot/internal/config"
+	"github.com/reelser-bot/internal/services/users"
+)
+
+// Ограничения импорта CSV, присланного в Telegram
+const (
+	maxImportFileSize = 1 << 20
+	importTimeout     = 30 * time.Second
+)
+
+// errImportFileTooBig файл импорта больше maxImportFileSize
+var errImportFileTooBig = fmt.Errorf("файл больше %d КБ", maxImportFileSize>>10)
+
+// newUsersStore создает хранилище импортированных пользователей. Предупреждение
+// о квоте использует тот же порог, что и предупреждение о бюджете трафика
+func newUsersStore(logger *slog.Logger, cfg *config.Config) *users.Store {
+	return users.NewStore(
+		logger,
+		filepath.Join(cfg.Storage.DataDir, "users.json"),
+		cfg.Download.BandwidthWarnPercent,
+		clock.Real{},
+	)
+}
+
+// importUsers сохраняет пользователей из импорта и выдает им доступ: всем — как
+// разрешенным пользователям, ролям admin — права администратора. Если прошлый импорт
+// выдал пользователю роль admin, а новый — user, права администратора отзываются
+// (кроме ADMIN_IDS). Администраторов, назначенных не импортом, импорт не понижает
+func (h *Handler) importUsers(records []users.Record) (added, updated int) {
+	result := h.users.Import(records)
+	h.grantImportedRoles(records)
+	h.revokeImportedAdmins(result.Demoted)
+	return result.Added, result.Updated
+}
+
+// grantImportedRoles выдает доступ и права администратора импортированным пользователям
+func (h *Handler) grantImportedRoles(records []users.Record) {
+	if h.auth == nil {
+		return
+	}
+	for _, record := range records {
+		h.auth.Allow(record.UserID)
+		if record.Role == users.RoleAdmin && !h.auth.IsAdmin(record.UserID) {
+			h.auth.AddAdmin(record.UserID)
+		}
+	}
+}
+
+// revokeImportedAdmins отзывает права администратора, выданные прошлым импортом
+func (h *Handler) revokeImportedAdmins(demoted []int64) {
+	if h.auth == nil {
+		return
+	}
+	for _, userID := range demoted {
+		if !h.auth.RemoveAdmin(userID) {
+			h.logger.Warn("Imported user is listed in ADMIN_IDS, admin rights kept", slog.Int64("user_id", userID))
+			continue
+		}
+		h.logger.Info("Admin rights revoked by users import", slog.Int64("user_id", userID))
+	}
+}
+
+// importUsersFromFile импортирует пользователей из CSV при запуске (USERS_IMPORT_FILE).
+// Повторный запуск с тем же файлом ничего не меняет
+func (h *Handler) importUsersFromFile(path string) {
+	file, err := os.Open(path)
+	if err != nil {
+		h.logger.Error("Failed to open users import file", slog.String("file", path), slog.Any("error", err))
+		return
+	}
+	defer file.Close()
+
+	records, err := users.ParseCSV(file)
+	if err != nil {
+		h.logger.Error("Users import file is invalid, nothing imported",
+			slog.String("file", path),
+			slog.Any("error", err),
+		)
+		return
+	}
+
+	added, updated := h.importUsers(records)
+	h.logger.Info("Users imported from file",
+		slog.String("file", path),
+		slog.Int("records", len(records)),
+		slog.Int("added", added),
+		slog.Int("updated", updated),
+	)
+}
+
+// handleImportUsersCommand импортирует пользователей из CSV: строки после команды или
+// CSV-файл в сообщении, на которое ответили командой
+func (h *Handler) handleImportUsersCommand(ctx context.Context, message *tgbotapi.Message) {
+	chatID := message.Chat.ID
+
+	var source io.Reader
+	if args := strings.TrimSpace(message.CommandArguments()); args != "" {
+		source = strings.NewReader(args)
+	} else if reply := message.ReplyToMessage; reply != nil && reply.Document != nil {
+		data, err := h.downloadImportFile(ctx, reply.Document)
+		if errors.Is(err, errImportFileTooBig) {
+			h.sendMessage(chatID, "❌ Не удалось получить файл: "+err.Error())
+			return
+		}
+		if err != nil {
+			h.logger.Warn("Failed to download users import file", slog.Any("error", err))
+			h.sendMessage(chatID, "❌ Не удалось получить файл из Telegram, попробуй еще раз.")
+			return
+		}
+		source = strings.NewReader(data)
+	} else {
+		h.sendMessage(chatID, "Использование: ответь командой /import_users на CSV-файл или перечисли строки после команды:\n"+
+			"<code>/import_users\nuser_id,role,daily_quota\n123456789,admin\n987654321,user,20</code>\n"+
+			"Роль — user или admin, квота — ссылок в сутки (пусто — без ограничения).")
+		return
+	}
+
+	records, err := users.ParseCSV(source)
+	if err != nil {
+		h.sendMessage(chatID, "❌ Ничего не импортировано, исправь ошибки и пришли файл снова:\n"+
+			html.EscapeString(err.Error()))
+		return
+	}
+
+	added, updated := h.importUsers(records)
+
+	var admins, limited int
+	for _, record := range records {
+		if record.Role == users.RoleAdmin {
+			admins++
+		}
+		if record.DailyQuota > 0 {
+			limited++
+		}
+	}
+
+	h.logger.Info("Users imported by admin",
+		slog.Int64("admin_id", message.From.ID),
+		slog.Int("records", len(records)),
+		slog.Int("added", added),
+		slog.Int("updated", updated),
+	)
+	h.sendMessage(chatID, fmt.Sprintf(
+		"✅ Импорт завершен: %d записей, новых %d, изменено %d.\n👑 Администраторов: %d\n🎟 С суточной квотой: %d",
+		len(records), added, updated, admins, limited,
+	))
+}
+
+// downloadImportFile скачивает CSV-файл, присланный администратору. Адрес файла
+// содержит токен бота, поэтому в ошибки он не попадает
+func (h *Handler) downloadImportFile(ctx context.Context, doc *tgbotapi.Document) (string, error) {
+	if doc.FileSize > maxImportFileSize {
+		return "", errImportFileTooBig
+	}
+
+	fileURL, err := h.bot.GetFileDirectURL(doc.FileID)
+	if err != nil {
+		return "", withoutURL(err)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, importTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, fileURL, nil)
+	if err != nil {
+		return "", withoutURL(err)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return "", withoutURL(err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("unexpected status %s", resp.Status)
+	}
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxImportFileSize+1))
+	if err != nil {
+		return "", err
+	}
+	if len(data) > maxImportFileSize {
+		return "", errImportFileTooBig
+	}
+	return string(data), nil
+}
+
+// withoutURL убирает из ошибки HTTP-клиента адрес запроса: адреса Bot API содержат токен
+func withoutURL(err error) error {
+	var urlErr *url.Error
+	if errors.As(err, &urlErr) {
+		return fmt.Errorf("%s request failed: %w", urlErr.Op, urlErr.Err)
+	}
+	return err
+}
+
+// checkQuota учитывает ссылку в суточной квоте пользователя из импорта и сообщает, если
+// квота исчерпана. Учтенная ссылка отмечается в spec, чтобы вернуть ее в квоту, если
+// запрос не будет выполнен. Администраторы не ограничиваются
+func (h *Handler) checkQuota(spec *requestSpec) bool {
+	if h.auth.IsAdmin(spec.userID) {
+		return true
+	}
+
+	quota, ok := h.users.Take(spec.userID)
+	if ok {
+		spec.quotaTaken = quota > 0
+		return true
+	}
+
+	h.logger.Info("Daily quota exhausted",
+		slog.Int64("user_id", spec.userID),
+		slog.Int("quota", quota),
+	)
+	h.reply(*spec, fmt.Sprintf(
+		"⛔ Твой суточный лимит исчерпан: %d ссылок в сутки. Лимит обновится завтра.", quota,
+	))
+	return false
+}
+
+// quotaWarning возвращает предупреждение для подписи видео, если пользователь почти
+// израсходовал суточную квоту (тот же порог BANDWIDTH_WARN_PERCENT), иначе пустую строку
+func (h *Handler) quotaWarning(req *downloadRequest) string {
+	if !req.quotaTaken {
+		return ""
+	}
+	remaining, low := h.users.Low(req.userID)
+	if !low {
+		return ""
+	}
+	if remaining == 0 {
+		return "🎟 Это была последняя ссылка на сегодня: суточный лимит обновится завтра."
+	}
+	return fmt.Sprintf("🎟 Суточный лимит почти исчерпан: осталось ссылок на сегодня — %d.", remaining)
+}
+
+// refundQuota возвращает ссылку в суточную квоту, если запрос ее расходовал, но видео
+// не было отправлено: очередь переполнена, загрузка не удалась или отклонена лимитами
+func (h *Handler) refundQuota(spec requestSpec) {
+	if !spec.quotaTaken {
+		return
+	}
+	h.users.Refund(spec.userID)
+	h.logger.Debug("Daily quota refunded", slog.Int64("user_id", spec.userID))
+}
+
+// refundUndelivered возвращает ссылку в квоту, если задача завершилась без отправки
+// видео и без повтора. Задача, прерванная остановкой бота, будет продолжена после
+// запуска, поэтому квота остается учтенной
+func (h *Handler) refundUndelivered(req *downloadRequest) {
+	if req.baseCtx.Err() != nil || req.summary.settled() {
+		return
+	}
+	h.refundQuota(req.requestSpec)
+}
@@ -0,0 +1,73 @@
+package telegram
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"html"
+	"log/slog"
+	"net/http"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+
+	"github.com/reelser-bot/internal/buildinfo"
+)
+
+// healthPath адрес проверки состояния на сервере HEALTH_LISTEN
+const healthPath = "/healthz"
+
+// handleVersionCommand присылает версию бота и внешних утилит в JSON, чтобы ее
+// можно было целиком приложить к сообщению об ошибке
+func (h *Handler) handleVersionCommand(message *tgbotapi.Message) {
+	data, err := json.MarshalIndent(buildinfo.Get(), "", "  ")
+	if err != nil {
+		h.logger.Error("Failed to encode build info", slog.Any("error", err))
+		return
+	}
+	h.sendMessage(message.Chat.ID, "<pre>"+html.EscapeString(string(data))+"</pre>")
+}
+
+// healthResponse ответ проверки состояния. Сведений о сборке в нем нет: версии
+// утилит подсказали бы постороннему, какие уязвимости пробовать (они есть в /version)
+type healthResponse struct {
+	Status string `json:"status"`
+}
+
+// serveHealth запускает HTTP-сервер проверки состояния и останавливает его с ctx
+func (b *Bot) serveHealth(ctx context.Context) {
+	mux := http.NewServeMux()
+	mux.HandleFunc(healthPath, b.handleHealth)
+	server := &http.Server{
+		Addr:              b.healthListen,
+		Handler:           mux,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	stop := context.AfterFunc(ctx, func() {
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		server.Shutdown(shutdownCtx)
+	})
+	defer stop()
+
+	b.logger.Info("Serving health checks", slog.String("listen", b.healthListen), slog.String("path", healthPath))
+	if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		b.logger.Error("Health server stopped", slog.String("listen", b.healthListen), slog.Any("error", err))
+	}
+}
+
+// handleHealth отвечает 200, когда бот принимает апдейты, и 503 до прохождения
+// стартовых проверок
+func (b *Bot) handleHealth(w http.ResponseWriter, _ *http.Request) {
+	resp := healthResponse{Status: "ok"}
+	status := http.StatusOK
+	if !b.IsReady() {
+		resp.Status = "starting"
+		status = http.StatusServiceUnavailable
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(resp)
+}
@@ -0,0 +1,51 @@
+package telegram
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// videoPropsTimeout ограничивает ffprobe и создание превью перед отправкой
+const videoPropsTimeout = 30 * time.Second
+
+// addVideoProps добавляет в параметры sendVideo размеры и длительность видео, чтобы
+// клиенты показывали превью и полосу перемотки. Возвращает путь к превью (пусто — нет)
+func (h *Handler) addVideoProps(params tgbotapi.Params, filePath string) string {
+	ctx, cancel := context.WithTimeout(context.Background(), videoPropsTimeout)
+	defer cancel()
+
+	props := h.downloader.VideoProps(ctx, filePath)
+	params.AddNonZero("width", props.Width)
+	params.AddNonZero("height", props.Height)
+	params.AddNonZero("duration", int(props.Duration.Round(time.Second).Seconds()))
+	return props.Thumbnail
+}
+
+// videoFiles возвращает файлы запроса sendVideo: видео и превью, если оно есть
+func videoFiles(name string, r io.Reader, thumbnail string) []tgbotapi.RequestFile {
+	files := []tgbotapi.RequestFile{{
+		Name: "video",
+		Data: tgbotapi.FileReader{Name: name, Reader: r},
+	}}
+	if thumbnail != "" {
+		files = append(files, tgbotapi.RequestFile{
+			Name: "thumbnail",
+			Data: tgbotapi.FilePath(thumbnail),
+		})
+	}
+	return files
+}
+
+// removeThumbnail удаляет превью после отправки
+func (h *Handler) removeThumbnail(thumbnail string) {
+	if thumbnail == "" {
+		return
+	}
+	if err := h.downloader.Cleanup(thumbnail); err != nil {
+		h.logger.Warn("Failed to cleanup thumbnail", slog.String("file", thumbnail), slog.Any("error", err))
+	}
+}
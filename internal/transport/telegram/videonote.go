@@ -0,0 +1,89 @@
+package telegram
+
+import (
+	"context"
+	"fmt"
+	"html"
+	"io"
+	"log/slog"
+	"os"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+
+	"github.com/reelser-bot/internal/platform"
+	"github.com/reelser-bot/internal/services/downloader"
+)
+
+// sendVideoNote отправляет квадратное видео видеосообщением («кружком»)
+func (h *Handler) sendVideoNote(chatID int64, filePath string, maxAllowed int64) (*tgbotapi.Message, error) {
+	file, err := os.Open(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open file: %w", err)
+	}
+	defer file.Close()
+
+	fileInfo, err := file.Stat()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get file info: %w", err)
+	}
+	if fileInfo.Size() > maxAllowed {
+		return nil, fmt.Errorf("file size %d exceeds maximum allowed size %d", fileInfo.Size(), maxAllowed)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), videoPropsTimeout)
+	props := h.downloader.VideoProps(ctx, filePath)
+	cancel()
+	defer h.removeThumbnail(props.Thumbnail)
+
+	note := tgbotapi.NewVideoNote(chatID, downloader.VideoNoteSize, nil)
+	note.Duration = int(props.Duration.Round(time.Second).Seconds())
+	if props.Thumbnail != "" {
+		note.Thumb = tgbotapi.FilePath(props.Thumbnail)
+	}
+
+	h.logger.Info("Sending video note",
+		slog.Int64("chat_id", chatID),
+		slog.String("file", filePath),
+		slog.Int64("size", fileInfo.Size()),
+	)
+
+	var sent tgbotapi.Message
+	err = h.retryUpload(file, func(r io.Reader) error {
+		note.File = tgbotapi.FileReader{Name: fileInfo.Name(), Reader: r}
+
+		var err error
+		sent, err = h.bot.Send(note)
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to send video note: %w", err)
+	}
+
+	h.logger.Info("Video note sent successfully", slog.Int64("chat_id", chatID))
+	return &sent, nil
+}
+
+// convertToVideoNote готовит скачанное видео для /circle. Исходный файл удаляется;
+// при ошибке пользователь уже уведомлен
+func (h *Handler) convertToVideoNote(req *downloadRequest, filePath string) (string, bool) {
+	if platform.IsSlideshow(filePath) {
+		h.notify(req, "❌ Из фото-слайдшоу нельзя сделать видеосообщение.")
+		return "", false
+	}
+
+	note, err := h.downloader.ToVideoNote(req.phaseContext(), filePath)
+	if err != nil {
+		h.logger.Error("Failed to convert video to video note",
+			slog.String("file", filePath),
+			slog.Any("error", err),
+		)
+		h.recordFailure(req, err)
+		if h.notifyPhaseTimeout(req, err, "") {
+			return "", false
+		}
+		h.notify(req, "❌ Не удалось сделать видеосообщение: "+html.EscapeString(err.Error()))
+		return "", false
+	}
+	return note, true
+}
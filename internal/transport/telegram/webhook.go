@@ -0,0 +1,344 @@
+package telegram
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+
+	"github.com/reelser-bot/internal/services/shard"
+)
+
+const (
+	// webhookProbeTimeout таймаут проверки доступности вебхука перед возвратом на него
+	webhookProbeTimeout = 10 * time.Second
+	// webhookMaxBodySize ограничение размера тела запроса с апдейтом
+	webhookMaxBodySize = 1 << 20
+)
+
+// receiveUpdates возвращает канал апдейтов: через вебхук, если задан WEBHOOK_URL,
+// иначе через long polling
+func (b *Bot) receiveUpdates(ctx context.Context) <-chan incomingUpdate {
+	if b.webhook.URL == "" {
+		return b.pollUpdates(ctx)
+	}
+
+	ch := make(chan incomingUpdate, b.updateWorkers*2)
+	go b.runWebhook(ctx, ch)
+	return ch
+}
+
+// runWebhook принимает апдейты через вебхук и следит за его работой. Если Telegram
+// копит апдейты, а до бота они не доходят дольше WEBHOOK_STALL_TIMEOUT, бот удаляет
+// вебхук, переходит на long polling и сообщает администраторам. Когда адрес вебхука
+// снова доступен, бот возвращается на него
+func (b *Bot) runWebhook(ctx context.Context, ch chan<- incomingUpdate) {
+	webhookURL, err := url.Parse(b.webhook.URL)
+	if err != nil {
+		b.logger.Error("Invalid webhook URL, falling back to long polling", slog.Any("error", err))
+		b.poll(ctx, ch)
+		return
+	}
+
+	shutdown := b.serveWebhook(ctx, ch, webhookURL.Path)
+	defer shutdown()
+
+	if b.shards != nil {
+		go b.shards.Run(ctx)
+	}
+
+	receiver := &webhookReceiver{bot: b, ch: ch}
+	defer receiver.stop()
+	receiver.start(ctx)
+
+	ticker := time.NewTicker(b.webhook.CheckInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		receiver.check(ctx)
+	}
+}
+
+// serveWebhook запускает HTTP-сервер вебхука и возвращает функцию его остановки
+func (b *Bot) serveWebhook(ctx context.Context, ch chan<- incomingUpdate, path string) func() {
+	if path == "" {
+		path = "/"
+	}
+	mux := http.NewServeMux()
+	mux.HandleFunc(path, b.handleWebhook(ctx, ch))
+
+	server := &http.Server{
+		Addr:              b.webhook.Listen,
+		Handler:           mux,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	go func() {
+		if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			b.logger.Error("Webhook server stopped", slog.String("listen", b.webhook.Listen), slog.Any("error", err))
+		}
+	}()
+
+	return func() {
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		server.Shutdown(shutdownCtx)
+	}
+}
+
+// webhookReceiver переключает получение апдейтов между вебхуком и long polling
+type webhookReceiver struct {
+	bot *Bot
+	ch  chan<- incomingUpdate
+
+	// set вебхук установлен; с шардированием он переустанавливается на каждой проверке
+	set bool
+	// stopPolling != nil — вебхук не работает и апдейты получаются через long polling
+	stopPolling context.CancelFunc
+}
+
+// start устанавливает вебхук. С шардированием long polling недоступен: getUpdates
+// забирал бы апдейты у всех экземпляров сразу
+func (r *webhookReceiver) start(ctx context.Context) {
+	b := r.bot
+	b.lastWebhookUpdate.Store(time.Now().UnixNano())
+
+	err := b.setWebhook()
+	switch {
+	case err != nil && b.shards != nil:
+		b.logger.Error("Failed to set webhook, retrying on the next check", slog.Any("error", err))
+	case err != nil:
+		b.logger.Error("Failed to set webhook, falling back to long polling", slog.Any("error", err))
+		r.stopPolling = b.startPolling(ctx, r.ch)
+	default:
+		r.set = true
+		b.logger.Info("Receiving updates via webhook",
+			slog.String("url", b.webhook.URL),
+			slog.String("listen", b.webhook.Listen),
+		)
+	}
+}
+
+// check выполняет периодическую проверку вебхука
+func (r *webhookReceiver) check(ctx context.Context) {
+	switch {
+	case r.bot.shards != nil:
+		r.ensureSet()
+	case r.stopPolling == nil:
+		r.fallBackIfStalled(ctx)
+	default:
+		r.recoverIfReachable(ctx)
+	}
+}
+
+// stop останавливает long polling, если он запущен
+func (r *webhookReceiver) stop() {
+	if r.stopPolling != nil {
+		r.stopPolling()
+		r.stopPolling = nil
+	}
+}
+
+// ensureSet повторяет установку вебхука, если она не удалась (режим шардирования)
+func (r *webhookReceiver) ensureSet() {
+	if r.set {
+		return
+	}
+	b := r.bot
+	if err := b.setWebhook(); err != nil {
+		b.logger.Error("Failed to set webhook", slog.Any("error", err))
+		return
+	}
+	r.set = true
+	b.logger.Info("Receiving updates via webhook", slog.String("url", b.webhook.URL))
+}
+
+// fallBackIfStalled переходит на long polling, если вебхук перестал получать апдейты
+func (r *webhookReceiver) fallBackIfStalled(ctx context.Context) {
+	b := r.bot
+	if !b.webhookStalled() {
+		return
+	}
+	if err := b.deleteWebhook(); err != nil {
+		b.logger.Error("Failed to delete stalled webhook", slog.Any("error", err))
+		return
+	}
+
+	r.stopPolling = b.startPolling(ctx, r.ch)
+	b.logger.Warn("Webhook stalled, switched to long polling")
+	b.handler.notifyAdmins(fmt.Sprintf(
+		"⚠️ Вебхук не получает апдейты больше %s, хотя в Telegram есть необработанные. "+
+			"Бот переключился на long polling и вернется на вебхук, когда он станет доступен.",
+		formatAge(b.webhook.StallTimeout),
+	))
+}
+
+// recoverIfReachable возвращается с long polling на вебхук, когда его адрес снова отвечает
+func (r *webhookReceiver) recoverIfReachable(ctx context.Context) {
+	b := r.bot
+	if !b.webhookReachable(ctx) {
+		return
+	}
+	r.stop()
+
+	b.lastWebhookUpdate.Store(time.Now().UnixNano())
+	if err := b.setWebhook(); err != nil {
+		b.logger.Error("Failed to restore webhook", slog.Any("error", err))
+		r.stopPolling = b.startPolling(ctx, r.ch)
+		return
+	}
+
+	b.logger.Info("Webhook recovered, switched back from long polling")
+	b.handler.notifyAdmins("✅ Вебхук снова доступен, бот вернулся на получение апдейтов через вебхук.")
+}
+
+// handleWebhook принимает апдейты от Telegram и других экземпляров при шардировании.
+// GET-запросы используются как проверка доступности
+func (b *Bot) handleWebhook(ctx context.Context, ch chan<- incomingUpdate) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+			w.WriteHeader(http.StatusOK)
+			return
+		case http.MethodPost:
+		default:
+			w.WriteHeader(http.StatusMethodNotAllowed)
+			return
+		}
+
+		token := r.Header.Get("X-Telegram-Bot-Api-Secret-Token")
+		if b.webhook.Secret == "" || subtle.ConstantTimeCompare([]byte(token), []byte(b.webhook.Secret)) != 1 {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, webhookMaxBodySize))
+		if err != nil {
+			b.logger.Warn("Failed to read webhook update", slog.Any("error", err))
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		var update incomingUpdate
+		if err := json.Unmarshal(body, &update); err != nil {
+			b.logger.Warn("Failed to decode webhook update", slog.Any("error", err))
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		b.lastWebhookUpdate.Store(time.Now().UnixNano())
+
+		// Пересланный апдейт уже отмечен экземпляром, получившим его от Telegram;
+		// порядок update_id между экземплярами не гарантирован, поэтому он не сверяется.
+		// Заголовок сам по себе ничего не доказывает: принимается только подписанный апдейт
+		if r.Header.Get(shardForwardHeader) != "" {
+			if b.shards == nil || !shard.Verify(b.webhook.Sharding.Secret, body, r.Header.Get(shardSignatureHeader)) {
+				b.logger.Warn("Rejected forwarded update with invalid signature",
+					slog.String("from", r.Header.Get(shardForwardHeader)),
+				)
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			select {
+			case <-ctx.Done():
+			case ch <- update:
+			}
+			w.WriteHeader(http.StatusOK)
+			return
+		}
+
+		if b.markUpdate(update.UpdateID) {
+			if b.forwardUpdate(r.Context(), update, body) {
+				b.finishUpdate(update.UpdateID)
+			} else {
+				select {
+				case <-ctx.Done():
+				case ch <- update:
+				}
+			}
+		}
+		w.WriteHeader(http.StatusOK)
+	}
+}
+
+// startPolling запускает long polling в ch и возвращает функцию его остановки
+func (b *Bot) startPolling(ctx context.Context, ch chan<- incomingUpdate) context.CancelFunc {
+	pollCtx, cancel := context.WithCancel(ctx)
+	go b.poll(pollCtx, ch)
+	return cancel
+}
+
+// webhookStalled проверяет через getWebhookInfo, что у Telegram есть необработанные
+// апдейты, а через вебхук ничего не приходило дольше WEBHOOK_STALL_TIMEOUT
+func (b *Bot) webhookStalled() bool {
+	info, err := b.api.GetWebhookInfo()
+	if err != nil {
+		b.logger.Warn("Failed to get webhook info", slog.Any("error", err))
+		return false
+	}
+	if info.PendingUpdateCount == 0 {
+		return false
+	}
+
+	idle := time.Since(time.Unix(0, b.lastWebhookUpdate.Load()))
+	if idle < b.webhook.StallTimeout {
+		return false
+	}
+
+	b.logger.Warn("Webhook has pending updates but delivers nothing",
+		slog.Int("pending_updates", info.PendingUpdateCount),
+		slog.Duration("idle", idle.Round(time.Second)),
+		slog.String("last_error", info.LastErrorMessage),
+	)
+	return true
+}
+
+// webhookReachable проверяет, что адрес вебхука снова отвечает
+func (b *Bot) webhookReachable(ctx context.Context) bool {
+	probeCtx, cancel := context.WithTimeout(ctx, webhookProbeTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(probeCtx, http.MethodGet, b.webhook.URL, nil)
+	if err != nil {
+		return false
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		b.logger.Debug("Webhook is still unreachable", slog.Any("error", err))
+		return false
+	}
+	resp.Body.Close()
+
+	return resp.StatusCode == http.StatusOK
+}
+
+// setWebhook регистрирует вебхук в Telegram. Необработанные апдейты не сбрасываются
+func (b *Bot) setWebhook() error {
+	params := tgbotapi.Params{}
+	params.AddNonEmpty("url", b.webhook.URL)
+	params.AddNonEmpty("secret_token", b.webhook.Secret)
+
+	if _, err := b.api.MakeRequest("setWebhook", params); err != nil {
+		return fmt.Errorf("setWebhook failed: %w", err)
+	}
+	return nil
+}
+
+// deleteWebhook удаляет вебхук, чтобы getUpdates снова работал; апдейты сохраняются
+func (b *Bot) deleteWebhook() error {
+	if _, err := b.api.Request(tgbotapi.DeleteWebhookConfig{}); err != nil {
+		return fmt.Errorf("deleteWebhook failed: %w", err)
+	}
+	return nil
+}